@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/cloudformation-broker/admincli"
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+	"github.com/cf-platform-eng/cloudformation-broker/awsecr"
+	"github.com/cf-platform-eng/cloudformation-broker/awss3"
+	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+	"github.com/cf-platform-eng/cloudformation-broker/redact"
+)
+
+const adminCommand = "admin"
+
+// runAdmin runs an admin command directly against the stacks of the broker,
+// so that it can be used when the broker itself is down. Only errors are
+// logged, to stderr.
+func runAdmin(config *Config, args []string) int {
+	redactor := redact.New(config.CloudFormationConfig.Catalog.SensitiveNames()...)
+
+	logger := lager.NewLogger("cloudformation-broker-admin")
+	logger.RegisterSink(redact.NewSink(lager.NewWriterSink(os.Stderr, lager.ERROR), redactor))
+
+	awsSession := session.New(aws.NewConfig().WithRegion(config.CloudFormationConfig.Region))
+
+	stack := awscf.NewCloudFormationStack(cloudformation.New(awsSession), logger)
+	addNoEchoParameters(redactor, config.CloudFormationConfig.Catalog, stack, logger)
+
+	bucket := awss3.NewS3Bucket(awss3.New(awsSession), logger)
+	repository := awsecr.NewECRRepository(awsecr.New(awsSession), logger)
+	serviceBroker := cfbroker.New(config.CloudFormationConfig, stack, bucket, repository, logger)
+
+	if err := admincli.New(serviceBroker, redactor, os.Stdout).Run(args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
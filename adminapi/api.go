@@ -0,0 +1,130 @@
+package adminapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/frodenas/brokerapi/auth"
+	"github.com/gorilla/mux"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+)
+
+const cancelUpdateLogKey = "cancel-update"
+const retryDeleteLogKey = "retry-delete"
+const continueRollbackLogKey = "continue-rollback"
+const retagLogKey = "retag"
+const importInstanceLogKey = "import-instance"
+
+const instanceIDLogKey = "instance-id"
+
+const instanceMissingErrorKey = "instance-missing"
+const invalidStackStatusErrorKey = "invalid-stack-status"
+const stackNotOwnedErrorKey = "stack-not-owned"
+const instanceAlreadyExistsErrorKey = "instance-already-exists"
+const invalidImportErrorKey = "invalid-import"
+const unknownErrorKey = "unknown-error"
+const invalidRequestErrorKey = "invalid-request"
+
+// AdminBroker holds the operator actions that are not part of the Service
+// Broker API.
+type AdminBroker interface {
+	Instances() ([]cfbroker.InstanceSummary, error)
+	Instance(instanceID string) (cfbroker.InstanceDetails, error)
+	CancelUpdate(instanceID string) error
+	RetryDelete(instanceID string, resourcesToRetain []string) error
+	ContinueUpdateRollback(instanceID string, resourcesToSkip []string) error
+	Retag(instanceID string, tags map[string]string) error
+	ImportInstance(instanceID string, stackName string, serviceID string, planID string) error
+}
+
+type OperationResponse struct {
+	Description string `json:"description"`
+}
+
+func New(adminBroker AdminBroker, logLevels LogLevelController, drifts DriftDetector, logger lager.Logger, brokerCredentials brokerapi.BrokerCredentials) http.Handler {
+	router := mux.NewRouter()
+
+	router.HandleFunc("/admin/service_instances", listInstances(adminBroker, logger)).Methods("GET")
+	router.HandleFunc("/admin/service_instances/{instance_id}", showInstance(adminBroker, logger)).Methods("GET")
+	router.HandleFunc("/admin/service_instances/{instance_id}/cancel_update", cancelUpdate(adminBroker, logger)).Methods("POST")
+	router.HandleFunc("/admin/service_instances/{instance_id}/retry_delete", retryDelete(adminBroker, logger)).Methods("POST")
+	router.HandleFunc("/admin/service_instances/{instance_id}/continue_rollback", continueRollback(adminBroker, logger)).Methods("POST")
+	router.HandleFunc("/admin/service_instances/{instance_id}/tags", retag(adminBroker, logger)).Methods("PUT")
+	router.HandleFunc("/admin/service_instances/{instance_id}/import", importInstance(adminBroker, logger)).Methods("POST")
+	router.HandleFunc("/admin/service_instances/{instance_id}/drift", showDrift(drifts)).Methods("GET")
+	router.HandleFunc("/admin/service_instances/{instance_id}/drift", detectDrift(drifts, logger)).Methods("POST")
+	router.HandleFunc("/admin/log_level", getLogLevel(logLevels)).Methods("GET")
+	router.HandleFunc("/admin/log_level", setLogLevel(logLevels, logger)).Methods("PUT")
+	router.HandleFunc("/admin/log_level", resetLogLevel(logLevels, logger)).Methods("DELETE")
+
+	return auth.NewWrapper(brokerCredentials.Username, brokerCredentials.Password).Wrap(router)
+}
+
+// respondError maps the errors of the admin broker to a status code.
+func respondError(w http.ResponseWriter, logger lager.Logger, err error) {
+	if err == brokerapi.ErrInstanceDoesNotExist {
+		logger.Error(instanceMissingErrorKey, err)
+		respond(w, http.StatusNotFound, brokerapi.ErrorResponse{
+			Description: err.Error(),
+		})
+		return
+	}
+
+	if _, ok := err.(cfbroker.StackStatusError); ok {
+		logger.Error(invalidStackStatusErrorKey, err)
+		respond(w, http.StatusConflict, brokerapi.ErrorResponse{
+			Description: err.Error(),
+		})
+		return
+	}
+
+	if err == brokerapi.ErrInstanceAlreadyExists {
+		logger.Error(instanceAlreadyExistsErrorKey, err)
+		respond(w, http.StatusConflict, brokerapi.ErrorResponse{
+			Description: err.Error(),
+		})
+		return
+	}
+
+	if _, ok := err.(cfbroker.ImportError); ok {
+		logger.Error(invalidImportErrorKey, err)
+		respond(w, 422, brokerapi.ErrorResponse{
+			Description: err.Error(),
+		})
+		return
+	}
+
+	if _, ok := err.(cfbroker.OwnershipError); ok {
+		logger.Error(stackNotOwnedErrorKey, err)
+		respond(w, http.StatusConflict, brokerapi.ErrorResponse{
+			Description: err.Error(),
+		})
+		return
+	}
+
+	logger.Error(unknownErrorKey, err)
+	respond(w, http.StatusInternalServerError, brokerapi.ErrorResponse{
+		Description: err.Error(),
+	})
+}
+
+// decodeRequest decodes the JSON body of a request, which may be empty.
+func decodeRequest(req *http.Request, request interface{}) error {
+	if err := json.NewDecoder(req.Body).Decode(request); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+func respond(w http.ResponseWriter, status int, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(response)
+}
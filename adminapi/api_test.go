@@ -0,0 +1,537 @@
+package adminapi_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/adminapi"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/cf-platform-eng/cloudformation-broker/adminapi/fakes"
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+	"github.com/cf-platform-eng/cloudformation-broker/drift"
+	"github.com/cf-platform-eng/cloudformation-broker/logging"
+)
+
+var _ = Describe("Admin API", func() {
+	var (
+		adminAPI    http.Handler
+		adminBroker *fakes.FakeAdminBroker
+		logLevels   *fakes.FakeLogLevelController
+		drifts      *fakes.FakeDriftDetector
+		credentials = brokerapi.BrokerCredentials{
+			Username: "username",
+			Password: "password",
+		}
+	)
+
+	BeforeEach(func() {
+		adminBroker = &fakes.FakeAdminBroker{}
+		logLevels = &fakes.FakeLogLevelController{}
+		drifts = &fakes.FakeDriftDetector{}
+		adminAPI = New(adminBroker, logLevels, drifts, lagertest.NewTestLogger("admin-api"), credentials)
+	})
+
+	makeRequestWithBody := func(method string, path string, username string, password string, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(method, path, strings.NewReader(body))
+		request.SetBasicAuth(username, password)
+		adminAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	makeRequest := func(method string, path string, username string, password string) *httptest.ResponseRecorder {
+		return makeRequestWithBody(method, path, username, password, "")
+	}
+
+	Describe("cancel update", func() {
+		path := "/admin/service_instances/instance-id/cancel_update"
+
+		It("cancels the update of the instance", func() {
+			response := makeRequest("POST", path, credentials.Username, credentials.Password)
+			Expect(response.Code).To(Equal(http.StatusAccepted))
+			Expect(response.Header().Get("Content-Type")).To(Equal("application/json"))
+			Expect(adminBroker.CancelUpdateCalled).To(BeTrue())
+			Expect(adminBroker.CancelUpdateInstanceID).To(Equal("instance-id"))
+
+			operationResponse := OperationResponse{}
+			Expect(json.Unmarshal(response.Body.Bytes(), &operationResponse)).To(Succeed())
+			Expect(operationResponse.Description).To(Equal("Cancelling the update of the service instance"))
+		})
+
+		It("requires the broker credentials", func() {
+			response := makeRequest("POST", path, credentials.Username, "wrong-password")
+			Expect(response.Code).To(Equal(http.StatusUnauthorized))
+			Expect(adminBroker.CancelUpdateCalled).To(BeFalse())
+		})
+
+		It("only accepts POST requests", func() {
+			response := makeRequest("GET", path, credentials.Username, credentials.Password)
+			Expect(response.Code).To(Equal(http.StatusNotFound))
+			Expect(adminBroker.CancelUpdateCalled).To(BeFalse())
+		})
+
+		Context("when the instance does not exist", func() {
+			BeforeEach(func() {
+				adminBroker.CancelUpdateError = brokerapi.ErrInstanceDoesNotExist
+			})
+
+			It("returns a 404", func() {
+				response := makeRequest("POST", path, credentials.Username, credentials.Password)
+				Expect(response.Code).To(Equal(http.StatusNotFound))
+
+				errorResponse := brokerapi.ErrorResponse{}
+				Expect(json.Unmarshal(response.Body.Bytes(), &errorResponse)).To(Succeed())
+				Expect(errorResponse.Description).To(Equal(brokerapi.ErrInstanceDoesNotExist.Error()))
+			})
+		})
+
+		Context("when cancelling the update fails", func() {
+			BeforeEach(func() {
+				adminBroker.CancelUpdateError = errors.New("operation failed")
+			})
+
+			It("returns a 500", func() {
+				response := makeRequest("POST", path, credentials.Username, credentials.Password)
+				Expect(response.Code).To(Equal(http.StatusInternalServerError))
+
+				errorResponse := brokerapi.ErrorResponse{}
+				Expect(json.Unmarshal(response.Body.Bytes(), &errorResponse)).To(Succeed())
+				Expect(errorResponse.Description).To(Equal("operation failed"))
+			})
+		})
+	})
+
+	Describe("list instances", func() {
+		path := "/admin/service_instances"
+
+		BeforeEach(func() {
+			adminBroker.InstancesInstances = []cfbroker.InstanceSummary{
+				cfbroker.InstanceSummary{
+					InstanceID:   "instance-id",
+					StackName:    "cfbroker-instance-id",
+					StackID:      "stack-id",
+					StackStatus:  awscf.Status{Raw: "UPDATE_COMPLETE"},
+					CreationTime: time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC),
+				},
+			}
+		})
+
+		It("returns the instances of the broker", func() {
+			response := makeRequest("GET", path, credentials.Username, credentials.Password)
+			Expect(response.Code).To(Equal(http.StatusOK))
+			Expect(adminBroker.InstancesCalled).To(BeTrue())
+
+			instancesResponse := InstancesResponse{}
+			Expect(json.Unmarshal(response.Body.Bytes(), &instancesResponse)).To(Succeed())
+			Expect(instancesResponse.Instances).To(HaveLen(1))
+			Expect(instancesResponse.Instances[0].InstanceID).To(Equal("instance-id"))
+			Expect(instancesResponse.Instances[0].StackName).To(Equal("cfbroker-instance-id"))
+			Expect(instancesResponse.Instances[0].StackID).To(Equal("stack-id"))
+			Expect(instancesResponse.Instances[0].StackStatus).To(Equal("UPDATE_COMPLETE"))
+		})
+
+		It("requires the broker credentials", func() {
+			response := makeRequest("GET", path, credentials.Username, "wrong-password")
+			Expect(response.Code).To(Equal(http.StatusUnauthorized))
+			Expect(adminBroker.InstancesCalled).To(BeFalse())
+		})
+
+		Context("when listing the instances fails", func() {
+			BeforeEach(func() {
+				adminBroker.InstancesError = errors.New("operation failed")
+			})
+
+			It("returns a 500", func() {
+				response := makeRequest("GET", path, credentials.Username, credentials.Password)
+				Expect(response.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+
+	Describe("show instance", func() {
+		path := "/admin/service_instances/instance-id"
+
+		BeforeEach(func() {
+			adminBroker.InstanceDetails = cfbroker.InstanceDetails{
+				InstanceID:   "instance-id",
+				StackName:    "cfbroker-instance-id",
+				StackID:      "stack-id",
+				StackStatus:  awscf.Status{Raw: "UPDATE_ROLLBACK_FAILED", Reason: "rollback failed"},
+				Parameters:   map[string]string{"key": "value"},
+				Outputs:      map[string]string{"output": "value"},
+				Tags:         map[string]string{"tag": "value"},
+				CreationTime: time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC),
+				Events: []awscf.StackEvent{
+					awscf.StackEvent{
+						EventID:           "event-id",
+						LogicalResourceID: "Bucket",
+						ResourceType:      "AWS::S3::Bucket",
+						ResourceStatus:    "UPDATE_FAILED",
+					},
+				},
+				Template: "{}",
+			}
+		})
+
+		It("returns the details of the instance", func() {
+			response := makeRequest("GET", path, credentials.Username, credentials.Password)
+			Expect(response.Code).To(Equal(http.StatusOK))
+			Expect(adminBroker.InstanceInstanceID).To(Equal("instance-id"))
+
+			instanceResponse := InstanceResponse{}
+			Expect(json.Unmarshal(response.Body.Bytes(), &instanceResponse)).To(Succeed())
+			Expect(instanceResponse.StackStatus).To(Equal("UPDATE_ROLLBACK_FAILED"))
+			Expect(instanceResponse.StackStatusReason).To(Equal("rollback failed"))
+			Expect(instanceResponse.Parameters).To(Equal(map[string]string{"key": "value"}))
+			Expect(instanceResponse.Outputs).To(Equal(map[string]string{"output": "value"}))
+			Expect(instanceResponse.Tags).To(Equal(map[string]string{"tag": "value"}))
+			Expect(instanceResponse.LastUpdatedTime).To(BeNil())
+			Expect(instanceResponse.Events).To(HaveLen(1))
+			Expect(instanceResponse.Events[0].LogicalResourceID).To(Equal("Bucket"))
+			Expect(instanceResponse.Events[0].ResourceStatus).To(Equal("UPDATE_FAILED"))
+			Expect(instanceResponse.Template).To(Equal("{}"))
+		})
+
+		Context("when the instance does not exist", func() {
+			BeforeEach(func() {
+				adminBroker.InstanceError = brokerapi.ErrInstanceDoesNotExist
+			})
+
+			It("returns a 404", func() {
+				response := makeRequest("GET", path, credentials.Username, credentials.Password)
+				Expect(response.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+
+	Describe("retry delete", func() {
+		path := "/admin/service_instances/instance-id/retry_delete"
+
+		It("deletes the instance again, retaining the requested resources", func() {
+			response := makeRequestWithBody("POST", path, credentials.Username, credentials.Password, `{"resources_to_retain": ["Bucket"]}`)
+			Expect(response.Code).To(Equal(http.StatusAccepted))
+			Expect(adminBroker.RetryDeleteInstanceID).To(Equal("instance-id"))
+			Expect(adminBroker.RetryDeleteResourcesToRetain).To(Equal([]string{"Bucket"}))
+		})
+
+		It("accepts an empty body", func() {
+			response := makeRequest("POST", path, credentials.Username, credentials.Password)
+			Expect(response.Code).To(Equal(http.StatusAccepted))
+			Expect(adminBroker.RetryDeleteCalled).To(BeTrue())
+			Expect(adminBroker.RetryDeleteResourcesToRetain).To(BeEmpty())
+		})
+
+		It("returns a 400 if the request is not valid JSON", func() {
+			response := makeRequestWithBody("POST", path, credentials.Username, credentials.Password, "{")
+			Expect(response.Code).To(Equal(http.StatusBadRequest))
+			Expect(adminBroker.RetryDeleteCalled).To(BeFalse())
+		})
+
+		Context("when the stack status does not allow it", func() {
+			BeforeEach(func() {
+				adminBroker.RetryDeleteError = cfbroker.StackStatusError{
+					StackName:   "cfbroker-instance-id",
+					StackStatus: "CREATE_COMPLETE",
+					Action:      "deleted again",
+				}
+			})
+
+			It("returns a 409", func() {
+				response := makeRequest("POST", path, credentials.Username, credentials.Password)
+				Expect(response.Code).To(Equal(http.StatusConflict))
+
+				errorResponse := brokerapi.ErrorResponse{}
+				Expect(json.Unmarshal(response.Body.Bytes(), &errorResponse)).To(Succeed())
+				Expect(errorResponse.Description).To(Equal("Stack 'cfbroker-instance-id' status is 'CREATE_COMPLETE', it can not be deleted again"))
+			})
+		})
+
+		Context("when the stack is not owned by the broker", func() {
+			BeforeEach(func() {
+				adminBroker.RetryDeleteError = cfbroker.OwnershipError{
+					StackName: "cfbroker-instance-id",
+					Reason:    "it was created by broker 'other-broker-id'",
+				}
+			})
+
+			It("returns a 409", func() {
+				response := makeRequest("POST", path, credentials.Username, credentials.Password)
+				Expect(response.Code).To(Equal(http.StatusConflict))
+
+				errorResponse := brokerapi.ErrorResponse{}
+				Expect(json.Unmarshal(response.Body.Bytes(), &errorResponse)).To(Succeed())
+				Expect(errorResponse.Description).To(Equal("Stack 'cfbroker-instance-id' is not owned by the broker, it was created by broker 'other-broker-id'"))
+			})
+		})
+	})
+
+	Describe("continue rollback", func() {
+		path := "/admin/service_instances/instance-id/continue_rollback"
+
+		It("continues the rollback of the instance, skipping the requested resources", func() {
+			response := makeRequestWithBody("POST", path, credentials.Username, credentials.Password, `{"resources_to_skip": ["Bucket"]}`)
+			Expect(response.Code).To(Equal(http.StatusAccepted))
+			Expect(adminBroker.ContinueUpdateRollbackInstanceID).To(Equal("instance-id"))
+			Expect(adminBroker.ContinueUpdateRollbackResourcesToSkip).To(Equal([]string{"Bucket"}))
+		})
+
+		It("returns a 400 if the request is not valid JSON", func() {
+			response := makeRequestWithBody("POST", path, credentials.Username, credentials.Password, "{")
+			Expect(response.Code).To(Equal(http.StatusBadRequest))
+			Expect(adminBroker.ContinueUpdateRollbackCalled).To(BeFalse())
+		})
+
+		Context("when the instance does not exist", func() {
+			BeforeEach(func() {
+				adminBroker.ContinueUpdateRollbackError = brokerapi.ErrInstanceDoesNotExist
+			})
+
+			It("returns a 404", func() {
+				response := makeRequest("POST", path, credentials.Username, credentials.Password)
+				Expect(response.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+
+	Describe("retag", func() {
+		path := "/admin/service_instances/instance-id/tags"
+
+		It("updates the tags of the instance", func() {
+			response := makeRequestWithBody("PUT", path, credentials.Username, credentials.Password, `{"tags": {"cost-center": "1234"}}`)
+			Expect(response.Code).To(Equal(http.StatusAccepted))
+			Expect(adminBroker.RetagInstanceID).To(Equal("instance-id"))
+			Expect(adminBroker.RetagTags).To(Equal(map[string]string{"cost-center": "1234"}))
+		})
+
+		It("returns a 400 if there are no tags", func() {
+			response := makeRequestWithBody("PUT", path, credentials.Username, credentials.Password, `{"tags": {}}`)
+			Expect(response.Code).To(Equal(http.StatusBadRequest))
+			Expect(adminBroker.RetagCalled).To(BeFalse())
+		})
+
+		It("returns a 400 if the request is not valid JSON", func() {
+			response := makeRequestWithBody("PUT", path, credentials.Username, credentials.Password, "{")
+			Expect(response.Code).To(Equal(http.StatusBadRequest))
+			Expect(adminBroker.RetagCalled).To(BeFalse())
+		})
+
+		Context("when updating the tags fails", func() {
+			BeforeEach(func() {
+				adminBroker.RetagError = errors.New("operation failed")
+			})
+
+			It("returns a 500", func() {
+				response := makeRequestWithBody("PUT", path, credentials.Username, credentials.Password, `{"tags": {"cost-center": "1234"}}`)
+				Expect(response.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+
+	Describe("import", func() {
+		path := "/admin/service_instances/instance-id/import"
+		body := `{"stack_name": "legacy-stack", "service_id": "service-id", "plan_id": "plan-id"}`
+
+		It("imports the stack as the instance", func() {
+			response := makeRequestWithBody("POST", path, credentials.Username, credentials.Password, body)
+			Expect(response.Code).To(Equal(http.StatusOK))
+			Expect(adminBroker.ImportInstanceInstanceID).To(Equal("instance-id"))
+			Expect(adminBroker.ImportInstanceStackName).To(Equal("legacy-stack"))
+			Expect(adminBroker.ImportInstanceServiceID).To(Equal("service-id"))
+			Expect(adminBroker.ImportInstancePlanID).To(Equal("plan-id"))
+		})
+
+		It("returns a 400 if the stack name is missing", func() {
+			response := makeRequestWithBody("POST", path, credentials.Username, credentials.Password, `{"service_id": "service-id", "plan_id": "plan-id"}`)
+			Expect(response.Code).To(Equal(http.StatusBadRequest))
+			Expect(adminBroker.ImportInstanceCalled).To(BeFalse())
+		})
+
+		It("returns a 400 if the request is not valid JSON", func() {
+			response := makeRequestWithBody("POST", path, credentials.Username, credentials.Password, "{")
+			Expect(response.Code).To(Equal(http.StatusBadRequest))
+			Expect(adminBroker.ImportInstanceCalled).To(BeFalse())
+		})
+
+		Context("when the stack can not be imported", func() {
+			BeforeEach(func() {
+				adminBroker.ImportInstanceError = cfbroker.ImportError{StackName: "legacy-stack", Reason: "it does not exist"}
+			})
+
+			It("returns a 422", func() {
+				response := makeRequestWithBody("POST", path, credentials.Username, credentials.Password, body)
+				Expect(response.Code).To(Equal(422))
+
+				errorResponse := brokerapi.ErrorResponse{}
+				Expect(json.Unmarshal(response.Body.Bytes(), &errorResponse)).To(Succeed())
+				Expect(errorResponse.Description).To(Equal("Stack 'legacy-stack' can not be imported, it does not exist"))
+			})
+		})
+
+		Context("when the instance already exists", func() {
+			BeforeEach(func() {
+				adminBroker.ImportInstanceError = brokerapi.ErrInstanceAlreadyExists
+			})
+
+			It("returns a 409", func() {
+				response := makeRequestWithBody("POST", path, credentials.Username, credentials.Password, body)
+				Expect(response.Code).To(Equal(http.StatusConflict))
+			})
+		})
+	})
+
+	Describe("drift", func() {
+		path := "/admin/service_instances/instance-id/drift"
+
+		It("returns the last drift detection of the instance", func() {
+			drifts.ResultFound = true
+			drifts.ResultResult = drift.Result{
+				InstanceID:       "instance-id",
+				StackName:        "cfbroker-instance-id",
+				DetectionStatus:  "DETECTION_COMPLETE",
+				StackDriftStatus: "DRIFTED",
+				DriftedResources: []awscf.ResourceDrift{
+					awscf.ResourceDrift{
+						LogicalResourceID: "Bucket",
+						DriftStatus:       "MODIFIED",
+						PropertyDifferences: []awscf.PropertyDifference{
+							awscf.PropertyDifference{PropertyPath: "/VersioningConfiguration/Status", ExpectedValue: "Enabled", ActualValue: "Suspended", DifferenceType: "NOT_EQUAL"},
+						},
+					},
+				},
+			}
+
+			response := makeRequest("GET", path, credentials.Username, credentials.Password)
+			Expect(response.Code).To(Equal(http.StatusOK))
+			Expect(drifts.ResultInstanceID).To(Equal("instance-id"))
+
+			driftResponse := DriftResponse{}
+			Expect(json.Unmarshal(response.Body.Bytes(), &driftResponse)).To(Succeed())
+			Expect(driftResponse.StackDriftStatus).To(Equal("DRIFTED"))
+			Expect(driftResponse.DriftedResources).To(HaveLen(1))
+			Expect(driftResponse.DriftedResources[0].LogicalResourceID).To(Equal("Bucket"))
+			Expect(driftResponse.DriftedResources[0].PropertyDifferences[0].ActualValue).To(Equal("Suspended"))
+		})
+
+		It("returns a 404 if the drift of the instance is not known", func() {
+			response := makeRequest("GET", path, credentials.Username, credentials.Password)
+			Expect(response.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("starts detecting the drift of the instance", func() {
+			response := makeRequest("POST", path, credentials.Username, credentials.Password)
+			Expect(response.Code).To(Equal(http.StatusAccepted))
+			Expect(drifts.StartInstanceID).To(Equal("instance-id"))
+		})
+
+		Context("when the instance does not exist", func() {
+			BeforeEach(func() {
+				drifts.StartError = brokerapi.ErrInstanceDoesNotExist
+			})
+
+			It("returns a 404", func() {
+				response := makeRequest("POST", path, credentials.Username, credentials.Password)
+				Expect(response.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+
+	Describe("log level", func() {
+		path := "/admin/log_level"
+
+		var expiresAt time.Time
+
+		BeforeEach(func() {
+			expiresAt = time.Date(2016, time.March, 1, 10, 30, 0, 0, time.UTC)
+			logLevels.LevelsLevels = logging.Levels{
+				Sinks: []logging.SinkLevel{
+					{Name: "stdout", Level: "DEBUG", ConfiguredLevel: "INFO"},
+				},
+				OverrideLevel:     "DEBUG",
+				OverrideExpiresAt: expiresAt,
+			}
+		})
+
+		decodeLogLevelResponse := func(response *httptest.ResponseRecorder) LogLevelResponse {
+			logLevelResponse := LogLevelResponse{}
+			Expect(json.Unmarshal(response.Body.Bytes(), &logLevelResponse)).To(Succeed())
+			return logLevelResponse
+		}
+
+		It("returns the log levels of the sinks", func() {
+			response := makeRequest("GET", path, credentials.Username, credentials.Password)
+			Expect(response.Code).To(Equal(http.StatusOK))
+			Expect(decodeLogLevelResponse(response)).To(Equal(LogLevelResponse{
+				Sinks: []SinkLevelResponse{
+					{Name: "stdout", Level: "DEBUG", ConfiguredLevel: "INFO"},
+				},
+				OverrideLevel:     "DEBUG",
+				OverrideExpiresAt: &expiresAt,
+			}))
+		})
+
+		It("overrides the log level for the requested duration", func() {
+			response := makeRequestWithBody("PUT", path, credentials.Username, credentials.Password, `{"level":"debug","duration_in_minutes":30}`)
+			Expect(response.Code).To(Equal(http.StatusOK))
+			Expect(logLevels.SetLevelLevel).To(Equal("debug"))
+			Expect(logLevels.SetLevelDuration).To(Equal(30 * time.Minute))
+			Expect(decodeLogLevelResponse(response).OverrideLevel).To(Equal("DEBUG"))
+		})
+
+		It("resets the log level", func() {
+			logLevels.LevelsLevels = logging.Levels{
+				Sinks: []logging.SinkLevel{
+					{Name: "stdout", Level: "INFO", ConfiguredLevel: "INFO"},
+				},
+			}
+
+			response := makeRequest("DELETE", path, credentials.Username, credentials.Password)
+			Expect(response.Code).To(Equal(http.StatusOK))
+			Expect(logLevels.ResetCalled).To(BeTrue())
+			Expect(decodeLogLevelResponse(response).OverrideExpiresAt).To(BeNil())
+		})
+
+		It("requires the broker credentials", func() {
+			response := makeRequestWithBody("PUT", path, credentials.Username, "wrong-password", `{"level":"debug"}`)
+			Expect(response.Code).To(Equal(http.StatusUnauthorized))
+			Expect(logLevels.SetLevelCalled).To(BeFalse())
+		})
+
+		It("returns a 400 if the request is not valid JSON", func() {
+			response := makeRequestWithBody("PUT", path, credentials.Username, credentials.Password, `debug`)
+			Expect(response.Code).To(Equal(http.StatusBadRequest))
+			Expect(logLevels.SetLevelCalled).To(BeFalse())
+		})
+
+		It("returns a 400 if the duration is negative", func() {
+			response := makeRequestWithBody("PUT", path, credentials.Username, credentials.Password, `{"level":"debug","duration_in_minutes":-1}`)
+			Expect(response.Code).To(Equal(http.StatusBadRequest))
+			Expect(logLevels.SetLevelCalled).To(BeFalse())
+		})
+
+		Context("when the level is not valid", func() {
+			BeforeEach(func() {
+				logLevels.SetLevelError = errors.New("Invalid log level: verbose")
+			})
+
+			It("returns a 400", func() {
+				response := makeRequestWithBody("PUT", path, credentials.Username, credentials.Password, `{"level":"verbose"}`)
+				Expect(response.Code).To(Equal(http.StatusBadRequest))
+
+				errorResponse := brokerapi.ErrorResponse{}
+				Expect(json.Unmarshal(response.Body.Bytes(), &errorResponse)).To(Succeed())
+				Expect(errorResponse.Description).To(Equal("Invalid log level: verbose"))
+			})
+		})
+	})
+})
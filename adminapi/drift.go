@@ -0,0 +1,113 @@
+package adminapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/gorilla/mux"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/cloudformation-broker/drift"
+)
+
+const detectDriftLogKey = "detect-drift"
+
+// DriftDetector detects the drift of the stacks of service instances.
+type DriftDetector interface {
+	Start(instanceID string) error
+	Result(instanceID string) (drift.Result, bool)
+}
+
+type DriftResponse struct {
+	InstanceID            string                  `json:"instance_id"`
+	StackName             string                  `json:"stack_name"`
+	DetectionStatus       string                  `json:"detection_status"`
+	DetectionStatusReason string                  `json:"detection_status_reason,omitempty"`
+	StackDriftStatus      string                  `json:"stack_drift_status"`
+	DriftedResources      []ResourceDriftResponse `json:"drifted_resources"`
+	CheckedAt             time.Time               `json:"checked_at"`
+}
+
+type ResourceDriftResponse struct {
+	LogicalResourceID   string                       `json:"logical_resource_id"`
+	PhysicalResourceID  string                       `json:"physical_resource_id,omitempty"`
+	ResourceType        string                       `json:"resource_type"`
+	DriftStatus         string                       `json:"drift_status"`
+	PropertyDifferences []PropertyDifferenceResponse `json:"property_differences"`
+}
+
+type PropertyDifferenceResponse struct {
+	PropertyPath   string `json:"property_path"`
+	ExpectedValue  string `json:"expected_value"`
+	ActualValue    string `json:"actual_value"`
+	DifferenceType string `json:"difference_type"`
+}
+
+func showDrift(drifts DriftDetector) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		instanceID := mux.Vars(req)["instance_id"]
+
+		result, ok := drifts.Result(instanceID)
+		if !ok {
+			respond(w, http.StatusNotFound, brokerapi.ErrorResponse{
+				Description: "No drift detection has completed for the service instance",
+			})
+			return
+		}
+
+		respond(w, http.StatusOK, buildDriftResponse(result))
+	}
+}
+
+func detectDrift(drifts DriftDetector, logger lager.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		instanceID := mux.Vars(req)["instance_id"]
+
+		logger := logger.Session(detectDriftLogKey, lager.Data{
+			instanceIDLogKey: instanceID,
+		})
+
+		if err := drifts.Start(instanceID); err != nil {
+			respondError(w, logger, err)
+			return
+		}
+
+		respond(w, http.StatusAccepted, OperationResponse{
+			Description: "Detecting the drift of the service instance",
+		})
+	}
+}
+
+func buildDriftResponse(result drift.Result) DriftResponse {
+	driftResponse := DriftResponse{
+		InstanceID:            result.InstanceID,
+		StackName:             result.StackName,
+		DetectionStatus:       result.DetectionStatus,
+		DetectionStatusReason: result.DetectionStatusReason,
+		StackDriftStatus:      result.StackDriftStatus,
+		DriftedResources:      []ResourceDriftResponse{},
+		CheckedAt:             result.CheckedAt,
+	}
+
+	for _, resourceDrift := range result.DriftedResources {
+		resourceDriftResponse := ResourceDriftResponse{
+			LogicalResourceID:   resourceDrift.LogicalResourceID,
+			PhysicalResourceID:  resourceDrift.PhysicalResourceID,
+			ResourceType:        resourceDrift.ResourceType,
+			DriftStatus:         resourceDrift.DriftStatus,
+			PropertyDifferences: []PropertyDifferenceResponse{},
+		}
+		for _, propertyDifference := range resourceDrift.PropertyDifferences {
+			resourceDriftResponse.PropertyDifferences = append(resourceDriftResponse.PropertyDifferences, PropertyDifferenceResponse{
+				PropertyPath:   propertyDifference.PropertyPath,
+				ExpectedValue:  propertyDifference.ExpectedValue,
+				ActualValue:    propertyDifference.ActualValue,
+				DifferenceType: propertyDifference.DifferenceType,
+			})
+		}
+		driftResponse.DriftedResources = append(driftResponse.DriftedResources, resourceDriftResponse)
+	}
+
+	return driftResponse
+}
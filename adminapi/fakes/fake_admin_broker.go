@@ -0,0 +1,96 @@
+package fakes
+
+import (
+	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+)
+
+type FakeAdminBroker struct {
+	InstancesCalled    bool
+	InstancesInstances []cfbroker.InstanceSummary
+	InstancesError     error
+
+	InstanceCalled     bool
+	InstanceInstanceID string
+	InstanceDetails    cfbroker.InstanceDetails
+	InstanceError      error
+
+	CancelUpdateCalled     bool
+	CancelUpdateInstanceID string
+	CancelUpdateError      error
+
+	RetryDeleteCalled            bool
+	RetryDeleteInstanceID        string
+	RetryDeleteResourcesToRetain []string
+	RetryDeleteError             error
+
+	ContinueUpdateRollbackCalled          bool
+	ContinueUpdateRollbackInstanceID      string
+	ContinueUpdateRollbackResourcesToSkip []string
+	ContinueUpdateRollbackError           error
+
+	RetagCalled     bool
+	RetagInstanceID string
+	RetagTags       map[string]string
+	RetagError      error
+
+	ImportInstanceCalled     bool
+	ImportInstanceInstanceID string
+	ImportInstanceStackName  string
+	ImportInstanceServiceID  string
+	ImportInstancePlanID     string
+	ImportInstanceError      error
+}
+
+func (f *FakeAdminBroker) Instances() ([]cfbroker.InstanceSummary, error) {
+	f.InstancesCalled = true
+
+	return f.InstancesInstances, f.InstancesError
+}
+
+func (f *FakeAdminBroker) Instance(instanceID string) (cfbroker.InstanceDetails, error) {
+	f.InstanceCalled = true
+	f.InstanceInstanceID = instanceID
+
+	return f.InstanceDetails, f.InstanceError
+}
+
+func (f *FakeAdminBroker) CancelUpdate(instanceID string) error {
+	f.CancelUpdateCalled = true
+	f.CancelUpdateInstanceID = instanceID
+
+	return f.CancelUpdateError
+}
+
+func (f *FakeAdminBroker) RetryDelete(instanceID string, resourcesToRetain []string) error {
+	f.RetryDeleteCalled = true
+	f.RetryDeleteInstanceID = instanceID
+	f.RetryDeleteResourcesToRetain = resourcesToRetain
+
+	return f.RetryDeleteError
+}
+
+func (f *FakeAdminBroker) ContinueUpdateRollback(instanceID string, resourcesToSkip []string) error {
+	f.ContinueUpdateRollbackCalled = true
+	f.ContinueUpdateRollbackInstanceID = instanceID
+	f.ContinueUpdateRollbackResourcesToSkip = resourcesToSkip
+
+	return f.ContinueUpdateRollbackError
+}
+
+func (f *FakeAdminBroker) Retag(instanceID string, tags map[string]string) error {
+	f.RetagCalled = true
+	f.RetagInstanceID = instanceID
+	f.RetagTags = tags
+
+	return f.RetagError
+}
+
+func (f *FakeAdminBroker) ImportInstance(instanceID string, stackName string, serviceID string, planID string) error {
+	f.ImportInstanceCalled = true
+	f.ImportInstanceInstanceID = instanceID
+	f.ImportInstanceStackName = stackName
+	f.ImportInstanceServiceID = serviceID
+	f.ImportInstancePlanID = planID
+
+	return f.ImportInstanceError
+}
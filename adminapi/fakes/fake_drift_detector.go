@@ -0,0 +1,28 @@
+package fakes
+
+import (
+	"github.com/cf-platform-eng/cloudformation-broker/drift"
+)
+
+type FakeDriftDetector struct {
+	StartCalled     bool
+	StartInstanceID string
+	StartError      error
+
+	ResultInstanceID string
+	ResultResult     drift.Result
+	ResultFound      bool
+}
+
+func (f *FakeDriftDetector) Start(instanceID string) error {
+	f.StartCalled = true
+	f.StartInstanceID = instanceID
+
+	return f.StartError
+}
+
+func (f *FakeDriftDetector) Result(instanceID string) (drift.Result, bool) {
+	f.ResultInstanceID = instanceID
+
+	return f.ResultResult, f.ResultFound
+}
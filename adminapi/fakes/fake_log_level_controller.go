@@ -0,0 +1,34 @@
+package fakes
+
+import (
+	"time"
+
+	"github.com/cf-platform-eng/cloudformation-broker/logging"
+)
+
+type FakeLogLevelController struct {
+	LevelsLevels logging.Levels
+
+	SetLevelCalled   bool
+	SetLevelLevel    string
+	SetLevelDuration time.Duration
+	SetLevelError    error
+
+	ResetCalled bool
+}
+
+func (f *FakeLogLevelController) Levels() logging.Levels {
+	return f.LevelsLevels
+}
+
+func (f *FakeLogLevelController) SetLevel(level string, duration time.Duration) error {
+	f.SetLevelCalled = true
+	f.SetLevelLevel = level
+	f.SetLevelDuration = duration
+
+	return f.SetLevelError
+}
+
+func (f *FakeLogLevelController) Reset() {
+	f.ResetCalled = true
+}
@@ -0,0 +1,303 @@
+package adminapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/gorilla/mux"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+)
+
+const listInstancesLogKey = "list-instances"
+const showInstanceLogKey = "show-instance"
+
+type InstancesResponse struct {
+	Instances []InstanceSummaryResponse `json:"instances"`
+}
+
+type InstanceSummaryResponse struct {
+	InstanceID        string    `json:"instance_id"`
+	StackName         string    `json:"stack_name"`
+	StackID           string    `json:"stack_id"`
+	StackStatus       string    `json:"stack_status"`
+	StackStatusReason string    `json:"stack_status_reason,omitempty"`
+	CreationTime      time.Time `json:"creation_time"`
+}
+
+type InstanceResponse struct {
+	InstanceID        string            `json:"instance_id"`
+	StackName         string            `json:"stack_name"`
+	StackID           string            `json:"stack_id"`
+	StackStatus       string            `json:"stack_status"`
+	StackStatusReason string            `json:"stack_status_reason,omitempty"`
+	Parameters        map[string]string `json:"parameters"`
+	Outputs           map[string]string `json:"outputs"`
+	Tags              map[string]string `json:"tags"`
+	CreationTime      time.Time         `json:"creation_time"`
+	LastUpdatedTime   *time.Time        `json:"last_updated_time,omitempty"`
+	Events            []EventResponse   `json:"events"`
+	Template          string            `json:"template"`
+}
+
+type EventResponse struct {
+	EventID              string    `json:"event_id"`
+	LogicalResourceID    string    `json:"logical_resource_id"`
+	PhysicalResourceID   string    `json:"physical_resource_id,omitempty"`
+	ResourceType         string    `json:"resource_type"`
+	ResourceStatus       string    `json:"resource_status"`
+	ResourceStatusReason string    `json:"resource_status_reason,omitempty"`
+	Timestamp            time.Time `json:"timestamp"`
+}
+
+type RetryDeleteRequest struct {
+	ResourcesToRetain []string `json:"resources_to_retain"`
+}
+
+type ContinueRollbackRequest struct {
+	ResourcesToSkip []string `json:"resources_to_skip"`
+}
+
+type RetagRequest struct {
+	Tags map[string]string `json:"tags"`
+}
+
+type ImportInstanceRequest struct {
+	StackName string `json:"stack_name"`
+	ServiceID string `json:"service_id"`
+	PlanID    string `json:"plan_id"`
+}
+
+func listInstances(adminBroker AdminBroker, logger lager.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		logger := logger.Session(listInstancesLogKey)
+
+		instances, err := adminBroker.Instances()
+		if err != nil {
+			respondError(w, logger, err)
+			return
+		}
+
+		instancesResponse := InstancesResponse{Instances: []InstanceSummaryResponse{}}
+		for _, instance := range instances {
+			instancesResponse.Instances = append(instancesResponse.Instances, InstanceSummaryResponse{
+				InstanceID:        instance.InstanceID,
+				StackName:         instance.StackName,
+				StackID:           instance.StackID,
+				StackStatus:       instance.StackStatus.Raw,
+				StackStatusReason: instance.StackStatus.Reason,
+				CreationTime:      instance.CreationTime,
+			})
+		}
+
+		respond(w, http.StatusOK, instancesResponse)
+	}
+}
+
+func showInstance(adminBroker AdminBroker, logger lager.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		instanceID := mux.Vars(req)["instance_id"]
+
+		logger := logger.Session(showInstanceLogKey, lager.Data{
+			instanceIDLogKey: instanceID,
+		})
+
+		instance, err := adminBroker.Instance(instanceID)
+		if err != nil {
+			respondError(w, logger, err)
+			return
+		}
+
+		respond(w, http.StatusOK, NewInstanceResponse(instance))
+	}
+}
+
+func cancelUpdate(adminBroker AdminBroker, logger lager.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		instanceID := mux.Vars(req)["instance_id"]
+
+		logger := logger.Session(cancelUpdateLogKey, lager.Data{
+			instanceIDLogKey: instanceID,
+		})
+
+		if err := adminBroker.CancelUpdate(instanceID); err != nil {
+			respondError(w, logger, err)
+			return
+		}
+
+		respond(w, http.StatusAccepted, OperationResponse{
+			Description: "Cancelling the update of the service instance",
+		})
+	}
+}
+
+func retryDelete(adminBroker AdminBroker, logger lager.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		instanceID := mux.Vars(req)["instance_id"]
+
+		logger := logger.Session(retryDeleteLogKey, lager.Data{
+			instanceIDLogKey: instanceID,
+		})
+
+		var retryDeleteRequest RetryDeleteRequest
+		if err := decodeRequest(req, &retryDeleteRequest); err != nil {
+			logger.Error(invalidRequestErrorKey, err)
+			respond(w, http.StatusBadRequest, brokerapi.ErrorResponse{
+				Description: err.Error(),
+			})
+			return
+		}
+
+		if err := adminBroker.RetryDelete(instanceID, retryDeleteRequest.ResourcesToRetain); err != nil {
+			respondError(w, logger, err)
+			return
+		}
+
+		respond(w, http.StatusAccepted, OperationResponse{
+			Description: "Deleting the service instance again",
+		})
+	}
+}
+
+func continueRollback(adminBroker AdminBroker, logger lager.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		instanceID := mux.Vars(req)["instance_id"]
+
+		logger := logger.Session(continueRollbackLogKey, lager.Data{
+			instanceIDLogKey: instanceID,
+		})
+
+		var continueRollbackRequest ContinueRollbackRequest
+		if err := decodeRequest(req, &continueRollbackRequest); err != nil {
+			logger.Error(invalidRequestErrorKey, err)
+			respond(w, http.StatusBadRequest, brokerapi.ErrorResponse{
+				Description: err.Error(),
+			})
+			return
+		}
+
+		if err := adminBroker.ContinueUpdateRollback(instanceID, continueRollbackRequest.ResourcesToSkip); err != nil {
+			respondError(w, logger, err)
+			return
+		}
+
+		respond(w, http.StatusAccepted, OperationResponse{
+			Description: "Continuing the rollback of the service instance",
+		})
+	}
+}
+
+func retag(adminBroker AdminBroker, logger lager.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		instanceID := mux.Vars(req)["instance_id"]
+
+		logger := logger.Session(retagLogKey, lager.Data{
+			instanceIDLogKey: instanceID,
+		})
+
+		var retagRequest RetagRequest
+		if err := decodeRequest(req, &retagRequest); err != nil {
+			logger.Error(invalidRequestErrorKey, err)
+			respond(w, http.StatusBadRequest, brokerapi.ErrorResponse{
+				Description: err.Error(),
+			})
+			return
+		}
+
+		if len(retagRequest.Tags) == 0 {
+			respond(w, http.StatusBadRequest, brokerapi.ErrorResponse{
+				Description: "tags must not be empty",
+			})
+			return
+		}
+
+		if err := adminBroker.Retag(instanceID, retagRequest.Tags); err != nil {
+			respondError(w, logger, err)
+			return
+		}
+
+		respond(w, http.StatusAccepted, OperationResponse{
+			Description: "Updating the tags of the service instance",
+		})
+	}
+}
+
+func importInstance(adminBroker AdminBroker, logger lager.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		instanceID := mux.Vars(req)["instance_id"]
+
+		logger := logger.Session(importInstanceLogKey, lager.Data{
+			instanceIDLogKey: instanceID,
+		})
+
+		var importInstanceRequest ImportInstanceRequest
+		if err := decodeRequest(req, &importInstanceRequest); err != nil {
+			logger.Error(invalidRequestErrorKey, err)
+			respond(w, http.StatusBadRequest, brokerapi.ErrorResponse{
+				Description: err.Error(),
+			})
+			return
+		}
+
+		if importInstanceRequest.StackName == "" || importInstanceRequest.ServiceID == "" || importInstanceRequest.PlanID == "" {
+			respond(w, http.StatusBadRequest, brokerapi.ErrorResponse{
+				Description: "stack_name, service_id and plan_id must not be empty",
+			})
+			return
+		}
+
+		if err := adminBroker.ImportInstance(instanceID, importInstanceRequest.StackName, importInstanceRequest.ServiceID, importInstanceRequest.PlanID); err != nil {
+			respondError(w, logger, err)
+			return
+		}
+
+		respond(w, http.StatusOK, OperationResponse{
+			Description: "Imported the stack as the service instance",
+		})
+	}
+}
+
+// NewInstanceResponse builds the JSON representation of a service instance
+// shared by the admin API and the admin command.
+func NewInstanceResponse(instance cfbroker.InstanceDetails) InstanceResponse {
+	instanceResponse := InstanceResponse{
+		InstanceID:        instance.InstanceID,
+		StackName:         instance.StackName,
+		StackID:           instance.StackID,
+		StackStatus:       instance.StackStatus.Raw,
+		StackStatusReason: instance.StackStatus.Reason,
+		Parameters:        instance.Parameters,
+		Outputs:           instance.Outputs,
+		Tags:              instance.Tags,
+		CreationTime:      instance.CreationTime,
+		Events:            buildEventResponses(instance.Events),
+		Template:          instance.Template,
+	}
+
+	if !instance.LastUpdatedTime.IsZero() {
+		lastUpdatedTime := instance.LastUpdatedTime
+		instanceResponse.LastUpdatedTime = &lastUpdatedTime
+	}
+
+	return instanceResponse
+}
+
+func buildEventResponses(events []awscf.StackEvent) []EventResponse {
+	eventResponses := []EventResponse{}
+	for _, event := range events {
+		eventResponses = append(eventResponses, EventResponse{
+			EventID:              event.EventID,
+			LogicalResourceID:    event.LogicalResourceID,
+			PhysicalResourceID:   event.PhysicalResourceID,
+			ResourceType:         event.ResourceType,
+			ResourceStatus:       event.ResourceStatus,
+			ResourceStatusReason: event.ResourceStatusReason,
+			Timestamp:            event.Timestamp,
+		})
+	}
+
+	return eventResponses
+}
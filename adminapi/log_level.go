@@ -0,0 +1,112 @@
+package adminapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/cloudformation-broker/logging"
+)
+
+const setLogLevelLogKey = "set-log-level"
+const resetLogLevelLogKey = "reset-log-level"
+
+// LogLevelController changes the log levels of the broker at runtime.
+type LogLevelController interface {
+	Levels() logging.Levels
+	SetLevel(level string, duration time.Duration) error
+	Reset()
+}
+
+// LogLevelRequest overrides the log level of every sink, for
+// DurationInMinutes or until reset if it is zero.
+type LogLevelRequest struct {
+	Level             string `json:"level"`
+	DurationInMinutes int64  `json:"duration_in_minutes"`
+}
+
+type LogLevelResponse struct {
+	Sinks             []SinkLevelResponse `json:"sinks"`
+	OverrideLevel     string              `json:"override_level,omitempty"`
+	OverrideExpiresAt *time.Time          `json:"override_expires_at,omitempty"`
+}
+
+type SinkLevelResponse struct {
+	Name            string `json:"name"`
+	Level           string `json:"level"`
+	ConfiguredLevel string `json:"configured_level"`
+}
+
+func getLogLevel(logLevels LogLevelController) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		respond(w, http.StatusOK, buildLogLevelResponse(logLevels.Levels()))
+	}
+}
+
+func setLogLevel(logLevels LogLevelController, logger lager.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		logger := logger.Session(setLogLevelLogKey)
+
+		var logLevelRequest LogLevelRequest
+		if err := json.NewDecoder(req.Body).Decode(&logLevelRequest); err != nil {
+			logger.Error(invalidRequestErrorKey, err)
+			respond(w, http.StatusBadRequest, brokerapi.ErrorResponse{
+				Description: err.Error(),
+			})
+			return
+		}
+
+		if logLevelRequest.DurationInMinutes < 0 {
+			respond(w, http.StatusBadRequest, brokerapi.ErrorResponse{
+				Description: "duration_in_minutes must not be negative",
+			})
+			return
+		}
+
+		duration := time.Duration(logLevelRequest.DurationInMinutes) * time.Minute
+		if err := logLevels.SetLevel(logLevelRequest.Level, duration); err != nil {
+			logger.Error(invalidRequestErrorKey, err)
+			respond(w, http.StatusBadRequest, brokerapi.ErrorResponse{
+				Description: err.Error(),
+			})
+			return
+		}
+
+		respond(w, http.StatusOK, buildLogLevelResponse(logLevels.Levels()))
+	}
+}
+
+func resetLogLevel(logLevels LogLevelController, logger lager.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		logger.Info(resetLogLevelLogKey)
+
+		logLevels.Reset()
+
+		respond(w, http.StatusOK, buildLogLevelResponse(logLevels.Levels()))
+	}
+}
+
+func buildLogLevelResponse(levels logging.Levels) LogLevelResponse {
+	logLevelResponse := LogLevelResponse{
+		Sinks:         []SinkLevelResponse{},
+		OverrideLevel: levels.OverrideLevel,
+	}
+
+	if !levels.OverrideExpiresAt.IsZero() {
+		overrideExpiresAt := levels.OverrideExpiresAt
+		logLevelResponse.OverrideExpiresAt = &overrideExpiresAt
+	}
+
+	for _, sinkLevel := range levels.Sinks {
+		logLevelResponse.Sinks = append(logLevelResponse.Sinks, SinkLevelResponse{
+			Name:            sinkLevel.Name,
+			Level:           sinkLevel.Level,
+			ConfiguredLevel: sinkLevel.ConfiguredLevel,
+		})
+	}
+
+	return logLevelResponse
+}
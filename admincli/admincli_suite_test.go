@@ -0,0 +1,13 @@
+package admincli_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestAdminCLI(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Admin CLI Suite")
+}
@@ -0,0 +1,302 @@
+package admincli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cf-platform-eng/cloudformation-broker/adminapi"
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+	"github.com/cf-platform-eng/cloudformation-broker/redact"
+)
+
+const Usage = `Usage: cloudformation-broker -config <config-file> admin <command> [arguments]
+
+Commands:
+  list                                   List the service instance stacks
+  show <instance-id>                     Show the stack of a service instance
+  events <instance-id>                   Show the most recent events of the stack of a service instance
+  orphans                                List the stacks left behind by the broker
+  delete [-retain <ids>] <instance-id>   Delete the stack of a service instance, retaining the comma separated resources, if any
+  export <instance-id>                   Export the stack of a service instance as JSON
+  import -service <id> -plan <id> <stack-name> <instance-id>
+                                         Import an existing stack as a service instance of the service plan`
+
+// Broker holds the broker operations the admin commands are built on.
+type Broker interface {
+	Instances() ([]cfbroker.InstanceSummary, error)
+	Instance(instanceID string) (cfbroker.InstanceDetails, error)
+	InstanceEvents(instanceID string) ([]awscf.StackEvent, error)
+	OrphanedInstances() ([]cfbroker.OrphanedInstance, error)
+	DeleteInstanceStack(instanceID string, resourcesToRetain []string) error
+	ImportInstance(instanceID string, stackName string, serviceID string, planID string) error
+}
+
+// UsageError is returned when the admin command is not called properly.
+type UsageError struct {
+	Message string
+}
+
+func (e UsageError) Error() string {
+	return e.Message + "\n\n" + Usage
+}
+
+// CLI runs the admin commands against the stacks of the broker, without
+// going through the broker itself.
+type CLI struct {
+	broker   Broker
+	redactor *redact.Redactor
+	out      io.Writer
+}
+
+func New(broker Broker, redactor *redact.Redactor, out io.Writer) *CLI {
+	return &CLI{
+		broker:   broker,
+		redactor: redactor,
+		out:      out,
+	}
+}
+
+func (c *CLI) Run(args []string) error {
+	if len(args) == 0 {
+		return UsageError{Message: "Missing command"}
+	}
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "list":
+		return c.list(args)
+	case "show":
+		return c.show(args)
+	case "events":
+		return c.events(args)
+	case "orphans":
+		return c.orphans(args)
+	case "delete":
+		return c.delete(args)
+	case "export":
+		return c.export(args)
+	case "import":
+		return c.importInstance(args)
+	}
+
+	return UsageError{Message: fmt.Sprintf("Unknown command '%s'", command)}
+}
+
+func (c *CLI) list(args []string) error {
+	if len(args) != 0 {
+		return UsageError{Message: "The list command takes no arguments"}
+	}
+
+	instances, err := c.broker.Instances()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "INSTANCE ID\tSTACK NAME\tSTACK STATUS\tCREATED")
+	for _, instance := range instances {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", instance.InstanceID, instance.StackName, instance.StackStatus.Raw, formatTime(instance.CreationTime))
+	}
+
+	return w.Flush()
+}
+
+func (c *CLI) show(args []string) error {
+	instanceID, err := instanceIDArg("show", args)
+	if err != nil {
+		return err
+	}
+
+	instance, err := c.broker.Instance(instanceID)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Instance ID:\t%s\n", instance.InstanceID)
+	fmt.Fprintf(w, "Stack Name:\t%s\n", instance.StackName)
+	fmt.Fprintf(w, "Stack ID:\t%s\n", instance.StackID)
+	fmt.Fprintf(w, "Stack Status:\t%s\n", instance.StackStatus.Raw)
+	if instance.StackStatus.Reason != "" {
+		fmt.Fprintf(w, "Stack Status Reason:\t%s\n", instance.StackStatus.Reason)
+	}
+	fmt.Fprintf(w, "Created:\t%s\n", formatTime(instance.CreationTime))
+	fmt.Fprintf(w, "Last Updated:\t%s\n", formatTime(instance.LastUpdatedTime))
+	c.writeValues(w, "Parameters", c.redactValues(instance.Parameters))
+	c.writeValues(w, "Outputs", c.redactValues(instance.Outputs))
+	c.writeValues(w, "Tags", instance.Tags)
+
+	return w.Flush()
+}
+
+func (c *CLI) events(args []string) error {
+	instanceID, err := instanceIDArg("events", args)
+	if err != nil {
+		return err
+	}
+
+	events, err := c.broker.InstanceEvents(instanceID)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "TIMESTAMP\tLOGICAL ID\tRESOURCE TYPE\tRESOURCE STATUS\tREASON")
+	for _, event := range events {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", formatTime(event.Timestamp), event.LogicalResourceID, event.ResourceType, event.ResourceStatus, event.ResourceStatusReason)
+	}
+
+	return w.Flush()
+}
+
+func (c *CLI) orphans(args []string) error {
+	if len(args) != 0 {
+		return UsageError{Message: "The orphans command takes no arguments"}
+	}
+
+	orphanedInstances, err := c.broker.OrphanedInstances()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "INSTANCE ID\tSTACK NAME\tSTACK STATUS\tREASON")
+	for _, orphanedInstance := range orphanedInstances {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", orphanedInstance.InstanceID, orphanedInstance.StackName, orphanedInstance.StackStatus.Raw, orphanedInstance.Reason)
+	}
+
+	return w.Flush()
+}
+
+func (c *CLI) delete(args []string) error {
+	flagSet := flag.NewFlagSet("delete", flag.ContinueOnError)
+	flagSet.SetOutput(ioutil.Discard)
+	retain := flagSet.String("retain", "", "Comma separated logical IDs of the resources to retain")
+	if err := flagSet.Parse(args); err != nil {
+		return UsageError{Message: err.Error()}
+	}
+
+	instanceID, err := instanceIDArg("delete", flagSet.Args())
+	if err != nil {
+		return err
+	}
+
+	var resourcesToRetain []string
+	if *retain != "" {
+		resourcesToRetain = strings.Split(*retain, ",")
+	}
+
+	if err := c.broker.DeleteInstanceStack(instanceID, resourcesToRetain); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "Deleting the stack of service instance '%s'\n", instanceID)
+
+	return nil
+}
+
+func (c *CLI) export(args []string) error {
+	instanceID, err := instanceIDArg("export", args)
+	if err != nil {
+		return err
+	}
+
+	instance, err := c.broker.Instance(instanceID)
+	if err != nil {
+		return err
+	}
+
+	instance.Parameters = c.redactValues(instance.Parameters)
+	instance.Outputs = c.redactValues(instance.Outputs)
+
+	output, err := json.MarshalIndent(adminapi.NewInstanceResponse(instance), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(c.out, string(output))
+
+	return err
+}
+
+func (c *CLI) importInstance(args []string) error {
+	flagSet := flag.NewFlagSet("import", flag.ContinueOnError)
+	flagSet.SetOutput(ioutil.Discard)
+	serviceID := flagSet.String("service", "", "ID of the service of the instance")
+	planID := flagSet.String("plan", "", "ID of the service plan of the instance")
+	if err := flagSet.Parse(args); err != nil {
+		return UsageError{Message: err.Error()}
+	}
+
+	if *serviceID == "" || *planID == "" {
+		return UsageError{Message: "The import command requires a service and a plan"}
+	}
+
+	if flagSet.NArg() != 2 {
+		return UsageError{Message: "The import command takes a stack name and an instance ID"}
+	}
+	stackName, instanceID := flagSet.Arg(0), flagSet.Arg(1)
+
+	if err := c.broker.ImportInstance(instanceID, stackName, *serviceID, *planID); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "Imported stack '%s' as service instance '%s'\n", stackName, instanceID)
+
+	return nil
+}
+
+func (c *CLI) redactValues(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+
+	redactedValues := make(map[string]string, len(values))
+	for key, value := range values {
+		if c.redactor.IsSensitive(key) {
+			redactedValues[key] = redact.Mask
+		} else {
+			redactedValues[key] = value
+		}
+	}
+
+	return redactedValues
+}
+
+func (c *CLI) writeValues(w io.Writer, title string, values map[string]string) {
+	fmt.Fprintf(w, "\n%s:\n", title)
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Fprintf(w, "  %s\t%s\n", key, values[key])
+	}
+}
+
+func instanceIDArg(command string, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", UsageError{Message: fmt.Sprintf("The %s command takes an instance ID", command)}
+	}
+
+	return args[0], nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,246 @@
+package admincli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/admincli"
+
+	"github.com/cf-platform-eng/cloudformation-broker/adminapi"
+	"github.com/cf-platform-eng/cloudformation-broker/admincli/fakes"
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+	"github.com/cf-platform-eng/cloudformation-broker/redact"
+)
+
+var _ = Describe("Admin CLI", func() {
+	var (
+		broker *fakes.FakeBroker
+		out    *bytes.Buffer
+		cli    *CLI
+
+		creationTime = time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		broker = &fakes.FakeBroker{}
+		out = &bytes.Buffer{}
+		cli = New(broker, redact.New("DBName"), out)
+	})
+
+	It("requires a command", func() {
+		err := cli.Run([]string{})
+		Expect(err).To(Equal(UsageError{Message: "Missing command"}))
+	})
+
+	It("rejects unknown commands", func() {
+		err := cli.Run([]string{"unknown"})
+		Expect(err).To(Equal(UsageError{Message: "Unknown command 'unknown'"}))
+		Expect(err.Error()).To(ContainSubstring(Usage))
+	})
+
+	Describe("list", func() {
+		BeforeEach(func() {
+			broker.InstancesInstances = []cfbroker.InstanceSummary{
+				cfbroker.InstanceSummary{
+					InstanceID:   "instance-id",
+					StackName:    "cfbroker-instance-id",
+					StackStatus:  awscf.NewStatus("CREATE_COMPLETE", ""),
+					CreationTime: creationTime,
+				},
+			}
+		})
+
+		It("lists the instances", func() {
+			Expect(cli.Run([]string{"list"})).To(Succeed())
+			Expect(out.String()).To(Equal(
+				"INSTANCE ID  STACK NAME            STACK STATUS     CREATED\n" +
+					"instance-id  cfbroker-instance-id  CREATE_COMPLETE  2015-06-01T12:00:00Z\n",
+			))
+		})
+
+		Context("when listing the instances fails", func() {
+			BeforeEach(func() {
+				broker.InstancesError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				Expect(cli.Run([]string{"list"})).To(MatchError("operation failed"))
+			})
+		})
+	})
+
+	Describe("show", func() {
+		BeforeEach(func() {
+			broker.InstanceDetails = cfbroker.InstanceDetails{
+				InstanceID:   "instance-id",
+				StackName:    "cfbroker-instance-id",
+				StackID:      "stack-id",
+				StackStatus:  awscf.NewStatus("UPDATE_ROLLBACK_FAILED", "rollback failed"),
+				Parameters:   map[string]string{"DBName": "test", "DBSize": "10"},
+				Outputs:      map[string]string{"DBPassword": "secret"},
+				Tags:         map[string]string{"Owner": "Cloud Foundry"},
+				CreationTime: creationTime,
+			}
+		})
+
+		It("shows the instance stack, redacting sensitive values", func() {
+			Expect(cli.Run([]string{"show", "instance-id"})).To(Succeed())
+			Expect(broker.InstanceInstanceID).To(Equal("instance-id"))
+			Expect(out.String()).To(ContainSubstring("Stack Status:         UPDATE_ROLLBACK_FAILED\n"))
+			Expect(out.String()).To(ContainSubstring("Stack Status Reason:  rollback failed\n"))
+			Expect(out.String()).To(ContainSubstring("Last Updated:         -\n"))
+			Expect(out.String()).To(ContainSubstring("DBName"))
+			Expect(out.String()).To(ContainSubstring("DBSize"))
+			Expect(out.String()).To(ContainSubstring("10"))
+			Expect(out.String()).To(ContainSubstring("Owner"))
+			Expect(out.String()).ToNot(ContainSubstring("test"))
+			Expect(out.String()).ToNot(ContainSubstring("secret"))
+		})
+
+		It("requires an instance ID", func() {
+			err := cli.Run([]string{"show"})
+			Expect(err).To(Equal(UsageError{Message: "The show command takes an instance ID"}))
+			Expect(broker.InstanceCalled).To(BeFalse())
+		})
+	})
+
+	Describe("events", func() {
+		BeforeEach(func() {
+			broker.InstanceEventsEvents = []awscf.StackEvent{
+				awscf.StackEvent{
+					LogicalResourceID:    "Bucket",
+					ResourceType:         "AWS::S3::Bucket",
+					ResourceStatus:       "DELETE_FAILED",
+					ResourceStatusReason: "The bucket is not empty",
+					Timestamp:            creationTime,
+				},
+			}
+		})
+
+		It("lists the events of the instance stack", func() {
+			Expect(cli.Run([]string{"events", "instance-id"})).To(Succeed())
+			Expect(broker.InstanceEventsInstanceID).To(Equal("instance-id"))
+			Expect(out.String()).To(Equal(
+				"TIMESTAMP             LOGICAL ID  RESOURCE TYPE    RESOURCE STATUS  REASON\n" +
+					"2015-06-01T12:00:00Z  Bucket      AWS::S3::Bucket  DELETE_FAILED    The bucket is not empty\n",
+			))
+		})
+	})
+
+	Describe("orphans", func() {
+		BeforeEach(func() {
+			broker.OrphanedInstancesInstances = []cfbroker.OrphanedInstance{
+				cfbroker.OrphanedInstance{
+					InstanceSummary: cfbroker.InstanceSummary{
+						InstanceID:  "instance-id",
+						StackName:   "cfbroker-instance-id",
+						StackStatus: awscf.NewStatus("DELETE_FAILED", ""),
+					},
+					Reason: "Stack deletion failed",
+				},
+			}
+		})
+
+		It("lists the orphaned instances", func() {
+			Expect(cli.Run([]string{"orphans"})).To(Succeed())
+			Expect(out.String()).To(Equal(
+				"INSTANCE ID  STACK NAME            STACK STATUS   REASON\n" +
+					"instance-id  cfbroker-instance-id  DELETE_FAILED  Stack deletion failed\n",
+			))
+		})
+	})
+
+	Describe("delete", func() {
+		It("deletes the instance stack", func() {
+			Expect(cli.Run([]string{"delete", "instance-id"})).To(Succeed())
+			Expect(broker.DeleteInstanceStackInstanceID).To(Equal("instance-id"))
+			Expect(broker.DeleteInstanceStackResourcesToRetain).To(BeNil())
+			Expect(out.String()).To(Equal("Deleting the stack of service instance 'instance-id'\n"))
+		})
+
+		It("retains the requested resources", func() {
+			Expect(cli.Run([]string{"delete", "-retain", "Bucket,Queue", "instance-id"})).To(Succeed())
+			Expect(broker.DeleteInstanceStackResourcesToRetain).To(Equal([]string{"Bucket", "Queue"}))
+		})
+
+		It("rejects unknown flags", func() {
+			err := cli.Run([]string{"delete", "-force", "instance-id"})
+			Expect(err).To(BeAssignableToTypeOf(UsageError{}))
+			Expect(broker.DeleteInstanceStackCalled).To(BeFalse())
+		})
+
+		Context("when deleting the stack fails", func() {
+			BeforeEach(func() {
+				broker.DeleteInstanceStackError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				Expect(cli.Run([]string{"delete", "instance-id"})).To(MatchError("operation failed"))
+				Expect(out.String()).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("import", func() {
+		It("imports the stack as a service instance", func() {
+			Expect(cli.Run([]string{"import", "-service", "service-id", "-plan", "plan-id", "legacy-stack", "instance-id"})).To(Succeed())
+			Expect(broker.ImportInstanceInstanceID).To(Equal("instance-id"))
+			Expect(broker.ImportInstanceStackName).To(Equal("legacy-stack"))
+			Expect(broker.ImportInstanceServiceID).To(Equal("service-id"))
+			Expect(broker.ImportInstancePlanID).To(Equal("plan-id"))
+			Expect(out.String()).To(Equal("Imported stack 'legacy-stack' as service instance 'instance-id'\n"))
+		})
+
+		It("requires a service and a plan", func() {
+			err := cli.Run([]string{"import", "-service", "service-id", "legacy-stack", "instance-id"})
+			Expect(err).To(BeAssignableToTypeOf(UsageError{}))
+			Expect(broker.ImportInstanceCalled).To(BeFalse())
+		})
+
+		It("requires a stack name and an instance ID", func() {
+			err := cli.Run([]string{"import", "-service", "service-id", "-plan", "plan-id", "legacy-stack"})
+			Expect(err).To(BeAssignableToTypeOf(UsageError{}))
+			Expect(broker.ImportInstanceCalled).To(BeFalse())
+		})
+
+		Context("when importing the stack fails", func() {
+			BeforeEach(func() {
+				broker.ImportInstanceError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				Expect(cli.Run([]string{"import", "-service", "service-id", "-plan", "plan-id", "legacy-stack", "instance-id"})).To(MatchError("operation failed"))
+				Expect(out.String()).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("export", func() {
+		BeforeEach(func() {
+			broker.InstanceDetails = cfbroker.InstanceDetails{
+				InstanceID:  "instance-id",
+				StackName:   "cfbroker-instance-id",
+				StackStatus: awscf.NewStatus("CREATE_COMPLETE", ""),
+				Parameters:  map[string]string{"DBName": "test", "DBSize": "10"},
+				Template:    `{"Resources":{}}`,
+			}
+		})
+
+		It("exports the instance stack as JSON, redacting sensitive values", func() {
+			Expect(cli.Run([]string{"export", "instance-id"})).To(Succeed())
+
+			instanceResponse := adminapi.InstanceResponse{}
+			Expect(json.Unmarshal(out.Bytes(), &instanceResponse)).To(Succeed())
+			Expect(instanceResponse.InstanceID).To(Equal("instance-id"))
+			Expect(instanceResponse.StackStatus).To(Equal("CREATE_COMPLETE"))
+			Expect(instanceResponse.Parameters).To(Equal(map[string]string{"DBName": redact.Mask, "DBSize": "10"}))
+			Expect(instanceResponse.Template).To(Equal(`{"Resources":{}}`))
+		})
+	})
+})
@@ -0,0 +1,82 @@
+package fakes
+
+import (
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+)
+
+type FakeBroker struct {
+	InstancesCalled    bool
+	InstancesInstances []cfbroker.InstanceSummary
+	InstancesError     error
+
+	InstanceCalled     bool
+	InstanceInstanceID string
+	InstanceDetails    cfbroker.InstanceDetails
+	InstanceError      error
+
+	InstanceEventsCalled     bool
+	InstanceEventsInstanceID string
+	InstanceEventsEvents     []awscf.StackEvent
+	InstanceEventsError      error
+
+	OrphanedInstancesCalled    bool
+	OrphanedInstancesInstances []cfbroker.OrphanedInstance
+	OrphanedInstancesError     error
+
+	DeleteInstanceStackCalled            bool
+	DeleteInstanceStackInstanceID        string
+	DeleteInstanceStackResourcesToRetain []string
+	DeleteInstanceStackError             error
+
+	ImportInstanceCalled     bool
+	ImportInstanceInstanceID string
+	ImportInstanceStackName  string
+	ImportInstanceServiceID  string
+	ImportInstancePlanID     string
+	ImportInstanceError      error
+}
+
+func (f *FakeBroker) Instances() ([]cfbroker.InstanceSummary, error) {
+	f.InstancesCalled = true
+
+	return f.InstancesInstances, f.InstancesError
+}
+
+func (f *FakeBroker) Instance(instanceID string) (cfbroker.InstanceDetails, error) {
+	f.InstanceCalled = true
+	f.InstanceInstanceID = instanceID
+
+	return f.InstanceDetails, f.InstanceError
+}
+
+func (f *FakeBroker) InstanceEvents(instanceID string) ([]awscf.StackEvent, error) {
+	f.InstanceEventsCalled = true
+	f.InstanceEventsInstanceID = instanceID
+
+	return f.InstanceEventsEvents, f.InstanceEventsError
+}
+
+func (f *FakeBroker) OrphanedInstances() ([]cfbroker.OrphanedInstance, error) {
+	f.OrphanedInstancesCalled = true
+
+	return f.OrphanedInstancesInstances, f.OrphanedInstancesError
+}
+
+func (f *FakeBroker) DeleteInstanceStack(instanceID string, resourcesToRetain []string) error {
+	f.DeleteInstanceStackCalled = true
+	f.DeleteInstanceStackInstanceID = instanceID
+	f.DeleteInstanceStackResourcesToRetain = resourcesToRetain
+
+	return f.DeleteInstanceStackError
+}
+
+func (f *FakeBroker) ImportInstance(instanceID string, stackName string, serviceID string, planID string) error {
+	f.ImportInstanceCalled = true
+	f.ImportInstanceInstanceID = instanceID
+	f.ImportInstanceStackName = stackName
+	f.ImportInstanceServiceID = serviceID
+	f.ImportInstancePlanID = planID
+
+	return f.ImportInstanceError
+}
@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/cloudformation-broker/redact"
+)
+
+const OutcomeSucceeded = "succeeded"
+const OutcomeAccepted = "accepted"
+const OutcomeFailed = "failed"
+
+// StackIDResolver finds the ID of the stack of a service instance among the
+// stacks the broker already knows, so auditing a request does not describe
+// the stack again.
+type StackIDResolver interface {
+	TrackedStackID(instanceID string) (string, bool)
+}
+
+// Record is the audit trail entry of a service broker operation.
+type Record struct {
+	Operation           string
+	InstanceID          string
+	BindingID           string
+	ServiceID           string
+	PlanID              string
+	OrganizationID      string
+	SpaceID             string
+	OriginatingIdentity OriginatingIdentity
+	Parameters          map[string]interface{}
+	StackID             string
+	Outcome             string
+	Error               string
+}
+
+// Auditor writes audit records to a dedicated logger, so they can be sent to
+// their own sinks regardless of the broker log level.
+type Auditor struct {
+	logger   lager.Logger
+	stackIDs StackIDResolver
+	redactor *redact.Redactor
+}
+
+func New(logger lager.Logger, stackIDs StackIDResolver, redactor *redact.Redactor) *Auditor {
+	return &Auditor{
+		logger:   logger,
+		stackIDs: stackIDs,
+		redactor: redactor,
+	}
+}
+
+func (a *Auditor) Record(record Record) {
+	if record.StackID == "" && record.InstanceID != "" {
+		// The stack might not exist, for example if provisioning it failed
+		if stackID, ok := a.stackIDs.TrackedStackID(record.InstanceID); ok {
+			record.StackID = stackID
+		}
+	}
+
+	data := lager.Data{
+		"operation":   record.Operation,
+		"instance_id": record.InstanceID,
+		"service_id":  record.ServiceID,
+		"plan_id":     record.PlanID,
+		"stack_id":    record.StackID,
+		"outcome":     record.Outcome,
+	}
+
+	if record.BindingID != "" {
+		data["binding_id"] = record.BindingID
+	}
+	if record.OrganizationID != "" {
+		data["organization_id"] = record.OrganizationID
+	}
+	if record.SpaceID != "" {
+		data["space_id"] = record.SpaceID
+	}
+	if record.OriginatingIdentity.Platform != "" {
+		data["originating_identity"] = record.OriginatingIdentity
+	}
+	if record.Parameters != nil {
+		data["parameters"] = a.redactor.Parameters(record.Parameters)
+	}
+	if record.Error != "" {
+		data["error"] = record.Error
+	}
+
+	a.logger.Info(record.Operation, data)
+}
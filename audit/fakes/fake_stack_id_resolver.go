@@ -0,0 +1,15 @@
+package fakes
+
+type FakeStackIDResolver struct {
+	TrackedStackIDCalled     bool
+	TrackedStackIDInstanceID string
+	TrackedStackIDStackID    string
+	TrackedStackIDFound      bool
+}
+
+func (f *FakeStackIDResolver) TrackedStackID(instanceID string) (string, bool) {
+	f.TrackedStackIDCalled = true
+	f.TrackedStackIDInstanceID = instanceID
+
+	return f.TrackedStackIDStackID, f.TrackedStackIDFound
+}
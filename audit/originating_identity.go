@@ -0,0 +1,50 @@
+package audit
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// OriginatingIdentityHeader identifies the platform user that triggered a
+// Service Broker API request.
+const OriginatingIdentityHeader = "X-Broker-API-Originating-Identity"
+
+// OriginatingIdentity holds the platform of the originating identity header
+// and its decoded value. The raw value is kept when it cannot be decoded.
+type OriginatingIdentity struct {
+	Platform string                 `json:"platform"`
+	Value    map[string]interface{} `json:"value,omitempty"`
+	Raw      string                 `json:"raw,omitempty"`
+}
+
+// ParseOriginatingIdentity parses an originating identity header, formatted
+// as the platform followed by the base64 encoded JSON identity.
+func ParseOriginatingIdentity(header string) OriginatingIdentity {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return OriginatingIdentity{}
+	}
+
+	parts := strings.SplitN(header, " ", 2)
+	originatingIdentity := OriginatingIdentity{Platform: parts[0]}
+	if len(parts) < 2 {
+		return originatingIdentity
+	}
+
+	encodedValue := strings.TrimSpace(parts[1])
+	decodedValue, err := base64.StdEncoding.DecodeString(encodedValue)
+	if err != nil {
+		originatingIdentity.Raw = encodedValue
+		return originatingIdentity
+	}
+
+	value := map[string]interface{}{}
+	if err := json.Unmarshal(decodedValue, &value); err != nil {
+		originatingIdentity.Raw = encodedValue
+		return originatingIdentity
+	}
+	originatingIdentity.Value = value
+
+	return originatingIdentity
+}
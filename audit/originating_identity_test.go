@@ -0,0 +1,31 @@
+package audit_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/audit"
+)
+
+var _ = Describe("ParseOriginatingIdentity", func() {
+	It("decodes the identity value", func() {
+		// {"user_id": "683ea748-3092-4ff4-b656-39cacc4d5360"}
+		originatingIdentity := ParseOriginatingIdentity("cloudfoundry eyJ1c2VyX2lkIjogIjY4M2VhNzQ4LTMwOTItNGZmNC1iNjU2LTM5Y2FjYzRkNTM2MCJ9")
+		Expect(originatingIdentity).To(Equal(OriginatingIdentity{
+			Platform: "cloudfoundry",
+			Value:    map[string]interface{}{"user_id": "683ea748-3092-4ff4-b656-39cacc4d5360"},
+		}))
+	})
+
+	It("keeps the raw value when it cannot be decoded", func() {
+		originatingIdentity := ParseOriginatingIdentity("cloudfoundry not-base64")
+		Expect(originatingIdentity).To(Equal(OriginatingIdentity{
+			Platform: "cloudfoundry",
+			Raw:      "not-base64",
+		}))
+	})
+
+	It("returns an empty identity when there is no header", func() {
+		Expect(ParseOriginatingIdentity("")).To(Equal(OriginatingIdentity{}))
+	})
+})
@@ -0,0 +1,132 @@
+package audit
+
+import (
+	"net/http"
+
+	"github.com/frodenas/brokerapi"
+)
+
+// NewHandler serves the Service Broker API built by newBrokerAPI with a
+// service broker that audits the operations of each request on behalf of
+// the request originating identity.
+func NewHandler(serviceBroker brokerapi.ServiceBroker, auditor *Auditor, newBrokerAPI func(brokerapi.ServiceBroker) http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		originatingIdentity := ParseOriginatingIdentity(req.Header.Get(OriginatingIdentityHeader))
+		newBrokerAPI(NewServiceBroker(serviceBroker, auditor, originatingIdentity)).ServeHTTP(w, req)
+	})
+}
+
+// ServiceBroker records an audit record for each operation of the service
+// broker it wraps that changes service instances or bindings. Catalog and
+// last operation requests are read only and are not audited.
+type ServiceBroker struct {
+	serviceBroker       brokerapi.ServiceBroker
+	auditor             *Auditor
+	originatingIdentity OriginatingIdentity
+}
+
+func NewServiceBroker(serviceBroker brokerapi.ServiceBroker, auditor *Auditor, originatingIdentity OriginatingIdentity) *ServiceBroker {
+	return &ServiceBroker{
+		serviceBroker:       serviceBroker,
+		auditor:             auditor,
+		originatingIdentity: originatingIdentity,
+	}
+}
+
+func (b *ServiceBroker) Services() brokerapi.CatalogResponse {
+	return b.serviceBroker.Services()
+}
+
+func (b *ServiceBroker) Provision(instanceID string, details brokerapi.ProvisionDetails, acceptsIncomplete bool) (brokerapi.ProvisioningResponse, bool, error) {
+	provisioningResponse, asynch, err := b.serviceBroker.Provision(instanceID, details, acceptsIncomplete)
+
+	b.record(Record{
+		Operation:      "provision",
+		InstanceID:     instanceID,
+		ServiceID:      details.ServiceID,
+		PlanID:         details.PlanID,
+		OrganizationID: details.OrganizationGUID,
+		SpaceID:        details.SpaceGUID,
+		Parameters:     details.Parameters,
+	}, asynch, err)
+
+	return provisioningResponse, asynch, err
+}
+
+func (b *ServiceBroker) Update(instanceID string, details brokerapi.UpdateDetails, acceptsIncomplete bool) (bool, error) {
+	asynch, err := b.serviceBroker.Update(instanceID, details, acceptsIncomplete)
+
+	b.record(Record{
+		Operation:      "update",
+		InstanceID:     instanceID,
+		ServiceID:      details.ServiceID,
+		PlanID:         details.PlanID,
+		OrganizationID: details.PreviousValues.OrganizationID,
+		SpaceID:        details.PreviousValues.SpaceID,
+		Parameters:     details.Parameters,
+	}, asynch, err)
+
+	return asynch, err
+}
+
+func (b *ServiceBroker) Deprovision(instanceID string, details brokerapi.DeprovisionDetails, acceptsIncomplete bool) (bool, error) {
+	asynch, err := b.serviceBroker.Deprovision(instanceID, details, acceptsIncomplete)
+
+	b.record(Record{
+		Operation:  "deprovision",
+		InstanceID: instanceID,
+		ServiceID:  details.ServiceID,
+		PlanID:     details.PlanID,
+	}, asynch, err)
+
+	return asynch, err
+}
+
+func (b *ServiceBroker) Bind(instanceID, bindingID string, details brokerapi.BindDetails) (brokerapi.BindingResponse, error) {
+	bindingResponse, err := b.serviceBroker.Bind(instanceID, bindingID, details)
+
+	b.record(Record{
+		Operation:  "bind",
+		InstanceID: instanceID,
+		BindingID:  bindingID,
+		ServiceID:  details.ServiceID,
+		PlanID:     details.PlanID,
+		Parameters: details.Parameters,
+	}, false, err)
+
+	return bindingResponse, err
+}
+
+func (b *ServiceBroker) Unbind(instanceID, bindingID string, details brokerapi.UnbindDetails) error {
+	err := b.serviceBroker.Unbind(instanceID, bindingID, details)
+
+	b.record(Record{
+		Operation:  "unbind",
+		InstanceID: instanceID,
+		BindingID:  bindingID,
+		ServiceID:  details.ServiceID,
+		PlanID:     details.PlanID,
+	}, false, err)
+
+	return err
+}
+
+func (b *ServiceBroker) LastOperation(instanceID string) (brokerapi.LastOperationResponse, error) {
+	return b.serviceBroker.LastOperation(instanceID)
+}
+
+func (b *ServiceBroker) record(record Record, asynch bool, err error) {
+	record.OriginatingIdentity = b.originatingIdentity
+
+	switch {
+	case err != nil:
+		record.Outcome = OutcomeFailed
+		record.Error = err.Error()
+	case asynch:
+		record.Outcome = OutcomeAccepted
+	default:
+		record.Outcome = OutcomeSucceeded
+	}
+
+	b.auditor.Record(record)
+}
@@ -0,0 +1,151 @@
+package audit_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/audit"
+
+	"github.com/frodenas/brokerapi"
+	brokerfakes "github.com/frodenas/brokerapi/fakes"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/cf-platform-eng/cloudformation-broker/audit/fakes"
+	"github.com/cf-platform-eng/cloudformation-broker/redact"
+)
+
+var _ = Describe("ServiceBroker", func() {
+	var (
+		fakeServiceBroker *brokerfakes.FakeServiceBroker
+		stackIDResolver   *fakes.FakeStackIDResolver
+		auditLogger       *lagertest.TestLogger
+		identity          OriginatingIdentity
+		serviceBroker     *ServiceBroker
+	)
+
+	BeforeEach(func() {
+		fakeServiceBroker = &brokerfakes.FakeServiceBroker{}
+		stackIDResolver = &fakes.FakeStackIDResolver{TrackedStackIDStackID: "test-stack-id", TrackedStackIDFound: true}
+		auditLogger = lagertest.NewTestLogger("audit")
+		identity = OriginatingIdentity{Platform: "cloudfoundry", Value: map[string]interface{}{"user_id": "user-id"}}
+	})
+
+	JustBeforeEach(func() {
+		serviceBroker = NewServiceBroker(fakeServiceBroker, New(auditLogger, stackIDResolver, redact.New()), identity)
+	})
+
+	lastRecord := func() lager.LogFormat {
+		logs := auditLogger.Logs()
+		Expect(logs).ToNot(BeEmpty())
+		return logs[len(logs)-1]
+	}
+
+	var _ = Describe("Provision", func() {
+		var details brokerapi.ProvisionDetails
+
+		BeforeEach(func() {
+			fakeServiceBroker.ProvisionAsynch = true
+			details = brokerapi.ProvisionDetails{
+				ServiceID:        "service-id",
+				PlanID:           "plan-id",
+				OrganizationGUID: "organization-id",
+				SpaceGUID:        "space-id",
+				Parameters:       map[string]interface{}{"DBName": "test-db", "DBPassword": "test-password"},
+			}
+		})
+
+		It("calls the wrapped service broker", func() {
+			_, asynch, err := serviceBroker.Provision("instance-id", details, true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(asynch).To(BeTrue())
+			Expect(fakeServiceBroker.ProvisionInstanceID).To(Equal("instance-id"))
+			Expect(fakeServiceBroker.ProvisionDetails.Parameters["DBPassword"]).To(Equal("test-password"))
+		})
+
+		It("records the operation with the parameters redacted", func() {
+			serviceBroker.Provision("instance-id", details, true)
+
+			record := lastRecord()
+			Expect(record.Message).To(Equal("audit.provision"))
+			Expect(record.LogLevel).To(Equal(lager.INFO))
+			Expect(record.Data["operation"]).To(Equal("provision"))
+			Expect(record.Data["instance_id"]).To(Equal("instance-id"))
+			Expect(record.Data["service_id"]).To(Equal("service-id"))
+			Expect(record.Data["plan_id"]).To(Equal("plan-id"))
+			Expect(record.Data["organization_id"]).To(Equal("organization-id"))
+			Expect(record.Data["space_id"]).To(Equal("space-id"))
+			Expect(record.Data["stack_id"]).To(Equal("test-stack-id"))
+			Expect(record.Data["outcome"]).To(Equal(OutcomeAccepted))
+			Expect(record.Data["parameters"]).To(Equal(map[string]interface{}{"DBName": "test-db", "DBPassword": "[REDACTED]"}))
+			Expect(record.Data["originating_identity"]).To(Equal(map[string]interface{}{
+				"platform": "cloudfoundry",
+				"value":    map[string]interface{}{"user_id": "user-id"},
+			}))
+			Expect(stackIDResolver.TrackedStackIDInstanceID).To(Equal("instance-id"))
+		})
+
+		Context("when provisioning fails", func() {
+			BeforeEach(func() {
+				fakeServiceBroker.ProvisionAsynch = false
+				fakeServiceBroker.ProvisionError = errors.New("operation failed")
+				stackIDResolver.TrackedStackIDStackID = ""
+				stackIDResolver.TrackedStackIDFound = false
+			})
+
+			It("records the failure", func() {
+				_, _, err := serviceBroker.Provision("instance-id", details, true)
+				Expect(err).To(HaveOccurred())
+
+				record := lastRecord()
+				Expect(record.Data["outcome"]).To(Equal(OutcomeFailed))
+				Expect(record.Data["error"]).To(Equal("operation failed"))
+				Expect(record.Data["stack_id"]).To(Equal(""))
+			})
+		})
+	})
+
+	var _ = Describe("Bind", func() {
+		It("records the operation", func() {
+			serviceBroker.Bind("instance-id", "binding-id", brokerapi.BindDetails{ServiceID: "service-id", PlanID: "plan-id"})
+
+			record := lastRecord()
+			Expect(record.Data["operation"]).To(Equal("bind"))
+			Expect(record.Data["binding_id"]).To(Equal("binding-id"))
+			Expect(record.Data["outcome"]).To(Equal(OutcomeSucceeded))
+		})
+	})
+
+	var _ = Describe("LastOperation", func() {
+		It("is not audited", func() {
+			serviceBroker.LastOperation("instance-id")
+			Expect(auditLogger.Logs()).To(BeEmpty())
+		})
+	})
+
+	var _ = Describe("NewHandler", func() {
+		It("audits on behalf of the request originating identity", func() {
+			handler := NewHandler(fakeServiceBroker, New(auditLogger, stackIDResolver, redact.New()), func(serviceBroker brokerapi.ServiceBroker) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					serviceBroker.Unbind("instance-id", "binding-id", brokerapi.UnbindDetails{})
+				})
+			})
+
+			request, _ := http.NewRequest("DELETE", "/v2/service_instances/instance-id/service_bindings/binding-id", nil)
+			// {"user_id": "user-id"}
+			request.Header.Set(OriginatingIdentityHeader, "cloudfoundry eyJ1c2VyX2lkIjogInVzZXItaWQifQ==")
+			handler.ServeHTTP(httptest.NewRecorder(), request)
+
+			record := lastRecord()
+			Expect(record.Data["operation"]).To(Equal("unbind"))
+			Expect(record.Data["originating_identity"]).To(Equal(map[string]interface{}{
+				"platform": "cloudfoundry",
+				"value":    map[string]interface{}{"user_id": "user-id"},
+			}))
+		})
+	})
+})
@@ -0,0 +1,245 @@
+package awscf
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+)
+
+// CloudFormation operations not yet available in the vendored AWS SDK. They
+// are sent through the CloudFormation client request pipeline, so they are
+// signed, serialized and retried as any other CloudFormation API call.
+
+const opContinueUpdateRollback = "ContinueUpdateRollback"
+
+type continueUpdateRollbackInput struct {
+	StackName       *string   `type:"string" required:"true"`
+	ResourcesToSkip []*string `type:"list"`
+
+	metadataContinueUpdateRollbackInput `json:"-" xml:"-"`
+}
+
+type metadataContinueUpdateRollbackInput struct {
+	SDKShapeTraits bool `type:"structure"`
+}
+
+type continueUpdateRollbackOutput struct {
+	metadataContinueUpdateRollbackOutput `json:"-" xml:"-"`
+}
+
+type metadataContinueUpdateRollbackOutput struct {
+	SDKShapeTraits bool `type:"structure"`
+}
+
+func continueUpdateRollback(cfsvc *cloudformation.CloudFormation, input *continueUpdateRollbackInput) (*continueUpdateRollbackOutput, error) {
+	op := &request.Operation{
+		Name:       opContinueUpdateRollback,
+		HTTPMethod: "POST",
+		HTTPPath:   "/",
+	}
+
+	output := &continueUpdateRollbackOutput{}
+	req := cfsvc.NewRequest(op, input, output)
+
+	return output, req.Send()
+}
+
+// UpdateStack and DeleteStack inputs with the Tags and RetainResources
+// fields missing from the vendored AWS SDK.
+
+const opUpdateStack = "UpdateStack"
+const opDeleteStack = "DeleteStack"
+
+type updateStackTagsInput struct {
+	StackName           *string                     `type:"string" required:"true"`
+	UsePreviousTemplate *bool                       `type:"boolean"`
+	Parameters          []*cloudformation.Parameter `type:"list"`
+	Capabilities        []*string                   `type:"list"`
+	Tags                []*cloudformation.Tag       `type:"list"`
+
+	metadataUpdateStackTagsInput `json:"-" xml:"-"`
+}
+
+type metadataUpdateStackTagsInput struct {
+	SDKShapeTraits bool `type:"structure"`
+}
+
+type deleteStackRetainingResourcesInput struct {
+	StackName       *string   `type:"string" required:"true"`
+	RetainResources []*string `type:"list"`
+
+	metadataDeleteStackRetainingResourcesInput `json:"-" xml:"-"`
+}
+
+type metadataDeleteStackRetainingResourcesInput struct {
+	SDKShapeTraits bool `type:"structure"`
+}
+
+func updateStackTags(cfsvc *cloudformation.CloudFormation, input *updateStackTagsInput) (*cloudformation.UpdateStackOutput, error) {
+	op := &request.Operation{
+		Name:       opUpdateStack,
+		HTTPMethod: "POST",
+		HTTPPath:   "/",
+	}
+
+	output := &cloudformation.UpdateStackOutput{}
+	req := cfsvc.NewRequest(op, input, output)
+
+	return output, req.Send()
+}
+
+func deleteStackRetainingResources(cfsvc *cloudformation.CloudFormation, input *deleteStackRetainingResourcesInput) (*cloudformation.DeleteStackOutput, error) {
+	op := &request.Operation{
+		Name:       opDeleteStack,
+		HTTPMethod: "POST",
+		HTTPPath:   "/",
+	}
+
+	output := &cloudformation.DeleteStackOutput{}
+	req := cfsvc.NewRequest(op, input, output)
+
+	return output, req.Send()
+}
+
+// Drift detection operations.
+
+const opDetectStackDrift = "DetectStackDrift"
+const opDescribeStackDriftDetectionStatus = "DescribeStackDriftDetectionStatus"
+const opDescribeStackResourceDrifts = "DescribeStackResourceDrifts"
+
+type detectStackDriftInput struct {
+	StackName *string `type:"string" required:"true"`
+
+	metadataDetectStackDriftInput `json:"-" xml:"-"`
+}
+
+type metadataDetectStackDriftInput struct {
+	SDKShapeTraits bool `type:"structure"`
+}
+
+type detectStackDriftOutput struct {
+	StackDriftDetectionId *string `type:"string" required:"true"`
+
+	metadataDetectStackDriftOutput `json:"-" xml:"-"`
+}
+
+type metadataDetectStackDriftOutput struct {
+	SDKShapeTraits bool `type:"structure"`
+}
+
+type describeStackDriftDetectionStatusInput struct {
+	StackDriftDetectionId *string `type:"string" required:"true"`
+
+	metadataDescribeStackDriftDetectionStatusInput `json:"-" xml:"-"`
+}
+
+type metadataDescribeStackDriftDetectionStatusInput struct {
+	SDKShapeTraits bool `type:"structure"`
+}
+
+type describeStackDriftDetectionStatusOutput struct {
+	StackId                   *string    `type:"string" required:"true"`
+	StackDriftDetectionId     *string    `type:"string" required:"true"`
+	StackDriftStatus          *string    `type:"string"`
+	DetectionStatus           *string    `type:"string" required:"true"`
+	DetectionStatusReason     *string    `type:"string"`
+	DriftedStackResourceCount *int64     `type:"integer"`
+	Timestamp                 *time.Time `type:"timestamp" timestampFormat:"iso8601" required:"true"`
+
+	metadataDescribeStackDriftDetectionStatusOutput `json:"-" xml:"-"`
+}
+
+type metadataDescribeStackDriftDetectionStatusOutput struct {
+	SDKShapeTraits bool `type:"structure"`
+}
+
+type describeStackResourceDriftsInput struct {
+	StackName                       *string   `type:"string" required:"true"`
+	StackResourceDriftStatusFilters []*string `type:"list"`
+	NextToken                       *string   `type:"string"`
+
+	metadataDescribeStackResourceDriftsInput `json:"-" xml:"-"`
+}
+
+type metadataDescribeStackResourceDriftsInput struct {
+	SDKShapeTraits bool `type:"structure"`
+}
+
+type describeStackResourceDriftsOutput struct {
+	StackResourceDrifts []*stackResourceDrift `type:"list" required:"true"`
+	NextToken           *string               `type:"string"`
+
+	metadataDescribeStackResourceDriftsOutput `json:"-" xml:"-"`
+}
+
+type metadataDescribeStackResourceDriftsOutput struct {
+	SDKShapeTraits bool `type:"structure"`
+}
+
+type stackResourceDrift struct {
+	LogicalResourceId        *string               `type:"string" required:"true"`
+	PhysicalResourceId       *string               `type:"string"`
+	ResourceType             *string               `type:"string" required:"true"`
+	StackResourceDriftStatus *string               `type:"string" required:"true"`
+	PropertyDifferences      []*propertyDifference `type:"list"`
+	Timestamp                *time.Time            `type:"timestamp" timestampFormat:"iso8601" required:"true"`
+
+	metadataStackResourceDrift `json:"-" xml:"-"`
+}
+
+type metadataStackResourceDrift struct {
+	SDKShapeTraits bool `type:"structure"`
+}
+
+type propertyDifference struct {
+	PropertyPath   *string `type:"string" required:"true"`
+	ExpectedValue  *string `type:"string" required:"true"`
+	ActualValue    *string `type:"string" required:"true"`
+	DifferenceType *string `type:"string" required:"true"`
+
+	metadataPropertyDifference `json:"-" xml:"-"`
+}
+
+type metadataPropertyDifference struct {
+	SDKShapeTraits bool `type:"structure"`
+}
+
+func detectStackDrift(cfsvc *cloudformation.CloudFormation, input *detectStackDriftInput) (*detectStackDriftOutput, error) {
+	op := &request.Operation{
+		Name:       opDetectStackDrift,
+		HTTPMethod: "POST",
+		HTTPPath:   "/",
+	}
+
+	output := &detectStackDriftOutput{}
+	req := cfsvc.NewRequest(op, input, output)
+
+	return output, req.Send()
+}
+
+func describeStackDriftDetectionStatus(cfsvc *cloudformation.CloudFormation, input *describeStackDriftDetectionStatusInput) (*describeStackDriftDetectionStatusOutput, error) {
+	op := &request.Operation{
+		Name:       opDescribeStackDriftDetectionStatus,
+		HTTPMethod: "POST",
+		HTTPPath:   "/",
+	}
+
+	output := &describeStackDriftDetectionStatusOutput{}
+	req := cfsvc.NewRequest(op, input, output)
+
+	return output, req.Send()
+}
+
+func describeStackResourceDrifts(cfsvc *cloudformation.CloudFormation, input *describeStackResourceDriftsInput) (*describeStackResourceDriftsOutput, error) {
+	op := &request.Operation{
+		Name:       opDescribeStackResourceDrifts,
+		HTTPMethod: "POST",
+		HTTPPath:   "/",
+	}
+
+	output := &describeStackResourceDriftsOutput{}
+	req := cfsvc.NewRequest(op, input, output)
+
+	return output, req.Send()
+}
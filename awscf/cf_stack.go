@@ -2,6 +2,8 @@ package awscf
 
 import (
 	"errors"
+	"sort"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -24,6 +26,8 @@ func NewCloudFormationStack(
 	}
 }
 
+// Describe describes a stack by name or by ID. Deleted stacks can only be
+// described by ID.
 func (s *CloudFormationStack) Describe(stackName string) (StackDetails, error) {
 	stackDetails := StackDetails{}
 
@@ -48,7 +52,7 @@ func (s *CloudFormationStack) Describe(stackName string) (StackDetails, error) {
 	}
 
 	for _, stack := range stack.Stacks {
-		if aws.StringValue(stack.StackName) == stackName {
+		if aws.StringValue(stack.StackName) == stackName || aws.StringValue(stack.StackId) == stackName {
 			s.logger.Debug("describe-stacks", lager.Data{"stack": stack})
 			return s.buildStackDetails(stack), nil
 		}
@@ -57,7 +61,8 @@ func (s *CloudFormationStack) Describe(stackName string) (StackDetails, error) {
 	return stackDetails, ErrStackDoesNotExist
 }
 
-func (s *CloudFormationStack) Create(stackName string, stackDetails StackDetails) error {
+// Create creates a stack and returns its ID.
+func (s *CloudFormationStack) Create(stackName string, stackDetails StackDetails) (string, error) {
 	createStackInput := s.buildCreateStackInput(stackName, stackDetails)
 	s.logger.Debug("create-stack", lager.Data{"input": createStackInput})
 
@@ -65,13 +70,13 @@ func (s *CloudFormationStack) Create(stackName string, stackDetails StackDetails
 	if err != nil {
 		s.logger.Error("aws-cloudformation-error", err)
 		if awsErr, ok := err.(awserr.Error); ok {
-			return errors.New(awsErr.Code() + ": " + awsErr.Message())
+			return "", errors.New(awsErr.Code() + ": " + awsErr.Message())
 		}
-		return err
+		return "", err
 	}
 	s.logger.Debug("create-stack", lager.Data{"output": createStackOutput})
 
-	return nil
+	return aws.StringValue(createStackOutput.StackId), nil
 }
 
 func (s *CloudFormationStack) Modify(stackName string, stackDetails StackDetails) error {
@@ -116,7 +121,461 @@ func (s *CloudFormationStack) Delete(stackName string) error {
 	return nil
 }
 
+func (s *CloudFormationStack) ContinueUpdateRollback(stackName string, resourcesToSkip []string) error {
+	continueUpdateRollbackInput := &continueUpdateRollbackInput{
+		StackName: aws.String(stackName),
+	}
+	if len(resourcesToSkip) > 0 {
+		continueUpdateRollbackInput.ResourcesToSkip = aws.StringSlice(resourcesToSkip)
+	}
+	s.logger.Debug("continue-update-rollback", lager.Data{"input": continueUpdateRollbackInput})
+
+	continueUpdateRollbackOutput, err := continueUpdateRollback(s.cfsvc, continueUpdateRollbackInput)
+	if err != nil {
+		s.logger.Error("aws-cloudformation-error", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			if reqErr, ok := err.(awserr.RequestFailure); ok {
+				// AWS CloudFormation returns a 400 if Stack is not found
+				if reqErr.StatusCode() == 400 && awsErr.Code() == "ValidationError" && isStackNotFoundMessage(awsErr.Message()) {
+					return ErrStackDoesNotExist
+				}
+			}
+			return errors.New(awsErr.Code() + ": " + awsErr.Message())
+		}
+		return err
+	}
+	s.logger.Debug("continue-update-rollback", lager.Data{"output": continueUpdateRollbackOutput})
+
+	return nil
+}
+
+func (s *CloudFormationStack) CancelUpdate(stackName string) error {
+	cancelUpdateStackInput := &cloudformation.CancelUpdateStackInput{
+		StackName: aws.String(stackName),
+	}
+	s.logger.Debug("cancel-update-stack", lager.Data{"input": cancelUpdateStackInput})
+
+	cancelUpdateStackOutput, err := s.cfsvc.CancelUpdateStack(cancelUpdateStackInput)
+	if err != nil {
+		s.logger.Error("aws-cloudformation-error", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			if reqErr, ok := err.(awserr.RequestFailure); ok {
+				// AWS CloudFormation returns a 400 if Stack is not found
+				if reqErr.StatusCode() == 400 && awsErr.Code() == "ValidationError" && isStackNotFoundMessage(awsErr.Message()) {
+					return ErrStackDoesNotExist
+				}
+			}
+			return errors.New(awsErr.Code() + ": " + awsErr.Message())
+		}
+		return err
+	}
+	s.logger.Debug("cancel-update-stack", lager.Data{"output": cancelUpdateStackOutput})
+
+	return nil
+}
+
+func (s *CloudFormationStack) ListResources(stackName string) ([]StackResource, error) {
+	var stackResources []StackResource
+
+	listStackResourcesInput := &cloudformation.ListStackResourcesInput{
+		StackName: aws.String(stackName),
+	}
+
+	for {
+		s.logger.Debug("list-stack-resources", lager.Data{"input": listStackResourcesInput})
+
+		listStackResourcesOutput, err := s.cfsvc.ListStackResources(listStackResourcesInput)
+		if err != nil {
+			s.logger.Error("aws-cloudformation-error", err)
+			if awsErr, ok := err.(awserr.Error); ok {
+				if reqErr, ok := err.(awserr.RequestFailure); ok {
+					// AWS CloudFormation returns a 400 if Stack is not found
+					if reqErr.StatusCode() == 400 && awsErr.Code() == "ValidationError" && isStackNotFoundMessage(awsErr.Message()) {
+						return stackResources, ErrStackDoesNotExist
+					}
+				}
+				return stackResources, errors.New(awsErr.Code() + ": " + awsErr.Message())
+			}
+			return stackResources, err
+		}
+
+		for _, resource := range listStackResourcesOutput.StackResourceSummaries {
+			stackResources = append(stackResources, StackResource{
+				LogicalResourceID:    aws.StringValue(resource.LogicalResourceId),
+				PhysicalResourceID:   aws.StringValue(resource.PhysicalResourceId),
+				ResourceType:         aws.StringValue(resource.ResourceType),
+				ResourceStatus:       aws.StringValue(resource.ResourceStatus),
+				ResourceStatusReason: aws.StringValue(resource.ResourceStatusReason),
+			})
+		}
+
+		if aws.StringValue(listStackResourcesOutput.NextToken) == "" {
+			break
+		}
+		listStackResourcesInput.NextToken = listStackResourcesOutput.NextToken
+	}
+
+	return stackResources, nil
+}
+
+// List returns the stacks of the account and region, except the ones that
+// have already been deleted.
+func (s *CloudFormationStack) List() ([]StackSummary, error) {
+	var stackSummaries []StackSummary
+
+	listStacksInput := &cloudformation.ListStacksInput{}
+
+	for {
+		s.logger.Debug("list-stacks", lager.Data{"input": listStacksInput})
+
+		listStacksOutput, err := s.cfsvc.ListStacks(listStacksInput)
+		if err != nil {
+			s.logger.Error("aws-cloudformation-error", err)
+			if awsErr, ok := err.(awserr.Error); ok {
+				return stackSummaries, errors.New(awsErr.Code() + ": " + awsErr.Message())
+			}
+			return stackSummaries, err
+		}
+
+		for _, stack := range listStacksOutput.StackSummaries {
+			if aws.StringValue(stack.StackStatus) == cloudformation.StackStatusDeleteComplete {
+				continue
+			}
+			stackSummaries = append(stackSummaries, StackSummary{
+				StackName:    aws.StringValue(stack.StackName),
+				StackID:      aws.StringValue(stack.StackId),
+				Status:       NewStatus(aws.StringValue(stack.StackStatus), aws.StringValue(stack.StackStatusReason)),
+				CreationTime: aws.TimeValue(stack.CreationTime),
+			})
+		}
+
+		if aws.StringValue(listStacksOutput.NextToken) == "" {
+			break
+		}
+		listStacksInput.NextToken = listStacksOutput.NextToken
+	}
+
+	return stackSummaries, nil
+}
+
+// DescribeAll describes the stacks of the account and region, except the
+// ones that have already been deleted.
+func (s *CloudFormationStack) DescribeAll() ([]StackDetails, error) {
+	var stacksDetails []StackDetails
+
+	describeStacksInput := &cloudformation.DescribeStacksInput{}
+
+	for {
+		s.logger.Debug("describe-stacks", lager.Data{"input": describeStacksInput})
+
+		describeStacksOutput, err := s.cfsvc.DescribeStacks(describeStacksInput)
+		if err != nil {
+			s.logger.Error("aws-cloudformation-error", err)
+			if awsErr, ok := err.(awserr.Error); ok {
+				return stacksDetails, errors.New(awsErr.Code() + ": " + awsErr.Message())
+			}
+			return stacksDetails, err
+		}
+
+		for _, stack := range describeStacksOutput.Stacks {
+			if aws.StringValue(stack.StackStatus) == cloudformation.StackStatusDeleteComplete {
+				continue
+			}
+			stacksDetails = append(stacksDetails, s.buildStackDetails(stack))
+		}
+
+		if aws.StringValue(describeStacksOutput.NextToken) == "" {
+			break
+		}
+		describeStacksInput.NextToken = describeStacksOutput.NextToken
+	}
+
+	return stacksDetails, nil
+}
+
+// NoEchoParameters returns the parameters of a template whose values must
+// not be displayed.
+func (s *CloudFormationStack) NoEchoParameters(templateURL string) ([]string, error) {
+	var noEchoParameters []string
+
+	getTemplateSummaryInput := &cloudformation.GetTemplateSummaryInput{
+		TemplateURL: aws.String(templateURL),
+	}
+	s.logger.Debug("get-template-summary", lager.Data{"input": getTemplateSummaryInput})
+
+	getTemplateSummaryOutput, err := s.cfsvc.GetTemplateSummary(getTemplateSummaryInput)
+	if err != nil {
+		s.logger.Error("aws-cloudformation-error", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			return noEchoParameters, errors.New(awsErr.Code() + ": " + awsErr.Message())
+		}
+		return noEchoParameters, err
+	}
+
+	for _, parameter := range getTemplateSummaryOutput.Parameters {
+		if aws.BoolValue(parameter.NoEcho) {
+			noEchoParameters = append(noEchoParameters, aws.StringValue(parameter.ParameterKey))
+		}
+	}
+
+	return noEchoParameters, nil
+}
+
+// Events returns the most recent events of a stack, newest first.
+func (s *CloudFormationStack) Events(stackName string) ([]StackEvent, error) {
+	var stackEvents []StackEvent
+
+	describeStackEventsInput := &cloudformation.DescribeStackEventsInput{
+		StackName: aws.String(stackName),
+	}
+	s.logger.Debug("describe-stack-events", lager.Data{"input": describeStackEventsInput})
+
+	describeStackEventsOutput, err := s.cfsvc.DescribeStackEvents(describeStackEventsInput)
+	if err != nil {
+		s.logger.Error("aws-cloudformation-error", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			if reqErr, ok := err.(awserr.RequestFailure); ok {
+				// AWS CloudFormation returns a 400 if Stack is not found
+				if reqErr.StatusCode() == 400 && awsErr.Code() == "ValidationError" && isStackNotFoundMessage(awsErr.Message()) {
+					return stackEvents, ErrStackDoesNotExist
+				}
+			}
+			return stackEvents, errors.New(awsErr.Code() + ": " + awsErr.Message())
+		}
+		return stackEvents, err
+	}
+
+	for _, event := range describeStackEventsOutput.StackEvents {
+		stackEvents = append(stackEvents, StackEvent{
+			EventID:              aws.StringValue(event.EventId),
+			LogicalResourceID:    aws.StringValue(event.LogicalResourceId),
+			PhysicalResourceID:   aws.StringValue(event.PhysicalResourceId),
+			ResourceType:         aws.StringValue(event.ResourceType),
+			ResourceStatus:       aws.StringValue(event.ResourceStatus),
+			ResourceStatusReason: aws.StringValue(event.ResourceStatusReason),
+			Timestamp:            aws.TimeValue(event.Timestamp),
+		})
+	}
+
+	return stackEvents, nil
+}
+
+// Template returns the body of the template the stack was last created or
+// updated with.
+func (s *CloudFormationStack) Template(stackName string) (string, error) {
+	getTemplateInput := &cloudformation.GetTemplateInput{
+		StackName: aws.String(stackName),
+	}
+	s.logger.Debug("get-template", lager.Data{"input": getTemplateInput})
+
+	getTemplateOutput, err := s.cfsvc.GetTemplate(getTemplateInput)
+	if err != nil {
+		s.logger.Error("aws-cloudformation-error", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			if reqErr, ok := err.(awserr.RequestFailure); ok {
+				// AWS CloudFormation returns a 400 if Stack is not found
+				if reqErr.StatusCode() == 400 && awsErr.Code() == "ValidationError" && isStackNotFoundMessage(awsErr.Message()) {
+					return "", ErrStackDoesNotExist
+				}
+			}
+			return "", errors.New(awsErr.Code() + ": " + awsErr.Message())
+		}
+		return "", err
+	}
+
+	return aws.StringValue(getTemplateOutput.TemplateBody), nil
+}
+
+// UpdateTags replaces the tags of a stack, keeping its template and
+// parameter values.
+func (s *CloudFormationStack) UpdateTags(stackName string, tags map[string]string) error {
+	stackDetails, err := s.Describe(stackName)
+	if err != nil {
+		return err
+	}
+
+	updateStackTagsInput := &updateStackTagsInput{
+		StackName:           aws.String(stackName),
+		UsePreviousTemplate: aws.Bool(true),
+		Tags:                BuilCloudFormationTags(tags),
+	}
+
+	var parameterKeys []string
+	for key := range stackDetails.Parameters {
+		parameterKeys = append(parameterKeys, key)
+	}
+	sort.Strings(parameterKeys)
+	for _, key := range parameterKeys {
+		updateStackTagsInput.Parameters = append(updateStackTagsInput.Parameters, &cloudformation.Parameter{
+			ParameterKey:     aws.String(key),
+			UsePreviousValue: aws.Bool(true),
+		})
+	}
+
+	if len(stackDetails.Capabilities) > 0 {
+		updateStackTagsInput.Capabilities = aws.StringSlice(stackDetails.Capabilities)
+	}
+	s.logger.Debug("update-stack-tags", lager.Data{"input": updateStackTagsInput})
+
+	updateStackOutput, err := updateStackTags(s.cfsvc, updateStackTagsInput)
+	if err != nil {
+		s.logger.Error("aws-cloudformation-error", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			if reqErr, ok := err.(awserr.RequestFailure); ok {
+				// AWS CloudFormation returns a 400 if Stack is not found
+				if reqErr.StatusCode() == 400 && awsErr.Code() == "ValidationError" && isStackNotFoundMessage(awsErr.Message()) {
+					return ErrStackDoesNotExist
+				}
+			}
+			return errors.New(awsErr.Code() + ": " + awsErr.Message())
+		}
+		return err
+	}
+	s.logger.Debug("update-stack-tags", lager.Data{"output": updateStackOutput})
+
+	return nil
+}
+
+// DeleteRetainingResources deletes a stack whose deletion failed, leaving
+// the given resources in place instead of trying to delete them again.
+func (s *CloudFormationStack) DeleteRetainingResources(stackName string, resourcesToRetain []string) error {
+	deleteStackRetainingResourcesInput := &deleteStackRetainingResourcesInput{
+		StackName: aws.String(stackName),
+	}
+	if len(resourcesToRetain) > 0 {
+		deleteStackRetainingResourcesInput.RetainResources = aws.StringSlice(resourcesToRetain)
+	}
+	s.logger.Debug("delete-stack", lager.Data{"input": deleteStackRetainingResourcesInput})
+
+	deleteStackOutput, err := deleteStackRetainingResources(s.cfsvc, deleteStackRetainingResourcesInput)
+	if err != nil {
+		s.logger.Error("aws-cloudformation-error", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			return errors.New(awsErr.Code() + ": " + awsErr.Message())
+		}
+		return err
+	}
+	s.logger.Debug("delete-stack", lager.Data{"output": deleteStackOutput})
+
+	return nil
+}
+
+// DetectDrift starts detecting the drift of a stack and returns the ID of
+// the detection.
+func (s *CloudFormationStack) DetectDrift(stackName string) (string, error) {
+	detectStackDriftInput := &detectStackDriftInput{
+		StackName: aws.String(stackName),
+	}
+	s.logger.Debug("detect-stack-drift", lager.Data{"input": detectStackDriftInput})
+
+	detectStackDriftOutput, err := detectStackDrift(s.cfsvc, detectStackDriftInput)
+	if err != nil {
+		s.logger.Error("aws-cloudformation-error", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			if reqErr, ok := err.(awserr.RequestFailure); ok {
+				// AWS CloudFormation returns a 400 if Stack is not found
+				if reqErr.StatusCode() == 400 && awsErr.Code() == "ValidationError" && isStackNotFoundMessage(awsErr.Message()) {
+					return "", ErrStackDoesNotExist
+				}
+			}
+			return "", errors.New(awsErr.Code() + ": " + awsErr.Message())
+		}
+		return "", err
+	}
+	s.logger.Debug("detect-stack-drift", lager.Data{"output": detectStackDriftOutput})
+
+	return aws.StringValue(detectStackDriftOutput.StackDriftDetectionId), nil
+}
+
+// DriftDetectionStatus returns the status of a drift detection and, once it
+// is complete, the drift status of its stack.
+func (s *CloudFormationStack) DriftDetectionStatus(detectionID string) (DriftDetection, error) {
+	describeStackDriftDetectionStatusInput := &describeStackDriftDetectionStatusInput{
+		StackDriftDetectionId: aws.String(detectionID),
+	}
+	s.logger.Debug("describe-stack-drift-detection-status", lager.Data{"input": describeStackDriftDetectionStatusInput})
+
+	describeStackDriftDetectionStatusOutput, err := describeStackDriftDetectionStatus(s.cfsvc, describeStackDriftDetectionStatusInput)
+	if err != nil {
+		s.logger.Error("aws-cloudformation-error", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			return DriftDetection{}, errors.New(awsErr.Code() + ": " + awsErr.Message())
+		}
+		return DriftDetection{}, err
+	}
+	s.logger.Debug("describe-stack-drift-detection-status", lager.Data{"output": describeStackDriftDetectionStatusOutput})
+
+	return DriftDetection{
+		DetectionID:           aws.StringValue(describeStackDriftDetectionStatusOutput.StackDriftDetectionId),
+		StackID:               aws.StringValue(describeStackDriftDetectionStatusOutput.StackId),
+		DetectionStatus:       aws.StringValue(describeStackDriftDetectionStatusOutput.DetectionStatus),
+		DetectionStatusReason: aws.StringValue(describeStackDriftDetectionStatusOutput.DetectionStatusReason),
+		StackDriftStatus:      aws.StringValue(describeStackDriftDetectionStatusOutput.StackDriftStatus),
+		DriftedResourceCount:  aws.Int64Value(describeStackDriftDetectionStatusOutput.DriftedStackResourceCount),
+		Timestamp:             aws.TimeValue(describeStackDriftDetectionStatusOutput.Timestamp),
+	}, nil
+}
+
+// ResourceDrifts returns the resources of a stack that were modified or
+// deleted outside of AWS CloudFormation, as found by the last drift
+// detection.
+func (s *CloudFormationStack) ResourceDrifts(stackName string) ([]ResourceDrift, error) {
+	resourceDrifts := []ResourceDrift{}
+
+	describeStackResourceDriftsInput := &describeStackResourceDriftsInput{
+		StackName:                       aws.String(stackName),
+		StackResourceDriftStatusFilters: aws.StringSlice([]string{"MODIFIED", "DELETED"}),
+	}
+
+	for {
+		s.logger.Debug("describe-stack-resource-drifts", lager.Data{"input": describeStackResourceDriftsInput})
+
+		describeStackResourceDriftsOutput, err := describeStackResourceDrifts(s.cfsvc, describeStackResourceDriftsInput)
+		if err != nil {
+			s.logger.Error("aws-cloudformation-error", err)
+			if awsErr, ok := err.(awserr.Error); ok {
+				if reqErr, ok := err.(awserr.RequestFailure); ok {
+					// AWS CloudFormation returns a 400 if Stack is not found
+					if reqErr.StatusCode() == 400 && awsErr.Code() == "ValidationError" && isStackNotFoundMessage(awsErr.Message()) {
+						return resourceDrifts, ErrStackDoesNotExist
+					}
+				}
+				return resourceDrifts, errors.New(awsErr.Code() + ": " + awsErr.Message())
+			}
+			return resourceDrifts, err
+		}
+
+		for _, drift := range describeStackResourceDriftsOutput.StackResourceDrifts {
+			resourceDrift := ResourceDrift{
+				LogicalResourceID:  aws.StringValue(drift.LogicalResourceId),
+				PhysicalResourceID: aws.StringValue(drift.PhysicalResourceId),
+				ResourceType:       aws.StringValue(drift.ResourceType),
+				DriftStatus:        aws.StringValue(drift.StackResourceDriftStatus),
+				Timestamp:          aws.TimeValue(drift.Timestamp),
+			}
+			for _, difference := range drift.PropertyDifferences {
+				resourceDrift.PropertyDifferences = append(resourceDrift.PropertyDifferences, PropertyDifference{
+					PropertyPath:   aws.StringValue(difference.PropertyPath),
+					ExpectedValue:  aws.StringValue(difference.ExpectedValue),
+					ActualValue:    aws.StringValue(difference.ActualValue),
+					DifferenceType: aws.StringValue(difference.DifferenceType),
+				})
+			}
+			resourceDrifts = append(resourceDrifts, resourceDrift)
+		}
+
+		if aws.StringValue(describeStackResourceDriftsOutput.NextToken) == "" {
+			break
+		}
+		describeStackResourceDriftsInput.NextToken = describeStackResourceDriftsOutput.NextToken
+	}
+
+	return resourceDrifts, nil
+}
+
 func (s *CloudFormationStack) buildStackDetails(stack *cloudformation.Stack) StackDetails {
+	status := NewStatus(aws.StringValue(stack.StackStatus), aws.StringValue(stack.StackStatusReason))
+
 	stackDetails := StackDetails{
 		StackName:        aws.StringValue(stack.StackName),
 		Capabilities:     aws.StringValueSlice(stack.Capabilities),
@@ -124,8 +583,11 @@ func (s *CloudFormationStack) buildStackDetails(stack *cloudformation.Stack) Sta
 		Description:      aws.StringValue(stack.Description),
 		NotificationARNs: aws.StringValueSlice(stack.NotificationARNs),
 		StackID:          aws.StringValue(stack.StackId),
-		StackStatus:      s.stackStatus(aws.StringValue(stack.StackStatus)),
+		Status:           status,
+		StackStatus:      status.Summary(),
 		TimeoutInMinutes: aws.Int64Value(stack.TimeoutInMinutes),
+		CreationTime:     aws.TimeValue(stack.CreationTime),
+		LastUpdatedTime:  aws.TimeValue(stack.LastUpdatedTime),
 	}
 
 	if stack.Parameters != nil && len(stack.Parameters) > 0 {
@@ -142,6 +604,13 @@ func (s *CloudFormationStack) buildStackDetails(stack *cloudformation.Stack) Sta
 		}
 	}
 
+	if stack.Tags != nil && len(stack.Tags) > 0 {
+		stackDetails.Tags = make(map[string]string)
+		for _, tag := range stack.Tags {
+			stackDetails.Tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+	}
+
 	return stackDetails
 }
 
@@ -219,29 +688,9 @@ func (s *CloudFormationStack) buildUpdateStackInput(stackName string, stackDetai
 	return updateStackInput
 }
 
-func (s *CloudFormationStack) stackStatus(status string) string {
-	switch status {
-	case cloudformation.StackStatusCreateComplete:
-		return StatusSucceeded
-	case cloudformation.StackStatusDeleteComplete:
-		return StatusSucceeded
-	case cloudformation.StackStatusUpdateComplete:
-		return StatusSucceeded
-	case cloudformation.StackStatusCreateInProgress:
-		return StatusInProgress
-	case cloudformation.StackStatusDeleteInProgress:
-		return StatusInProgress
-	case cloudformation.StackStatusUpdateInProgress:
-		return StatusInProgress
-	case cloudformation.StackStatusRollbackInProgress:
-		return StatusInProgress
-	case cloudformation.StackStatusUpdateCompleteCleanupInProgress:
-		return StatusInProgress
-	case cloudformation.StackStatusUpdateRollbackCompleteCleanupInProgress:
-		return StatusInProgress
-	case cloudformation.StackStatusUpdateRollbackInProgress:
-		return StatusInProgress
-	default:
-		return StatusFailed
-	}
+// AWS CloudFormation returns a 400 both for missing stacks and for stacks not
+// in a valid state for the requested action, so look at the message to tell
+// them apart
+func isStackNotFoundMessage(message string) bool {
+	return strings.HasSuffix(message, "does not exist")
 }
@@ -2,6 +2,11 @@ package awscf_test
 
 import (
 	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -12,11 +17,19 @@ import (
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/private/protocol/query"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/pivotal-golang/lager"
 	"github.com/pivotal-golang/lager/lagertest"
 )
 
+func xmlResponse(body string) *http.Response {
+	return &http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
+
 var _ = Describe("CloudFormation Stack", func() {
 	var (
 		stackName string
@@ -66,6 +79,11 @@ var _ = Describe("CloudFormation Stack", func() {
 				NotificationARNs: []string{"test-notification-arn"},
 				StackID:          "test-stack-id",
 				StackStatus:      StatusSucceeded,
+				Status: Status{
+					Operation: OperationCreate,
+					Phase:     PhaseComplete,
+					Raw:       cloudformation.StackStatusCreateComplete,
+				},
 				TimeoutInMinutes: int64(1),
 			}
 
@@ -150,10 +168,63 @@ var _ = Describe("CloudFormation Stack", func() {
 			})
 		})
 
+		Context("when the Stack has Tags", func() {
+			BeforeEach(func() {
+				describeStack.Tags = []*cloudformation.Tag{
+					&cloudformation.Tag{
+						Key:   aws.String("test-tag-key-1"),
+						Value: aws.String("test-tag-value-1"),
+					},
+				}
+
+				properStackDetails.Tags = map[string]string{
+					"test-tag-key-1": "test-tag-value-1",
+				}
+			})
+
+			It("returns the proper Stack Details", func() {
+				stackDetails, err := stack.Describe(stackName)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stackDetails).To(Equal(properStackDetails))
+			})
+		})
+
+		Context("when the Stack has been updated", func() {
+			BeforeEach(func() {
+				creationTime := time.Date(2015, time.November, 1, 10, 0, 0, 0, time.UTC)
+				lastUpdatedTime := time.Date(2015, time.November, 2, 10, 0, 0, 0, time.UTC)
+				describeStack.CreationTime = aws.Time(creationTime)
+				describeStack.LastUpdatedTime = aws.Time(lastUpdatedTime)
+				properStackDetails.CreationTime = creationTime
+				properStackDetails.LastUpdatedTime = lastUpdatedTime
+			})
+
+			It("returns the proper Stack Details", func() {
+				stackDetails, err := stack.Describe(stackName)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stackDetails).To(Equal(properStackDetails))
+			})
+		})
+
+		Context("when the Stack has a Status Reason", func() {
+			BeforeEach(func() {
+				describeStack.StackStatusReason = aws.String("test-stack-status-reason")
+				properStackDetails.Status.Reason = "test-stack-status-reason"
+			})
+
+			It("returns the proper Stack Details", func() {
+				stackDetails, err := stack.Describe(stackName)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stackDetails).To(Equal(properStackDetails))
+			})
+		})
+
 		Context("when the Stack Status is in progress", func() {
 			BeforeEach(func() {
 				describeStack.StackStatus = aws.String(cloudformation.StackStatusCreateInProgress)
 				properStackDetails.StackStatus = StatusInProgress
+				properStackDetails.Status.Phase = PhaseInProgress
+				properStackDetails.Status.Raw = cloudformation.StackStatusCreateInProgress
 			})
 
 			It("returns the proper Stack Details", func() {
@@ -167,6 +238,27 @@ var _ = Describe("CloudFormation Stack", func() {
 			BeforeEach(func() {
 				describeStack.StackStatus = aws.String(cloudformation.StackStatusCreateFailed)
 				properStackDetails.StackStatus = StatusFailed
+				properStackDetails.Status.Phase = PhaseFailed
+				properStackDetails.Status.Raw = cloudformation.StackStatusCreateFailed
+			})
+
+			It("returns the proper Stack Details", func() {
+				stackDetails, err := stack.Describe(stackName)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stackDetails).To(Equal(properStackDetails))
+			})
+		})
+
+		Context("when the Stack update was rolled back", func() {
+			BeforeEach(func() {
+				describeStack.StackStatus = aws.String(cloudformation.StackStatusUpdateRollbackComplete)
+				properStackDetails.StackStatus = StatusFailed
+				properStackDetails.Status = Status{
+					Operation:  OperationUpdate,
+					Phase:      PhaseComplete,
+					RolledBack: true,
+					Raw:        cloudformation.StackStatusUpdateRollbackComplete,
+				}
 			})
 
 			It("returns the proper Stack Details", func() {
@@ -176,6 +268,24 @@ var _ = Describe("CloudFormation Stack", func() {
 			})
 		})
 
+		Context("when the Stack is described by ID", func() {
+			BeforeEach(func() {
+				describeStack.StackStatus = aws.String(cloudformation.StackStatusDeleteComplete)
+				describeStacksInput.StackName = aws.String("test-stack-id")
+				properStackDetails.Status = Status{
+					Operation: OperationDelete,
+					Phase:     PhaseComplete,
+					Raw:       cloudformation.StackStatusDeleteComplete,
+				}
+			})
+
+			It("returns the proper Stack Details, even once deleted", func() {
+				stackDetails, err := stack.Describe("test-stack-id")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stackDetails).To(Equal(properStackDetails))
+			})
+		})
+
 		Context("when the Stack does not exists", func() {
 			JustBeforeEach(func() {
 				describeStacksInput = &cloudformation.DescribeStacksInput{
@@ -256,14 +366,17 @@ var _ = Describe("CloudFormation Stack", func() {
 				Expect(r.Operation.Name).To(MatchRegexp("CreateStack"))
 				Expect(r.Params).To(BeAssignableToTypeOf(&cloudformation.CreateStackInput{}))
 				Expect(r.Params).To(Equal(createStackInput))
+				data := r.Data.(*cloudformation.CreateStackOutput)
+				data.StackId = aws.String("test-stack-id")
 				r.Error = createStackError
 			}
 			cfsvc.Handlers.Send.PushBack(cfCall)
 		})
 
-		It("creates the Stack", func() {
-			err := stack.Create(stackName, stackDetails)
+		It("creates the Stack and returns its ID", func() {
+			stackID, err := stack.Create(stackName, stackDetails)
 			Expect(err).ToNot(HaveOccurred())
+			Expect(stackID).To(Equal("test-stack-id"))
 		})
 
 		Context("when has Capabilities", func() {
@@ -273,7 +386,7 @@ var _ = Describe("CloudFormation Stack", func() {
 			})
 
 			It("makes the proper call", func() {
-				err := stack.Create(stackName, stackDetails)
+				_, err := stack.Create(stackName, stackDetails)
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
@@ -285,7 +398,7 @@ var _ = Describe("CloudFormation Stack", func() {
 			})
 
 			It("makes the proper call", func() {
-				err := stack.Create(stackName, stackDetails)
+				_, err := stack.Create(stackName, stackDetails)
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
@@ -297,7 +410,7 @@ var _ = Describe("CloudFormation Stack", func() {
 			})
 
 			It("makes the proper call", func() {
-				err := stack.Create(stackName, stackDetails)
+				_, err := stack.Create(stackName, stackDetails)
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
@@ -309,7 +422,7 @@ var _ = Describe("CloudFormation Stack", func() {
 			})
 
 			It("makes the proper call", func() {
-				err := stack.Create(stackName, stackDetails)
+				_, err := stack.Create(stackName, stackDetails)
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
@@ -323,7 +436,7 @@ var _ = Describe("CloudFormation Stack", func() {
 			})
 
 			It("makes the proper call", func() {
-				err := stack.Create(stackName, stackDetails)
+				_, err := stack.Create(stackName, stackDetails)
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
@@ -335,7 +448,7 @@ var _ = Describe("CloudFormation Stack", func() {
 			})
 
 			It("makes the proper call", func() {
-				err := stack.Create(stackName, stackDetails)
+				_, err := stack.Create(stackName, stackDetails)
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
@@ -347,7 +460,7 @@ var _ = Describe("CloudFormation Stack", func() {
 			})
 
 			It("makes the proper call", func() {
-				err := stack.Create(stackName, stackDetails)
+				_, err := stack.Create(stackName, stackDetails)
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
@@ -361,7 +474,7 @@ var _ = Describe("CloudFormation Stack", func() {
 			})
 
 			It("makes the proper call", func() {
-				err := stack.Create(stackName, stackDetails)
+				_, err := stack.Create(stackName, stackDetails)
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
@@ -373,7 +486,7 @@ var _ = Describe("CloudFormation Stack", func() {
 			})
 
 			It("makes the proper call", func() {
-				err := stack.Create(stackName, stackDetails)
+				_, err := stack.Create(stackName, stackDetails)
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
@@ -384,7 +497,7 @@ var _ = Describe("CloudFormation Stack", func() {
 			})
 
 			It("returns the proper error", func() {
-				err := stack.Create(stackName, stackDetails)
+				_, err := stack.Create(stackName, stackDetails)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal("operation failed"))
 			})
@@ -395,7 +508,7 @@ var _ = Describe("CloudFormation Stack", func() {
 				})
 
 				It("returns the proper error", func() {
-					err := stack.Create(stackName, stackDetails)
+					_, err := stack.Create(stackName, stackDetails)
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(Equal("code: message"))
 				})
@@ -595,4 +708,952 @@ var _ = Describe("CloudFormation Stack", func() {
 			})
 		})
 	})
+
+	var _ = Describe("ContinueUpdateRollback", func() {
+		var (
+			resourcesToSkip []string
+
+			continueUpdateRollbackParams url.Values
+			continueUpdateRollbackError  error
+		)
+
+		BeforeEach(func() {
+			resourcesToSkip = []string{}
+			continueUpdateRollbackError = nil
+		})
+
+		JustBeforeEach(func() {
+			cfsvc.Handlers.Clear()
+			cfsvc.Handlers.Build.PushBack(query.Build)
+
+			cfCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(Equal("ContinueUpdateRollback"))
+				body, err := ioutil.ReadAll(r.Body)
+				Expect(err).ToNot(HaveOccurred())
+				continueUpdateRollbackParams, err = url.ParseQuery(string(body))
+				Expect(err).ToNot(HaveOccurred())
+				r.Error = continueUpdateRollbackError
+			}
+			cfsvc.Handlers.Send.PushBack(cfCall)
+		})
+
+		It("makes the proper call", func() {
+			err := stack.ContinueUpdateRollback(stackName, resourcesToSkip)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(continueUpdateRollbackParams.Get("Action")).To(Equal("ContinueUpdateRollback"))
+			Expect(continueUpdateRollbackParams.Get("StackName")).To(Equal(stackName))
+			Expect(continueUpdateRollbackParams).ToNot(HaveKey("ResourcesToSkip.member.1"))
+		})
+
+		Context("when has ResourcesToSkip", func() {
+			BeforeEach(func() {
+				resourcesToSkip = []string{"test-resource-1", "test-resource-2"}
+			})
+
+			It("makes the proper call", func() {
+				err := stack.ContinueUpdateRollback(stackName, resourcesToSkip)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(continueUpdateRollbackParams.Get("ResourcesToSkip.member.1")).To(Equal("test-resource-1"))
+				Expect(continueUpdateRollbackParams.Get("ResourcesToSkip.member.2")).To(Equal("test-resource-2"))
+			})
+		})
+
+		Context("when continuing the rollback fails", func() {
+			BeforeEach(func() {
+				continueUpdateRollbackError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				err := stack.ContinueUpdateRollback(stackName, resourcesToSkip)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			Context("and it is an AWS error", func() {
+				BeforeEach(func() {
+					awsError := awserr.New("ValidationError", "Stack is not in UPDATE_ROLLBACK_FAILED state", errors.New("operation failed"))
+					continueUpdateRollbackError = awserr.NewRequestFailure(awsError, 400, "request-id")
+				})
+
+				It("returns the proper error", func() {
+					err := stack.ContinueUpdateRollback(stackName, resourcesToSkip)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("ValidationError: Stack is not in UPDATE_ROLLBACK_FAILED state"))
+				})
+			})
+
+			Context("and the Stack does not exist", func() {
+				BeforeEach(func() {
+					awsError := awserr.New("ValidationError", "Stack [cloudformation-stack] does not exist", errors.New("operation failed"))
+					continueUpdateRollbackError = awserr.NewRequestFailure(awsError, 400, "request-id")
+				})
+
+				It("returns the proper error", func() {
+					err := stack.ContinueUpdateRollback(stackName, resourcesToSkip)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(ErrStackDoesNotExist))
+				})
+			})
+		})
+	})
+
+	var _ = Describe("Events", func() {
+		var (
+			describeStackEventsInput  *cloudformation.DescribeStackEventsInput
+			describeStackEventsOutput *cloudformation.DescribeStackEventsOutput
+			describeStackEventsError  error
+
+			timestamp time.Time
+		)
+
+		BeforeEach(func() {
+			timestamp = time.Date(2016, time.March, 1, 10, 30, 0, 0, time.UTC)
+			describeStackEventsInput = &cloudformation.DescribeStackEventsInput{
+				StackName: aws.String(stackName),
+			}
+			describeStackEventsOutput = &cloudformation.DescribeStackEventsOutput{
+				StackEvents: []*cloudformation.StackEvent{
+					&cloudformation.StackEvent{
+						EventId:              aws.String("test-event-id"),
+						LogicalResourceId:    aws.String("Bucket"),
+						PhysicalResourceId:   aws.String("test-bucket"),
+						ResourceType:         aws.String("AWS::S3::Bucket"),
+						ResourceStatus:       aws.String("DELETE_FAILED"),
+						ResourceStatusReason: aws.String("The bucket you tried to delete is not empty"),
+						Timestamp:            aws.Time(timestamp),
+					},
+				},
+			}
+			describeStackEventsError = nil
+		})
+
+		JustBeforeEach(func() {
+			cfsvc.Handlers.Clear()
+
+			cfCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(Equal("DescribeStackEvents"))
+				Expect(r.Params).To(Equal(describeStackEventsInput))
+				data := r.Data.(*cloudformation.DescribeStackEventsOutput)
+				*data = *describeStackEventsOutput
+				r.Error = describeStackEventsError
+			}
+			cfsvc.Handlers.Send.PushBack(cfCall)
+		})
+
+		It("returns the events of the stack", func() {
+			stackEvents, err := stack.Events(stackName)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stackEvents).To(Equal([]StackEvent{
+				StackEvent{
+					EventID:              "test-event-id",
+					LogicalResourceID:    "Bucket",
+					PhysicalResourceID:   "test-bucket",
+					ResourceType:         "AWS::S3::Bucket",
+					ResourceStatus:       "DELETE_FAILED",
+					ResourceStatusReason: "The bucket you tried to delete is not empty",
+					Timestamp:            timestamp,
+				},
+			}))
+		})
+
+		Context("when the Stack does not exist", func() {
+			BeforeEach(func() {
+				awsError := awserr.New("ValidationError", "Stack [cloudformation-stack] does not exist", errors.New("operation failed"))
+				describeStackEventsError = awserr.NewRequestFailure(awsError, 400, "request-id")
+			})
+
+			It("returns the proper error", func() {
+				_, err := stack.Events(stackName)
+				Expect(err).To(Equal(ErrStackDoesNotExist))
+			})
+		})
+	})
+
+	var _ = Describe("Template", func() {
+		var getTemplateError error
+
+		BeforeEach(func() {
+			getTemplateError = nil
+		})
+
+		JustBeforeEach(func() {
+			cfsvc.Handlers.Clear()
+
+			cfCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(Equal("GetTemplate"))
+				Expect(r.Params).To(Equal(&cloudformation.GetTemplateInput{StackName: aws.String(stackName)}))
+				data := r.Data.(*cloudformation.GetTemplateOutput)
+				data.TemplateBody = aws.String(`{"Resources":{}}`)
+				r.Error = getTemplateError
+			}
+			cfsvc.Handlers.Send.PushBack(cfCall)
+		})
+
+		It("returns the template body of the stack", func() {
+			templateBody, err := stack.Template(stackName)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(templateBody).To(Equal(`{"Resources":{}}`))
+		})
+
+		Context("when getting the template fails", func() {
+			BeforeEach(func() {
+				getTemplateError = awserr.New("code", "message", errors.New("operation failed"))
+			})
+
+			It("returns the proper error", func() {
+				_, err := stack.Template(stackName)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("code: message"))
+			})
+		})
+	})
+
+	var _ = Describe("UpdateTags", func() {
+		var (
+			updateStackParams url.Values
+			updateStackError  error
+		)
+
+		BeforeEach(func() {
+			updateStackParams = nil
+			updateStackError = nil
+		})
+
+		JustBeforeEach(func() {
+			cfsvc.Handlers.Clear()
+			cfsvc.Handlers.Build.PushBack(query.Build)
+
+			cfCall = func(r *request.Request) {
+				switch r.Operation.Name {
+				case "DescribeStacks":
+					data := r.Data.(*cloudformation.DescribeStacksOutput)
+					data.Stacks = []*cloudformation.Stack{
+						&cloudformation.Stack{
+							StackName:    aws.String(stackName),
+							Capabilities: aws.StringSlice([]string{"CAPABILITY_IAM"}),
+							Parameters: []*cloudformation.Parameter{
+								&cloudformation.Parameter{ParameterKey: aws.String("b-parameter"), ParameterValue: aws.String("b-value")},
+								&cloudformation.Parameter{ParameterKey: aws.String("a-parameter"), ParameterValue: aws.String("a-value")},
+							},
+						},
+					}
+				case "UpdateStack":
+					body, err := ioutil.ReadAll(r.Body)
+					Expect(err).ToNot(HaveOccurred())
+					updateStackParams, err = url.ParseQuery(string(body))
+					Expect(err).ToNot(HaveOccurred())
+					r.Error = updateStackError
+				default:
+					Fail("unexpected operation " + r.Operation.Name)
+				}
+			}
+			cfsvc.Handlers.Send.PushBack(cfCall)
+		})
+
+		It("updates the tags keeping the template and parameters", func() {
+			err := stack.UpdateTags(stackName, map[string]string{"Owner": "Cloud Foundry"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(updateStackParams.Get("StackName")).To(Equal(stackName))
+			Expect(updateStackParams.Get("UsePreviousTemplate")).To(Equal("true"))
+			Expect(updateStackParams.Get("Parameters.member.1.ParameterKey")).To(Equal("a-parameter"))
+			Expect(updateStackParams.Get("Parameters.member.1.UsePreviousValue")).To(Equal("true"))
+			Expect(updateStackParams.Get("Parameters.member.2.ParameterKey")).To(Equal("b-parameter"))
+			Expect(updateStackParams).ToNot(HaveKey("Parameters.member.1.ParameterValue"))
+			Expect(updateStackParams.Get("Capabilities.member.1")).To(Equal("CAPABILITY_IAM"))
+			Expect(updateStackParams.Get("Tags.member.1.Key")).To(Equal("Owner"))
+			Expect(updateStackParams.Get("Tags.member.1.Value")).To(Equal("Cloud Foundry"))
+		})
+
+		Context("when updating the stack fails", func() {
+			BeforeEach(func() {
+				awsError := awserr.New("ValidationError", "Stack is in UPDATE_IN_PROGRESS state and can not be updated.", errors.New("operation failed"))
+				updateStackError = awserr.NewRequestFailure(awsError, 400, "request-id")
+			})
+
+			It("returns the proper error", func() {
+				err := stack.UpdateTags(stackName, map[string]string{"Owner": "Cloud Foundry"})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("ValidationError: Stack is in UPDATE_IN_PROGRESS state and can not be updated."))
+			})
+		})
+	})
+
+	var _ = Describe("DeleteRetainingResources", func() {
+		var (
+			deleteStackParams url.Values
+			deleteStackError  error
+		)
+
+		BeforeEach(func() {
+			deleteStackError = nil
+		})
+
+		JustBeforeEach(func() {
+			cfsvc.Handlers.Clear()
+			cfsvc.Handlers.Build.PushBack(query.Build)
+
+			cfCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(Equal("DeleteStack"))
+				body, err := ioutil.ReadAll(r.Body)
+				Expect(err).ToNot(HaveOccurred())
+				deleteStackParams, err = url.ParseQuery(string(body))
+				Expect(err).ToNot(HaveOccurred())
+				r.Error = deleteStackError
+			}
+			cfsvc.Handlers.Send.PushBack(cfCall)
+		})
+
+		It("makes the proper call", func() {
+			err := stack.DeleteRetainingResources(stackName, []string{"Bucket"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deleteStackParams.Get("Action")).To(Equal("DeleteStack"))
+			Expect(deleteStackParams.Get("StackName")).To(Equal(stackName))
+			Expect(deleteStackParams.Get("RetainResources.member.1")).To(Equal("Bucket"))
+		})
+
+		Context("when deleting the stack fails", func() {
+			BeforeEach(func() {
+				deleteStackError = awserr.New("code", "message", errors.New("operation failed"))
+			})
+
+			It("returns the proper error", func() {
+				err := stack.DeleteRetainingResources(stackName, nil)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("code: message"))
+			})
+		})
+	})
+
+	var _ = Describe("DetectDrift", func() {
+		var (
+			detectStackDriftParams url.Values
+			detectStackDriftError  error
+		)
+
+		BeforeEach(func() {
+			detectStackDriftError = nil
+		})
+
+		JustBeforeEach(func() {
+			cfsvc.Handlers.Clear()
+			cfsvc.Handlers.Build.PushBack(query.Build)
+			cfsvc.Handlers.Unmarshal.PushBack(query.Unmarshal)
+
+			cfCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(Equal("DetectStackDrift"))
+				body, err := ioutil.ReadAll(r.Body)
+				Expect(err).ToNot(HaveOccurred())
+				detectStackDriftParams, err = url.ParseQuery(string(body))
+				Expect(err).ToNot(HaveOccurred())
+				r.HTTPResponse = xmlResponse(`<DetectStackDriftResponse><DetectStackDriftResult><StackDriftDetectionId>test-detection-id</StackDriftDetectionId></DetectStackDriftResult></DetectStackDriftResponse>`)
+				r.Error = detectStackDriftError
+			}
+			cfsvc.Handlers.Send.PushBack(cfCall)
+		})
+
+		It("returns the detection ID", func() {
+			detectionID, err := stack.DetectDrift(stackName)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(detectionID).To(Equal("test-detection-id"))
+			Expect(detectStackDriftParams.Get("Action")).To(Equal("DetectStackDrift"))
+			Expect(detectStackDriftParams.Get("StackName")).To(Equal(stackName))
+		})
+
+		Context("when the stack does not exist", func() {
+			BeforeEach(func() {
+				detectStackDriftError = awserr.NewRequestFailure(awserr.New("ValidationError", "Stack with id "+stackName+" does not exist", nil), 400, "")
+			})
+
+			It("returns the proper error", func() {
+				_, err := stack.DetectDrift(stackName)
+				Expect(err).To(Equal(ErrStackDoesNotExist))
+			})
+		})
+	})
+
+	var _ = Describe("DriftDetectionStatus", func() {
+		var (
+			describeStackDriftDetectionStatusParams url.Values
+			describeStackDriftDetectionStatusError  error
+		)
+
+		BeforeEach(func() {
+			describeStackDriftDetectionStatusError = nil
+		})
+
+		JustBeforeEach(func() {
+			cfsvc.Handlers.Clear()
+			cfsvc.Handlers.Build.PushBack(query.Build)
+			cfsvc.Handlers.Unmarshal.PushBack(query.Unmarshal)
+
+			cfCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(Equal("DescribeStackDriftDetectionStatus"))
+				body, err := ioutil.ReadAll(r.Body)
+				Expect(err).ToNot(HaveOccurred())
+				describeStackDriftDetectionStatusParams, err = url.ParseQuery(string(body))
+				Expect(err).ToNot(HaveOccurred())
+				r.HTTPResponse = xmlResponse(`<DescribeStackDriftDetectionStatusResponse><DescribeStackDriftDetectionStatusResult>
+					<StackId>test-stack-id</StackId>
+					<StackDriftDetectionId>test-detection-id</StackDriftDetectionId>
+					<StackDriftStatus>DRIFTED</StackDriftStatus>
+					<DetectionStatus>DETECTION_COMPLETE</DetectionStatus>
+					<DriftedStackResourceCount>2</DriftedStackResourceCount>
+					<Timestamp>2018-11-13T12:00:00Z</Timestamp>
+				</DescribeStackDriftDetectionStatusResult></DescribeStackDriftDetectionStatusResponse>`)
+				r.Error = describeStackDriftDetectionStatusError
+			}
+			cfsvc.Handlers.Send.PushBack(cfCall)
+		})
+
+		It("returns the status of the detection", func() {
+			driftDetection, err := stack.DriftDetectionStatus("test-detection-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(describeStackDriftDetectionStatusParams.Get("StackDriftDetectionId")).To(Equal("test-detection-id"))
+			Expect(driftDetection).To(Equal(DriftDetection{
+				DetectionID:          "test-detection-id",
+				StackID:              "test-stack-id",
+				DetectionStatus:      DriftDetectionComplete,
+				StackDriftStatus:     StackDriftStatusDrifted,
+				DriftedResourceCount: 2,
+				Timestamp:            time.Date(2018, 11, 13, 12, 0, 0, 0, time.UTC),
+			}))
+		})
+
+		Context("when describing the detection fails", func() {
+			BeforeEach(func() {
+				describeStackDriftDetectionStatusError = awserr.New("code", "message", errors.New("operation failed"))
+			})
+
+			It("returns the proper error", func() {
+				_, err := stack.DriftDetectionStatus("test-detection-id")
+				Expect(err).To(MatchError("code: message"))
+			})
+		})
+	})
+
+	var _ = Describe("ResourceDrifts", func() {
+		var (
+			describeStackResourceDriftsParams []url.Values
+			describeStackResourceDriftsPages  []string
+		)
+
+		BeforeEach(func() {
+			describeStackResourceDriftsParams = []url.Values{}
+			describeStackResourceDriftsPages = []string{
+				`<DescribeStackResourceDriftsResponse><DescribeStackResourceDriftsResult>
+					<StackResourceDrifts><member>
+						<LogicalResourceId>Bucket</LogicalResourceId>
+						<PhysicalResourceId>test-bucket</PhysicalResourceId>
+						<ResourceType>AWS::S3::Bucket</ResourceType>
+						<StackResourceDriftStatus>MODIFIED</StackResourceDriftStatus>
+						<PropertyDifferences><member>
+							<PropertyPath>/VersioningConfiguration/Status</PropertyPath>
+							<ExpectedValue>Enabled</ExpectedValue>
+							<ActualValue>Suspended</ActualValue>
+							<DifferenceType>NOT_EQUAL</DifferenceType>
+						</member></PropertyDifferences>
+						<Timestamp>2018-11-13T12:00:00Z</Timestamp>
+					</member></StackResourceDrifts>
+					<NextToken>test-next-token</NextToken>
+				</DescribeStackResourceDriftsResult></DescribeStackResourceDriftsResponse>`,
+				`<DescribeStackResourceDriftsResponse><DescribeStackResourceDriftsResult>
+					<StackResourceDrifts><member>
+						<LogicalResourceId>Queue</LogicalResourceId>
+						<ResourceType>AWS::SQS::Queue</ResourceType>
+						<StackResourceDriftStatus>DELETED</StackResourceDriftStatus>
+						<Timestamp>2018-11-13T12:00:00Z</Timestamp>
+					</member></StackResourceDrifts>
+				</DescribeStackResourceDriftsResult></DescribeStackResourceDriftsResponse>`,
+			}
+		})
+
+		JustBeforeEach(func() {
+			cfsvc.Handlers.Clear()
+			cfsvc.Handlers.Build.PushBack(query.Build)
+			cfsvc.Handlers.Unmarshal.PushBack(query.Unmarshal)
+
+			cfCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(Equal("DescribeStackResourceDrifts"))
+				body, err := ioutil.ReadAll(r.Body)
+				Expect(err).ToNot(HaveOccurred())
+				params, err := url.ParseQuery(string(body))
+				Expect(err).ToNot(HaveOccurred())
+				r.HTTPResponse = xmlResponse(describeStackResourceDriftsPages[len(describeStackResourceDriftsParams)])
+				describeStackResourceDriftsParams = append(describeStackResourceDriftsParams, params)
+			}
+			cfsvc.Handlers.Send.PushBack(cfCall)
+		})
+
+		It("returns the drifted resources of every page", func() {
+			resourceDrifts, err := stack.ResourceDrifts(stackName)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resourceDrifts).To(Equal([]ResourceDrift{
+				ResourceDrift{
+					LogicalResourceID:  "Bucket",
+					PhysicalResourceID: "test-bucket",
+					ResourceType:       "AWS::S3::Bucket",
+					DriftStatus:        "MODIFIED",
+					PropertyDifferences: []PropertyDifference{
+						PropertyDifference{
+							PropertyPath:   "/VersioningConfiguration/Status",
+							ExpectedValue:  "Enabled",
+							ActualValue:    "Suspended",
+							DifferenceType: "NOT_EQUAL",
+						},
+					},
+					Timestamp: time.Date(2018, 11, 13, 12, 0, 0, 0, time.UTC),
+				},
+				ResourceDrift{
+					LogicalResourceID: "Queue",
+					ResourceType:      "AWS::SQS::Queue",
+					DriftStatus:       "DELETED",
+					Timestamp:         time.Date(2018, 11, 13, 12, 0, 0, 0, time.UTC),
+				},
+			}))
+
+			Expect(describeStackResourceDriftsParams).To(HaveLen(2))
+			Expect(describeStackResourceDriftsParams[0].Get("StackName")).To(Equal(stackName))
+			Expect(describeStackResourceDriftsParams[0].Get("StackResourceDriftStatusFilters.member.1")).To(Equal("MODIFIED"))
+			Expect(describeStackResourceDriftsParams[0].Get("StackResourceDriftStatusFilters.member.2")).To(Equal("DELETED"))
+			Expect(describeStackResourceDriftsParams[1].Get("NextToken")).To(Equal("test-next-token"))
+		})
+	})
+
+	var _ = Describe("ListResources", func() {
+		var (
+			listStackResourcesInputs []*cloudformation.ListStackResourcesInput
+			listStackResourcesPages  []*cloudformation.ListStackResourcesOutput
+			listStackResourcesError  error
+
+			properStackResources []StackResource
+		)
+
+		BeforeEach(func() {
+			listStackResourcesInputs = []*cloudformation.ListStackResourcesInput{}
+			listStackResourcesPages = []*cloudformation.ListStackResourcesOutput{
+				&cloudformation.ListStackResourcesOutput{
+					StackResourceSummaries: []*cloudformation.StackResourceSummary{
+						&cloudformation.StackResourceSummary{
+							LogicalResourceId:  aws.String("Bucket"),
+							PhysicalResourceId: aws.String("test-bucket"),
+							ResourceType:       aws.String("AWS::S3::Bucket"),
+							ResourceStatus:     aws.String("CREATE_COMPLETE"),
+						},
+					},
+					NextToken: aws.String("test-next-token"),
+				},
+				&cloudformation.ListStackResourcesOutput{
+					StackResourceSummaries: []*cloudformation.StackResourceSummary{
+						&cloudformation.StackResourceSummary{
+							LogicalResourceId:    aws.String("Database"),
+							ResourceType:         aws.String("AWS::RDS::DBInstance"),
+							ResourceStatus:       aws.String("CREATE_IN_PROGRESS"),
+							ResourceStatusReason: aws.String("Resource creation Initiated"),
+						},
+					},
+				},
+			}
+			listStackResourcesError = nil
+
+			properStackResources = []StackResource{
+				StackResource{
+					LogicalResourceID:  "Bucket",
+					PhysicalResourceID: "test-bucket",
+					ResourceType:       "AWS::S3::Bucket",
+					ResourceStatus:     "CREATE_COMPLETE",
+				},
+				StackResource{
+					LogicalResourceID:    "Database",
+					ResourceType:         "AWS::RDS::DBInstance",
+					ResourceStatus:       "CREATE_IN_PROGRESS",
+					ResourceStatusReason: "Resource creation Initiated",
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			cfsvc.Handlers.Clear()
+
+			cfCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(Equal("ListStackResources"))
+				Expect(r.Params).To(BeAssignableToTypeOf(&cloudformation.ListStackResourcesInput{}))
+				input := *r.Params.(*cloudformation.ListStackResourcesInput)
+				listStackResourcesInputs = append(listStackResourcesInputs, &input)
+				data := r.Data.(*cloudformation.ListStackResourcesOutput)
+				*data = *listStackResourcesPages[len(listStackResourcesInputs)-1]
+				r.Error = listStackResourcesError
+			}
+			cfsvc.Handlers.Send.PushBack(cfCall)
+		})
+
+		It("returns the resources of every page", func() {
+			stackResources, err := stack.ListResources(stackName)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stackResources).To(Equal(properStackResources))
+			Expect(listStackResourcesInputs).To(HaveLen(2))
+			Expect(aws.StringValue(listStackResourcesInputs[0].StackName)).To(Equal(stackName))
+			Expect(listStackResourcesInputs[0].NextToken).To(BeNil())
+			Expect(aws.StringValue(listStackResourcesInputs[1].NextToken)).To(Equal("test-next-token"))
+		})
+
+		Context("when listing the resources fails", func() {
+			BeforeEach(func() {
+				listStackResourcesError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := stack.ListResources(stackName)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			Context("and it is an AWS error", func() {
+				BeforeEach(func() {
+					listStackResourcesError = awserr.New("code", "message", errors.New("operation failed"))
+				})
+
+				It("returns the proper error", func() {
+					_, err := stack.ListResources(stackName)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("code: message"))
+				})
+			})
+
+			Context("and the Stack does not exist", func() {
+				BeforeEach(func() {
+					awsError := awserr.New("ValidationError", "Stack with id cloudformation-stack does not exist", errors.New("operation failed"))
+					listStackResourcesError = awserr.NewRequestFailure(awsError, 400, "request-id")
+				})
+
+				It("returns the proper error", func() {
+					_, err := stack.ListResources(stackName)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(ErrStackDoesNotExist))
+				})
+			})
+		})
+	})
+
+	var _ = Describe("List", func() {
+		var (
+			listStacksInputs []*cloudformation.ListStacksInput
+			listStacksPages  []*cloudformation.ListStacksOutput
+			listStacksError  error
+
+			creationTime time.Time
+		)
+
+		BeforeEach(func() {
+			creationTime = time.Date(2015, time.November, 1, 10, 0, 0, 0, time.UTC)
+
+			listStacksInputs = []*cloudformation.ListStacksInput{}
+			listStacksPages = []*cloudformation.ListStacksOutput{
+				&cloudformation.ListStacksOutput{
+					StackSummaries: []*cloudformation.StackSummary{
+						&cloudformation.StackSummary{
+							StackName:    aws.String("cf-instance-1"),
+							StackId:      aws.String("test-stack-id-1"),
+							StackStatus:  aws.String(cloudformation.StackStatusCreateComplete),
+							CreationTime: aws.Time(creationTime),
+						},
+						&cloudformation.StackSummary{
+							StackName:   aws.String("cf-instance-2"),
+							StackId:     aws.String("test-stack-id-2"),
+							StackStatus: aws.String(cloudformation.StackStatusDeleteComplete),
+						},
+					},
+					NextToken: aws.String("test-next-token"),
+				},
+				&cloudformation.ListStacksOutput{
+					StackSummaries: []*cloudformation.StackSummary{
+						&cloudformation.StackSummary{
+							StackName:         aws.String("cf-instance-3"),
+							StackId:           aws.String("test-stack-id-3"),
+							StackStatus:       aws.String(cloudformation.StackStatusUpdateRollbackComplete),
+							StackStatusReason: aws.String("test-reason"),
+						},
+					},
+				},
+			}
+			listStacksError = nil
+		})
+
+		JustBeforeEach(func() {
+			cfsvc.Handlers.Clear()
+
+			cfCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(Equal("ListStacks"))
+				Expect(r.Params).To(BeAssignableToTypeOf(&cloudformation.ListStacksInput{}))
+				input := *r.Params.(*cloudformation.ListStacksInput)
+				listStacksInputs = append(listStacksInputs, &input)
+				data := r.Data.(*cloudformation.ListStacksOutput)
+				*data = *listStacksPages[len(listStacksInputs)-1]
+				r.Error = listStacksError
+			}
+			cfsvc.Handlers.Send.PushBack(cfCall)
+		})
+
+		It("returns the stacks not deleted of every page", func() {
+			stackSummaries, err := stack.List()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stackSummaries).To(Equal([]StackSummary{
+				StackSummary{
+					StackName:    "cf-instance-1",
+					StackID:      "test-stack-id-1",
+					Status:       NewStatus(cloudformation.StackStatusCreateComplete, ""),
+					CreationTime: creationTime,
+				},
+				StackSummary{
+					StackName: "cf-instance-3",
+					StackID:   "test-stack-id-3",
+					Status:    NewStatus(cloudformation.StackStatusUpdateRollbackComplete, "test-reason"),
+				},
+			}))
+			Expect(listStacksInputs).To(HaveLen(2))
+			Expect(listStacksInputs[0].NextToken).To(BeNil())
+			Expect(aws.StringValue(listStacksInputs[1].NextToken)).To(Equal("test-next-token"))
+		})
+
+		Context("when listing the stacks fails", func() {
+			BeforeEach(func() {
+				listStacksError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := stack.List()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			Context("and it is an AWS error", func() {
+				BeforeEach(func() {
+					listStacksError = awserr.New("code", "message", errors.New("operation failed"))
+				})
+
+				It("returns the proper error", func() {
+					_, err := stack.List()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("code: message"))
+				})
+			})
+		})
+	})
+
+	var _ = Describe("DescribeAll", func() {
+		var (
+			describeStacksInputs []*cloudformation.DescribeStacksInput
+			describeStacksPages  []*cloudformation.DescribeStacksOutput
+			describeStacksError  error
+		)
+
+		BeforeEach(func() {
+			describeStacksInputs = []*cloudformation.DescribeStacksInput{}
+			describeStacksPages = []*cloudformation.DescribeStacksOutput{
+				&cloudformation.DescribeStacksOutput{
+					Stacks: []*cloudformation.Stack{
+						&cloudformation.Stack{
+							StackName:   aws.String("cf-instance-1"),
+							StackId:     aws.String("test-stack-id-1"),
+							StackStatus: aws.String(cloudformation.StackStatusCreateComplete),
+							Tags: []*cloudformation.Tag{
+								&cloudformation.Tag{Key: aws.String("Instance ID"), Value: aws.String("instance-1")},
+							},
+						},
+						&cloudformation.Stack{
+							StackName:   aws.String("cf-instance-2"),
+							StackId:     aws.String("test-stack-id-2"),
+							StackStatus: aws.String(cloudformation.StackStatusDeleteComplete),
+						},
+					},
+					NextToken: aws.String("test-next-token"),
+				},
+				&cloudformation.DescribeStacksOutput{
+					Stacks: []*cloudformation.Stack{
+						&cloudformation.Stack{
+							StackName:   aws.String("cf-instance-3"),
+							StackId:     aws.String("test-stack-id-3"),
+							StackStatus: aws.String(cloudformation.StackStatusUpdateComplete),
+						},
+					},
+				},
+			}
+			describeStacksError = nil
+		})
+
+		JustBeforeEach(func() {
+			cfsvc.Handlers.Clear()
+
+			cfCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(Equal("DescribeStacks"))
+				Expect(r.Params).To(BeAssignableToTypeOf(&cloudformation.DescribeStacksInput{}))
+				input := *r.Params.(*cloudformation.DescribeStacksInput)
+				describeStacksInputs = append(describeStacksInputs, &input)
+				data := r.Data.(*cloudformation.DescribeStacksOutput)
+				*data = *describeStacksPages[len(describeStacksInputs)-1]
+				r.Error = describeStacksError
+			}
+			cfsvc.Handlers.Send.PushBack(cfCall)
+		})
+
+		It("describes the stacks not deleted of every page", func() {
+			stacksDetails, err := stack.DescribeAll()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stacksDetails).To(HaveLen(2))
+			Expect(stacksDetails[0].StackName).To(Equal("cf-instance-1"))
+			Expect(stacksDetails[0].StackID).To(Equal("test-stack-id-1"))
+			Expect(stacksDetails[0].Tags).To(Equal(map[string]string{"Instance ID": "instance-1"}))
+			Expect(stacksDetails[1].StackName).To(Equal("cf-instance-3"))
+			Expect(stacksDetails[1].Status.Raw).To(Equal(cloudformation.StackStatusUpdateComplete))
+			Expect(describeStacksInputs).To(HaveLen(2))
+			Expect(describeStacksInputs[0].StackName).To(BeNil())
+			Expect(describeStacksInputs[0].NextToken).To(BeNil())
+			Expect(aws.StringValue(describeStacksInputs[1].NextToken)).To(Equal("test-next-token"))
+		})
+
+		Context("when describing the stacks fails", func() {
+			BeforeEach(func() {
+				describeStacksError = awserr.New("code", "message", errors.New("operation failed"))
+			})
+
+			It("returns the proper error", func() {
+				_, err := stack.DescribeAll()
+				Expect(err).To(MatchError("code: message"))
+			})
+		})
+	})
+
+	var _ = Describe("NoEchoParameters", func() {
+		var (
+			getTemplateSummaryInput  *cloudformation.GetTemplateSummaryInput
+			getTemplateSummaryOutput *cloudformation.GetTemplateSummaryOutput
+			getTemplateSummaryError  error
+		)
+
+		BeforeEach(func() {
+			getTemplateSummaryInput = &cloudformation.GetTemplateSummaryInput{
+				TemplateURL: aws.String("test-template-url"),
+			}
+			getTemplateSummaryOutput = &cloudformation.GetTemplateSummaryOutput{
+				Parameters: []*cloudformation.ParameterDeclaration{
+					&cloudformation.ParameterDeclaration{ParameterKey: aws.String("DBName")},
+					&cloudformation.ParameterDeclaration{ParameterKey: aws.String("DBPassword"), NoEcho: aws.Bool(true)},
+					&cloudformation.ParameterDeclaration{ParameterKey: aws.String("DBUsername"), NoEcho: aws.Bool(false)},
+				},
+			}
+			getTemplateSummaryError = nil
+		})
+
+		JustBeforeEach(func() {
+			cfsvc.Handlers.Clear()
+
+			cfCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(Equal("GetTemplateSummary"))
+				Expect(r.Params).To(BeAssignableToTypeOf(&cloudformation.GetTemplateSummaryInput{}))
+				Expect(r.Params).To(Equal(getTemplateSummaryInput))
+				data := r.Data.(*cloudformation.GetTemplateSummaryOutput)
+				*data = *getTemplateSummaryOutput
+				r.Error = getTemplateSummaryError
+			}
+			cfsvc.Handlers.Send.PushBack(cfCall)
+		})
+
+		It("returns the NoEcho parameters of the template", func() {
+			noEchoParameters, err := stack.NoEchoParameters("test-template-url")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(noEchoParameters).To(Equal([]string{"DBPassword"}))
+		})
+
+		Context("when getting the template summary fails", func() {
+			BeforeEach(func() {
+				getTemplateSummaryError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := stack.NoEchoParameters("test-template-url")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			Context("and it is an AWS error", func() {
+				BeforeEach(func() {
+					getTemplateSummaryError = awserr.New("code", "message", errors.New("operation failed"))
+				})
+
+				It("returns the proper error", func() {
+					_, err := stack.NoEchoParameters("test-template-url")
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("code: message"))
+				})
+			})
+		})
+	})
+
+	var _ = Describe("CancelUpdate", func() {
+		var (
+			cancelUpdateStackInput *cloudformation.CancelUpdateStackInput
+			cancelUpdateStackError error
+		)
+
+		BeforeEach(func() {
+			cancelUpdateStackInput = &cloudformation.CancelUpdateStackInput{
+				StackName: aws.String(stackName),
+			}
+			cancelUpdateStackError = nil
+		})
+
+		JustBeforeEach(func() {
+			cfsvc.Handlers.Clear()
+
+			cfCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(Equal("CancelUpdateStack"))
+				Expect(r.Params).To(BeAssignableToTypeOf(&cloudformation.CancelUpdateStackInput{}))
+				Expect(r.Params).To(Equal(cancelUpdateStackInput))
+				r.Error = cancelUpdateStackError
+			}
+			cfsvc.Handlers.Send.PushBack(cfCall)
+		})
+
+		It("does not return error", func() {
+			err := stack.CancelUpdate(stackName)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when cancelling the update fails", func() {
+			BeforeEach(func() {
+				cancelUpdateStackError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				err := stack.CancelUpdate(stackName)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			Context("and it is an AWS error", func() {
+				BeforeEach(func() {
+					awsError := awserr.New("ValidationError", "CancelUpdateStack cannot be called from current stack status", errors.New("operation failed"))
+					cancelUpdateStackError = awserr.NewRequestFailure(awsError, 400, "request-id")
+				})
+
+				It("returns the proper error", func() {
+					err := stack.CancelUpdate(stackName)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("ValidationError: CancelUpdateStack cannot be called from current stack status"))
+				})
+			})
+
+			Context("and the Stack does not exist", func() {
+				BeforeEach(func() {
+					awsError := awserr.New("ValidationError", "Stack [cloudformation-stack] does not exist", errors.New("operation failed"))
+					cancelUpdateStackError = awserr.NewRequestFailure(awsError, 400, "request-id")
+				})
+
+				It("returns the proper error", func() {
+					err := stack.CancelUpdate(stackName)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(ErrStackDoesNotExist))
+				})
+			})
+		})
+	})
 })
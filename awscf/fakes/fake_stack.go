@@ -10,9 +10,18 @@ type FakeStack struct {
 	DescribeStackDetails awscf.StackDetails
 	DescribeError        error
 
+	// DescribeStackDetailsByName, when set, overrides DescribeStackDetails
+	// for the stacks it holds.
+	DescribeStackDetailsByName map[string]awscf.StackDetails
+
+	// DescribeErrorByName, when set, overrides DescribeError for the stack
+	// names it holds.
+	DescribeErrorByName map[string]error
+
 	CreateCalled       bool
 	CreateStackName    string
 	CreateStackDetails awscf.StackDetails
+	CreateStackID      string
 	CreateError        error
 
 	ModifyCalled       bool
@@ -23,21 +32,90 @@ type FakeStack struct {
 	DeleteCalled    bool
 	DeleteStackName string
 	DeleteError     error
+
+	ContinueUpdateRollbackCalled          bool
+	ContinueUpdateRollbackStackName       string
+	ContinueUpdateRollbackResourcesToSkip []string
+	ContinueUpdateRollbackError           error
+
+	CancelUpdateCalled    bool
+	CancelUpdateStackName string
+	CancelUpdateError     error
+
+	ListResourcesCalled         bool
+	ListResourcesStackName      string
+	ListResourcesStackResources []awscf.StackResource
+	ListResourcesError          error
+
+	ListCalled         bool
+	ListStackSummaries []awscf.StackSummary
+	ListError          error
+
+	DescribeAllCalled       bool
+	DescribeAllStackDetails []awscf.StackDetails
+	DescribeAllError        error
+
+	NoEchoParametersCalled      bool
+	NoEchoParametersTemplateURL string
+	NoEchoParametersParameters  []string
+	NoEchoParametersError       error
+
+	EventsCalled      bool
+	EventsStackName   string
+	EventsStackEvents []awscf.StackEvent
+	EventsError       error
+
+	TemplateCalled    bool
+	TemplateStackName string
+	TemplateBody      string
+	TemplateError     error
+
+	UpdateTagsCalled    bool
+	UpdateTagsStackName string
+	UpdateTagsTags      map[string]string
+	UpdateTagsError     error
+
+	DeleteRetainingResourcesCalled            bool
+	DeleteRetainingResourcesStackName         string
+	DeleteRetainingResourcesResourcesToRetain []string
+	DeleteRetainingResourcesError             error
+
+	DetectDriftCalled      bool
+	DetectDriftStackName   string
+	DetectDriftDetectionID string
+	DetectDriftError       error
+
+	DriftDetectionStatusCalled      bool
+	DriftDetectionStatusDetectionID string
+	DriftDetectionStatusDetection   awscf.DriftDetection
+	DriftDetectionStatusError       error
+
+	ResourceDriftsCalled    bool
+	ResourceDriftsStackName string
+	ResourceDriftsDrifts    []awscf.ResourceDrift
+	ResourceDriftsError     error
 }
 
 func (f *FakeStack) Describe(stackName string) (awscf.StackDetails, error) {
 	f.DescribeCalled = true
 	f.DescribeStackName = stackName
 
+	if err, ok := f.DescribeErrorByName[stackName]; ok {
+		return awscf.StackDetails{}, err
+	}
+	if stackDetails, ok := f.DescribeStackDetailsByName[stackName]; ok {
+		return stackDetails, f.DescribeError
+	}
+
 	return f.DescribeStackDetails, f.DescribeError
 }
 
-func (f *FakeStack) Create(stackName string, stackDetails awscf.StackDetails) error {
+func (f *FakeStack) Create(stackName string, stackDetails awscf.StackDetails) (string, error) {
 	f.CreateCalled = true
 	f.CreateStackName = stackName
 	f.CreateStackDetails = stackDetails
 
-	return f.CreateError
+	return f.CreateStackID, f.CreateError
 }
 
 func (f *FakeStack) Modify(stackName string, stackDetails awscf.StackDetails) error {
@@ -54,3 +132,95 @@ func (f *FakeStack) Delete(stackName string) error {
 
 	return f.DeleteError
 }
+
+func (f *FakeStack) ContinueUpdateRollback(stackName string, resourcesToSkip []string) error {
+	f.ContinueUpdateRollbackCalled = true
+	f.ContinueUpdateRollbackStackName = stackName
+	f.ContinueUpdateRollbackResourcesToSkip = resourcesToSkip
+
+	return f.ContinueUpdateRollbackError
+}
+
+func (f *FakeStack) CancelUpdate(stackName string) error {
+	f.CancelUpdateCalled = true
+	f.CancelUpdateStackName = stackName
+
+	return f.CancelUpdateError
+}
+
+func (f *FakeStack) ListResources(stackName string) ([]awscf.StackResource, error) {
+	f.ListResourcesCalled = true
+	f.ListResourcesStackName = stackName
+
+	return f.ListResourcesStackResources, f.ListResourcesError
+}
+
+func (f *FakeStack) List() ([]awscf.StackSummary, error) {
+	f.ListCalled = true
+
+	return f.ListStackSummaries, f.ListError
+}
+
+func (f *FakeStack) DescribeAll() ([]awscf.StackDetails, error) {
+	f.DescribeAllCalled = true
+
+	return f.DescribeAllStackDetails, f.DescribeAllError
+}
+
+func (f *FakeStack) NoEchoParameters(templateURL string) ([]string, error) {
+	f.NoEchoParametersCalled = true
+	f.NoEchoParametersTemplateURL = templateURL
+
+	return f.NoEchoParametersParameters, f.NoEchoParametersError
+}
+
+func (f *FakeStack) Events(stackName string) ([]awscf.StackEvent, error) {
+	f.EventsCalled = true
+	f.EventsStackName = stackName
+
+	return f.EventsStackEvents, f.EventsError
+}
+
+func (f *FakeStack) Template(stackName string) (string, error) {
+	f.TemplateCalled = true
+	f.TemplateStackName = stackName
+
+	return f.TemplateBody, f.TemplateError
+}
+
+func (f *FakeStack) UpdateTags(stackName string, tags map[string]string) error {
+	f.UpdateTagsCalled = true
+	f.UpdateTagsStackName = stackName
+	f.UpdateTagsTags = tags
+
+	return f.UpdateTagsError
+}
+
+func (f *FakeStack) DeleteRetainingResources(stackName string, resourcesToRetain []string) error {
+	f.DeleteRetainingResourcesCalled = true
+	f.DeleteRetainingResourcesStackName = stackName
+	f.DeleteRetainingResourcesResourcesToRetain = resourcesToRetain
+
+	return f.DeleteRetainingResourcesError
+}
+
+func (f *FakeStack) DetectDrift(stackName string) (string, error) {
+	f.DetectDriftCalled = true
+	f.DetectDriftStackName = stackName
+
+	return f.DetectDriftDetectionID, f.DetectDriftError
+}
+
+func (f *FakeStack) DriftDetectionStatus(detectionID string) (awscf.DriftDetection, error) {
+	f.DriftDetectionStatusCalled = true
+	f.DriftDetectionStatusDetectionID = detectionID
+
+	return f.DriftDetectionStatusDetection, f.DriftDetectionStatusError
+}
+
+func (f *FakeStack) ResourceDrifts(stackName string) ([]awscf.ResourceDrift, error) {
+	f.ResourceDriftsCalled = true
+	f.ResourceDriftsStackName = stackName
+
+	return f.ResourceDriftsDrifts, f.ResourceDriftsError
+}
@@ -2,6 +2,7 @@ package awscf
 
 import (
 	"errors"
+	"time"
 )
 
 const StatusInProgress = "in progress"
@@ -10,9 +11,22 @@ const StatusSucceeded = "succeeded"
 
 type Stack interface {
 	Describe(stackName string) (StackDetails, error)
-	Create(stackName string, stackDetails StackDetails) error
+	Create(stackName string, stackDetails StackDetails) (string, error)
 	Modify(stackName string, stackDetails StackDetails) error
 	Delete(stackName string) error
+	ContinueUpdateRollback(stackName string, resourcesToSkip []string) error
+	CancelUpdate(stackName string) error
+	ListResources(stackName string) ([]StackResource, error)
+	List() ([]StackSummary, error)
+	DescribeAll() ([]StackDetails, error)
+	NoEchoParameters(templateURL string) ([]string, error)
+	Events(stackName string) ([]StackEvent, error)
+	Template(stackName string) (string, error)
+	UpdateTags(stackName string, tags map[string]string) error
+	DeleteRetainingResources(stackName string, resourcesToRetain []string) error
+	DetectDrift(stackName string) (string, error)
+	DriftDetectionStatus(detectionID string) (DriftDetection, error)
+	ResourceDrifts(stackName string) ([]ResourceDrift, error)
 }
 
 type StackDetails struct {
@@ -28,9 +42,75 @@ type StackDetails struct {
 	StackID          string
 	StackPolicyURL   string
 	StackStatus      string
+	Status           Status
 	Tags             map[string]string
 	TemplateURL      string
 	TimeoutInMinutes int64
+	CreationTime     time.Time
+	LastUpdatedTime  time.Time
+}
+
+type StackSummary struct {
+	StackName    string
+	StackID      string
+	Status       Status
+	CreationTime time.Time
+}
+
+type StackEvent struct {
+	EventID              string
+	LogicalResourceID    string
+	PhysicalResourceID   string
+	ResourceType         string
+	ResourceStatus       string
+	ResourceStatusReason string
+	Timestamp            time.Time
+}
+
+// Drift detection and stack drift statuses.
+const (
+	DriftDetectionInProgress = "DETECTION_IN_PROGRESS"
+	DriftDetectionComplete   = "DETECTION_COMPLETE"
+	DriftDetectionFailed     = "DETECTION_FAILED"
+
+	StackDriftStatusDrifted    = "DRIFTED"
+	StackDriftStatusInSync     = "IN_SYNC"
+	StackDriftStatusUnknown    = "UNKNOWN"
+	StackDriftStatusNotChecked = "NOT_CHECKED"
+)
+
+type DriftDetection struct {
+	DetectionID           string
+	StackID               string
+	DetectionStatus       string
+	DetectionStatusReason string
+	StackDriftStatus      string
+	DriftedResourceCount  int64
+	Timestamp             time.Time
+}
+
+type ResourceDrift struct {
+	LogicalResourceID   string
+	PhysicalResourceID  string
+	ResourceType        string
+	DriftStatus         string
+	PropertyDifferences []PropertyDifference
+	Timestamp           time.Time
+}
+
+type PropertyDifference struct {
+	PropertyPath   string
+	ExpectedValue  string
+	ActualValue    string
+	DifferenceType string
+}
+
+type StackResource struct {
+	LogicalResourceID    string
+	PhysicalResourceID   string
+	ResourceType         string
+	ResourceStatus       string
+	ResourceStatusReason string
 }
 
 var (
@@ -0,0 +1,104 @@
+package awscf
+
+import (
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+)
+
+const OperationCreate = "create"
+const OperationUpdate = "update"
+const OperationDelete = "delete"
+const OperationImport = "import"
+
+const PhaseInProgress = "in progress"
+const PhaseComplete = "complete"
+const PhaseFailed = "failed"
+
+// CloudFormation stack statuses not yet available in the vendored AWS SDK
+const stackStatusReviewInProgress = "REVIEW_IN_PROGRESS"
+const stackStatusUpdateFailed = "UPDATE_FAILED"
+const stackStatusImportInProgress = "IMPORT_IN_PROGRESS"
+const stackStatusImportComplete = "IMPORT_COMPLETE"
+const stackStatusImportRollbackInProgress = "IMPORT_ROLLBACK_IN_PROGRESS"
+const stackStatusImportRollbackFailed = "IMPORT_ROLLBACK_FAILED"
+const stackStatusImportRollbackComplete = "IMPORT_ROLLBACK_COMPLETE"
+
+// Status breaks a CloudFormation stack status down into the operation it
+// refers to, the phase that operation is in, and whether it was rolled back.
+type Status struct {
+	Operation  string
+	Phase      string
+	RolledBack bool
+	Raw        string
+	Reason     string
+}
+
+var stackStatuses = map[string]Status{
+	cloudformation.StackStatusCreateInProgress:                        {Operation: OperationCreate, Phase: PhaseInProgress},
+	cloudformation.StackStatusCreateFailed:                            {Operation: OperationCreate, Phase: PhaseFailed},
+	cloudformation.StackStatusCreateComplete:                          {Operation: OperationCreate, Phase: PhaseComplete},
+	cloudformation.StackStatusRollbackInProgress:                      {Operation: OperationCreate, Phase: PhaseInProgress, RolledBack: true},
+	cloudformation.StackStatusRollbackFailed:                          {Operation: OperationCreate, Phase: PhaseFailed, RolledBack: true},
+	cloudformation.StackStatusRollbackComplete:                        {Operation: OperationCreate, Phase: PhaseComplete, RolledBack: true},
+	stackStatusReviewInProgress:                                       {Operation: OperationCreate, Phase: PhaseInProgress},
+	cloudformation.StackStatusDeleteInProgress:                        {Operation: OperationDelete, Phase: PhaseInProgress},
+	cloudformation.StackStatusDeleteFailed:                            {Operation: OperationDelete, Phase: PhaseFailed},
+	cloudformation.StackStatusDeleteComplete:                          {Operation: OperationDelete, Phase: PhaseComplete},
+	cloudformation.StackStatusUpdateInProgress:                        {Operation: OperationUpdate, Phase: PhaseInProgress},
+	cloudformation.StackStatusUpdateCompleteCleanupInProgress:         {Operation: OperationUpdate, Phase: PhaseInProgress},
+	cloudformation.StackStatusUpdateComplete:                          {Operation: OperationUpdate, Phase: PhaseComplete},
+	stackStatusUpdateFailed:                                           {Operation: OperationUpdate, Phase: PhaseFailed},
+	cloudformation.StackStatusUpdateRollbackInProgress:                {Operation: OperationUpdate, Phase: PhaseInProgress, RolledBack: true},
+	cloudformation.StackStatusUpdateRollbackFailed:                    {Operation: OperationUpdate, Phase: PhaseFailed, RolledBack: true},
+	cloudformation.StackStatusUpdateRollbackCompleteCleanupInProgress: {Operation: OperationUpdate, Phase: PhaseInProgress, RolledBack: true},
+	cloudformation.StackStatusUpdateRollbackComplete:                  {Operation: OperationUpdate, Phase: PhaseComplete, RolledBack: true},
+	stackStatusImportInProgress:                                       {Operation: OperationImport, Phase: PhaseInProgress},
+	stackStatusImportComplete:                                         {Operation: OperationImport, Phase: PhaseComplete},
+	stackStatusImportRollbackInProgress:                               {Operation: OperationImport, Phase: PhaseInProgress, RolledBack: true},
+	stackStatusImportRollbackFailed:                                   {Operation: OperationImport, Phase: PhaseFailed, RolledBack: true},
+	stackStatusImportRollbackComplete:                                 {Operation: OperationImport, Phase: PhaseComplete, RolledBack: true},
+}
+
+// NewStatus builds the Status of a raw CloudFormation stack status. Unknown
+// statuses are reported as failed.
+func NewStatus(rawStatus string, reason string) Status {
+	status, ok := stackStatuses[rawStatus]
+	if !ok {
+		status = Status{Phase: PhaseFailed}
+	}
+
+	status.Raw = rawStatus
+	status.Reason = reason
+
+	return status
+}
+
+// Summary collapses the status into StatusSucceeded, StatusInProgress or
+// StatusFailed.
+func (s Status) Summary() string {
+	switch {
+	case s.Phase == PhaseInProgress:
+		return StatusInProgress
+	case s.Phase == PhaseComplete && !s.RolledBack:
+		return StatusSucceeded
+	default:
+		return StatusFailed
+	}
+}
+
+// Usable returns whether the stack resources are in a consistent state,
+// either the one requested or, after a rolled back update or import, the
+// previous one.
+func (s Status) Usable() bool {
+	if s.Phase != PhaseComplete {
+		return false
+	}
+
+	switch s.Operation {
+	case OperationCreate:
+		return !s.RolledBack
+	case OperationUpdate, OperationImport:
+		return true
+	default:
+		return false
+	}
+}
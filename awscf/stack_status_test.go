@@ -0,0 +1,59 @@
+package awscf_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/awscf"
+)
+
+var _ = Describe("Status", func() {
+	statuses := []struct {
+		rawStatus  string
+		operation  string
+		phase      string
+		rolledBack bool
+		summary    string
+		usable     bool
+	}{
+		{"CREATE_IN_PROGRESS", OperationCreate, PhaseInProgress, false, StatusInProgress, false},
+		{"CREATE_FAILED", OperationCreate, PhaseFailed, false, StatusFailed, false},
+		{"CREATE_COMPLETE", OperationCreate, PhaseComplete, false, StatusSucceeded, true},
+		{"ROLLBACK_IN_PROGRESS", OperationCreate, PhaseInProgress, true, StatusInProgress, false},
+		{"ROLLBACK_FAILED", OperationCreate, PhaseFailed, true, StatusFailed, false},
+		{"ROLLBACK_COMPLETE", OperationCreate, PhaseComplete, true, StatusFailed, false},
+		{"REVIEW_IN_PROGRESS", OperationCreate, PhaseInProgress, false, StatusInProgress, false},
+		{"DELETE_IN_PROGRESS", OperationDelete, PhaseInProgress, false, StatusInProgress, false},
+		{"DELETE_FAILED", OperationDelete, PhaseFailed, false, StatusFailed, false},
+		{"DELETE_COMPLETE", OperationDelete, PhaseComplete, false, StatusSucceeded, false},
+		{"UPDATE_IN_PROGRESS", OperationUpdate, PhaseInProgress, false, StatusInProgress, false},
+		{"UPDATE_COMPLETE_CLEANUP_IN_PROGRESS", OperationUpdate, PhaseInProgress, false, StatusInProgress, false},
+		{"UPDATE_COMPLETE", OperationUpdate, PhaseComplete, false, StatusSucceeded, true},
+		{"UPDATE_FAILED", OperationUpdate, PhaseFailed, false, StatusFailed, false},
+		{"UPDATE_ROLLBACK_IN_PROGRESS", OperationUpdate, PhaseInProgress, true, StatusInProgress, false},
+		{"UPDATE_ROLLBACK_FAILED", OperationUpdate, PhaseFailed, true, StatusFailed, false},
+		{"UPDATE_ROLLBACK_COMPLETE_CLEANUP_IN_PROGRESS", OperationUpdate, PhaseInProgress, true, StatusInProgress, false},
+		{"UPDATE_ROLLBACK_COMPLETE", OperationUpdate, PhaseComplete, true, StatusFailed, true},
+		{"IMPORT_IN_PROGRESS", OperationImport, PhaseInProgress, false, StatusInProgress, false},
+		{"IMPORT_COMPLETE", OperationImport, PhaseComplete, false, StatusSucceeded, true},
+		{"IMPORT_ROLLBACK_IN_PROGRESS", OperationImport, PhaseInProgress, true, StatusInProgress, false},
+		{"IMPORT_ROLLBACK_FAILED", OperationImport, PhaseFailed, true, StatusFailed, false},
+		{"IMPORT_ROLLBACK_COMPLETE", OperationImport, PhaseComplete, true, StatusFailed, true},
+		{"UNKNOWN", "", PhaseFailed, false, StatusFailed, false},
+	}
+
+	for _, s := range statuses {
+		expected := s
+
+		It("builds the proper Status for '"+expected.rawStatus+"'", func() {
+			status := NewStatus(expected.rawStatus, "test-reason")
+			Expect(status.Operation).To(Equal(expected.operation))
+			Expect(status.Phase).To(Equal(expected.phase))
+			Expect(status.RolledBack).To(Equal(expected.rolledBack))
+			Expect(status.Raw).To(Equal(expected.rawStatus))
+			Expect(status.Reason).To(Equal("test-reason"))
+			Expect(status.Summary()).To(Equal(expected.summary))
+			Expect(status.Usable()).To(Equal(expected.usable))
+		})
+	}
+})
@@ -0,0 +1,13 @@
+package awsecr_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestAWSECR(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AWS ECR Suite")
+}
@@ -0,0 +1,179 @@
+package awsecr
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/private/signer/v4"
+)
+
+// ServiceName is the name of the service the client will make API calls to.
+const ServiceName = "ecr"
+
+const targetPrefix = "AmazonEC2ContainerRegistry_V20150921"
+
+const opListImages = "ListImages"
+const opBatchDeleteImage = "BatchDeleteImage"
+
+// ECR is a minimal Amazon EC2 Container Registry client built on top of the
+// AWS SDK request pipeline. It only implements the operations needed to
+// delete all images from a repository.
+type ECR struct {
+	*client.Client
+}
+
+type ListImagesInput struct {
+	RepositoryName *string `json:"repositoryName,omitempty"`
+	NextToken      *string `json:"nextToken,omitempty"`
+}
+
+type ListImagesOutput struct {
+	ImageIDs  []ImageIdentifier `json:"imageIds"`
+	NextToken *string           `json:"nextToken"`
+}
+
+type ImageIdentifier struct {
+	ImageDigest *string `json:"imageDigest,omitempty"`
+	ImageTag    *string `json:"imageTag,omitempty"`
+}
+
+type BatchDeleteImageInput struct {
+	RepositoryName *string           `json:"repositoryName,omitempty"`
+	ImageIDs       []ImageIdentifier `json:"imageIds"`
+}
+
+type BatchDeleteImageOutput struct {
+	ImageIDs []ImageIdentifier `json:"imageIds"`
+	Failures []ImageFailure    `json:"failures"`
+}
+
+type ImageFailure struct {
+	ImageID       ImageIdentifier `json:"imageId"`
+	FailureCode   *string         `json:"failureCode"`
+	FailureReason *string         `json:"failureReason"`
+}
+
+type jsonErrorResponse struct {
+	Code    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+// New creates a new instance of the ECR client with a session.
+func New(p client.ConfigProvider, cfgs ...*aws.Config) *ECR {
+	c := p.ClientConfig(ServiceName, cfgs...)
+
+	svc := &ECR{
+		Client: client.New(
+			*c.Config,
+			metadata.ClientInfo{
+				ServiceName:   ServiceName,
+				SigningRegion: c.SigningRegion,
+				Endpoint:      c.Endpoint,
+				APIVersion:    "2015-09-21",
+				JSONVersion:   "1.1",
+				TargetPrefix:  targetPrefix,
+			},
+			c.Handlers,
+		),
+	}
+
+	svc.Handlers.Sign.PushBack(v4.Sign)
+	svc.Handlers.Build.PushBack(build)
+	svc.Handlers.Unmarshal.PushBack(unmarshal)
+	svc.Handlers.UnmarshalMeta.PushBack(unmarshalMeta)
+	svc.Handlers.UnmarshalError.PushBack(unmarshalError)
+
+	return svc
+}
+
+// ListImages lists all the image IDs for a given repository.
+func (c *ECR) ListImages(input *ListImagesInput) (*ListImagesOutput, error) {
+	op := &request.Operation{
+		Name:       opListImages,
+		HTTPMethod: "POST",
+		HTTPPath:   "/",
+	}
+
+	output := &ListImagesOutput{}
+	req := c.NewRequest(op, input, output)
+
+	return output, req.Send()
+}
+
+// BatchDeleteImage deletes a list of specified images within a repository.
+func (c *ECR) BatchDeleteImage(input *BatchDeleteImageInput) (*BatchDeleteImageOutput, error) {
+	op := &request.Operation{
+		Name:       opBatchDeleteImage,
+		HTTPMethod: "POST",
+		HTTPPath:   "/",
+	}
+
+	output := &BatchDeleteImageOutput{}
+	req := c.NewRequest(op, input, output)
+
+	return output, req.Send()
+}
+
+func build(r *request.Request) {
+	body, err := json.Marshal(r.Params)
+	if err != nil {
+		r.Error = awserr.New("SerializationError", "failed to encode ECR JSON request", err)
+		return
+	}
+
+	r.SetBufferBody(body)
+	r.HTTPRequest.Header.Set("X-Amz-Target", r.ClientInfo.TargetPrefix+"."+r.Operation.Name)
+	r.HTTPRequest.Header.Set("Content-Type", "application/x-amz-json-"+r.ClientInfo.JSONVersion)
+}
+
+func unmarshal(r *request.Request) {
+	defer r.HTTPResponse.Body.Close()
+
+	if err := json.NewDecoder(r.HTTPResponse.Body).Decode(r.Data); err != nil && err != io.EOF {
+		r.Error = awserr.New("SerializationError", "failed to decode ECR JSON response", err)
+	}
+}
+
+func unmarshalMeta(r *request.Request) {
+	r.RequestID = r.HTTPResponse.Header.Get("X-Amzn-Requestid")
+}
+
+func unmarshalError(r *request.Request) {
+	defer r.HTTPResponse.Body.Close()
+
+	body, err := ioutil.ReadAll(r.HTTPResponse.Body)
+	if err != nil {
+		r.Error = awserr.New("SerializationError", "failed to read ECR JSON error response", err)
+		return
+	}
+
+	resp := &jsonErrorResponse{}
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, resp); err != nil {
+			r.Error = awserr.New("SerializationError", "failed to decode ECR JSON error response", err)
+			return
+		}
+	}
+
+	code := resp.Code
+	if i := strings.LastIndex(code, "#"); i >= 0 {
+		code = code[i+1:]
+	}
+	if code == "" {
+		code = "UnknownError"
+	}
+
+	r.Error = awserr.NewRequestFailure(
+		awserr.New(code, resp.Message, nil),
+		r.HTTPResponse.StatusCode,
+		r.RequestID,
+	)
+}
@@ -0,0 +1,100 @@
+package awsecr
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/pivotal-golang/lager"
+)
+
+// ECR BatchDeleteImage accepts up to 100 image IDs per call
+const maxBatchDeleteImages = 100
+
+type ECRRepository struct {
+	ecrsvc *ECR
+	logger lager.Logger
+}
+
+func NewECRRepository(
+	ecrsvc *ECR,
+	logger lager.Logger,
+) *ECRRepository {
+	return &ECRRepository{
+		ecrsvc: ecrsvc,
+		logger: logger.Session("ecr-repository"),
+	}
+}
+
+func (r *ECRRepository) DeleteImages(repositoryName string) error {
+	imageIDs := []ImageIdentifier{}
+
+	listImagesInput := &ListImagesInput{
+		RepositoryName: aws.String(repositoryName),
+	}
+
+	for {
+		r.logger.Debug("list-images", lager.Data{"input": listImagesInput})
+
+		listImagesOutput, err := r.ecrsvc.ListImages(listImagesInput)
+		if err != nil {
+			r.logger.Error("aws-ecr-error", err)
+			if awsErr, ok := err.(awserr.Error); ok {
+				// Nothing to delete if the repository is already gone
+				if awsErr.Code() == "RepositoryNotFoundException" {
+					return nil
+				}
+				return errors.New(awsErr.Code() + ": " + awsErr.Message())
+			}
+			return err
+		}
+
+		imageIDs = append(imageIDs, listImagesOutput.ImageIDs...)
+
+		if aws.StringValue(listImagesOutput.NextToken) == "" {
+			break
+		}
+		listImagesInput.NextToken = listImagesOutput.NextToken
+	}
+
+	for start := 0; start < len(imageIDs); start += maxBatchDeleteImages {
+		end := start + maxBatchDeleteImages
+		if end > len(imageIDs) {
+			end = len(imageIDs)
+		}
+
+		if err := r.batchDeleteImage(repositoryName, imageIDs[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *ECRRepository) batchDeleteImage(repositoryName string, imageIDs []ImageIdentifier) error {
+	batchDeleteImageInput := &BatchDeleteImageInput{
+		RepositoryName: aws.String(repositoryName),
+		ImageIDs:       imageIDs,
+	}
+	r.logger.Debug("batch-delete-image", lager.Data{"input": batchDeleteImageInput})
+
+	batchDeleteImageOutput, err := r.ecrsvc.BatchDeleteImage(batchDeleteImageInput)
+	if err != nil {
+		r.logger.Error("aws-ecr-error", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			return errors.New(awsErr.Code() + ": " + awsErr.Message())
+		}
+		return err
+	}
+
+	for _, failure := range batchDeleteImageOutput.Failures {
+		// Images referenced by several tags are reported once per tag
+		if aws.StringValue(failure.FailureCode) == "ImageNotFound" {
+			continue
+		}
+		return fmt.Errorf("%s: %s", aws.StringValue(failure.FailureCode), aws.StringValue(failure.FailureReason))
+	}
+
+	return nil
+}
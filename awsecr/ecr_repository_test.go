@@ -0,0 +1,220 @@
+package awsecr_test
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/awsecr"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+)
+
+var _ = Describe("ECR Repository", func() {
+	var (
+		repositoryName string
+
+		awsSession *session.Session
+		ecrsvc     *ECR
+		ecrCall    func(r *request.Request)
+
+		testSink *lagertest.TestSink
+		logger   lager.Logger
+
+		repository Repository
+	)
+
+	BeforeEach(func() {
+		repositoryName = "ecr-repository"
+	})
+
+	JustBeforeEach(func() {
+		awsSession = session.New(nil)
+		ecrsvc = New(awsSession)
+
+		logger = lager.NewLogger("ecrrepository_test")
+		testSink = lagertest.NewTestSink()
+		logger.RegisterSink(testSink)
+
+		repository = NewECRRepository(ecrsvc, logger)
+	})
+
+	var _ = Describe("DeleteImages", func() {
+		var (
+			listImagesOutputs []*ListImagesOutput
+			listImagesInputs  []*ListImagesInput
+			listImagesError   error
+
+			batchDeleteImageInputs []*BatchDeleteImageInput
+			batchDeleteImageOutput *BatchDeleteImageOutput
+			batchDeleteImageError  error
+		)
+
+		BeforeEach(func() {
+			listImagesOutputs = []*ListImagesOutput{
+				&ListImagesOutput{
+					ImageIDs:  []ImageIdentifier{ImageIdentifier{ImageDigest: aws.String("digest-1")}},
+					NextToken: aws.String("next-token"),
+				},
+				&ListImagesOutput{
+					ImageIDs: []ImageIdentifier{ImageIdentifier{ImageDigest: aws.String("digest-2"), ImageTag: aws.String("latest")}},
+				},
+			}
+			listImagesInputs = []*ListImagesInput{}
+			listImagesError = nil
+
+			batchDeleteImageInputs = []*BatchDeleteImageInput{}
+			batchDeleteImageOutput = &BatchDeleteImageOutput{}
+			batchDeleteImageError = nil
+		})
+
+		JustBeforeEach(func() {
+			ecrsvc.Handlers.Clear()
+
+			ecrCall = func(r *request.Request) {
+				switch r.Operation.Name {
+				case "ListImages":
+					Expect(r.Params).To(BeAssignableToTypeOf(&ListImagesInput{}))
+					input := *r.Params.(*ListImagesInput)
+					listImagesInputs = append(listImagesInputs, &input)
+					data := r.Data.(*ListImagesOutput)
+					*data = *listImagesOutputs[len(listImagesInputs)-1]
+					r.Error = listImagesError
+				case "BatchDeleteImage":
+					Expect(r.Params).To(BeAssignableToTypeOf(&BatchDeleteImageInput{}))
+					batchDeleteImageInputs = append(batchDeleteImageInputs, r.Params.(*BatchDeleteImageInput))
+					data := r.Data.(*BatchDeleteImageOutput)
+					*data = *batchDeleteImageOutput
+					r.Error = batchDeleteImageError
+				default:
+					Fail("unexpected operation " + r.Operation.Name)
+				}
+			}
+			ecrsvc.Handlers.Send.PushBack(ecrCall)
+		})
+
+		It("lists every page of images", func() {
+			err := repository.DeleteImages(repositoryName)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(listImagesInputs).To(Equal([]*ListImagesInput{
+				&ListImagesInput{RepositoryName: aws.String(repositoryName)},
+				&ListImagesInput{RepositoryName: aws.String(repositoryName), NextToken: aws.String("next-token")},
+			}))
+		})
+
+		It("deletes all images", func() {
+			err := repository.DeleteImages(repositoryName)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(batchDeleteImageInputs).To(Equal([]*BatchDeleteImageInput{
+				&BatchDeleteImageInput{
+					RepositoryName: aws.String(repositoryName),
+					ImageIDs: []ImageIdentifier{
+						ImageIdentifier{ImageDigest: aws.String("digest-1")},
+						ImageIdentifier{ImageDigest: aws.String("digest-2"), ImageTag: aws.String("latest")},
+					},
+				},
+			}))
+		})
+
+		Context("when there are more images than a batch can hold", func() {
+			BeforeEach(func() {
+				imageIDs := []ImageIdentifier{}
+				for i := 0; i < 150; i++ {
+					imageIDs = append(imageIDs, ImageIdentifier{ImageDigest: aws.String(fmt.Sprintf("digest-%d", i))})
+				}
+				listImagesOutputs = []*ListImagesOutput{&ListImagesOutput{ImageIDs: imageIDs}}
+			})
+
+			It("deletes the images in batches", func() {
+				err := repository.DeleteImages(repositoryName)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(batchDeleteImageInputs).To(HaveLen(2))
+				Expect(batchDeleteImageInputs[0].ImageIDs).To(HaveLen(100))
+				Expect(batchDeleteImageInputs[1].ImageIDs).To(HaveLen(50))
+			})
+		})
+
+		Context("when the repository has no images", func() {
+			BeforeEach(func() {
+				listImagesOutputs = []*ListImagesOutput{&ListImagesOutput{}}
+			})
+
+			It("does not delete any image", func() {
+				err := repository.DeleteImages(repositoryName)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(batchDeleteImageInputs).To(BeEmpty())
+			})
+		})
+
+		Context("when listing the images fails", func() {
+			BeforeEach(func() {
+				listImagesError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				err := repository.DeleteImages(repositoryName)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			Context("and it is an AWS error", func() {
+				BeforeEach(func() {
+					listImagesError = awserr.New("code", "message", errors.New("operation failed"))
+				})
+
+				It("returns the proper error", func() {
+					err := repository.DeleteImages(repositoryName)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("code: message"))
+				})
+			})
+
+			Context("and the repository does not exist", func() {
+				BeforeEach(func() {
+					listImagesError = awserr.New("RepositoryNotFoundException", "message", errors.New("operation failed"))
+				})
+
+				It("does not return error", func() {
+					err := repository.DeleteImages(repositoryName)
+					Expect(err).ToNot(HaveOccurred())
+				})
+			})
+		})
+
+		Context("when deleting the images fails", func() {
+			BeforeEach(func() {
+				batchDeleteImageError = awserr.New("code", "message", errors.New("operation failed"))
+			})
+
+			It("returns the proper error", func() {
+				err := repository.DeleteImages(repositoryName)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("code: message"))
+			})
+		})
+
+		Context("when some images could not be deleted", func() {
+			BeforeEach(func() {
+				batchDeleteImageOutput = &BatchDeleteImageOutput{
+					Failures: []ImageFailure{
+						ImageFailure{FailureCode: aws.String("ImageNotFound"), FailureReason: aws.String("not found")},
+						ImageFailure{FailureCode: aws.String("InvalidImageDigest"), FailureReason: aws.String("invalid digest")},
+					},
+				}
+			})
+
+			It("returns the proper error", func() {
+				err := repository.DeleteImages(repositoryName)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("InvalidImageDigest: invalid digest"))
+			})
+		})
+	})
+})
@@ -0,0 +1,14 @@
+package fakes
+
+type FakeRepository struct {
+	DeleteImagesCalled         bool
+	DeleteImagesRepositoryName string
+	DeleteImagesError          error
+}
+
+func (f *FakeRepository) DeleteImages(repositoryName string) error {
+	f.DeleteImagesCalled = true
+	f.DeleteImagesRepositoryName = repositoryName
+
+	return f.DeleteImagesError
+}
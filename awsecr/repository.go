@@ -0,0 +1,5 @@
+package awsecr
+
+type Repository interface {
+	DeleteImages(repositoryName string) error
+}
@@ -0,0 +1,13 @@
+package awss3_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestAWSS3(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AWS S3 Suite")
+}
@@ -0,0 +1,5 @@
+package awss3
+
+type Bucket interface {
+	Empty(bucketName string) error
+}
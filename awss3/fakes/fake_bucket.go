@@ -0,0 +1,14 @@
+package fakes
+
+type FakeBucket struct {
+	EmptyCalled     bool
+	EmptyBucketName string
+	EmptyError      error
+}
+
+func (f *FakeBucket) Empty(bucketName string) error {
+	f.EmptyCalled = true
+	f.EmptyBucketName = bucketName
+
+	return f.EmptyError
+}
@@ -0,0 +1,209 @@
+package awss3
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/private/protocol/rest"
+	"github.com/aws/aws-sdk-go/private/signer/v4"
+)
+
+// ServiceName is the name of the service the client will make API calls to.
+const ServiceName = "s3"
+
+const opListObjectVersions = "ListObjectVersions"
+const opDeleteObjects = "DeleteObjects"
+
+// S3 is a minimal Amazon S3 client built on top of the AWS SDK request
+// pipeline. It only implements the operations needed to empty a bucket.
+type S3 struct {
+	*client.Client
+}
+
+type ListObjectVersionsInput struct {
+	Bucket          *string
+	KeyMarker       *string
+	VersionIDMarker *string
+}
+
+type ListObjectVersionsOutput struct {
+	IsTruncated         *bool           `xml:"IsTruncated"`
+	NextKeyMarker       *string         `xml:"NextKeyMarker"`
+	NextVersionIDMarker *string         `xml:"NextVersionIdMarker"`
+	Versions            []ObjectVersion `xml:"Version"`
+	DeleteMarkers       []ObjectVersion `xml:"DeleteMarker"`
+}
+
+type ObjectVersion struct {
+	Key       *string `xml:"Key"`
+	VersionID *string `xml:"VersionId"`
+}
+
+type DeleteObjectsInput struct {
+	Bucket  *string
+	Objects []ObjectVersion
+}
+
+type DeleteObjectsOutput struct {
+	Errors []DeleteError `xml:"Error"`
+}
+
+type DeleteError struct {
+	Key       *string `xml:"Key"`
+	VersionID *string `xml:"VersionId"`
+	Code      *string `xml:"Code"`
+	Message   *string `xml:"Message"`
+}
+
+type deleteRequest struct {
+	XMLName xml.Name        `xml:"Delete"`
+	Objects []ObjectVersion `xml:"Object"`
+	Quiet   bool            `xml:"Quiet"`
+}
+
+type xmlErrorResponse struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string   `xml:"Code"`
+	Message   string   `xml:"Message"`
+	RequestID string   `xml:"RequestId"`
+}
+
+// New creates a new instance of the S3 client with a session.
+func New(p client.ConfigProvider, cfgs ...*aws.Config) *S3 {
+	c := p.ClientConfig(ServiceName, cfgs...)
+
+	svc := &S3{
+		Client: client.New(
+			*c.Config,
+			metadata.ClientInfo{
+				ServiceName:   ServiceName,
+				SigningRegion: c.SigningRegion,
+				Endpoint:      c.Endpoint,
+				APIVersion:    "2006-03-01",
+			},
+			c.Handlers,
+		),
+	}
+
+	svc.Handlers.Sign.PushBack(v4.Sign)
+	svc.Handlers.Build.PushBack(build)
+	svc.Handlers.Unmarshal.PushBack(unmarshal)
+	svc.Handlers.UnmarshalMeta.PushBack(unmarshalMeta)
+	svc.Handlers.UnmarshalError.PushBack(unmarshalError)
+
+	return svc
+}
+
+// ListObjectVersions returns metadata about all of the versions of objects in a bucket.
+func (c *S3) ListObjectVersions(input *ListObjectVersionsInput) (*ListObjectVersionsOutput, error) {
+	op := &request.Operation{
+		Name:       opListObjectVersions,
+		HTTPMethod: "GET",
+		HTTPPath:   "/",
+	}
+
+	output := &ListObjectVersionsOutput{}
+	req := c.NewRequest(op, input, output)
+
+	return output, req.Send()
+}
+
+// DeleteObjects removes up to 1000 object versions from a bucket in a single
+// request, only reporting the versions that could not be deleted.
+func (c *S3) DeleteObjects(input *DeleteObjectsInput) (*DeleteObjectsOutput, error) {
+	op := &request.Operation{
+		Name:       opDeleteObjects,
+		HTTPMethod: "POST",
+		HTTPPath:   "/",
+	}
+
+	output := &DeleteObjectsOutput{}
+	req := c.NewRequest(op, input, output)
+
+	return output, req.Send()
+}
+
+func build(r *request.Request) {
+	query := url.Values{}
+	path := "/"
+
+	switch input := r.Params.(type) {
+	case *ListObjectVersionsInput:
+		path += rest.EscapePath(aws.StringValue(input.Bucket), true)
+		query.Set("versions", "")
+		if input.KeyMarker != nil {
+			query.Set("key-marker", aws.StringValue(input.KeyMarker))
+		}
+		if input.VersionIDMarker != nil {
+			query.Set("version-id-marker", aws.StringValue(input.VersionIDMarker))
+		}
+	case *DeleteObjectsInput:
+		path += rest.EscapePath(aws.StringValue(input.Bucket), true)
+		query.Set("delete", "")
+
+		body, err := xml.Marshal(deleteRequest{Objects: input.Objects, Quiet: true})
+		if err != nil {
+			r.Error = awserr.New("SerializationError", "failed to encode S3 XML request", err)
+			return
+		}
+		// S3 requires the MD5 digest of multi-object delete requests
+		digest := md5.Sum(body)
+		r.HTTPRequest.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(digest[:]))
+		r.HTTPRequest.Header.Set("Content-Type", "application/xml")
+		r.SetBufferBody(body)
+	}
+
+	r.HTTPRequest.URL.Opaque = "//" + r.HTTPRequest.URL.Host + path
+	r.HTTPRequest.URL.RawQuery = query.Encode()
+}
+
+func unmarshal(r *request.Request) {
+	defer r.HTTPResponse.Body.Close()
+
+	switch data := r.Data.(type) {
+	case *ListObjectVersionsOutput:
+		if err := xml.NewDecoder(r.HTTPResponse.Body).Decode(data); err != nil && err != io.EOF {
+			r.Error = awserr.New("SerializationError", "failed to decode S3 XML response", err)
+		}
+	case *DeleteObjectsOutput:
+		if err := xml.NewDecoder(r.HTTPResponse.Body).Decode(data); err != nil && err != io.EOF {
+			r.Error = awserr.New("SerializationError", "failed to decode S3 XML response", err)
+		}
+	default:
+		io.Copy(ioutil.Discard, r.HTTPResponse.Body)
+	}
+}
+
+func unmarshalMeta(r *request.Request) {
+	r.RequestID = r.HTTPResponse.Header.Get("X-Amz-Request-Id")
+}
+
+func unmarshalError(r *request.Request) {
+	defer r.HTTPResponse.Body.Close()
+
+	resp := &xmlErrorResponse{}
+	err := xml.NewDecoder(r.HTTPResponse.Body).Decode(resp)
+	if err != nil && err != io.EOF {
+		r.Error = awserr.New("SerializationError", "failed to decode S3 XML error response", err)
+		return
+	}
+
+	if resp.Code == "" {
+		resp.Code = "UnknownError"
+	}
+
+	r.Error = awserr.NewRequestFailure(
+		awserr.New(resp.Code, resp.Message, nil),
+		r.HTTPResponse.StatusCode,
+		resp.RequestID,
+	)
+}
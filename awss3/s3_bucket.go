@@ -0,0 +1,94 @@
+package awss3
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/pivotal-golang/lager"
+)
+
+// S3 DeleteObjects accepts up to 1000 object versions per call
+const maxDeleteObjects = 1000
+
+type S3Bucket struct {
+	s3svc  *S3
+	logger lager.Logger
+}
+
+func NewS3Bucket(
+	s3svc *S3,
+	logger lager.Logger,
+) *S3Bucket {
+	return &S3Bucket{
+		s3svc:  s3svc,
+		logger: logger.Session("s3-bucket"),
+	}
+}
+
+func (b *S3Bucket) Empty(bucketName string) error {
+	listObjectVersionsInput := &ListObjectVersionsInput{
+		Bucket: aws.String(bucketName),
+	}
+
+	for {
+		b.logger.Debug("list-object-versions", lager.Data{"input": listObjectVersionsInput})
+
+		listObjectVersionsOutput, err := b.s3svc.ListObjectVersions(listObjectVersionsInput)
+		if err != nil {
+			b.logger.Error("aws-s3-error", err)
+			if awsErr, ok := err.(awserr.Error); ok {
+				// Nothing to empty if the bucket is already gone
+				if awsErr.Code() == "NoSuchBucket" {
+					return nil
+				}
+				return errors.New(awsErr.Code() + ": " + awsErr.Message())
+			}
+			return err
+		}
+
+		objectVersions := append(listObjectVersionsOutput.Versions, listObjectVersionsOutput.DeleteMarkers...)
+		for start := 0; start < len(objectVersions); start += maxDeleteObjects {
+			end := start + maxDeleteObjects
+			if end > len(objectVersions) {
+				end = len(objectVersions)
+			}
+
+			if err := b.deleteObjects(bucketName, objectVersions[start:end]); err != nil {
+				return err
+			}
+		}
+
+		if !aws.BoolValue(listObjectVersionsOutput.IsTruncated) {
+			return nil
+		}
+
+		listObjectVersionsInput.KeyMarker = listObjectVersionsOutput.NextKeyMarker
+		listObjectVersionsInput.VersionIDMarker = listObjectVersionsOutput.NextVersionIDMarker
+	}
+}
+
+func (b *S3Bucket) deleteObjects(bucketName string, objectVersions []ObjectVersion) error {
+	deleteObjectsInput := &DeleteObjectsInput{
+		Bucket:  aws.String(bucketName),
+		Objects: objectVersions,
+	}
+	b.logger.Debug("delete-objects", lager.Data{"input": deleteObjectsInput})
+
+	deleteObjectsOutput, err := b.s3svc.DeleteObjects(deleteObjectsInput)
+	if err != nil {
+		b.logger.Error("aws-s3-error", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			return errors.New(awsErr.Code() + ": " + awsErr.Message())
+		}
+		return err
+	}
+
+	if len(deleteObjectsOutput.Errors) > 0 {
+		deleteError := deleteObjectsOutput.Errors[0]
+		return fmt.Errorf("%s: %s", aws.StringValue(deleteError.Code), aws.StringValue(deleteError.Message))
+	}
+
+	return nil
+}
@@ -0,0 +1,231 @@
+package awss3_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/awss3"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+)
+
+var _ = Describe("S3 Bucket", func() {
+	var (
+		bucketName string
+
+		awsSession *session.Session
+		s3svc      *S3
+		s3Call     func(r *request.Request)
+
+		testSink *lagertest.TestSink
+		logger   lager.Logger
+
+		bucket Bucket
+	)
+
+	BeforeEach(func() {
+		bucketName = "s3-bucket"
+	})
+
+	JustBeforeEach(func() {
+		awsSession = session.New(nil)
+		s3svc = New(awsSession)
+
+		logger = lager.NewLogger("s3bucket_test")
+		testSink = lagertest.NewTestSink()
+		logger.RegisterSink(testSink)
+
+		bucket = NewS3Bucket(s3svc, logger)
+	})
+
+	var _ = Describe("Empty", func() {
+		var (
+			listObjectVersionsOutputs []*ListObjectVersionsOutput
+			listObjectVersionsInputs  []*ListObjectVersionsInput
+			listObjectVersionsError   error
+
+			deleteObjectsInputs []*DeleteObjectsInput
+			deleteObjectsOutput *DeleteObjectsOutput
+			deleteObjectsError  error
+		)
+
+		BeforeEach(func() {
+			listObjectVersionsOutputs = []*ListObjectVersionsOutput{
+				&ListObjectVersionsOutput{
+					IsTruncated:         aws.Bool(true),
+					NextKeyMarker:       aws.String("key-2"),
+					NextVersionIDMarker: aws.String("version-2"),
+					Versions: []ObjectVersion{
+						ObjectVersion{Key: aws.String("key-1"), VersionID: aws.String("version-1")},
+					},
+				},
+				&ListObjectVersionsOutput{
+					IsTruncated: aws.Bool(false),
+					Versions: []ObjectVersion{
+						ObjectVersion{Key: aws.String("key-2"), VersionID: aws.String("version-2")},
+					},
+					DeleteMarkers: []ObjectVersion{
+						ObjectVersion{Key: aws.String("key-3"), VersionID: aws.String("version-3")},
+					},
+				},
+			}
+			listObjectVersionsInputs = []*ListObjectVersionsInput{}
+			listObjectVersionsError = nil
+
+			deleteObjectsInputs = []*DeleteObjectsInput{}
+			deleteObjectsOutput = &DeleteObjectsOutput{}
+			deleteObjectsError = nil
+		})
+
+		JustBeforeEach(func() {
+			s3svc.Handlers.Clear()
+
+			s3Call = func(r *request.Request) {
+				switch r.Operation.Name {
+				case "ListObjectVersions":
+					Expect(r.Params).To(BeAssignableToTypeOf(&ListObjectVersionsInput{}))
+					input := *r.Params.(*ListObjectVersionsInput)
+					listObjectVersionsInputs = append(listObjectVersionsInputs, &input)
+					data := r.Data.(*ListObjectVersionsOutput)
+					*data = *listObjectVersionsOutputs[len(listObjectVersionsInputs)-1]
+					r.Error = listObjectVersionsError
+				case "DeleteObjects":
+					Expect(r.Params).To(BeAssignableToTypeOf(&DeleteObjectsInput{}))
+					deleteObjectsInputs = append(deleteObjectsInputs, r.Params.(*DeleteObjectsInput))
+					data := r.Data.(*DeleteObjectsOutput)
+					*data = *deleteObjectsOutput
+					r.Error = deleteObjectsError
+				default:
+					Fail("unexpected operation " + r.Operation.Name)
+				}
+			}
+			s3svc.Handlers.Send.PushBack(s3Call)
+		})
+
+		It("lists every page of object versions", func() {
+			err := bucket.Empty(bucketName)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(listObjectVersionsInputs).To(Equal([]*ListObjectVersionsInput{
+				&ListObjectVersionsInput{
+					Bucket: aws.String(bucketName),
+				},
+				&ListObjectVersionsInput{
+					Bucket:          aws.String(bucketName),
+					KeyMarker:       aws.String("key-2"),
+					VersionIDMarker: aws.String("version-2"),
+				},
+			}))
+		})
+
+		It("deletes every object version and delete marker, a page at a time", func() {
+			err := bucket.Empty(bucketName)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deleteObjectsInputs).To(Equal([]*DeleteObjectsInput{
+				&DeleteObjectsInput{
+					Bucket: aws.String(bucketName),
+					Objects: []ObjectVersion{
+						ObjectVersion{Key: aws.String("key-1"), VersionID: aws.String("version-1")},
+					},
+				},
+				&DeleteObjectsInput{
+					Bucket: aws.String(bucketName),
+					Objects: []ObjectVersion{
+						ObjectVersion{Key: aws.String("key-2"), VersionID: aws.String("version-2")},
+						ObjectVersion{Key: aws.String("key-3"), VersionID: aws.String("version-3")},
+					},
+				},
+			}))
+		})
+
+		Context("when a page has more object versions than a request can delete", func() {
+			BeforeEach(func() {
+				versions := []ObjectVersion{}
+				for i := 0; i < 1500; i++ {
+					versions = append(versions, ObjectVersion{Key: aws.String("key"), VersionID: aws.String("version")})
+				}
+				listObjectVersionsOutputs = []*ListObjectVersionsOutput{
+					&ListObjectVersionsOutput{IsTruncated: aws.Bool(false), Versions: versions},
+				}
+			})
+
+			It("deletes them in batches of 1000", func() {
+				err := bucket.Empty(bucketName)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deleteObjectsInputs).To(HaveLen(2))
+				Expect(deleteObjectsInputs[0].Objects).To(HaveLen(1000))
+				Expect(deleteObjectsInputs[1].Objects).To(HaveLen(500))
+			})
+		})
+
+		Context("when listing the object versions fails", func() {
+			BeforeEach(func() {
+				listObjectVersionsError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				err := bucket.Empty(bucketName)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			Context("and it is an AWS error", func() {
+				BeforeEach(func() {
+					listObjectVersionsError = awserr.New("code", "message", errors.New("operation failed"))
+				})
+
+				It("returns the proper error", func() {
+					err := bucket.Empty(bucketName)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("code: message"))
+				})
+			})
+
+			Context("and the bucket does not exist", func() {
+				BeforeEach(func() {
+					listObjectVersionsError = awserr.New("NoSuchBucket", "message", errors.New("operation failed"))
+				})
+
+				It("does not return error", func() {
+					err := bucket.Empty(bucketName)
+					Expect(err).ToNot(HaveOccurred())
+				})
+			})
+		})
+
+		Context("when deleting the objects fails", func() {
+			BeforeEach(func() {
+				deleteObjectsError = awserr.New("code", "message", errors.New("operation failed"))
+			})
+
+			It("returns the proper error", func() {
+				err := bucket.Empty(bucketName)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("code: message"))
+				Expect(deleteObjectsInputs).To(HaveLen(1))
+			})
+		})
+
+		Context("when an object can not be deleted", func() {
+			BeforeEach(func() {
+				deleteObjectsOutput = &DeleteObjectsOutput{
+					Errors: []DeleteError{
+						DeleteError{Key: aws.String("key-1"), VersionID: aws.String("version-1"), Code: aws.String("AccessDenied"), Message: aws.String("Access Denied")},
+					},
+				}
+			})
+
+			It("returns the proper error", func() {
+				err := bucket.Empty(bucketName)
+				Expect(err).To(MatchError("AccessDenied: Access Denied"))
+				Expect(deleteObjectsInputs).To(HaveLen(1))
+			})
+		})
+	})
+})
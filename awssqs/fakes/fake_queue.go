@@ -0,0 +1,28 @@
+package fakes
+
+import (
+	"github.com/cf-platform-eng/cloudformation-broker/awssqs"
+)
+
+type FakeQueue struct {
+	ReceiveCalled   bool
+	ReceiveMessages []awssqs.QueueMessage
+	ReceiveError    error
+
+	DeleteCalled         bool
+	DeleteReceiptHandles []string
+	DeleteError          error
+}
+
+func (f *FakeQueue) Receive() ([]awssqs.QueueMessage, error) {
+	f.ReceiveCalled = true
+
+	return f.ReceiveMessages, f.ReceiveError
+}
+
+func (f *FakeQueue) Delete(receiptHandle string) error {
+	f.DeleteCalled = true
+	f.DeleteReceiptHandles = append(f.DeleteReceiptHandles, receiptHandle)
+
+	return f.DeleteError
+}
@@ -0,0 +1,12 @@
+package awssqs
+
+type Queue interface {
+	Receive() ([]QueueMessage, error)
+	Delete(receiptHandle string) error
+}
+
+type QueueMessage struct {
+	MessageID     string
+	ReceiptHandle string
+	Body          string
+}
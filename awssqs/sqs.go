@@ -0,0 +1,102 @@
+package awssqs
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/private/protocol/query"
+	"github.com/aws/aws-sdk-go/private/signer/v4"
+)
+
+// ServiceName is the name of the service the client will make API calls to.
+const ServiceName = "sqs"
+
+const opReceiveMessage = "ReceiveMessage"
+const opDeleteMessage = "DeleteMessage"
+
+// SQS is a minimal Amazon SQS client built on top of the AWS SDK request
+// pipeline. It only implements the operations needed to consume a queue.
+type SQS struct {
+	*client.Client
+}
+
+type ReceiveMessageInput struct {
+	QueueUrl            *string
+	MaxNumberOfMessages *int64
+	WaitTimeSeconds     *int64
+}
+
+type ReceiveMessageOutput struct {
+	Messages []*Message `locationNameList:"Message" type:"list" flattened:"true"`
+}
+
+type Message struct {
+	MessageId     *string
+	ReceiptHandle *string
+	Body          *string
+}
+
+type DeleteMessageInput struct {
+	QueueUrl      *string
+	ReceiptHandle *string
+}
+
+type DeleteMessageOutput struct{}
+
+// New creates a new instance of the SQS client with a session. The endpoint
+// of the configuration, if any, points the client to an SQS-compatible
+// service other than Amazon SQS.
+func New(p client.ConfigProvider, cfgs ...*aws.Config) *SQS {
+	c := p.ClientConfig(ServiceName, cfgs...)
+
+	svc := &SQS{
+		Client: client.New(
+			*c.Config,
+			metadata.ClientInfo{
+				ServiceName:   ServiceName,
+				SigningRegion: c.SigningRegion,
+				Endpoint:      c.Endpoint,
+				APIVersion:    "2012-11-05",
+			},
+			c.Handlers,
+		),
+	}
+
+	svc.Handlers.Sign.PushBack(v4.Sign)
+	svc.Handlers.Build.PushBack(query.Build)
+	svc.Handlers.Unmarshal.PushBack(query.Unmarshal)
+	svc.Handlers.UnmarshalMeta.PushBack(query.UnmarshalMeta)
+	svc.Handlers.UnmarshalError.PushBack(query.UnmarshalError)
+
+	return svc
+}
+
+// ReceiveMessage retrieves messages from a queue, waiting for them up to
+// the requested time.
+func (c *SQS) ReceiveMessage(input *ReceiveMessageInput) (*ReceiveMessageOutput, error) {
+	op := &request.Operation{
+		Name:       opReceiveMessage,
+		HTTPMethod: "POST",
+		HTTPPath:   "/",
+	}
+
+	output := &ReceiveMessageOutput{}
+	req := c.NewRequest(op, input, output)
+
+	return output, req.Send()
+}
+
+// DeleteMessage deletes a received message from a queue.
+func (c *SQS) DeleteMessage(input *DeleteMessageInput) (*DeleteMessageOutput, error) {
+	op := &request.Operation{
+		Name:       opDeleteMessage,
+		HTTPMethod: "POST",
+		HTTPPath:   "/",
+	}
+
+	output := &DeleteMessageOutput{}
+	req := c.NewRequest(op, input, output)
+
+	return output, req.Send()
+}
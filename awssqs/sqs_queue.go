@@ -0,0 +1,81 @@
+package awssqs
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/pivotal-golang/lager"
+)
+
+// SQS ReceiveMessage returns up to 10 messages, and waits up to 20 seconds
+// for them to arrive
+const maxReceiveMessages = 10
+const receiveWaitTimeSeconds = 20
+
+type SQSQueue struct {
+	queueURL string
+	sqssvc   *SQS
+	logger   lager.Logger
+}
+
+func NewSQSQueue(
+	queueURL string,
+	sqssvc *SQS,
+	logger lager.Logger,
+) *SQSQueue {
+	return &SQSQueue{
+		queueURL: queueURL,
+		sqssvc:   sqssvc,
+		logger:   logger.Session("sqs-queue"),
+	}
+}
+
+// Receive long polls the queue for messages, returning none if no message
+// arrived in time.
+func (q *SQSQueue) Receive() ([]QueueMessage, error) {
+	receiveMessageInput := &ReceiveMessageInput{
+		QueueUrl:            aws.String(q.queueURL),
+		MaxNumberOfMessages: aws.Int64(maxReceiveMessages),
+		WaitTimeSeconds:     aws.Int64(receiveWaitTimeSeconds),
+	}
+	q.logger.Debug("receive-message", lager.Data{"input": receiveMessageInput})
+
+	receiveMessageOutput, err := q.sqssvc.ReceiveMessage(receiveMessageInput)
+	if err != nil {
+		q.logger.Error("aws-sqs-error", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			return nil, errors.New(awsErr.Code() + ": " + awsErr.Message())
+		}
+		return nil, err
+	}
+
+	queueMessages := []QueueMessage{}
+	for _, message := range receiveMessageOutput.Messages {
+		queueMessages = append(queueMessages, QueueMessage{
+			MessageID:     aws.StringValue(message.MessageId),
+			ReceiptHandle: aws.StringValue(message.ReceiptHandle),
+			Body:          aws.StringValue(message.Body),
+		})
+	}
+
+	return queueMessages, nil
+}
+
+func (q *SQSQueue) Delete(receiptHandle string) error {
+	deleteMessageInput := &DeleteMessageInput{
+		QueueUrl:      aws.String(q.queueURL),
+		ReceiptHandle: aws.String(receiptHandle),
+	}
+	q.logger.Debug("delete-message", lager.Data{"input": deleteMessageInput})
+
+	if _, err := q.sqssvc.DeleteMessage(deleteMessageInput); err != nil {
+		q.logger.Error("aws-sqs-error", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			return errors.New(awsErr.Code() + ": " + awsErr.Message())
+		}
+		return err
+	}
+
+	return nil
+}
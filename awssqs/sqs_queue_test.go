@@ -0,0 +1,165 @@
+package awssqs_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/awssqs"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+)
+
+// The queue is tested against a local SQS-compatible stand-in, as the broker
+// can be pointed at one through the SQS endpoint.
+var _ = Describe("SQS Queue", func() {
+	var (
+		queueURL string
+
+		server       *httptest.Server
+		requests     []url.Values
+		responseCode int
+		responseBody string
+
+		sqssvc *SQS
+
+		testSink *lagertest.TestSink
+		logger   lager.Logger
+
+		queue Queue
+	)
+
+	BeforeEach(func() {
+		queueURL = "http://sqs.local/queue/stack-notifications"
+
+		requests = []url.Values{}
+		responseCode = http.StatusOK
+		responseBody = ""
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+
+			Expect(r.ParseForm()).To(Succeed())
+			requests = append(requests, r.PostForm)
+
+			w.WriteHeader(responseCode)
+			fmt.Fprint(w, responseBody)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	JustBeforeEach(func() {
+		sqssvc = New(session.New(nil), aws.NewConfig().
+			WithRegion("us-east-1").
+			WithEndpoint(server.URL).
+			WithCredentials(credentials.NewStaticCredentials("access-key-id", "secret-access-key", "")).
+			WithMaxRetries(0))
+
+		logger = lager.NewLogger("sqsqueue_test")
+		testSink = lagertest.NewTestSink()
+		logger.RegisterSink(testSink)
+
+		queue = NewSQSQueue(queueURL, sqssvc, logger)
+	})
+
+	var _ = Describe("Receive", func() {
+		BeforeEach(func() {
+			responseBody = `<ReceiveMessageResponse>
+  <ReceiveMessageResult>
+    <Message>
+      <MessageId>message-id-1</MessageId>
+      <ReceiptHandle>receipt-handle-1</ReceiptHandle>
+      <Body>body-1</Body>
+    </Message>
+    <Message>
+      <MessageId>message-id-2</MessageId>
+      <ReceiptHandle>receipt-handle-2</ReceiptHandle>
+      <Body>body-2</Body>
+    </Message>
+  </ReceiveMessageResult>
+  <ResponseMetadata><RequestId>request-id</RequestId></ResponseMetadata>
+</ReceiveMessageResponse>`
+		})
+
+		It("long polls the queue", func() {
+			_, err := queue.Receive()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].Get("Action")).To(Equal("ReceiveMessage"))
+			Expect(requests[0].Get("QueueUrl")).To(Equal(queueURL))
+			Expect(requests[0].Get("MaxNumberOfMessages")).To(Equal("10"))
+			Expect(requests[0].Get("WaitTimeSeconds")).To(Equal("20"))
+		})
+
+		It("returns the received messages", func() {
+			messages, err := queue.Receive()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(messages).To(Equal([]QueueMessage{
+				QueueMessage{MessageID: "message-id-1", ReceiptHandle: "receipt-handle-1", Body: "body-1"},
+				QueueMessage{MessageID: "message-id-2", ReceiptHandle: "receipt-handle-2", Body: "body-2"},
+			}))
+		})
+
+		Context("when no message arrives", func() {
+			BeforeEach(func() {
+				responseBody = `<ReceiveMessageResponse><ReceiveMessageResult/></ReceiveMessageResponse>`
+			})
+
+			It("returns no messages", func() {
+				messages, err := queue.Receive()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(messages).To(BeEmpty())
+			})
+		})
+
+		Context("when receiving the messages fails", func() {
+			BeforeEach(func() {
+				responseCode = http.StatusBadRequest
+				responseBody = `<ErrorResponse><Error><Code>AWS.SimpleQueueService.NonExistentQueue</Code><Message>The specified queue does not exist.</Message></Error><RequestId>request-id</RequestId></ErrorResponse>`
+			})
+
+			It("returns the proper error", func() {
+				_, err := queue.Receive()
+				Expect(err).To(MatchError("AWS.SimpleQueueService.NonExistentQueue: The specified queue does not exist."))
+			})
+		})
+	})
+
+	var _ = Describe("Delete", func() {
+		BeforeEach(func() {
+			responseBody = `<DeleteMessageResponse><ResponseMetadata><RequestId>request-id</RequestId></ResponseMetadata></DeleteMessageResponse>`
+		})
+
+		It("deletes the message", func() {
+			err := queue.Delete("receipt-handle-1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].Get("Action")).To(Equal("DeleteMessage"))
+			Expect(requests[0].Get("QueueUrl")).To(Equal(queueURL))
+			Expect(requests[0].Get("ReceiptHandle")).To(Equal("receipt-handle-1"))
+		})
+
+		Context("when deleting the message fails", func() {
+			BeforeEach(func() {
+				responseCode = http.StatusBadRequest
+				responseBody = `<ErrorResponse><Error><Code>ReceiptHandleIsInvalid</Code><Message>The receipt handle is not valid.</Message></Error><RequestId>request-id</RequestId></ErrorResponse>`
+			})
+
+			It("returns the proper error", func() {
+				err := queue.Delete("receipt-handle-1")
+				Expect(err).To(MatchError("ReceiptHandleIsInvalid: The receipt handle is not valid."))
+			})
+		})
+	})
+})
@@ -3,13 +3,17 @@ package cfbroker
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"time"
 
+	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/frodenas/brokerapi"
 	"github.com/mitchellh/mapstructure"
 	"github.com/pivotal-golang/lager"
 
 	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+	"github.com/cf-platform-eng/cloudformation-broker/awsecr"
+	"github.com/cf-platform-eng/cloudformation-broker/awss3"
 )
 
 const instanceIDLogKey = "instance-id"
@@ -17,34 +21,88 @@ const bindingIDLogKey = "binding-id"
 const detailsLogKey = "details"
 const acceptsIncompleteLogKey = "acceptsIncomplete"
 
+const preDeleteWebhookTimeout = 30 * time.Second
+
+const brokerTagValue = "AWS CloudFormation Service Broker"
+
 type ProvisionParameters map[string]string
 
 type UpdateParameters map[string]string
 
 type CloudFormationBroker struct {
 	cloudformationPrefix         string
+	brokerID                     string
+	stackNameTemplate            string
 	allowUserProvisionParameters bool
 	allowUserUpdateParameters    bool
 	catalog                      Catalog
 	stack                        awscf.Stack
+	baseStack                    awscf.Stack
+	bucket                       awss3.Bucket
+	repository                   awsecr.Repository
+	httpClient                   *http.Client
+	operations                   *operationTracker
+	stacks                       *stackTracker
+	locks                        *instanceLocks
+	issuedOperations             *operationTokenTracker
+	templateResources            *templateResourceTracker
+	operationTokens              *OperationTokens
+	driftReporter                DriftReporter
+	notificationTopicARN         string
+	stackStatusCache             StackStatusCache
 	logger                       lager.Logger
 }
 
+// DriftReporter describes the drift of the stack of a service instance, if
+// it is known to have drifted.
+type DriftReporter interface {
+	DriftDescription(instanceID string) string
+}
+
 func New(
 	config Config,
 	stack awscf.Stack,
+	bucket awss3.Bucket,
+	repository awsecr.Repository,
 	logger lager.Logger,
 ) *CloudFormationBroker {
 	return &CloudFormationBroker{
 		cloudformationPrefix:         config.CloudFormationPrefix,
+		brokerID:                     config.BrokerID,
+		stackNameTemplate:            config.stackNameTemplate(),
 		allowUserProvisionParameters: config.AllowUserProvisionParameters,
 		allowUserUpdateParameters:    config.AllowUserUpdateParameters,
 		catalog:                      config.Catalog,
 		stack:                        stack,
+		baseStack:                    stack,
+		bucket:                       bucket,
+		repository:                   repository,
+		httpClient:                   &http.Client{Timeout: preDeleteWebhookTimeout},
+		operations:                   newOperationTracker(),
+		stacks:                       newStackTracker(),
+		locks:                        newInstanceLocks(),
+		issuedOperations:             newOperationTokenTracker(),
+		templateResources:            newTemplateResourceTracker(),
 		logger:                       logger.Session("broker"),
 	}
 }
 
+// WithStack returns a broker that manages the same instances and tracked
+// operations through another stack, such as one instrumented for a single
+// request.
+func (b *CloudFormationBroker) WithStack(stack awscf.Stack) *CloudFormationBroker {
+	broker := *b
+	broker.stack = stack
+
+	return &broker
+}
+
+// ReportDrift adds the drift of the stack, as described by driftReporter, to
+// the description of failed last operations.
+func (b *CloudFormationBroker) ReportDrift(driftReporter DriftReporter) {
+	b.driftReporter = driftReporter
+}
+
 func (b *CloudFormationBroker) Services() brokerapi.CatalogResponse {
 	catalogResponse := brokerapi.CatalogResponse{}
 
@@ -74,10 +132,12 @@ func (b *CloudFormationBroker) Provision(instanceID string, details brokerapi.Pr
 
 	provisioningResponse := brokerapi.ProvisioningResponse{}
 
-	if !acceptsIncomplete {
+	if !b.acceptsOperation(details.PlanID, acceptsIncomplete) {
 		return provisioningResponse, true, brokerapi.ErrAsyncRequired
 	}
 
+	defer b.locks.Lock(instanceID)()
+
 	provisionParameters := ProvisionParameters{}
 	if b.allowUserProvisionParameters {
 		if err := mapstructure.Decode(details.Parameters, &provisionParameters); err != nil {
@@ -90,12 +150,46 @@ func (b *CloudFormationBroker) Provision(instanceID string, details brokerapi.Pr
 		return provisioningResponse, true, fmt.Errorf("Service Plan '%s' not found", details.PlanID)
 	}
 
+	b.operations.Clear(instanceID)
+	b.stacks.Forget(instanceID)
+	b.issuedOperations.Forget(instanceID)
+	b.stacks.SetPlan(instanceID, details.ServiceID, details.PlanID)
+
 	createStackDetails := b.createStackDetails(instanceID, servicePlan, provisionParameters, details)
-	if err := b.stack.Create(b.stackName(instanceID), *createStackDetails); err != nil {
+
+	// A new instance has no stack, so the stacks of the account are not
+	// looked up for a rolled back stack named with another template.
+	existingStackName, _, err := b.findNamedStack(instanceID)
+	if err != nil {
 		return provisioningResponse, true, err
 	}
 
-	return provisioningResponse, true, nil
+	existingStackDetails, err := b.stack.Describe(existingStackName)
+	if err != nil && err != awscf.ErrStackDoesNotExist {
+		return provisioningResponse, true, err
+	}
+
+	if err == nil && b.isRolledBackStack(existingStackDetails) {
+		if err := b.replaceRolledBackStack(instanceID, *createStackDetails); err != nil {
+			return provisioningResponse, true, err
+		}
+	} else {
+		b.stacks.Forget(instanceID)
+		b.stacks.SetName(instanceID, b.newStackName(instanceID, details.ServiceID, details.PlanID))
+
+		stackID, err := b.stack.Create(b.stackName(instanceID), *createStackDetails)
+		if err != nil {
+			return provisioningResponse, true, err
+		}
+		b.stacks.SetID(instanceID, stackID)
+	}
+
+	asynch, err := b.completeOperation(instanceID, operationProvision, details.PlanID, acceptsIncomplete)
+	if err == nil && asynch {
+		err = b.issueOperationToken(instanceID, operationProvision, nil)
+	}
+
+	return provisioningResponse, asynch, err
 }
 
 func (b *CloudFormationBroker) Update(instanceID string, details brokerapi.UpdateDetails, acceptsIncomplete bool) (bool, error) {
@@ -105,16 +199,11 @@ func (b *CloudFormationBroker) Update(instanceID string, details brokerapi.Updat
 		acceptsIncompleteLogKey: acceptsIncomplete,
 	})
 
-	if !acceptsIncomplete {
+	if !b.acceptsOperation(details.PlanID, acceptsIncomplete) {
 		return true, brokerapi.ErrAsyncRequired
 	}
 
-	updateParameters := UpdateParameters{}
-	if b.allowUserUpdateParameters {
-		if err := mapstructure.Decode(details.Parameters, &updateParameters); err != nil {
-			return true, err
-		}
-	}
+	defer b.locks.Lock(instanceID)()
 
 	service, ok := b.catalog.FindService(details.ServiceID)
 	if !ok {
@@ -130,6 +219,37 @@ func (b *CloudFormationBroker) Update(instanceID string, details brokerapi.Updat
 		return true, fmt.Errorf("Service Plan '%s' not found", details.PlanID)
 	}
 
+	if isContinueUpdateRollback(details.Parameters) && !b.allowUserUpdateParameters {
+		return true, fmt.Errorf("Parameter '%s' is not allowed", ContinueUpdateRollbackParameter)
+	}
+
+	updateParameters := UpdateParameters{}
+	if b.allowUserUpdateParameters && !isContinueUpdateRollback(details.Parameters) {
+		if err := mapstructure.Decode(details.Parameters, &updateParameters); err != nil {
+			return true, err
+		}
+	}
+
+	b.stacks.SetPlan(instanceID, details.ServiceID, details.PreviousValues.PlanID)
+
+	stackDetails, err := b.ownedStack(instanceID, details.ServiceID)
+	if err != nil {
+		return true, err
+	}
+
+	if err := b.checkConcurrentOperation(instanceID, stackDetails); err != nil {
+		return true, err
+	}
+
+	if isContinueUpdateRollback(details.Parameters) {
+		if err := b.continueUpdateRollback(instanceID, details.Parameters); err != nil {
+			return true, err
+		}
+		return b.completeTokenedOperation(instanceID, operationUpdate, details.PlanID, acceptsIncomplete, stackDetails)
+	}
+
+	b.operations.Clear(instanceID)
+
 	modifyStackDetails := b.modifyStackDetails(instanceID, servicePlan, updateParameters, details)
 	if err := b.stack.Modify(b.stackName(instanceID), *modifyStackDetails); err != nil {
 		if err == awscf.ErrStackDoesNotExist {
@@ -138,7 +258,12 @@ func (b *CloudFormationBroker) Update(instanceID string, details brokerapi.Updat
 		return true, err
 	}
 
-	return true, nil
+	if servicePlan.CloudFormationProperties.UpdateTimeoutInMinutes > 0 {
+		updateTimeout := time.Duration(servicePlan.CloudFormationProperties.UpdateTimeoutInMinutes) * time.Minute
+		b.operations.SetUpdateTimeout(instanceID, fmt.Sprintf("Updating stack '%s'", b.stackName(instanceID)), updateTimeout)
+	}
+
+	return b.completeTokenedOperation(instanceID, operationUpdate, details.PlanID, acceptsIncomplete, stackDetails)
 }
 
 func (b *CloudFormationBroker) Deprovision(instanceID string, details brokerapi.DeprovisionDetails, acceptsIncomplete bool) (bool, error) {
@@ -148,18 +273,36 @@ func (b *CloudFormationBroker) Deprovision(instanceID string, details brokerapi.
 		acceptsIncompleteLogKey: acceptsIncomplete,
 	})
 
-	if !acceptsIncomplete {
+	if !b.acceptsOperation(details.PlanID, acceptsIncomplete) {
 		return true, brokerapi.ErrAsyncRequired
 	}
 
-	if err := b.stack.Delete(b.stackName(instanceID)); err != nil {
-		if err == awscf.ErrStackDoesNotExist {
-			return true, brokerapi.ErrInstanceDoesNotExist
-		}
+	defer b.locks.Lock(instanceID)()
+
+	b.stacks.SetPlan(instanceID, details.ServiceID, details.PlanID)
+
+	stackDetails, err := b.ownedStack(instanceID, details.ServiceID)
+	if err != nil {
+		return true, err
+	}
+
+	if err := b.checkConcurrentOperation(instanceID, stackDetails); err != nil {
 		return true, err
 	}
 
-	return true, nil
+	b.operations.Clear(instanceID)
+
+	servicePlan, ok := b.catalog.FindServicePlan(details.PlanID)
+	// A rolled back stack has no resources left to clean up
+	if ok && len(servicePlan.CloudFormationProperties.PreDeleteHooks) > 0 && stackDetails.Status.Raw != cloudformation.StackStatusRollbackComplete {
+		b.operations.Set(instanceID, brokerapi.LastOperationInProgress, fmt.Sprintf("Running pre-delete hooks for stack '%s'", b.stackName(instanceID)))
+		// The hooks outlive the request, so they must not use its stack
+		go b.WithStack(b.baseStack).deleteStackWithHooks(instanceID, stackDetails, servicePlan.CloudFormationProperties.PreDeleteHooks)
+	} else if err := b.deleteStack(instanceID); err != nil {
+		return true, err
+	}
+
+	return b.completeTokenedOperation(instanceID, operationDeprovision, details.PlanID, acceptsIncomplete, stackDetails)
 }
 
 func (b *CloudFormationBroker) Bind(instanceID, bindingID string, details brokerapi.BindDetails) (brokerapi.BindingResponse, error) {
@@ -180,11 +323,16 @@ func (b *CloudFormationBroker) Bind(instanceID, bindingID string, details broker
 		return bindingResponse, brokerapi.ErrInstanceNotBindable
 	}
 
-	stackDetails, err := b.stack.Describe(b.stackName(instanceID))
+	defer b.locks.Lock(instanceID)()
+
+	b.stacks.SetPlan(instanceID, details.ServiceID, details.PlanID)
+
+	stackDetails, err := b.describeInstanceStack(instanceID)
 	if err != nil {
-		if err == awscf.ErrStackDoesNotExist {
-			return bindingResponse, brokerapi.ErrInstanceDoesNotExist
-		}
+		return bindingResponse, err
+	}
+
+	if err := b.checkConcurrentOperation(instanceID, stackDetails); err != nil {
 		return bindingResponse, err
 	}
 
@@ -212,9 +360,27 @@ func (b *CloudFormationBroker) LastOperation(instanceID string) (brokerapi.LastO
 		instanceIDLogKey: instanceID,
 	})
 
+	if lastOperationResponse, superseded := b.supersededOperation(instanceID); superseded {
+		return lastOperationResponse, nil
+	}
+
+	if err := b.trackRequestedStack(instanceID); err != nil {
+		return brokerapi.LastOperationResponse{}, err
+	}
+
+	operation, tracked := b.operations.Get(instanceID)
+	if tracked {
+		if operation.PendingStack != nil {
+			return b.createPendingStack(instanceID, operation)
+		}
+		if !operation.watchesStack() {
+			return operation.LastOperationResponse, nil
+		}
+	}
+
 	lastOperationResponse := brokerapi.LastOperationResponse{State: brokerapi.LastOperationFailed}
 
-	stackDetails, err := b.stack.Describe(b.stackName(instanceID))
+	stackDetails, err := b.describeNotifiedStack(instanceID)
 	if err != nil {
 		if err == awscf.ErrStackDoesNotExist {
 			return lastOperationResponse, brokerapi.ErrInstanceDoesNotExist
@@ -222,22 +388,96 @@ func (b *CloudFormationBroker) LastOperation(instanceID string) (brokerapi.LastO
 		return lastOperationResponse, err
 	}
 
+	if !tracked && b.interruptedDeprovision(instanceID, stackDetails) {
+		lastOperationResponse.Description = fmt.Sprintf("Deprovisioning was interrupted before stack '%s' was deleted, deprovision the service instance again", b.stackName(instanceID))
+		return lastOperationResponse, nil
+	}
+
+	if !tracked && b.supersededProvision(instanceID, stackDetails) {
+		lastOperationResponse.State = brokerapi.LastOperationSucceeded
+		lastOperationResponse.Description = fmt.Sprintf("Stack '%s' was created, it is being updated since", b.stackName(instanceID))
+		return lastOperationResponse, nil
+	}
+
+	// Only a stack described by ID is found once deleted
+	if stackDetails.Status.Raw == cloudformation.StackStatusDeleteComplete {
+		b.operations.Clear(instanceID)
+		b.templateResources.Forget(b.stackName(instanceID))
+		b.stacks.Forget(instanceID)
+		b.issuedOperations.Forget(instanceID)
+		lastOperationResponse.State = brokerapi.LastOperationSucceeded
+		lastOperationResponse.Description = fmt.Sprintf("Stack '%s' was deleted", b.stackName(instanceID))
+		return lastOperationResponse, nil
+	}
+
+	if tracked && operation.UpdateTimeout > 0 {
+		operation = b.timeOutUpdate(instanceID, operation, stackDetails)
+	}
+
+	if tracked && operation.CancelReason != "" {
+		return b.cancelledUpdateResponse(instanceID, operation, stackDetails), nil
+	}
+
 	lastOperationResponse.Description = fmt.Sprintf("Stack '%s' status is '%s'", b.stackName(instanceID), stackDetails.StackStatus)
 
+	if tracked && stackDetails.StackStatus != awscf.StatusInProgress {
+		b.operations.Clear(instanceID)
+		if operation.ExpectedStackStatus != "" && stackDetails.Status.Raw == operation.ExpectedStackStatus {
+			lastOperationResponse.State = brokerapi.LastOperationSucceeded
+			lastOperationResponse.Description = fmt.Sprintf("Stack '%s' status is '%s'", b.stackName(instanceID), stackDetails.Status.Raw)
+			return lastOperationResponse, nil
+		}
+	}
+
 	switch stackDetails.StackStatus {
 	case awscf.StatusSucceeded:
 		lastOperationResponse.State = brokerapi.LastOperationSucceeded
 	case awscf.StatusInProgress:
 		lastOperationResponse.State = brokerapi.LastOperationInProgress
+		if progress := b.stackProgress(b.stackName(instanceID), stackDetails); progress != "" {
+			lastOperationResponse.Description = fmt.Sprintf("%s: %s", lastOperationResponse.Description, progress)
+		}
 	default:
 		lastOperationResponse.State = brokerapi.LastOperationFailed
+		if stackDetails.Status.Raw != "" {
+			lastOperationResponse.Description = b.failedStackDescription(instanceID, stackDetails.Status)
+		}
+		if b.driftReporter != nil {
+			if driftDescription := b.driftReporter.DriftDescription(instanceID); driftDescription != "" {
+				lastOperationResponse.Description = fmt.Sprintf("%s. %s", lastOperationResponse.Description, driftDescription)
+			}
+		}
 	}
 
 	return lastOperationResponse, nil
 }
 
-func (b *CloudFormationBroker) stackName(instanceID string) string {
-	return fmt.Sprintf("%s-%s", b.cloudformationPrefix, instanceID)
+// failedStackDescription tells a failed operation that left the instance
+// usable, such as a rolled back update, apart from one that broke it.
+func (b *CloudFormationBroker) failedStackDescription(instanceID string, status awscf.Status) string {
+	var description string
+	if status.Usable() {
+		description = fmt.Sprintf("The %s of stack '%s' failed and was rolled back, the service instance is still usable with its previous configuration", status.Operation, b.stackName(instanceID))
+	} else {
+		description = fmt.Sprintf("Stack '%s' status is '%s', the service instance may not be usable", b.stackName(instanceID), status.Raw)
+	}
+
+	if status.Reason != "" {
+		description = fmt.Sprintf("%s: %s", description, status.Reason)
+	}
+
+	return description
+}
+
+func (b *CloudFormationBroker) deleteStack(instanceID string) error {
+	if err := b.stack.Delete(b.stackName(instanceID)); err != nil {
+		if err == awscf.ErrStackDoesNotExist {
+			return brokerapi.ErrInstanceDoesNotExist
+		}
+		return err
+	}
+
+	return nil
 }
 
 func (b *CloudFormationBroker) createStackDetails(instanceID string, servicePlan ServicePlan, provisionParameters ProvisionParameters, details brokerapi.ProvisionDetails) *awscf.StackDetails {
@@ -251,7 +491,7 @@ func (b *CloudFormationBroker) createStackDetails(instanceID string, servicePlan
 		stackDetails.Parameters[key] = value
 	}
 
-	stackDetails.Tags = b.stackTags("Created", details.ServiceID, details.PlanID, details.OrganizationGUID, details.SpaceGUID)
+	stackDetails.Tags = b.stackTags("Created", instanceID, details.ServiceID, details.PlanID, details.OrganizationGUID, details.SpaceGUID)
 
 	return stackDetails
 }
@@ -274,7 +514,7 @@ func (b *CloudFormationBroker) stackDetailsFromPlan(servicePlan ServicePlan) *aw
 	stackDetails := &awscf.StackDetails{
 		Capabilities:     servicePlan.CloudFormationProperties.Capabilities,
 		DisableRollback:  servicePlan.CloudFormationProperties.DisableRollback,
-		NotificationARNs: servicePlan.CloudFormationProperties.NotificationARNs,
+		NotificationARNs: b.notificationARNs(servicePlan.CloudFormationProperties.NotificationARNs),
 		OnFailure:        servicePlan.CloudFormationProperties.OnFailure,
 		Parameters:       servicePlan.CloudFormationProperties.Parameters,
 		ResourceTypes:    servicePlan.CloudFormationProperties.ResourceTypes,
@@ -286,21 +526,27 @@ func (b *CloudFormationBroker) stackDetailsFromPlan(servicePlan ServicePlan) *aw
 	return stackDetails
 }
 
-func (b *CloudFormationBroker) stackTags(action, serviceID, planID, organizationID, spaceID string) map[string]string {
+func (b *CloudFormationBroker) stackTags(action, instanceID, serviceID, planID, organizationID, spaceID string) map[string]string {
 	tags := make(map[string]string)
 
 	tags["Owner"] = "Cloud Foundry"
 
-	tags[action+" by"] = "AWS CloudFormation Service Broker"
+	tags[action+" by"] = brokerTagValue
+
+	if b.brokerID != "" {
+		tags[brokerIDTagKey] = b.brokerID
+	}
 
 	tags[action+" at"] = time.Now().Format(time.RFC822Z)
 
+	tags[instanceIDTagKey] = instanceID
+
 	if serviceID != "" {
-		tags["Service ID"] = serviceID
+		tags[serviceIDTagKey] = serviceID
 	}
 
 	if planID != "" {
-		tags["Plan ID"] = planID
+		tags[planIDTagKey] = planID
 	}
 
 	if organizationID != "" {
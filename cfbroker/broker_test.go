@@ -1,7 +1,14 @@
 package cfbroker_test
 
 import (
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -14,8 +21,47 @@ import (
 
 	"github.com/cf-platform-eng/cloudformation-broker/awscf"
 	cffake "github.com/cf-platform-eng/cloudformation-broker/awscf/fakes"
+	ecrfake "github.com/cf-platform-eng/cloudformation-broker/awsecr/fakes"
+	s3fake "github.com/cf-platform-eng/cloudformation-broker/awss3/fakes"
 )
 
+type driftDescriptions map[string]string
+
+func (d driftDescriptions) DriftDescription(instanceID string) string {
+	return d[instanceID]
+}
+
+// countingStack counts the stacks created through it, taking long enough to
+// create one for concurrent requests to overlap.
+type countingStack struct {
+	*cffake.FakeStack
+	creates int32
+}
+
+func (s *countingStack) Create(stackName string, stackDetails awscf.StackDetails) (string, error) {
+	atomic.AddInt32(&s.creates, 1)
+	time.Sleep(10 * time.Millisecond)
+	return s.FakeStack.Create(stackName, stackDetails)
+}
+
+type notifiedStack struct {
+	status    awscf.Status
+	startedAt time.Time
+	resources []awscf.StackResource
+}
+
+type stackStatuses map[string]notifiedStack
+
+func (s stackStatuses) StackStatus(stackID string) (awscf.Status, time.Time, bool) {
+	notified, ok := s[stackID]
+	return notified.status, notified.startedAt, ok
+}
+
+func (s stackStatuses) StackResources(stackID string) ([]awscf.StackResource, bool) {
+	notified, ok := s[stackID]
+	return notified.resources, ok && len(notified.resources) > 0
+}
+
 var _ = Describe("CloudFormation Broker", func() {
 	var (
 		cfProperties1 CloudFormationProperties
@@ -28,13 +74,17 @@ var _ = Describe("CloudFormation Broker", func() {
 
 		config Config
 
-		stack *cffake.FakeStack
+		stack      *cffake.FakeStack
+		bucket     *s3fake.FakeBucket
+		repository *ecrfake.FakeRepository
 
 		testSink *lagertest.TestSink
 		logger   lager.Logger
 
 		cfBroker *CloudFormationBroker
 
+		brokerID                     string
+		stackNameTemplate            string
 		allowUserProvisionParameters bool
 		allowUserUpdateParameters    bool
 		serviceBindable              bool
@@ -46,12 +96,16 @@ var _ = Describe("CloudFormation Broker", func() {
 	)
 
 	BeforeEach(func() {
+		brokerID = ""
+		stackNameTemplate = ""
 		allowUserProvisionParameters = true
 		allowUserUpdateParameters = true
 		serviceBindable = true
 		planUpdateable = true
 
 		stack = &cffake.FakeStack{}
+		bucket = &s3fake.FakeBucket{}
+		repository = &ecrfake.FakeRepository{}
 
 		cfProperties1 = CloudFormationProperties{}
 		cfProperties2 = CloudFormationProperties{}
@@ -95,6 +149,8 @@ var _ = Describe("CloudFormation Broker", func() {
 		config = Config{
 			Region:                       "scloudformation-region",
 			CloudFormationPrefix:         "cf",
+			BrokerID:                     brokerID,
+			StackNameTemplate:            stackNameTemplate,
 			AllowUserProvisionParameters: allowUserProvisionParameters,
 			AllowUserUpdateParameters:    allowUserUpdateParameters,
 			Catalog:                      catalog,
@@ -104,7 +160,7 @@ var _ = Describe("CloudFormation Broker", func() {
 		testSink = lagertest.NewTestSink()
 		logger.RegisterSink(testSink)
 
-		cfBroker = New(config, stack, logger)
+		cfBroker = New(config, stack, bucket, repository, logger)
 	})
 
 	var _ = Describe("Services", func() {
@@ -201,10 +257,12 @@ var _ = Describe("CloudFormation Broker", func() {
 			Expect(stack.CreateStackDetails.Tags["Owner"]).To(Equal("Cloud Foundry"))
 			Expect(stack.CreateStackDetails.Tags["Created by"]).To(Equal("AWS CloudFormation Service Broker"))
 			Expect(stack.CreateStackDetails.Tags).To(HaveKey("Created at"))
+			Expect(stack.CreateStackDetails.Tags["Instance ID"]).To(Equal(instanceID))
 			Expect(stack.CreateStackDetails.Tags["Service ID"]).To(Equal("Service-1"))
 			Expect(stack.CreateStackDetails.Tags["Plan ID"]).To(Equal("Plan-1"))
 			Expect(stack.CreateStackDetails.Tags["Organization ID"]).To(Equal("organization-id"))
 			Expect(stack.CreateStackDetails.Tags["Space ID"]).To(Equal("space-id"))
+			Expect(stack.CreateStackDetails.Tags).ToNot(HaveKey("Broker ID"))
 			Expect(stack.CreateStackDetails.TemplateURL).To(Equal(""))
 			Expect(stack.CreateStackDetails.TimeoutInMinutes).To(Equal(int64(0)))
 			Expect(err).ToNot(HaveOccurred())
@@ -244,6 +302,19 @@ var _ = Describe("CloudFormation Broker", func() {
 				Expect(stack.CreateStackDetails.NotificationARNs).To(Equal([]string{"test-notification-arns"}))
 				Expect(err).ToNot(HaveOccurred())
 			})
+
+			Context("when the broker receives stack notifications", func() {
+				JustBeforeEach(func() {
+					cfBroker.ReceiveStackNotifications("test-topic-arn", stackStatuses{})
+				})
+
+				It("adds the notification topic", func() {
+					_, _, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(stack.CreateStackDetails.NotificationARNs).To(Equal([]string{"test-notification-arns", "test-topic-arn"}))
+					Expect(cfProperties1.NotificationARNs).To(Equal([]string{"test-notification-arns"}))
+				})
+			})
 		})
 
 		Context("when has OnFailure", func() {
@@ -306,6 +377,58 @@ var _ = Describe("CloudFormation Broker", func() {
 			})
 		})
 
+		It("tracks the created Stack by its ID", func() {
+			stack.CreateStackID = "stack-id"
+			_, _, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = cfBroker.LastOperation(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stack.DescribeStackName).To(Equal("stack-id"))
+		})
+
+		Context("when the broker has an ID", func() {
+			BeforeEach(func() {
+				brokerID = "broker-id"
+			})
+
+			It("tags the Stack with the broker ID", func() {
+				_, _, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stack.CreateStackDetails.Tags["Broker ID"]).To(Equal("broker-id"))
+			})
+		})
+
+		Context("when the broker has a stack name template", func() {
+			BeforeEach(func() {
+				stackNameTemplate = "{{prefix}}-{{service}}-{{instance_id}}"
+			})
+
+			It("names the Stack after the template, sanitized and hashed", func() {
+				_, _, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stack.CreateStackName).To(Equal("cf-Service-1-instance-id-d7cd149e"))
+			})
+		})
+
+		Context("when the instance ID is not a valid stack name", func() {
+			It("hashes the Stack name", func() {
+				_, _, err := cfBroker.Provision("Instance_ID", provisionDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stack.CreateStackName).To(Equal("cf-Instance-ID-a5c407de"))
+				Expect(stack.CreateStackDetails.Tags["Instance ID"]).To(Equal("Instance_ID"))
+			})
+		})
+
+		Context("when the Stack name is too long", func() {
+			It("truncates and hashes the Stack name", func() {
+				_, _, err := cfBroker.Provision(strings.Repeat("a", 130), provisionDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stack.CreateStackName).To(HaveLen(128))
+				Expect(stack.CreateStackName).To(Equal("cf-" + strings.Repeat("a", 116) + "-a7eaf7fa"))
+			})
+		})
+
 		Context("when has TimeoutInMinutes", func() {
 			BeforeEach(func() {
 				cfProperties1.TimeoutInMinutes = int64(1)
@@ -377,6 +500,119 @@ var _ = Describe("CloudFormation Broker", func() {
 			})
 		})
 
+		Context("when the plan has a synchronous timeout", func() {
+			BeforeEach(func() {
+				cfProperties1.SynchronousTimeoutInSeconds = 1
+				stack.CreateStackID = "stack-id"
+				stack.DescribeStackDetailsByName = map[string]awscf.StackDetails{
+					"stack-id": awscf.StackDetails{
+						StackName:   stackName,
+						StackID:     "stack-id",
+						StackStatus: awscf.StatusSucceeded,
+						Status:      awscf.NewStatus("CREATE_COMPLETE", ""),
+					},
+				}
+			})
+
+			It("waits for the stack to be created", func() {
+				_, asynch, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(asynch).To(BeFalse())
+				Expect(stack.CreateCalled).To(BeTrue())
+				Expect(stack.DescribeStackName).To(Equal("stack-id"))
+			})
+
+			It("does not issue an operation token", func() {
+				operationTokens := &OperationTokens{}
+				_, _, err := cfBroker.WithOperationTokens(operationTokens).Provision(instanceID, provisionDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(operationTokens.Issued).To(BeEmpty())
+			})
+
+			Context("and the request does not accept incomplete", func() {
+				BeforeEach(func() {
+					acceptsIncomplete = false
+				})
+
+				It("provisions the instance synchronously", func() {
+					_, asynch, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(asynch).To(BeFalse())
+				})
+
+				Context("and the stack is still in progress at the timeout", func() {
+					BeforeEach(func() {
+						stack.DescribeStackDetailsByName["stack-id"] = awscf.StackDetails{
+							StackName:   stackName,
+							StackID:     "stack-id",
+							StackStatus: awscf.StatusInProgress,
+							Status:      awscf.NewStatus("CREATE_IN_PROGRESS", ""),
+						}
+					})
+
+					It("returns the proper error", func() {
+						_, asynch, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+						Expect(asynch).To(BeFalse())
+						Expect(err).To(Equal(SynchronousTimeoutError{StackName: stackName, Operation: "provision", Timeout: time.Second}))
+						Expect(err.Error()).To(Equal("The provision of stack 'cf-instance-id' did not complete within 1s and is still in progress, retry once it completes"))
+					})
+
+					It("does not hold the instance lock while waiting", func() {
+						provisioned := make(chan struct{})
+						go func() {
+							defer GinkgoRecover()
+							defer close(provisioned)
+							cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+						}()
+
+						Eventually(func() bool {
+							_, ok := cfBroker.TrackedStackID(instanceID)
+							return ok
+						}).Should(BeTrue())
+						Expect(cfBroker.CancelUpdate(instanceID)).To(HaveOccurred())
+						Expect(provisioned).ToNot(BeClosed())
+						Eventually(provisioned, 5*time.Second).Should(BeClosed())
+					})
+				})
+			})
+
+			Context("and the stack is still in progress at the timeout", func() {
+				BeforeEach(func() {
+					stack.DescribeStackDetailsByName["stack-id"] = awscf.StackDetails{
+						StackName:   stackName,
+						StackID:     "stack-id",
+						StackStatus: awscf.StatusInProgress,
+						Status:      awscf.NewStatus("CREATE_IN_PROGRESS", ""),
+					}
+				})
+
+				It("falls back to provisioning the instance asynchronously", func() {
+					_, asynch, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(asynch).To(BeTrue())
+				})
+			})
+
+			Context("and the stack creation fails", func() {
+				BeforeEach(func() {
+					stack.DescribeStackDetailsByName["stack-id"] = awscf.StackDetails{
+						StackName:   stackName,
+						StackID:     "stack-id",
+						StackStatus: awscf.StatusFailed,
+						Status:      awscf.NewStatus("CREATE_FAILED", "Resource creation cancelled"),
+					}
+				})
+
+				It("returns the proper error", func() {
+					_, asynch, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(asynch).To(BeFalse())
+					Expect(err).To(MatchError("Stack 'cf-instance-id' status is 'CREATE_FAILED', the service instance may not be usable: Resource creation cancelled"))
+					Expect(err).To(BeAssignableToTypeOf(SynchronousOperationError{}))
+					Expect(err.(SynchronousOperationError).Operation).To(Equal("provision"))
+				})
+			})
+		})
+
 		Context("when Service Plan is not found", func() {
 			BeforeEach(func() {
 				provisionDetails.PlanID = "unknown"
@@ -389,6 +625,196 @@ var _ = Describe("CloudFormation Broker", func() {
 			})
 		})
 
+		Context("when a rolled back Stack with the same name exists", func() {
+			BeforeEach(func() {
+				stack.DescribeStackDetails = awscf.StackDetails{
+					StackName:   stackName,
+					StackStatus: awscf.StatusFailed,
+					Status:      awscf.NewStatus("ROLLBACK_COMPLETE", ""),
+					Tags:        map[string]string{"Created by": "AWS CloudFormation Service Broker"},
+				}
+			})
+
+			It("returns the proper response", func() {
+				provisioningResponse, asynch, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+				Expect(provisioningResponse).To(Equal(properProvisioningResponse))
+				Expect(asynch).To(BeTrue())
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("deletes the rolled back Stack instead of creating it", func() {
+				_, _, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stack.DeleteCalled).To(BeTrue())
+				Expect(stack.DeleteStackName).To(Equal(stackName))
+				Expect(stack.CreateCalled).To(BeFalse())
+			})
+
+			It("creates the Stack once the rolled back Stack is gone", func() {
+				_, _, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+
+				stack.DescribeStackDetails.Status.Raw = "DELETE_IN_PROGRESS"
+				lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationInProgress))
+				Expect(lastOperationResponse.Description).To(Equal("Deleting rolled back stack '" + stackName + "' before creating it again"))
+				Expect(stack.CreateCalled).To(BeFalse())
+
+				stack.DescribeError = awscf.ErrStackDoesNotExist
+				lastOperationResponse, err = cfBroker.LastOperation(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationInProgress))
+				Expect(stack.CreateCalled).To(BeTrue())
+				Expect(stack.CreateStackName).To(Equal(stackName))
+				Expect(stack.CreateStackDetails.Tags["Service ID"]).To(Equal("Service-1"))
+
+				stack.DescribeError = nil
+				stack.DescribeStackDetails = awscf.StackDetails{StackName: stackName, StackStatus: awscf.StatusInProgress}
+				lastOperationResponse, err = cfBroker.LastOperation(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse.Description).To(Equal("Stack '" + stackName + "' status is '" + awscf.StatusInProgress + "'"))
+			})
+
+			It("creates the Stack only once when the last operation is polled concurrently", func() {
+				_, _, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+
+				stack.DescribeError = awscf.ErrStackDoesNotExist
+				requestStack := &countingStack{FakeStack: stack}
+
+				var wg sync.WaitGroup
+				for i := 0; i < 5; i++ {
+					wg.Add(1)
+					go func() {
+						defer GinkgoRecover()
+						defer wg.Done()
+						lastOperationResponse, err := cfBroker.WithStack(requestStack).LastOperation(instanceID)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationInProgress))
+					}()
+				}
+				wg.Wait()
+
+				Expect(atomic.LoadInt32(&requestStack.creates)).To(Equal(int32(1)))
+			})
+
+			Context("and the rolled back Stack cannot be deleted", func() {
+				It("reports the failure in the last operation", func() {
+					_, _, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+
+					stack.DescribeStackDetails.Status.Raw = "DELETE_FAILED"
+					stack.DescribeStackDetails.Status.Reason = "resource in use"
+					lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationFailed))
+					Expect(lastOperationResponse.Description).To(Equal("Deleting rolled back stack '" + stackName + "' failed: resource in use"))
+					Expect(stack.CreateCalled).To(BeFalse())
+				})
+			})
+
+			Context("and creating the Stack fails", func() {
+				BeforeEach(func() {
+					stack.CreateError = errors.New("operation failed")
+				})
+
+				It("reports the failure in the last operation", func() {
+					_, _, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+
+					stack.DescribeError = awscf.ErrStackDoesNotExist
+					lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationFailed))
+					Expect(lastOperationResponse.Description).To(Equal("Creating stack '" + stackName + "' failed: operation failed"))
+				})
+			})
+
+			Context("and the rolled back Stack was not created by the broker", func() {
+				BeforeEach(func() {
+					stack.DescribeStackDetails.Tags = map[string]string{}
+				})
+
+				It("does not delete the Stack", func() {
+					_, _, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(stack.DeleteCalled).To(BeFalse())
+					Expect(stack.CreateCalled).To(BeTrue())
+				})
+			})
+
+			Context("and the rolled back Stack was named before the stack name template", func() {
+				BeforeEach(func() {
+					stackNameTemplate = "{{prefix}}-{{service}}-{{instance_id}}"
+					stack.DescribeErrorByName = map[string]error{
+						"cf-Service-1-instance-id-d7cd149e": awscf.ErrStackDoesNotExist,
+					}
+				})
+
+				It("deletes the rolled back Stack by its existing name", func() {
+					_, _, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(stack.DescribeStackName).To(Equal(stackName))
+					Expect(stack.DeleteStackName).To(Equal(stackName))
+					Expect(stack.CreateCalled).To(BeFalse())
+				})
+
+				It("does not look the stacks of the account up", func() {
+					_, _, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(stack.DescribeAllCalled).To(BeFalse())
+				})
+			})
+
+			Context("and the rolled back Stack was named with another template", func() {
+				BeforeEach(func() {
+					stack.DescribeErrorByName = map[string]error{
+						stackName: awscf.ErrStackDoesNotExist,
+					}
+					stack.DescribeAllStackDetails = []awscf.StackDetails{
+						awscf.StackDetails{
+							StackName: "cf-renamed-stack",
+							Tags:      map[string]string{"Created by": "AWS CloudFormation Service Broker", "Instance ID": instanceID},
+						},
+					}
+				})
+
+				It("creates the Stack without looking the stacks of the account up", func() {
+					_, _, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(stack.DescribeAllCalled).To(BeFalse())
+					Expect(stack.DeleteCalled).To(BeFalse())
+					Expect(stack.CreateStackName).To(Equal(stackName))
+				})
+			})
+
+			Context("and deleting the rolled back Stack fails", func() {
+				BeforeEach(func() {
+					stack.DeleteError = errors.New("operation failed")
+				})
+
+				It("returns the proper error", func() {
+					_, _, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("operation failed"))
+				})
+			})
+		})
+
+		Context("when describing the existing Stack fails", func() {
+			BeforeEach(func() {
+				stack.DescribeError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, _, err := cfBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+				Expect(stack.CreateCalled).To(BeFalse())
+			})
+		})
+
 		Context("when creating the Stack fails", func() {
 			BeforeEach(func() {
 				stack.CreateError = errors.New("operation failed")
@@ -421,6 +847,12 @@ var _ = Describe("CloudFormation Broker", func() {
 				},
 			}
 			acceptsIncomplete = true
+
+			stack.DescribeStackDetails = awscf.StackDetails{
+				StackName: stackName,
+				Status:    awscf.NewStatus("CREATE_COMPLETE", ""),
+				Tags:      map[string]string{"Created by": "AWS CloudFormation Service Broker", "Service ID": "Service-2"},
+			}
 		})
 
 		It("returns the proper response", func() {
@@ -450,6 +882,19 @@ var _ = Describe("CloudFormation Broker", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		Context("when the Stack is named after its plan", func() {
+			BeforeEach(func() {
+				stackNameTemplate = "{{prefix}}-{{plan}}-{{instance_id}}"
+			})
+
+			It("modifies the Stack named after the previous plan", func() {
+				_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stack.ModifyStackName).To(Equal("cf-Plan-1-instance-id-ea8e24b1"))
+				Expect(stack.DescribeAllCalled).To(BeFalse())
+			})
+		})
+
 		Context("when has Capabilities", func() {
 			BeforeEach(func() {
 				cfProperties2.Capabilities = []string{"test-capabilities"}
@@ -484,6 +929,18 @@ var _ = Describe("CloudFormation Broker", func() {
 				Expect(stack.ModifyStackDetails.NotificationARNs).To(Equal([]string{"test-notification-arns"}))
 				Expect(err).ToNot(HaveOccurred())
 			})
+
+			Context("when the broker receives stack notifications", func() {
+				JustBeforeEach(func() {
+					cfBroker.ReceiveStackNotifications("test-topic-arn", stackStatuses{})
+				})
+
+				It("adds the notification topic", func() {
+					_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(stack.ModifyStackDetails.NotificationARNs).To(Equal([]string{"test-notification-arns", "test-topic-arn"}))
+				})
+			})
 		})
 
 		Context("when has OnFailure", func() {
@@ -570,6 +1027,36 @@ var _ = Describe("CloudFormation Broker", func() {
 			})
 		})
 
+		Context("when the Stack has an operation in progress", func() {
+			BeforeEach(func() {
+				stack.DescribeStackDetails.Status = awscf.NewStatus("CREATE_IN_PROGRESS", "")
+			})
+
+			It("returns the proper error", func() {
+				_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).To(Equal(ConcurrencyError{StackName: stackName, Operation: "status is 'CREATE_IN_PROGRESS'"}))
+				Expect(err.Error()).To(Equal("Stack 'cf-instance-id' has an operation in progress (status is 'CREATE_IN_PROGRESS'), retry once it completes"))
+				Expect(stack.ModifyCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the plan has a synchronous timeout", func() {
+			BeforeEach(func() {
+				cfProperties2.SynchronousTimeoutInSeconds = 1
+				acceptsIncomplete = false
+
+				stack.DescribeStackDetails.StackStatus = awscf.StatusSucceeded
+				stack.DescribeStackDetails.Status = awscf.NewStatus("UPDATE_COMPLETE", "")
+			})
+
+			It("updates the instance synchronously", func() {
+				asynch, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(asynch).To(BeFalse())
+				Expect(stack.ModifyCalled).To(BeTrue())
+			})
+		})
+
 		Context("when has user provision parameters", func() {
 			BeforeEach(func() {
 				updateDetails.Parameters = map[string]interface{}{"test-key-1": "test-value-1", "test-key-2": "test-value-2"}
@@ -653,44 +1140,356 @@ var _ = Describe("CloudFormation Broker", func() {
 			})
 		})
 
-		Context("when modifying the Stack fails", func() {
+		Context("when continuing an update rollback", func() {
 			BeforeEach(func() {
-				stack.ModifyError = errors.New("operation failed")
+				updateDetails.Parameters = map[string]interface{}{
+					"continue_update_rollback": true,
+					"resources_to_skip":        []interface{}{"test-resource-1", "test-resource-2"},
+				}
 			})
 
-			It("returns the proper error", func() {
+			It("returns the proper response", func() {
+				asynch, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(asynch).To(BeTrue())
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("makes the proper calls", func() {
 				_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(Equal("operation failed"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stack.ModifyCalled).To(BeFalse())
+				Expect(stack.ContinueUpdateRollbackCalled).To(BeTrue())
+				Expect(stack.ContinueUpdateRollbackStackName).To(Equal(stackName))
+				Expect(stack.ContinueUpdateRollbackResourcesToSkip).To(Equal([]string{"test-resource-1", "test-resource-2"}))
 			})
 
-			Context("when the Stack does not exists", func() {
+			It("reports the rolled back Stack as succeeded", func() {
+				_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+
+				stack.DescribeStackDetails = awscf.StackDetails{
+					StackStatus: awscf.StatusInProgress,
+					Status:      awscf.NewStatus("UPDATE_ROLLBACK_IN_PROGRESS", ""),
+				}
+				lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationInProgress))
+
+				stack.DescribeStackDetails = awscf.StackDetails{
+					StackStatus: awscf.StatusFailed,
+					Status:      awscf.NewStatus("UPDATE_ROLLBACK_COMPLETE", ""),
+				}
+				lastOperationResponse, err = cfBroker.LastOperation(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationSucceeded))
+				Expect(lastOperationResponse.Description).To(Equal("Stack '" + stackName + "' status is 'UPDATE_ROLLBACK_COMPLETE'"))
+			})
+
+			Context("and the Plan is not updateable", func() {
 				BeforeEach(func() {
-					stack.ModifyError = awscf.ErrStackDoesNotExist
+					planUpdateable = false
 				})
 
 				It("returns the proper error", func() {
 					_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-					Expect(err).To(HaveOccurred())
-					Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+					Expect(err).To(Equal(brokerapi.ErrInstanceNotUpdateable))
+					Expect(stack.ContinueUpdateRollbackCalled).To(BeFalse())
 				})
 			})
-		})
-	})
 
-	var _ = Describe("Deprovision", func() {
-		var (
-			deprovisionDetails brokerapi.DeprovisionDetails
-			acceptsIncomplete  bool
-		)
-
-		BeforeEach(func() {
-			deprovisionDetails = brokerapi.DeprovisionDetails{
-				ServiceID: "Service-1",
-				PlanID:    "Plan-1",
-			}
-			acceptsIncomplete = true
-		})
+			Context("and user update parameters are not allowed", func() {
+				BeforeEach(func() {
+					allowUserUpdateParameters = false
+				})
+
+				It("returns the proper error", func() {
+					_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(err).To(MatchError("Parameter 'continue_update_rollback' is not allowed"))
+					Expect(stack.ContinueUpdateRollbackCalled).To(BeFalse())
+				})
+			})
+
+			Context("and the Service Plan is not found", func() {
+				BeforeEach(func() {
+					updateDetails.PlanID = "unknown"
+				})
+
+				It("returns the proper error", func() {
+					_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(err).To(MatchError("Service Plan 'unknown' not found"))
+					Expect(stack.ContinueUpdateRollbackCalled).To(BeFalse())
+				})
+			})
+
+			Context("and it is not true", func() {
+				BeforeEach(func() {
+					updateDetails.Parameters = map[string]interface{}{"continue_update_rollback": false}
+				})
+
+				It("returns the proper error", func() {
+					_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("Parameter 'continue_update_rollback' must be true"))
+					Expect(stack.ContinueUpdateRollbackCalled).To(BeFalse())
+				})
+			})
+
+			Context("and other parameters are sent", func() {
+				BeforeEach(func() {
+					updateDetails.Parameters["test-key-1"] = "test-value-1"
+				})
+
+				It("returns the proper error", func() {
+					_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("Parameter 'test-key-1' can not be used together with 'continue_update_rollback'"))
+					Expect(stack.ContinueUpdateRollbackCalled).To(BeFalse())
+				})
+			})
+
+			Context("and continuing the rollback fails", func() {
+				BeforeEach(func() {
+					stack.ContinueUpdateRollbackError = errors.New("operation failed")
+				})
+
+				It("returns the proper error", func() {
+					_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("operation failed"))
+				})
+			})
+
+			Context("and the Stack does not exists", func() {
+				BeforeEach(func() {
+					stack.ContinueUpdateRollbackError = awscf.ErrStackDoesNotExist
+				})
+
+				It("returns the proper error", func() {
+					_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+				})
+			})
+		})
+
+		Context("when the Plan has an update timeout", func() {
+			BeforeEach(func() {
+				cfProperties2.UpdateTimeoutInMinutes = 30
+			})
+
+			It("reports the update as in progress before the timeout", func() {
+				_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+
+				stack.DescribeStackDetails = awscf.StackDetails{
+					StackStatus:     awscf.StatusInProgress,
+					Status:          awscf.NewStatus("UPDATE_IN_PROGRESS", ""),
+					LastUpdatedTime: time.Now().Add(-10 * time.Minute),
+				}
+				lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationInProgress))
+				Expect(stack.CancelUpdateCalled).To(BeFalse())
+			})
+
+			It("cancels the update once the timeout has passed", func() {
+				_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+
+				stack.DescribeStackDetails = awscf.StackDetails{
+					StackStatus:     awscf.StatusInProgress,
+					Status:          awscf.NewStatus("UPDATE_IN_PROGRESS", ""),
+					LastUpdatedTime: time.Now().Add(-40 * time.Minute),
+				}
+				lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stack.CancelUpdateCalled).To(BeTrue())
+				Expect(stack.CancelUpdateStackName).To(Equal(stackName))
+				Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationInProgress))
+				Expect(lastOperationResponse.Description).To(Equal("Update of stack '" + stackName + "' was cancelled (timed out after 30 minutes), stack status is 'UPDATE_IN_PROGRESS'"))
+
+				stack.DescribeStackDetails = awscf.StackDetails{
+					StackStatus: awscf.StatusFailed,
+					Status:      awscf.NewStatus("UPDATE_ROLLBACK_COMPLETE", ""),
+				}
+				lastOperationResponse, err = cfBroker.LastOperation(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationFailed))
+				Expect(lastOperationResponse.Description).To(Equal("Update of stack '" + stackName + "' was cancelled (timed out after 30 minutes) and rolled back to its previous configuration"))
+			})
+
+			Context("and the broker receives stack notifications", func() {
+				var statuses stackStatuses
+
+				BeforeEach(func() {
+					statuses = stackStatuses{}
+					stack.DescribeStackDetails.StackID = "stack-id"
+				})
+
+				JustBeforeEach(func() {
+					cfBroker.ReceiveStackNotifications("test-topic-arn", statuses)
+				})
+
+				It("reports the update notified before the timeout as in progress", func() {
+					_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+
+					statuses["stack-id"] = notifiedStack{status: awscf.NewStatus("UPDATE_IN_PROGRESS", ""), startedAt: time.Now().Add(-10 * time.Minute)}
+					stack.DescribeCalled = false
+					lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationInProgress))
+					Expect(stack.DescribeCalled).To(BeFalse())
+					Expect(stack.CancelUpdateCalled).To(BeFalse())
+				})
+
+				It("cancels the update notified once the timeout has passed", func() {
+					_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+
+					statuses["stack-id"] = notifiedStack{status: awscf.NewStatus("UPDATE_IN_PROGRESS", ""), startedAt: time.Now().Add(-40 * time.Minute)}
+					_, err = cfBroker.LastOperation(instanceID)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(stack.CancelUpdateCalled).To(BeTrue())
+				})
+			})
+
+			It("reports the update as succeeded if it completes in time", func() {
+				_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+
+				stack.DescribeStackDetails = awscf.StackDetails{
+					StackStatus: awscf.StatusSucceeded,
+					Status:      awscf.NewStatus("UPDATE_COMPLETE", ""),
+				}
+				lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationSucceeded))
+				Expect(stack.CancelUpdateCalled).To(BeFalse())
+			})
+
+			Context("and cancelling the update fails", func() {
+				BeforeEach(func() {
+					stack.CancelUpdateError = errors.New("operation failed")
+				})
+
+				It("keeps reporting the stack status", func() {
+					_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+
+					stack.DescribeStackDetails = awscf.StackDetails{
+						StackStatus:     awscf.StatusInProgress,
+						Status:          awscf.NewStatus("UPDATE_IN_PROGRESS", ""),
+						LastUpdatedTime: time.Now().Add(-40 * time.Minute),
+					}
+					lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationInProgress))
+					Expect(lastOperationResponse.Description).To(Equal("Stack '" + stackName + "' status is 'in progress'"))
+				})
+			})
+		})
+
+		Context("when the Stack was created by another broker", func() {
+			BeforeEach(func() {
+				brokerID = "broker-id"
+				stack.DescribeStackDetails.Tags["Broker ID"] = "other-broker-id"
+			})
+
+			It("does not modify the Stack", func() {
+				_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).To(Equal(OwnershipError{StackName: stackName, Reason: "it was created by broker 'other-broker-id'"}))
+				Expect(stack.ModifyCalled).To(BeFalse())
+			})
+
+			It("does not continue an update rollback", func() {
+				updateDetails.Parameters = map[string]interface{}{"continue_update_rollback": true}
+				_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).To(BeAssignableToTypeOf(OwnershipError{}))
+				Expect(stack.ContinueUpdateRollbackCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the Stack carries no broker ID tag", func() {
+			BeforeEach(func() {
+				brokerID = "broker-id"
+			})
+
+			It("does not modify the Stack", func() {
+				_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).To(Equal(OwnershipError{StackName: stackName, Reason: "it carries no 'Broker ID' tag"}))
+				Expect(stack.ModifyCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the Stack belongs to another Service", func() {
+			BeforeEach(func() {
+				stack.DescribeStackDetails.Tags["Service ID"] = "Service-1"
+			})
+
+			It("does not modify the Stack", func() {
+				_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).To(Equal(OwnershipError{StackName: stackName, Reason: "it belongs to service 'Service-1', not 'Service-2'"}))
+				Expect(stack.ModifyCalled).To(BeFalse())
+			})
+		})
+
+		Context("when describing the Stack fails", func() {
+			BeforeEach(func() {
+				stack.DescribeError = awscf.ErrStackDoesNotExist
+			})
+
+			It("returns the proper error", func() {
+				_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+				Expect(stack.ModifyCalled).To(BeFalse())
+			})
+		})
+
+		Context("when modifying the Stack fails", func() {
+			BeforeEach(func() {
+				stack.ModifyError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			Context("when the Stack does not exists", func() {
+				BeforeEach(func() {
+					stack.ModifyError = awscf.ErrStackDoesNotExist
+				})
+
+				It("returns the proper error", func() {
+					_, err := cfBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+				})
+			})
+		})
+	})
+
+	var _ = Describe("Deprovision", func() {
+		var (
+			deprovisionDetails brokerapi.DeprovisionDetails
+			acceptsIncomplete  bool
+		)
+
+		BeforeEach(func() {
+			deprovisionDetails = brokerapi.DeprovisionDetails{
+				ServiceID: "Service-1",
+				PlanID:    "Plan-1",
+			}
+			acceptsIncomplete = true
+
+			stack.DescribeStackDetails = awscf.StackDetails{
+				StackName: stackName,
+				Status:    awscf.NewStatus("CREATE_COMPLETE", ""),
+				Tags:      map[string]string{"Created by": "AWS CloudFormation Service Broker", "Service ID": "Service-1"},
+			}
+		})
 
 		It("returns the proper response", func() {
 			asynch, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
@@ -705,229 +1504,1738 @@ var _ = Describe("CloudFormation Broker", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 
-		Context("when request does not accept incomplete", func() {
+		Context("when request does not accept incomplete", func() {
+			BeforeEach(func() {
+				acceptsIncomplete = false
+			})
+
+			It("returns the proper error", func() {
+				_, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+				Expect(err).To(HaveOccurred())
+				Expect(err).To(Equal(brokerapi.ErrAsyncRequired))
+			})
+		})
+
+		Context("when the Stack has an operation in progress", func() {
+			BeforeEach(func() {
+				stack.DescribeStackDetails.Status = awscf.NewStatus("UPDATE_IN_PROGRESS", "")
+			})
+
+			It("returns the proper error", func() {
+				_, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+				Expect(err).To(Equal(ConcurrencyError{StackName: stackName, Operation: "status is 'UPDATE_IN_PROGRESS'"}))
+				Expect(stack.DeleteCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the plan has a synchronous timeout", func() {
+			BeforeEach(func() {
+				cfProperties1.SynchronousTimeoutInSeconds = 1
+				acceptsIncomplete = false
+
+				stack.DescribeStackDetails.StackID = "stack-id"
+				stack.DescribeStackDetailsByName = map[string]awscf.StackDetails{
+					"stack-id": awscf.StackDetails{
+						StackName:   stackName,
+						StackID:     "stack-id",
+						StackStatus: awscf.StatusSucceeded,
+						Status:      awscf.NewStatus("DELETE_COMPLETE", ""),
+					},
+				}
+			})
+
+			It("deprovisions the instance synchronously once the stack is deleted", func() {
+				asynch, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+				Expect(stack.DeleteCalled).To(BeTrue())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(asynch).To(BeFalse())
+			})
+		})
+
+		Context("when deleting the Stack fails", func() {
+			BeforeEach(func() {
+				stack.DeleteError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			Context("when the Stack does not exists", func() {
+				BeforeEach(func() {
+					stack.DeleteError = awscf.ErrStackDoesNotExist
+				})
+
+				It("returns the proper error", func() {
+					_, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+				})
+			})
+		})
+
+		It("reports the deleted Stack as succeeded", func() {
+			stack.DescribeStackDetails.StackID = "stack-id"
+			_, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+			Expect(err).ToNot(HaveOccurred())
+
+			stack.DescribeStackDetails.Status = awscf.NewStatus("DELETE_COMPLETE", "")
+			stack.DescribeStackDetails.StackStatus = awscf.StatusSucceeded
+			lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stack.DescribeStackName).To(Equal("stack-id"))
+			Expect(lastOperationResponse).To(Equal(brokerapi.LastOperationResponse{
+				State:       brokerapi.LastOperationSucceeded,
+				Description: "Stack '" + stackName + "' was deleted",
+			}))
+
+			stack.DescribeError = awscf.ErrStackDoesNotExist
+			_, err = cfBroker.LastOperation(instanceID)
+			Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+			Expect(stack.DescribeStackName).To(Equal(stackName))
+		})
+
+		It("does not find the deleted Stack any longer", func() {
+			stack.DescribeStackDetails.StackID = "stack-id"
+			_, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+			Expect(err).ToNot(HaveOccurred())
+
+			stack.DescribeStackDetails.Status = awscf.NewStatus("DELETE_COMPLETE", "")
+			_, err = cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+			Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+		})
+
+		Context("when the Stack was not created by the broker", func() {
+			BeforeEach(func() {
+				stack.DescribeStackDetails.Tags = map[string]string{}
+			})
+
+			It("does not delete the Stack", func() {
+				_, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+				Expect(err).To(Equal(OwnershipError{StackName: stackName, Reason: "it was not created by a service broker"}))
+				Expect(stack.DeleteCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the broker has an ID", func() {
+			BeforeEach(func() {
+				brokerID = "broker-id"
+			})
+
+			It("does not delete Stacks tagged before brokers had an ID", func() {
+				_, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+				Expect(err).To(Equal(OwnershipError{StackName: stackName, Reason: "it carries no 'Broker ID' tag"}))
+				Expect(stack.DeleteCalled).To(BeFalse())
+			})
+
+			It("deletes Stacks tagged with its ID", func() {
+				stack.DescribeStackDetails.Tags["Broker ID"] = "broker-id"
+				_, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stack.DeleteCalled).To(BeTrue())
+			})
+
+			Context("and the Stack was created by another broker", func() {
+				BeforeEach(func() {
+					stack.DescribeStackDetails.Tags["Broker ID"] = "other-broker-id"
+				})
+
+				It("does not delete the Stack", func() {
+					_, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+					Expect(err).To(Equal(OwnershipError{StackName: stackName, Reason: "it was created by broker 'other-broker-id'"}))
+					Expect(stack.DeleteCalled).To(BeFalse())
+				})
+			})
+		})
+
+		Context("when the Stack does not exist", func() {
+			BeforeEach(func() {
+				stack.DescribeError = awscf.ErrStackDoesNotExist
+			})
+
+			It("returns the proper error", func() {
+				_, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+				Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+				Expect(stack.DeleteCalled).To(BeFalse())
+			})
+		})
+
+		Context("when has PreDeleteHooks", func() {
+			var (
+				webhookServer   *httptest.Server
+				webhookStatus   int
+				webhookRequests chan map[string]string
+				webhookRelease  chan bool
+			)
+
+			lastOperationState := func() string {
+				lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				return lastOperationResponse.State
+			}
+
+			lastOperationDescription := func() string {
+				lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				return lastOperationResponse.Description
+			}
+
+			BeforeEach(func() {
+				webhookStatus = http.StatusOK
+				webhookRequests = make(chan map[string]string, 1)
+				webhookRelease = nil
+				webhookServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					payload := map[string]string{}
+					json.NewDecoder(r.Body).Decode(&payload)
+					webhookRequests <- payload
+					if webhookRelease != nil {
+						<-webhookRelease
+					}
+					w.WriteHeader(webhookStatus)
+				}))
+
+				cfProperties1.PreDeleteHooks = []PreDeleteHook{
+					PreDeleteHook{Type: PreDeleteHookEmptyS3Bucket, Output: "BucketName"},
+					PreDeleteHook{Type: PreDeleteHookDeleteECRImages, Output: "RepositoryName"},
+					PreDeleteHook{Type: PreDeleteHookWebhook, URL: webhookServer.URL},
+				}
+
+				stack.DescribeStackDetails = awscf.StackDetails{
+					StackName:   stackName,
+					StackID:     "stack-id",
+					StackStatus: awscf.StatusInProgress,
+					Outputs: map[string]string{
+						"BucketName":     "bucket-name",
+						"RepositoryName": "repository-name",
+					},
+					Tags: map[string]string{"Created by": "AWS CloudFormation Service Broker"},
+				}
+			})
+
+			AfterEach(func() {
+				webhookServer.Close()
+			})
+
+			It("returns the proper response", func() {
+				asynch, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+				Expect(asynch).To(BeTrue())
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("runs the hooks before deleting the Stack", func() {
+				_, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(lastOperationDescription).Should(Equal("Stack '" + stackName + "' status is '" + awscf.StatusInProgress + "'"))
+				Expect(stack.DeleteCalled).To(BeTrue())
+				Expect(bucket.EmptyBucketName).To(Equal("bucket-name"))
+				Expect(repository.DeleteImagesRepositoryName).To(Equal("repository-name"))
+				Expect(<-webhookRequests).To(Equal(map[string]string{
+					"instance_id": instanceID,
+					"stack_name":  stackName,
+					"stack_id":    "stack-id",
+				}))
+				Expect(stack.DeleteStackName).To(Equal(stackName))
+			})
+
+			It("reports the hooks progress in the last operation", func() {
+				cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+				lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationInProgress))
+				Expect(lastOperationResponse.Description).To(ContainSubstring("pre-delete hook"))
+			})
+
+			It("rejects other requests on the instance while the hooks run", func() {
+				webhookRelease = make(chan bool)
+				defer close(webhookRelease)
+
+				cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+				Eventually(webhookRequests).Should(Receive())
+
+				_, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+				Expect(err).To(Equal(ConcurrencyError{
+					StackName: stackName,
+					Operation: "Running pre-delete hook 3 of 3 (webhook) for stack '" + stackName + "'",
+				}))
+			})
+
+			It("runs the hooks through the broker stack rather than the request stack", func() {
+				requestStack := &cffake.FakeStack{DescribeStackDetails: stack.DescribeStackDetails}
+
+				_, err := cfBroker.WithStack(requestStack).Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(lastOperationDescription).Should(Equal("Stack '" + stackName + "' status is '" + awscf.StatusInProgress + "'"))
+				Expect(stack.DeleteCalled).To(BeTrue())
+				Expect(requestStack.DeleteCalled).To(BeFalse())
+			})
+
+			Context("and the broker restarts before the hooks complete", func() {
+				var deprovisionToken string
+
+				BeforeEach(func() {
+					stack.DescribeStackDetails.StackStatus = awscf.StatusSucceeded
+					stack.DescribeStackDetails.Status = awscf.NewStatus("CREATE_COMPLETE", "")
+					deprovisionToken = OperationToken{Operation: "deprovision", StackID: "stack-id", RequestToken: "request-token"}.String()
+				})
+
+				It("reports the deprovision as failed", func() {
+					restartedBroker := New(config, stack, bucket, repository, logger)
+					lastOperationResponse, err := restartedBroker.WithOperationTokens(&OperationTokens{Requested: deprovisionToken}).LastOperation(instanceID)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(lastOperationResponse).To(Equal(brokerapi.LastOperationResponse{
+						State:       brokerapi.LastOperationFailed,
+						Description: "Deprovisioning was interrupted before stack '" + stackName + "' was deleted, deprovision the service instance again",
+					}))
+				})
+
+				It("reports the deprovision as in progress once the stack is being deleted", func() {
+					stack.DescribeStackDetails.StackStatus = awscf.StatusInProgress
+					stack.DescribeStackDetails.Status = awscf.NewStatus("DELETE_IN_PROGRESS", "")
+
+					restartedBroker := New(config, stack, bucket, repository, logger)
+					lastOperationResponse, err := restartedBroker.WithOperationTokens(&OperationTokens{Requested: deprovisionToken}).LastOperation(instanceID)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationInProgress))
+				})
+			})
+
+			Context("and a hook fails", func() {
+				BeforeEach(func() {
+					repository.DeleteImagesError = errors.New("operation failed")
+				})
+
+				It("does not delete the Stack", func() {
+					_, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+					Eventually(lastOperationState).Should(Equal(brokerapi.LastOperationFailed))
+					Expect(stack.DeleteCalled).To(BeFalse())
+					Expect(bucket.EmptyCalled).To(BeTrue())
+				})
+
+				It("reports the failure in the last operation", func() {
+					cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+					Eventually(lastOperationState).Should(Equal(brokerapi.LastOperationFailed))
+					Expect(lastOperationDescription()).To(Equal("Pre-delete hook 2 of 3 (delete_ecr_images) failed, stack '" + stackName + "' was not deleted: operation failed"))
+				})
+			})
+
+			Context("and the webhook returns an error status", func() {
+				BeforeEach(func() {
+					webhookStatus = http.StatusInternalServerError
+				})
+
+				It("does not delete the Stack", func() {
+					_, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+					Eventually(lastOperationState).Should(Equal(brokerapi.LastOperationFailed))
+					Expect(stack.DeleteCalled).To(BeFalse())
+				})
+			})
+
+			Context("and the hook Output is not found", func() {
+				BeforeEach(func() {
+					stack.DescribeStackDetails.Outputs = map[string]string{}
+				})
+
+				It("reports the failure in the last operation", func() {
+					cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+					Eventually(lastOperationState).Should(Equal(brokerapi.LastOperationFailed))
+					Expect(lastOperationDescription()).To(ContainSubstring("Stack Output 'BucketName' not found"))
+					Expect(stack.DeleteCalled).To(BeFalse())
+				})
+			})
+
+			Context("and deleting the Stack fails", func() {
+				BeforeEach(func() {
+					stack.DeleteError = errors.New("operation failed")
+				})
+
+				It("reports the failure in the last operation", func() {
+					cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+					Eventually(lastOperationState).Should(Equal(brokerapi.LastOperationFailed))
+					Expect(lastOperationDescription()).To(Equal("Deleting stack '" + stackName + "' failed: operation failed"))
+				})
+			})
+
+			Context("and the Stack was rolled back", func() {
+				BeforeEach(func() {
+					stack.DescribeStackDetails.Status.Raw = "ROLLBACK_COMPLETE"
+					stack.DescribeStackDetails.Outputs = nil
+				})
+
+				It("deletes the Stack without running the hooks", func() {
+					asynch, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+					Expect(asynch).To(BeTrue())
+					Expect(err).ToNot(HaveOccurred())
+					Expect(stack.DeleteCalled).To(BeTrue())
+					Expect(bucket.EmptyCalled).To(BeFalse())
+					Expect(repository.DeleteImagesCalled).To(BeFalse())
+				})
+			})
+
+			Context("and the Stack does not exists", func() {
+				BeforeEach(func() {
+					stack.DescribeError = awscf.ErrStackDoesNotExist
+				})
+
+				It("returns the proper error", func() {
+					_, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+				})
+			})
+		})
+	})
+
+	var _ = Describe("Bind", func() {
+		var (
+			bindDetails brokerapi.BindDetails
+			credentials map[string]string
+		)
+
+		BeforeEach(func() {
+			bindDetails = brokerapi.BindDetails{
+				ServiceID:  "Service-1",
+				PlanID:     "Plan-1",
+				AppGUID:    "Application-1",
+				Parameters: map[string]interface{}{},
+			}
+
+			credentials = map[string]string{
+				"test-output-key-1": "test-output-key-1",
+				"test-output-key-2": "test-output-key-2",
+				"test-output-key-3": "test-output-key-3",
+			}
+
+			stack.DescribeStackDetails = awscf.StackDetails{
+				StackName: stackName,
+				Outputs:   credentials,
+			}
+		})
+
+		It("returns the proper response", func() {
+			bindingResponse, err := cfBroker.Bind(instanceID, bindingID, bindDetails)
+			Expect(bindingResponse.Credentials).To(Equal(credentials))
+			Expect(bindingResponse.SyslogDrainURL).To(BeEmpty())
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("makes the proper calls", func() {
+			_, err := cfBroker.Bind(instanceID, bindingID, bindDetails)
+			Expect(stack.DescribeCalled).To(BeTrue())
+			Expect(stack.DescribeStackName).To(Equal(stackName))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when Service is not found", func() {
+			BeforeEach(func() {
+				bindDetails.ServiceID = "unknown"
+			})
+
+			It("returns the proper error", func() {
+				_, err := cfBroker.Bind(instanceID, bindingID, bindDetails)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Service 'unknown' not found"))
+			})
+		})
+
+		Context("when Service is not bindable", func() {
+			BeforeEach(func() {
+				serviceBindable = false
+			})
+
+			It("returns the proper error", func() {
+				_, err := cfBroker.Bind(instanceID, bindingID, bindDetails)
+				Expect(err).To(HaveOccurred())
+				Expect(err).To(Equal(brokerapi.ErrInstanceNotBindable))
+			})
+		})
+
+		Context("when the Stack has an operation in progress", func() {
+			BeforeEach(func() {
+				stack.DescribeStackDetails.Status = awscf.NewStatus("CREATE_IN_PROGRESS", "")
+			})
+
+			It("returns the proper error", func() {
+				_, err := cfBroker.Bind(instanceID, bindingID, bindDetails)
+				Expect(err).To(Equal(ConcurrencyError{StackName: stackName, Operation: "status is 'CREATE_IN_PROGRESS'"}))
+			})
+		})
+
+		Context("when describing the Stack fails", func() {
+			BeforeEach(func() {
+				stack.DescribeError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := cfBroker.Bind(instanceID, bindingID, bindDetails)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			Context("when the Stack does not exists", func() {
+				BeforeEach(func() {
+					stack.DescribeError = awscf.ErrStackDoesNotExist
+				})
+
+				It("returns the proper error", func() {
+					_, err := cfBroker.Bind(instanceID, bindingID, bindDetails)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+				})
+			})
+		})
+	})
+
+	var _ = Describe("Unbind", func() {
+		var (
+			unbindDetails brokerapi.UnbindDetails
+		)
+
+		BeforeEach(func() {
+			unbindDetails = brokerapi.UnbindDetails{
+				ServiceID: "Service-1",
+				PlanID:    "Plan-1",
+			}
+		})
+
+		It("does not return error", func() {
+			err := cfBroker.Unbind(instanceID, bindingID, unbindDetails)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	var _ = Describe("LastOperation", func() {
+		var (
+			stackStatus                 string
+			lastOperationState          string
+			properLastOperationResponse brokerapi.LastOperationResponse
+		)
+
+		JustBeforeEach(func() {
+			stack.DescribeStackDetails = awscf.StackDetails{
+				StackName:   stackName,
+				StackStatus: stackStatus,
+			}
+
+			properLastOperationResponse = brokerapi.LastOperationResponse{
+				State:       lastOperationState,
+				Description: "Stack '" + stackName + "' status is '" + stackStatus + "'",
+			}
+		})
+
+		Context("when describing the Stack fails", func() {
+			BeforeEach(func() {
+				stack.DescribeError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := cfBroker.LastOperation(instanceID)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			Context("when the Stack does not exists", func() {
+				BeforeEach(func() {
+					stack.DescribeError = awscf.ErrStackDoesNotExist
+				})
+
+				It("returns the proper error", func() {
+					_, err := cfBroker.LastOperation(instanceID)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+				})
+			})
+		})
+
+		Context("when the broker receives stack notifications", func() {
+			var statuses stackStatuses
+
+			BeforeEach(func() {
+				statuses = stackStatuses{}
+				stackStatus = awscf.StatusSucceeded
+				lastOperationState = brokerapi.LastOperationSucceeded
+			})
+
+			JustBeforeEach(func() {
+				cfBroker.ReceiveStackNotifications("test-topic-arn", statuses)
+
+				stack.CreateStackID = "stack-id"
+				_, _, err := cfBroker.Provision(instanceID, brokerapi.ProvisionDetails{ServiceID: "Service-1", PlanID: "Plan-1"}, true)
+				Expect(err).ToNot(HaveOccurred())
+				stack.DescribeCalled = false
+			})
+
+			Context("and the Stack is notified in progress", func() {
+				BeforeEach(func() {
+					statuses["stack-id"] = notifiedStack{status: awscf.NewStatus("CREATE_IN_PROGRESS", "")}
+				})
+
+				It("does not describe the Stack", func() {
+					lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationInProgress))
+					Expect(lastOperationResponse.Description).To(Equal("Stack '" + stackName + "' status is 'in progress'"))
+					Expect(stack.DescribeCalled).To(BeFalse())
+				})
+			})
+
+			Context("and resources of the Stack are notified", func() {
+				BeforeEach(func() {
+					statuses["stack-id"] = notifiedStack{
+						status: awscf.NewStatus("CREATE_IN_PROGRESS", ""),
+						resources: []awscf.StackResource{
+							awscf.StackResource{LogicalResourceID: "Bucket", ResourceType: "AWS::S3::Bucket", ResourceStatus: "CREATE_COMPLETE"},
+							awscf.StackResource{LogicalResourceID: "Queue", ResourceType: "AWS::SQS::Queue", ResourceStatus: "CREATE_IN_PROGRESS"},
+						},
+					}
+					stack.TemplateBody = `{"Resources":{"Bucket":{},"Queue":{},"Database":{}}}`
+				})
+
+				It("reports the progress from the notified resources", func() {
+					lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(lastOperationResponse.Description).To(Equal("Stack '" + stackName + "' status is 'in progress': 1 of 3 resources complete, currently creating AWS::SQS::Queue Queue"))
+					Expect(stack.ListResourcesCalled).To(BeFalse())
+				})
+			})
+
+			Context("and the Stack is notified complete", func() {
+				BeforeEach(func() {
+					statuses["stack-id"] = notifiedStack{status: awscf.NewStatus("CREATE_COMPLETE", "")}
+				})
+
+				It("describes the Stack", func() {
+					lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(lastOperationResponse).To(Equal(properLastOperationResponse))
+					Expect(stack.DescribeStackName).To(Equal("stack-id"))
+				})
+			})
+
+			Context("and the Stack is not notified", func() {
+				It("describes the Stack", func() {
+					lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(lastOperationResponse).To(Equal(properLastOperationResponse))
+					Expect(stack.DescribeCalled).To(BeTrue())
+				})
+			})
+		})
+
+		Context("when last operation is still in progress", func() {
+			BeforeEach(func() {
+				stackStatus = awscf.StatusInProgress
+				lastOperationState = brokerapi.LastOperationInProgress
+			})
+
+			It("returns the proper LastOperationResponse", func() {
+				lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse).To(Equal(properLastOperationResponse))
+			})
+
+			Context("and the Stack has resources", func() {
+				BeforeEach(func() {
+					stack.ListResourcesStackResources = []awscf.StackResource{
+						awscf.StackResource{LogicalResourceID: "Bucket", ResourceType: "AWS::S3::Bucket", ResourceStatus: "CREATE_COMPLETE"},
+						awscf.StackResource{LogicalResourceID: "Queue", ResourceType: "AWS::SQS::Queue", ResourceStatus: "CREATE_COMPLETE"},
+						awscf.StackResource{LogicalResourceID: "Database", ResourceType: "AWS::RDS::DBInstance", ResourceStatus: "CREATE_IN_PROGRESS"},
+						awscf.StackResource{LogicalResourceID: "Cache", ResourceType: "AWS::ElastiCache::CacheCluster", ResourceStatus: "CREATE_IN_PROGRESS"},
+					}
+					stack.TemplateBody = `{"Resources":{"Bucket":{},"Queue":{},"Database":{},"Cache":{},"Topic":{}}}`
+				})
+
+				JustBeforeEach(func() {
+					stack.DescribeStackDetails.Status = awscf.NewStatus("CREATE_IN_PROGRESS", "")
+				})
+
+				It("reports the progress of the resources", func() {
+					lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(stack.ListResourcesStackName).To(Equal(stackName))
+					Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationInProgress))
+					Expect(lastOperationResponse.Description).To(Equal("Stack '" + stackName + "' status is 'in progress': 2 of 5 resources complete, currently creating AWS::RDS::DBInstance Database and 1 more"))
+					Expect(stack.TemplateStackName).To(Equal(stackName))
+				})
+
+				It("reads the Stack template once per Stack operation", func() {
+					_, err := cfBroker.LastOperation(instanceID)
+					Expect(err).ToNot(HaveOccurred())
+
+					stack.TemplateCalled = false
+					lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(stack.TemplateCalled).To(BeFalse())
+					Expect(lastOperationResponse.Description).To(ContainSubstring("2 of 5 resources complete"))
+
+					stack.DescribeStackDetails.LastUpdatedTime = time.Now()
+					_, err = cfBroker.LastOperation(instanceID)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(stack.TemplateCalled).To(BeTrue())
+				})
+
+				Context("and the Stack template is written in YAML", func() {
+					BeforeEach(func() {
+						stack.TemplateBody = strings.Join([]string{
+							"AWSTemplateFormatVersion: '2010-09-09'",
+							"Resources:",
+							"  Bucket:",
+							"    Type: AWS::S3::Bucket",
+							"  Queue:",
+							"    Type: AWS::SQS::Queue",
+							"    Properties:",
+							"      QueueName: queue",
+							"  Database:",
+							"    Type: AWS::RDS::DBInstance",
+							"  Cache:",
+							"    Type: AWS::ElastiCache::CacheCluster",
+							"Outputs:",
+							"  BucketName:",
+							"    Value: !Ref Bucket",
+						}, "\n")
+					})
+
+					It("reports the progress of the resources without a total", func() {
+						lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(lastOperationResponse.Description).To(ContainSubstring("2 resources complete"))
+						Expect(lastOperationResponse.Description).ToNot(ContainSubstring(" of "))
+					})
+				})
+
+				Context("and the Stack template cannot be read", func() {
+					BeforeEach(func() {
+						stack.TemplateError = errors.New("operation failed")
+					})
+
+					It("reports the progress of the resources without a total", func() {
+						lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(lastOperationResponse.Description).To(Equal("Stack '" + stackName + "' status is 'in progress': 2 resources complete, currently creating AWS::RDS::DBInstance Database and 1 more"))
+					})
+				})
+
+				Context("and the Stack is being deleted", func() {
+					JustBeforeEach(func() {
+						stack.DescribeStackDetails.Status = awscf.NewStatus("DELETE_IN_PROGRESS", "")
+						stack.ListResourcesStackResources = []awscf.StackResource{
+							awscf.StackResource{LogicalResourceID: "Bucket", ResourceType: "AWS::S3::Bucket", ResourceStatus: "DELETE_COMPLETE"},
+							awscf.StackResource{LogicalResourceID: "Database", ResourceType: "AWS::RDS::DBInstance", ResourceStatus: "DELETE_IN_PROGRESS"},
+							awscf.StackResource{LogicalResourceID: "Queue", ResourceType: "AWS::SQS::Queue", ResourceStatus: "CREATE_COMPLETE"},
+						}
+					})
+
+					It("only counts deleted resources as complete", func() {
+						lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(lastOperationResponse.Description).To(Equal("Stack '" + stackName + "' status is 'in progress': 1 of 5 resources complete, currently deleting AWS::RDS::DBInstance Database"))
+					})
+				})
+
+				Context("and listing the resources fails", func() {
+					BeforeEach(func() {
+						stack.ListResourcesError = errors.New("operation failed")
+					})
+
+					It("returns the proper LastOperationResponse", func() {
+						lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(lastOperationResponse).To(Equal(properLastOperationResponse))
+					})
+				})
+			})
+		})
+
+		Context("when last operation failed", func() {
+			BeforeEach(func() {
+				stackStatus = awscf.StatusFailed
+				lastOperationState = brokerapi.LastOperationFailed
+			})
+
+			It("returns the proper LastOperationResponse", func() {
+				lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse).To(Equal(properLastOperationResponse))
+			})
+		})
+
+		Context("when an update failed and was rolled back", func() {
+			JustBeforeEach(func() {
+				stack.DescribeStackDetails = awscf.StackDetails{
+					StackName:   stackName,
+					StackStatus: awscf.StatusFailed,
+					Status:      awscf.NewStatus("UPDATE_ROLLBACK_COMPLETE", "Resource update cancelled"),
+				}
+			})
+
+			It("reports the instance as still usable", func() {
+				lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationFailed))
+				Expect(lastOperationResponse.Description).To(Equal("The update of stack '" + stackName + "' failed and was rolled back, the service instance is still usable with its previous configuration: Resource update cancelled"))
+			})
+
+			Context("and the stack has drifted", func() {
+				JustBeforeEach(func() {
+					cfBroker.ReportDrift(driftDescriptions{instanceID: "Stack '" + stackName + "' has drifted"})
+				})
+
+				It("reports the drift", func() {
+					lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(lastOperationResponse.Description).To(HaveSuffix("Resource update cancelled. Stack '" + stackName + "' has drifted"))
+				})
+			})
+		})
+
+		Context("when a create failed and was rolled back", func() {
+			JustBeforeEach(func() {
+				stack.DescribeStackDetails = awscf.StackDetails{
+					StackName:   stackName,
+					StackStatus: awscf.StatusFailed,
+					Status:      awscf.NewStatus("ROLLBACK_COMPLETE", ""),
+				}
+			})
+
+			It("reports the instance as not usable", func() {
+				lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationFailed))
+				Expect(lastOperationResponse.Description).To(Equal("Stack '" + stackName + "' status is 'ROLLBACK_COMPLETE', the service instance may not be usable"))
+			})
+		})
+
+		Context("when last operation succeeded", func() {
+			BeforeEach(func() {
+				stackStatus = awscf.StatusSucceeded
+				lastOperationState = brokerapi.LastOperationSucceeded
+			})
+
+			It("returns the proper LastOperationResponse", func() {
+				lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse).To(Equal(properLastOperationResponse))
+			})
+		})
+	})
+
+	var _ = Describe("CancelUpdate", func() {
+		BeforeEach(func() {
+			stack.DescribeStackDetails = awscf.StackDetails{
+				StackStatus: awscf.StatusInProgress,
+				Status:      awscf.NewStatus("UPDATE_IN_PROGRESS", ""),
+				Tags:        map[string]string{"Created by": "AWS CloudFormation Service Broker"},
+			}
+		})
+
+		It("makes the proper calls", func() {
+			err := cfBroker.CancelUpdate(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stack.CancelUpdateCalled).To(BeTrue())
+			Expect(stack.CancelUpdateStackName).To(Equal(stackName))
+		})
+
+		It("reports the cancellation in the LastOperation", func() {
+			err := cfBroker.CancelUpdate(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+
+			stack.DescribeStackDetails = awscf.StackDetails{
+				StackStatus: awscf.StatusInProgress,
+				Status:      awscf.NewStatus("UPDATE_ROLLBACK_IN_PROGRESS", ""),
+			}
+			lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationInProgress))
+			Expect(lastOperationResponse.Description).To(Equal("Update of stack '" + stackName + "' was cancelled (cancelled by an operator), stack status is 'UPDATE_ROLLBACK_IN_PROGRESS'"))
+
+			stack.DescribeStackDetails = awscf.StackDetails{
+				StackStatus: awscf.StatusFailed,
+				Status:      awscf.NewStatus("UPDATE_ROLLBACK_FAILED", "test-reason"),
+			}
+			lastOperationResponse, err = cfBroker.LastOperation(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationFailed))
+			Expect(lastOperationResponse.Description).To(Equal("Update of stack '" + stackName + "' was cancelled (cancelled by an operator), stack status is 'UPDATE_ROLLBACK_FAILED': test-reason"))
+
+			stack.DescribeStackDetails = awscf.StackDetails{
+				StackStatus: awscf.StatusFailed,
+				Status:      awscf.NewStatus("UPDATE_ROLLBACK_FAILED", ""),
+			}
+			lastOperationResponse, err = cfBroker.LastOperation(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lastOperationResponse.Description).To(Equal("Stack '" + stackName + "' status is 'UPDATE_ROLLBACK_FAILED', the service instance may not be usable"))
+		})
+
+		Context("when the Stack is not owned by the broker", func() {
+			BeforeEach(func() {
+				brokerID = "broker-id"
+				stack.DescribeStackDetails.Tags["Broker ID"] = "other-broker-id"
+			})
+
+			It("does not cancel the update", func() {
+				err := cfBroker.CancelUpdate(instanceID)
+				Expect(err).To(Equal(OwnershipError{StackName: stackName, Reason: "it was created by broker 'other-broker-id'"}))
+				Expect(stack.CancelUpdateCalled).To(BeFalse())
+			})
+		})
+
+		Context("when cancelling the update fails", func() {
+			BeforeEach(func() {
+				stack.CancelUpdateError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				err := cfBroker.CancelUpdate(instanceID)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			Context("and the Stack does not exists", func() {
+				BeforeEach(func() {
+					stack.CancelUpdateError = awscf.ErrStackDoesNotExist
+				})
+
+				It("returns the proper error", func() {
+					err := cfBroker.CancelUpdate(instanceID)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+				})
+			})
+		})
+	})
+
+	var _ = Describe("InstancesByStackStatus", func() {
+		BeforeEach(func() {
+			stack.ListStackSummaries = []awscf.StackSummary{
+				awscf.StackSummary{StackName: "cf-instance-1", Status: awscf.NewStatus("CREATE_COMPLETE", "")},
+				awscf.StackSummary{StackName: "cf-instance-2", Status: awscf.NewStatus("CREATE_COMPLETE", "")},
+				awscf.StackSummary{StackName: "cf-instance-3", Status: awscf.NewStatus("UPDATE_ROLLBACK_FAILED", "")},
+				awscf.StackSummary{StackName: "other-stack", Status: awscf.NewStatus("CREATE_COMPLETE", "")},
+			}
+		})
+
+		It("counts the instances by stack status", func() {
+			instances, err := cfBroker.InstancesByStackStatus()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(instances).To(Equal(map[string]int{
+				"CREATE_COMPLETE":        2,
+				"UPDATE_ROLLBACK_FAILED": 1,
+			}))
+		})
+
+		Context("when listing the stacks fails", func() {
+			BeforeEach(func() {
+				stack.ListError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := cfBroker.InstancesByStackStatus()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+		})
+	})
+
+	var _ = Describe("StackID", func() {
+		BeforeEach(func() {
+			stack.DescribeStackDetails = awscf.StackDetails{StackID: "test-stack-id"}
+		})
+
+		It("returns the ID of the instance stack", func() {
+			stackID, err := cfBroker.StackID(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stackID).To(Equal("test-stack-id"))
+			Expect(stack.DescribeStackName).To(Equal(stackName))
+		})
+
+		Context("when the Stack does not exists", func() {
+			BeforeEach(func() {
+				stack.DescribeError = awscf.ErrStackDoesNotExist
+			})
+
+			It("returns the proper error", func() {
+				_, err := cfBroker.StackID(instanceID)
+				Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+			})
+		})
+	})
+
+	var _ = Describe("TrackedStackID", func() {
+		It("returns the ID of the instance stack once known", func() {
+			_, ok := cfBroker.TrackedStackID(instanceID)
+			Expect(ok).To(BeFalse())
+
+			stack.DescribeStackDetails = awscf.StackDetails{StackID: "test-stack-id"}
+			_, err := cfBroker.StackID(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+
+			stack.DescribeCalled = false
+			stackID, ok := cfBroker.TrackedStackID(instanceID)
+			Expect(ok).To(BeTrue())
+			Expect(stackID).To(Equal("test-stack-id"))
+			Expect(stack.DescribeCalled).To(BeFalse())
+		})
+	})
+
+	var _ = Describe("WithStack", func() {
+		var otherStack *cffake.FakeStack
+
+		BeforeEach(func() {
+			otherStack = &cffake.FakeStack{}
+			otherStack.DescribeStackDetails = awscf.StackDetails{
+				StackID: "test-stack-id",
+				Tags:    map[string]string{"Created by": "AWS CloudFormation Service Broker"},
+			}
+		})
+
+		It("manages the instances through the other stack", func() {
+			stackID, err := cfBroker.WithStack(otherStack).StackID(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stackID).To(Equal("test-stack-id"))
+			Expect(otherStack.DescribeStackName).To(Equal(stackName))
+			Expect(stack.DescribeCalled).To(BeFalse())
+		})
+
+		It("shares the tracked operations", func() {
+			err := cfBroker.WithStack(otherStack).CancelUpdate(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(otherStack.CancelUpdateStackName).To(Equal(stackName))
+
+			stack.DescribeStackDetails = awscf.StackDetails{
+				StackStatus: awscf.StatusInProgress,
+				Status:      awscf.NewStatus("UPDATE_ROLLBACK_IN_PROGRESS", ""),
+			}
+			lastOperationResponse, err := cfBroker.LastOperation(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lastOperationResponse.Description).To(ContainSubstring("was cancelled (cancelled by an operator)"))
+		})
+	})
+
+	var _ = Describe("WithOperationTokens", func() {
+		var (
+			provisionTokens *OperationTokens
+			provisionToken  OperationToken
+		)
+
+		BeforeEach(func() {
+			stack.CreateStackID = "stack-id"
+		})
+
+		JustBeforeEach(func() {
+			provisionTokens = &OperationTokens{}
+			_, asynch, err := cfBroker.WithOperationTokens(provisionTokens).Provision(instanceID, brokerapi.ProvisionDetails{ServiceID: "Service-1", PlanID: "Plan-1"}, true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(asynch).To(BeTrue())
+
+			provisionToken, err = ParseOperationToken(provisionTokens.Issued)
+			Expect(err).ToNot(HaveOccurred())
+
+			stack.DescribeStackDetails = awscf.StackDetails{
+				StackName:   stackName,
+				StackID:     "stack-id",
+				StackStatus: awscf.StatusSucceeded,
+				Status:      awscf.NewStatus("CREATE_COMPLETE", ""),
+				Tags:        map[string]string{"Created by": "AWS CloudFormation Service Broker", "Service ID": "Service-1"},
+			}
+		})
+
+		lastOperation := func(token string) (brokerapi.LastOperationResponse, error) {
+			return cfBroker.WithOperationTokens(&OperationTokens{Requested: token}).LastOperation(instanceID)
+		}
+
+		It("issues a token to the provision operation", func() {
+			Expect(provisionToken.Operation).To(Equal("provision"))
+			Expect(provisionToken.StackID).To(Equal("stack-id"))
+			Expect(provisionToken.RequestToken).ToNot(BeEmpty())
+		})
+
+		It("answers the last operation of the token", func() {
+			lastOperationResponse, err := lastOperation(provisionTokens.Issued)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationSucceeded))
+		})
+
+		It("answers the last operation of the instance if the token is not valid", func() {
+			lastOperationResponse, err := lastOperation("invalid-token")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationSucceeded))
+		})
+
+		Context("when a later update is in progress", func() {
+			var updateTokens *OperationTokens
+
+			JustBeforeEach(func() {
+				updateTokens = &OperationTokens{}
+				asynch, err := cfBroker.WithOperationTokens(updateTokens).Update(instanceID, brokerapi.UpdateDetails{ServiceID: "Service-1", PlanID: "Plan-1"}, true)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(asynch).To(BeTrue())
+
+				stack.DescribeStackDetails.StackStatus = awscf.StatusInProgress
+				stack.DescribeStackDetails.Status = awscf.NewStatus("UPDATE_IN_PROGRESS", "")
+			})
+
+			It("issues another token to the update operation", func() {
+				updateToken, err := ParseOperationToken(updateTokens.Issued)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(updateToken.Operation).To(Equal("update"))
+				Expect(updateToken.StackID).To(Equal("stack-id"))
+				Expect(updateToken.RequestToken).ToNot(Equal(provisionToken.RequestToken))
+			})
+
+			It("answers a poll of the provision with its outcome", func() {
+				lastOperationResponse, err := lastOperation(provisionTokens.Issued)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse).To(Equal(brokerapi.LastOperationResponse{
+					State:       brokerapi.LastOperationSucceeded,
+					Description: "Stack 'cf-instance-id' status was 'CREATE_COMPLETE'",
+				}))
+			})
+
+			It("answers a poll of the update with the stack status", func() {
+				lastOperationResponse, err := lastOperation(updateTokens.Issued)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationInProgress))
+
+				lastOperationResponse, err = lastOperation("")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationInProgress))
+			})
+		})
+
+		Context("when the broker restarts", func() {
+			var restartedBroker *CloudFormationBroker
+
+			JustBeforeEach(func() {
+				stack.DescribeStackDetails.Tags["Instance ID"] = instanceID
+				restartedBroker = New(config, stack, bucket, repository, logger)
+			})
+
+			restartedLastOperation := func(token string) (brokerapi.LastOperationResponse, error) {
+				return restartedBroker.WithOperationTokens(&OperationTokens{Requested: token}).LastOperation(instanceID)
+			}
+
+			It("describes the stack by the ID of the polled token", func() {
+				lastOperationResponse, err := restartedLastOperation(provisionTokens.Issued)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationSucceeded))
+				Expect(stack.DescribeStackName).To(Equal("stack-id"))
+			})
+
+			It("does not describe by ID the stack of another instance", func() {
+				delete(stack.DescribeStackDetails.Tags, "Instance ID")
+
+				_, err := restartedLastOperation(provisionTokens.Issued)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stack.DescribeStackName).To(Equal(stackName))
+			})
+
+			It("answers a poll of the provision as succeeded once an update started", func() {
+				stack.DescribeStackDetails.StackStatus = awscf.StatusInProgress
+				stack.DescribeStackDetails.Status = awscf.NewStatus("UPDATE_IN_PROGRESS", "")
+
+				lastOperationResponse, err := restartedLastOperation(provisionTokens.Issued)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse).To(Equal(brokerapi.LastOperationResponse{
+					State:       brokerapi.LastOperationSucceeded,
+					Description: "Stack '" + stackName + "' was created, it is being updated since",
+				}))
+
+				lastOperationResponse, err = restartedLastOperation("")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationInProgress))
+			})
+		})
+	})
+
+	var _ = Describe("Instances", func() {
+		BeforeEach(func() {
+			stack.DescribeAllStackDetails = []awscf.StackDetails{
+				awscf.StackDetails{StackName: stackName, StackID: "test-stack-id", Status: awscf.NewStatus("CREATE_COMPLETE", "")},
+				awscf.StackDetails{
+					StackName: "cf-service-1-b8d5a3c1",
+					StackID:   "renamed-stack-id",
+					Status:    awscf.NewStatus("CREATE_COMPLETE", ""),
+					Tags:      map[string]string{"Instance ID": "Renamed_Instance"},
+				},
+				awscf.StackDetails{StackName: "other-stack", StackID: "other-stack-id", Status: awscf.NewStatus("CREATE_COMPLETE", "")},
+			}
+		})
+
+		It("returns the instances of the broker", func() {
+			instances, err := cfBroker.Instances()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(instances).To(Equal([]InstanceSummary{
+				InstanceSummary{
+					InstanceID:  instanceID,
+					StackName:   stackName,
+					StackID:     "test-stack-id",
+					StackStatus: awscf.NewStatus("CREATE_COMPLETE", ""),
+				},
+				InstanceSummary{
+					InstanceID:  "Renamed_Instance",
+					StackName:   "cf-service-1-b8d5a3c1",
+					StackID:     "renamed-stack-id",
+					StackStatus: awscf.NewStatus("CREATE_COMPLETE", ""),
+				},
+			}))
+		})
+
+		Context("when describing the stacks fails", func() {
+			BeforeEach(func() {
+				stack.DescribeAllError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := cfBroker.Instances()
+				Expect(err).To(MatchError("operation failed"))
+			})
+		})
+	})
+
+	var _ = Describe("Instance", func() {
+		BeforeEach(func() {
+			stack.DescribeStackDetails = awscf.StackDetails{
+				StackID:    "test-stack-id",
+				Status:     awscf.NewStatus("DELETE_FAILED", "The following resource(s) failed to delete: [Bucket]"),
+				Parameters: map[string]string{"DBName": "test"},
+				Outputs:    map[string]string{"Endpoint": "test-endpoint"},
+				Tags:       map[string]string{"Owner": "Cloud Foundry"},
+			}
+			stack.EventsStackEvents = []awscf.StackEvent{
+				awscf.StackEvent{EventID: "test-event-id", ResourceStatus: "DELETE_FAILED"},
+			}
+			stack.TemplateBody = `{"Resources":{}}`
+		})
+
+		It("returns the details, events and template of the instance stack", func() {
+			instance, err := cfBroker.Instance(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(instance).To(Equal(InstanceDetails{
+				InstanceID:  instanceID,
+				StackName:   stackName,
+				StackID:     "test-stack-id",
+				StackStatus: stack.DescribeStackDetails.Status,
+				Parameters:  map[string]string{"DBName": "test"},
+				Outputs:     map[string]string{"Endpoint": "test-endpoint"},
+				Tags:        map[string]string{"Owner": "Cloud Foundry"},
+				Events:      stack.EventsStackEvents,
+				Template:    `{"Resources":{}}`,
+			}))
+			Expect(stack.EventsStackName).To(Equal(stackName))
+			Expect(stack.TemplateStackName).To(Equal(stackName))
+		})
+
+		It("describes the Stack by the name the template gives it, without looking the stacks of the account up", func() {
+			_, err := cfBroker.Instance(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stack.DescribeStackName).To(Equal(stackName))
+			Expect(stack.DescribeAllCalled).To(BeFalse())
+		})
+
+		Context("when the Stack was named with another template", func() {
+			BeforeEach(func() {
+				stack.DescribeErrorByName = map[string]error{
+					stackName: awscf.ErrStackDoesNotExist,
+				}
+				stack.DescribeAllStackDetails = []awscf.StackDetails{
+					awscf.StackDetails{
+						StackName: "cf-service-1-instance-id",
+						Tags:      map[string]string{"Created by": "AWS CloudFormation Service Broker", "Instance ID": instanceID},
+					},
+				}
+			})
+
+			It("finds the Stack by its instance ID tag", func() {
+				instance, err := cfBroker.Instance(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(instance.StackName).To(Equal("cf-service-1-instance-id"))
+				Expect(stack.DescribeStackName).To(Equal("cf-service-1-instance-id"))
+			})
+
+			It("looks the Stack up only once", func() {
+				_, err := cfBroker.Instance(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stack.DescribeAllCalled).To(BeTrue())
+
+				stack.DescribeAllCalled = false
+				_, err = cfBroker.Instance(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stack.DescribeAllCalled).To(BeFalse())
+			})
+		})
+
+		Context("when no Stack carries the instance ID tag", func() {
+			BeforeEach(func() {
+				stack.DescribeErrorByName = map[string]error{
+					stackName: awscf.ErrStackDoesNotExist,
+				}
+			})
+
+			It("returns the proper error", func() {
+				_, err := cfBroker.Instance(instanceID)
+				Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+				Expect(stack.DescribeStackName).To(Equal(stackName))
+			})
+
+			It("looks the Stack up again, as it may have been created since", func() {
+				cfBroker.Instance(instanceID)
+				Expect(stack.DescribeAllCalled).To(BeTrue())
+
+				stack.DescribeAllCalled = false
+				cfBroker.Instance(instanceID)
+				Expect(stack.DescribeAllCalled).To(BeTrue())
+			})
+		})
+
+		Context("when looking the Stack up fails", func() {
 			BeforeEach(func() {
-				acceptsIncomplete = false
+				stack.DescribeErrorByName = map[string]error{
+					stackName: awscf.ErrStackDoesNotExist,
+				}
+				stack.DescribeAllError = errors.New("operation failed")
 			})
 
 			It("returns the proper error", func() {
-				_, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
-				Expect(err).To(HaveOccurred())
-				Expect(err).To(Equal(brokerapi.ErrAsyncRequired))
+				_, err := cfBroker.Instance(instanceID)
+				Expect(err).To(MatchError("operation failed"))
+				Expect(stack.EventsCalled).To(BeFalse())
 			})
 		})
 
-		Context("when deleting the Stack fails", func() {
+		Context("when the Stack does not exists", func() {
 			BeforeEach(func() {
-				stack.DeleteError = errors.New("operation failed")
+				stack.DescribeError = awscf.ErrStackDoesNotExist
 			})
 
 			It("returns the proper error", func() {
-				_, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(Equal("operation failed"))
+				_, err := cfBroker.Instance(instanceID)
+				Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
 			})
+		})
 
-			Context("when the Stack does not exists", func() {
-				BeforeEach(func() {
-					stack.DeleteError = awscf.ErrStackDoesNotExist
-				})
+		Context("when getting the events fails", func() {
+			BeforeEach(func() {
+				stack.EventsError = errors.New("operation failed")
+			})
 
-				It("returns the proper error", func() {
-					_, err := cfBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
-					Expect(err).To(HaveOccurred())
-					Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
-				})
+			It("returns the proper error", func() {
+				_, err := cfBroker.Instance(instanceID)
+				Expect(err).To(MatchError("operation failed"))
 			})
 		})
 	})
 
-	var _ = Describe("Bind", func() {
-		var (
-			bindDetails brokerapi.BindDetails
-			credentials map[string]string
-		)
-
+	var _ = Describe("InstanceEvents", func() {
 		BeforeEach(func() {
-			bindDetails = brokerapi.BindDetails{
-				ServiceID:  "Service-1",
-				PlanID:     "Plan-1",
-				AppGUID:    "Application-1",
-				Parameters: map[string]interface{}{},
+			stack.EventsStackEvents = []awscf.StackEvent{
+				awscf.StackEvent{EventID: "test-event-id", ResourceStatus: "CREATE_COMPLETE"},
 			}
+		})
 
-			credentials = map[string]string{
-				"test-output-key-1": "test-output-key-1",
-				"test-output-key-2": "test-output-key-2",
-				"test-output-key-3": "test-output-key-3",
+		It("returns the events of the instance stack", func() {
+			events, err := cfBroker.InstanceEvents(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(events).To(Equal(stack.EventsStackEvents))
+			Expect(stack.EventsStackName).To(Equal(stackName))
+		})
+
+		Context("when the Stack does not exists", func() {
+			BeforeEach(func() {
+				stack.EventsError = awscf.ErrStackDoesNotExist
+			})
+
+			It("returns the proper error", func() {
+				_, err := cfBroker.InstanceEvents(instanceID)
+				Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+			})
+		})
+	})
+
+	var _ = Describe("OrphanedInstances", func() {
+		BeforeEach(func() {
+			createdByBroker := func(stackName string, status string, planID string) awscf.StackDetails {
+				return awscf.StackDetails{
+					StackName: stackName,
+					Status:    awscf.NewStatus(status, ""),
+					Tags:      map[string]string{"Created by": "AWS CloudFormation Service Broker", "Service ID": "Service-1", "Plan ID": planID},
+				}
+			}
+			stack.DescribeAllStackDetails = []awscf.StackDetails{
+				createdByBroker("cf-healthy", "CREATE_COMPLETE", "Plan-1"),
+				createdByBroker("cf-rolled-back", "ROLLBACK_COMPLETE", "Plan-1"),
+				createdByBroker("cf-delete-failed", "DELETE_FAILED", "Plan-1"),
+				createdByBroker("cf-removed-plan", "CREATE_COMPLETE", "Plan-3"),
+				awscf.StackDetails{StackName: "cf-foreign", Status: awscf.NewStatus("ROLLBACK_COMPLETE", "")},
+				createdByBroker("other-stack", "ROLLBACK_COMPLETE", "Plan-1"),
 			}
+		})
+
+		It("returns the stacks created by the broker that are left behind", func() {
+			orphanedInstances, err := cfBroker.OrphanedInstances()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(orphanedInstances).To(HaveLen(3))
+			Expect(orphanedInstances[0].InstanceID).To(Equal("rolled-back"))
+			Expect(orphanedInstances[0].Reason).To(Equal("Stack creation failed and was rolled back"))
+			Expect(orphanedInstances[1].InstanceID).To(Equal("delete-failed"))
+			Expect(orphanedInstances[1].Reason).To(Equal("Stack deletion failed"))
+			Expect(orphanedInstances[2].InstanceID).To(Equal("removed-plan"))
+			Expect(orphanedInstances[2].Reason).To(Equal("Plan 'Plan-3' is no longer in the catalog"))
+		})
+
+		Context("when describing the stacks fails", func() {
+			BeforeEach(func() {
+				stack.DescribeAllError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := cfBroker.OrphanedInstances()
+				Expect(err).To(MatchError("operation failed"))
+			})
+		})
+	})
 
+	var _ = Describe("DeleteInstanceStack", func() {
+		BeforeEach(func() {
 			stack.DescribeStackDetails = awscf.StackDetails{
-				StackName: stackName,
-				Outputs:   credentials,
+				Status: awscf.NewStatus("CREATE_COMPLETE", ""),
+				Tags:   map[string]string{"Created by": "AWS CloudFormation Service Broker"},
 			}
 		})
 
-		It("returns the proper response", func() {
-			bindingResponse, err := cfBroker.Bind(instanceID, bindingID, bindDetails)
-			Expect(bindingResponse.Credentials).To(Equal(credentials))
-			Expect(bindingResponse.SyslogDrainURL).To(BeEmpty())
+		It("deletes the stack", func() {
+			err := cfBroker.DeleteInstanceStack(instanceID, nil)
 			Expect(err).ToNot(HaveOccurred())
+			Expect(stack.DeleteStackName).To(Equal(stackName))
+			Expect(stack.DeleteRetainingResourcesCalled).To(BeFalse())
 		})
 
-		It("makes the proper calls", func() {
-			_, err := cfBroker.Bind(instanceID, bindingID, bindDetails)
-			Expect(stack.DescribeCalled).To(BeTrue())
-			Expect(stack.DescribeStackName).To(Equal(stackName))
+		It("retains the requested resources", func() {
+			err := cfBroker.DeleteInstanceStack(instanceID, []string{"Bucket"})
 			Expect(err).ToNot(HaveOccurred())
+			Expect(stack.DeleteCalled).To(BeFalse())
+			Expect(stack.DeleteRetainingResourcesStackName).To(Equal(stackName))
+			Expect(stack.DeleteRetainingResourcesResourcesToRetain).To(Equal([]string{"Bucket"}))
 		})
 
-		Context("when Service is not found", func() {
+		Context("when the stack was not created by the broker", func() {
 			BeforeEach(func() {
-				bindDetails.ServiceID = "unknown"
+				stack.DescribeStackDetails = awscf.StackDetails{Status: awscf.NewStatus("CREATE_COMPLETE", "")}
 			})
 
 			It("returns the proper error", func() {
-				_, err := cfBroker.Bind(instanceID, bindingID, bindDetails)
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(Equal("Service 'unknown' not found"))
+				err := cfBroker.DeleteInstanceStack(instanceID, nil)
+				Expect(err).To(Equal(OwnershipError{StackName: stackName, Reason: "it was not created by a service broker"}))
+				Expect(stack.DeleteCalled).To(BeFalse())
 			})
 		})
 
-		Context("when Service is not bindable", func() {
+		Context("when the Stack does not exists", func() {
 			BeforeEach(func() {
-				serviceBindable = false
+				stack.DescribeError = awscf.ErrStackDoesNotExist
 			})
 
 			It("returns the proper error", func() {
-				_, err := cfBroker.Bind(instanceID, bindingID, bindDetails)
-				Expect(err).To(HaveOccurred())
-				Expect(err).To(Equal(brokerapi.ErrInstanceNotBindable))
+				err := cfBroker.DeleteInstanceStack(instanceID, nil)
+				Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
 			})
 		})
+	})
 
-		Context("when describing the Stack fails", func() {
+	var _ = Describe("RetryDelete", func() {
+		BeforeEach(func() {
+			stack.DescribeStackDetails = awscf.StackDetails{Status: awscf.NewStatus("DELETE_FAILED", ""), Tags: map[string]string{"Created by": "AWS CloudFormation Service Broker"}}
+		})
+
+		It("deletes the stack again retaining the resources", func() {
+			err := cfBroker.RetryDelete(instanceID, []string{"Bucket"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stack.DeleteRetainingResourcesStackName).To(Equal(stackName))
+			Expect(stack.DeleteRetainingResourcesResourcesToRetain).To(Equal([]string{"Bucket"}))
+		})
+
+		Context("when the stack deletion did not fail", func() {
 			BeforeEach(func() {
-				stack.DescribeError = errors.New("operation failed")
+				stack.DescribeStackDetails = awscf.StackDetails{Status: awscf.NewStatus("CREATE_COMPLETE", ""), Tags: map[string]string{"Created by": "AWS CloudFormation Service Broker"}}
 			})
 
 			It("returns the proper error", func() {
-				_, err := cfBroker.Bind(instanceID, bindingID, bindDetails)
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(Equal("operation failed"))
+				err := cfBroker.RetryDelete(instanceID, nil)
+				Expect(err).To(Equal(StackStatusError{StackName: stackName, StackStatus: "CREATE_COMPLETE", Action: "deleted again"}))
+				Expect(err.Error()).To(Equal("Stack '" + stackName + "' status is 'CREATE_COMPLETE', it can not be deleted again"))
+				Expect(stack.DeleteRetainingResourcesCalled).To(BeFalse())
 			})
+		})
 
-			Context("when the Stack does not exists", func() {
-				BeforeEach(func() {
-					stack.DescribeError = awscf.ErrStackDoesNotExist
-				})
+		Context("when the stack was not created by the broker", func() {
+			BeforeEach(func() {
+				stack.DescribeStackDetails.Tags = map[string]string{}
+			})
 
-				It("returns the proper error", func() {
-					_, err := cfBroker.Bind(instanceID, bindingID, bindDetails)
-					Expect(err).To(HaveOccurred())
-					Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
-				})
+			It("returns the proper error", func() {
+				err := cfBroker.RetryDelete(instanceID, nil)
+				Expect(err).To(BeAssignableToTypeOf(OwnershipError{}))
+				Expect(stack.DeleteRetainingResourcesCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the Stack does not exists", func() {
+			BeforeEach(func() {
+				stack.DescribeError = awscf.ErrStackDoesNotExist
+			})
+
+			It("returns the proper error", func() {
+				err := cfBroker.RetryDelete(instanceID, nil)
+				Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
 			})
 		})
 	})
 
-	var _ = Describe("Unbind", func() {
-		var (
-			unbindDetails brokerapi.UnbindDetails
-		)
+	var _ = Describe("ContinueUpdateRollback", func() {
+		BeforeEach(func() {
+			stack.DescribeStackDetails = awscf.StackDetails{Status: awscf.NewStatus("UPDATE_ROLLBACK_FAILED", ""), Tags: map[string]string{"Created by": "AWS CloudFormation Service Broker"}}
+		})
+
+		It("continues the rollback skipping the resources", func() {
+			err := cfBroker.ContinueUpdateRollback(instanceID, []string{"Database"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stack.ContinueUpdateRollbackStackName).To(Equal(stackName))
+			Expect(stack.ContinueUpdateRollbackResourcesToSkip).To(Equal([]string{"Database"}))
+		})
+
+		Context("when the stack rollback did not fail", func() {
+			BeforeEach(func() {
+				stack.DescribeStackDetails = awscf.StackDetails{Status: awscf.NewStatus("UPDATE_COMPLETE", ""), Tags: map[string]string{"Created by": "AWS CloudFormation Service Broker"}}
+			})
+
+			It("returns the proper error", func() {
+				err := cfBroker.ContinueUpdateRollback(instanceID, nil)
+				Expect(err).To(Equal(StackStatusError{StackName: stackName, StackStatus: "UPDATE_COMPLETE", Action: "rolled back"}))
+				Expect(stack.ContinueUpdateRollbackCalled).To(BeFalse())
+			})
+		})
+	})
 
+	var _ = Describe("Retag", func() {
 		BeforeEach(func() {
-			unbindDetails = brokerapi.UnbindDetails{
-				ServiceID: "Service-1",
-				PlanID:    "Plan-1",
+			stack.DescribeStackDetails = awscf.StackDetails{
+				Status: awscf.NewStatus("UPDATE_COMPLETE", ""),
+				Tags:   map[string]string{"Created by": "AWS CloudFormation Service Broker", "Cost Center": "old"},
 			}
 		})
 
-		It("does not return error", func() {
-			err := cfBroker.Unbind(instanceID, bindingID, unbindDetails)
+		It("merges the tags into the stack tags", func() {
+			err := cfBroker.Retag(instanceID, map[string]string{"Cost Center": "new"})
 			Expect(err).ToNot(HaveOccurred())
+			Expect(stack.UpdateTagsStackName).To(Equal(stackName))
+			Expect(stack.UpdateTagsTags).To(Equal(map[string]string{"Created by": "AWS CloudFormation Service Broker", "Cost Center": "new"}))
 		})
-	})
 
-	var _ = Describe("LastOperation", func() {
-		var (
-			stackStatus                 string
-			lastOperationState          string
-			properLastOperationResponse brokerapi.LastOperationResponse
-		)
+		Context("when the stack is not usable", func() {
+			BeforeEach(func() {
+				stack.DescribeStackDetails = awscf.StackDetails{Status: awscf.NewStatus("UPDATE_IN_PROGRESS", ""), Tags: map[string]string{"Created by": "AWS CloudFormation Service Broker"}}
+			})
 
-		JustBeforeEach(func() {
-			stack.DescribeStackDetails = awscf.StackDetails{
-				StackName:   stackName,
-				StackStatus: stackStatus,
+			It("returns the proper error", func() {
+				err := cfBroker.Retag(instanceID, map[string]string{"Cost Center": "new"})
+				Expect(err).To(Equal(StackStatusError{StackName: stackName, StackStatus: "UPDATE_IN_PROGRESS", Action: "retagged"}))
+				Expect(stack.UpdateTagsCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the stack was tagged before the broker had an ID", func() {
+			BeforeEach(func() {
+				brokerID = "broker-id"
+			})
+
+			It("adopts the stack when it is tagged with the broker ID", func() {
+				err := cfBroker.Retag(instanceID, map[string]string{"Broker ID": "broker-id"})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stack.UpdateTagsTags["Broker ID"]).To(Equal("broker-id"))
+			})
+
+			It("returns the proper error for other tags", func() {
+				err := cfBroker.Retag(instanceID, map[string]string{"Cost Center": "new"})
+				Expect(err).To(Equal(OwnershipError{StackName: stackName, Reason: "it carries no 'Broker ID' tag"}))
+				Expect(stack.UpdateTagsCalled).To(BeFalse())
+			})
+		})
+
+		Context("when updating the tags fails", func() {
+			BeforeEach(func() {
+				stack.UpdateTagsError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				err := cfBroker.Retag(instanceID, map[string]string{"Cost Center": "new"})
+				Expect(err).To(MatchError("operation failed"))
+			})
+		})
+	})
+
+	var _ = Describe("ImportInstance", func() {
+		BeforeEach(func() {
+			stack.DescribeStackDetailsByName = map[string]awscf.StackDetails{
+				"legacy-stack": awscf.StackDetails{
+					StackName: "legacy-stack",
+					StackID:   "legacy-stack-id",
+					Status:    awscf.NewStatus("UPDATE_COMPLETE", ""),
+					Tags:      map[string]string{"Team": "data"},
+				},
 			}
+		})
 
-			properLastOperationResponse = brokerapi.LastOperationResponse{
-				State:       lastOperationState,
-				Description: "Stack '" + stackName + "' status is '" + stackStatus + "'",
+		It("tags the Stack as a service instance of the broker", func() {
+			err := cfBroker.ImportInstance(instanceID, "legacy-stack", "Service-1", "Plan-1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stack.UpdateTagsStackName).To(Equal("legacy-stack"))
+			Expect(stack.UpdateTagsTags["Team"]).To(Equal("data"))
+			Expect(stack.UpdateTagsTags["Imported by"]).To(Equal("AWS CloudFormation Service Broker"))
+			Expect(stack.UpdateTagsTags).To(HaveKey("Imported at"))
+			Expect(stack.UpdateTagsTags["Instance ID"]).To(Equal(instanceID))
+			Expect(stack.UpdateTagsTags["Service ID"]).To(Equal("Service-1"))
+			Expect(stack.UpdateTagsTags["Plan ID"]).To(Equal("Plan-1"))
+			Expect(stack.UpdateTagsTags).ToNot(HaveKey("Created by"))
+		})
+
+		It("tracks the Stack as the instance Stack", func() {
+			err := cfBroker.ImportInstance(instanceID, "legacy-stack", "Service-1", "Plan-1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfBroker.StackName(instanceID)).To(Equal("legacy-stack"))
+
+			_, err = cfBroker.Instance(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stack.DescribeStackName).To(Equal("legacy-stack-id"))
+		})
+
+		It("finds the imported Stack among the instances", func() {
+			stack.DescribeAllStackDetails = []awscf.StackDetails{
+				awscf.StackDetails{
+					StackName: "legacy-stack",
+					StackID:   "legacy-stack-id",
+					Tags:      map[string]string{"Imported by": "AWS CloudFormation Service Broker", "Instance ID": instanceID},
+				},
+				awscf.StackDetails{StackName: "other-stack", Tags: map[string]string{"Instance ID": "other-instance-id"}},
 			}
+
+			instances, err := cfBroker.Instances()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(instances).To(HaveLen(1))
+			Expect(instances[0].InstanceID).To(Equal(instanceID))
+			Expect(instances[0].StackName).To(Equal("legacy-stack"))
+			Expect(cfBroker.StackName(instanceID)).To(Equal("legacy-stack"))
 		})
 
-		Context("when describing the Stack fails", func() {
+		Context("when the Service is not found", func() {
+			It("returns the proper error", func() {
+				err := cfBroker.ImportInstance(instanceID, "legacy-stack", "Service-3", "Plan-1")
+				Expect(err).To(Equal(ImportError{StackName: "legacy-stack", Reason: "service 'Service-3' is not in the catalog"}))
+				Expect(stack.UpdateTagsCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the Plan belongs to another Service", func() {
+			It("returns the proper error", func() {
+				err := cfBroker.ImportInstance(instanceID, "legacy-stack", "Service-1", "Plan-2")
+				Expect(err).To(Equal(ImportError{StackName: "legacy-stack", Reason: "service 'Service-1' has no plan 'Plan-2'"}))
+				Expect(stack.UpdateTagsCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the Stack does not exist", func() {
 			BeforeEach(func() {
-				stack.DescribeError = errors.New("operation failed")
+				stack.DescribeError = awscf.ErrStackDoesNotExist
 			})
 
 			It("returns the proper error", func() {
-				_, err := cfBroker.LastOperation(instanceID)
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(Equal("operation failed"))
+				err := cfBroker.ImportInstance(instanceID, "legacy-stack", "Service-1", "Plan-1")
+				Expect(err).To(Equal(ImportError{StackName: "legacy-stack", Reason: "it does not exist"}))
 			})
+		})
 
-			Context("when the Stack does not exists", func() {
-				BeforeEach(func() {
-					stack.DescribeError = awscf.ErrStackDoesNotExist
-				})
+		Context("when the Stack is already managed by the broker", func() {
+			BeforeEach(func() {
+				stack.DescribeStackDetailsByName["legacy-stack"] = awscf.StackDetails{
+					Status: awscf.NewStatus("CREATE_COMPLETE", ""),
+					Tags:   map[string]string{"Created by": "AWS CloudFormation Service Broker"},
+				}
+			})
 
-				It("returns the proper error", func() {
-					_, err := cfBroker.LastOperation(instanceID)
-					Expect(err).To(HaveOccurred())
-					Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
-				})
+			It("returns the proper error", func() {
+				err := cfBroker.ImportInstance(instanceID, "legacy-stack", "Service-1", "Plan-1")
+				Expect(err).To(Equal(ImportError{StackName: "legacy-stack", Reason: "it is already managed by the broker"}))
+				Expect(stack.UpdateTagsCalled).To(BeFalse())
 			})
 		})
 
-		Context("when last operation is still in progress", func() {
+		Context("when the Stack is managed by another broker", func() {
 			BeforeEach(func() {
-				stackStatus = awscf.StatusInProgress
-				lastOperationState = brokerapi.LastOperationInProgress
+				stack.DescribeStackDetailsByName["legacy-stack"] = awscf.StackDetails{
+					Status: awscf.NewStatus("CREATE_COMPLETE", ""),
+					Tags:   map[string]string{"Created by": "AWS CloudFormation Service Broker", "Broker ID": "other-broker-id"},
+				}
 			})
 
-			It("returns the proper LastOperationResponse", func() {
-				lastOperationResponse, err := cfBroker.LastOperation(instanceID)
-				Expect(err).ToNot(HaveOccurred())
-				Expect(lastOperationResponse).To(Equal(properLastOperationResponse))
+			It("returns the proper error", func() {
+				err := cfBroker.ImportInstance(instanceID, "legacy-stack", "Service-1", "Plan-1")
+				Expect(err).To(Equal(ImportError{StackName: "legacy-stack", Reason: "it was created by broker 'other-broker-id'"}))
+				Expect(stack.UpdateTagsCalled).To(BeFalse())
 			})
 		})
 
-		Context("when last operation failed", func() {
+		Context("when the Stack is tagged with the ID of another broker", func() {
 			BeforeEach(func() {
-				stackStatus = awscf.StatusFailed
-				lastOperationState = brokerapi.LastOperationFailed
+				stack.DescribeStackDetailsByName["legacy-stack"] = awscf.StackDetails{
+					Status: awscf.NewStatus("CREATE_COMPLETE", ""),
+					Tags:   map[string]string{"Broker ID": "other-broker-id"},
+				}
 			})
 
-			It("returns the proper LastOperationResponse", func() {
-				lastOperationResponse, err := cfBroker.LastOperation(instanceID)
-				Expect(err).ToNot(HaveOccurred())
-				Expect(lastOperationResponse).To(Equal(properLastOperationResponse))
+			It("returns the proper error", func() {
+				err := cfBroker.ImportInstance(instanceID, "legacy-stack", "Service-1", "Plan-1")
+				Expect(err).To(Equal(ImportError{StackName: "legacy-stack", Reason: "it was created by broker 'other-broker-id'"}))
+				Expect(stack.UpdateTagsCalled).To(BeFalse())
 			})
 		})
 
-		Context("when last operation succeeded", func() {
+		Context("when the Stack is not usable", func() {
 			BeforeEach(func() {
-				stackStatus = awscf.StatusSucceeded
-				lastOperationState = brokerapi.LastOperationSucceeded
+				stack.DescribeStackDetailsByName["legacy-stack"] = awscf.StackDetails{Status: awscf.NewStatus("UPDATE_IN_PROGRESS", "")}
 			})
 
-			It("returns the proper LastOperationResponse", func() {
-				lastOperationResponse, err := cfBroker.LastOperation(instanceID)
-				Expect(err).ToNot(HaveOccurred())
-				Expect(lastOperationResponse).To(Equal(properLastOperationResponse))
+			It("returns the proper error", func() {
+				err := cfBroker.ImportInstance(instanceID, "legacy-stack", "Service-1", "Plan-1")
+				Expect(err).To(Equal(StackStatusError{StackName: "legacy-stack", StackStatus: "UPDATE_IN_PROGRESS", Action: "imported"}))
+				Expect(stack.UpdateTagsCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the instance already has a Stack", func() {
+			BeforeEach(func() {
+				stack.DescribeStackDetails = awscf.StackDetails{
+					Status: awscf.NewStatus("CREATE_COMPLETE", ""),
+					Tags:   map[string]string{"Created by": "AWS CloudFormation Service Broker"},
+				}
+			})
+
+			It("returns the proper error", func() {
+				err := cfBroker.ImportInstance(instanceID, "legacy-stack", "Service-1", "Plan-1")
+				Expect(err).To(Equal(brokerapi.ErrInstanceAlreadyExists))
+				Expect(stack.UpdateTagsCalled).To(BeFalse())
+			})
+		})
+
+		Context("when updating the tags fails", func() {
+			BeforeEach(func() {
+				stack.UpdateTagsError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				err := cfBroker.ImportInstance(instanceID, "legacy-stack", "Service-1", "Plan-1")
+				Expect(err).To(MatchError("operation failed"))
+				Expect(cfBroker.StackName(instanceID)).To(Equal(stackName))
 			})
 		})
 	})
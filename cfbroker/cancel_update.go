@@ -0,0 +1,104 @@
+package cfbroker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+)
+
+const operatorCancelReason = "cancelled by an operator"
+
+// CancelUpdate cancels the update in progress of the instance stack, which
+// makes CloudFormation roll the stack back to its previous configuration.
+func (b *CloudFormationBroker) CancelUpdate(instanceID string) error {
+	b.logger.Info("cancel-update", lager.Data{
+		instanceIDLogKey: instanceID,
+	})
+
+	defer b.locks.Lock(instanceID)()
+
+	if _, err := b.ownedStack(instanceID, ""); err != nil {
+		return err
+	}
+
+	stackName := b.stackName(instanceID)
+	if err := b.stack.CancelUpdate(stackName); err != nil {
+		if err == awscf.ErrStackDoesNotExist {
+			return brokerapi.ErrInstanceDoesNotExist
+		}
+		return err
+	}
+
+	b.operations.SetCancelReason(instanceID, fmt.Sprintf("Cancelling the update of stack '%s'", stackName), operatorCancelReason)
+
+	return nil
+}
+
+// timeOutUpdate cancels the update of the stack if it has been in progress
+// for longer than the plan update timeout.
+func (b *CloudFormationBroker) timeOutUpdate(instanceID string, operation trackedOperation, stackDetails awscf.StackDetails) trackedOperation {
+	if stackDetails.Status.Raw != cloudformation.StackStatusUpdateInProgress {
+		return operation
+	}
+
+	if time.Since(stackDetails.LastUpdatedTime) <= operation.UpdateTimeout {
+		return operation
+	}
+
+	stackName := b.stackName(instanceID)
+	cancelReason := fmt.Sprintf("timed out after %d minutes", int64(operation.UpdateTimeout/time.Minute))
+
+	b.logger.Info("update-timed-out", lager.Data{
+		instanceIDLogKey: instanceID,
+		"update-timeout": operation.UpdateTimeout.String(),
+	})
+
+	if err := b.stack.CancelUpdate(stackName); err != nil {
+		// The update may have completed in the meantime, so the stack status
+		// is checked again on the next poll
+		b.logger.Error("cancel-update-error", err)
+		return operation
+	}
+
+	b.operations.SetCancelReason(instanceID, fmt.Sprintf("Cancelling the update of stack '%s'", stackName), cancelReason)
+	operation, _ = b.operations.Get(instanceID)
+
+	return operation
+}
+
+func (b *CloudFormationBroker) cancelledUpdateResponse(instanceID string, operation trackedOperation, stackDetails awscf.StackDetails) brokerapi.LastOperationResponse {
+	stackName := b.stackName(instanceID)
+
+	if stackDetails.StackStatus == awscf.StatusInProgress {
+		return brokerapi.LastOperationResponse{
+			State:       brokerapi.LastOperationInProgress,
+			Description: fmt.Sprintf("Update of stack '%s' was cancelled (%s), stack status is '%s'", stackName, operation.CancelReason, stackDetails.Status.Raw),
+		}
+	}
+
+	b.operations.Clear(instanceID)
+
+	switch stackDetails.Status.Raw {
+	case cloudformation.StackStatusUpdateRollbackComplete:
+		return brokerapi.LastOperationResponse{
+			State:       brokerapi.LastOperationFailed,
+			Description: fmt.Sprintf("Update of stack '%s' was cancelled (%s) and rolled back to its previous configuration", stackName, operation.CancelReason),
+		}
+	case cloudformation.StackStatusUpdateComplete:
+		// The update completed before it could be cancelled
+		return brokerapi.LastOperationResponse{
+			State:       brokerapi.LastOperationSucceeded,
+			Description: fmt.Sprintf("Stack '%s' status is '%s'", stackName, stackDetails.StackStatus),
+		}
+	default:
+		return brokerapi.LastOperationResponse{
+			State:       brokerapi.LastOperationFailed,
+			Description: fmt.Sprintf("Update of stack '%s' was cancelled (%s), stack status is '%s': %s", stackName, operation.CancelReason, stackDetails.Status.Raw, stackDetails.Status.Reason),
+		}
+	}
+}
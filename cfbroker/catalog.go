@@ -60,15 +60,35 @@ type Cost struct {
 }
 
 type CloudFormationProperties struct {
-	Capabilities     []string          `json:"capabilities,omitempty"`
-	DisableRollback  bool              `json:"disable_rollback,omitempty"`
-	NotificationARNs []string          `json:"notification_arns,omitempty"`
-	OnFailure        string            `json:"on_failure,omitempty"`
-	Parameters       map[string]string `json:"parameters,omitempty"`
-	ResourceTypes    []string          `json:"resource_types,omitempty"`
-	StackPolicyURL   string            `json:"stack_policy_url,omitempty"`
-	TemplateURL      string            `json:"template_url"`
-	TimeoutInMinutes int64             `json:"timeout_in_minutes,omitempty"`
+	Capabilities                []string          `json:"capabilities,omitempty"`
+	DisableRollback             bool              `json:"disable_rollback,omitempty"`
+	NotificationARNs            []string          `json:"notification_arns,omitempty"`
+	OnFailure                   string            `json:"on_failure,omitempty"`
+	Parameters                  map[string]string `json:"parameters,omitempty"`
+	ResourceTypes               []string          `json:"resource_types,omitempty"`
+	StackPolicyURL              string            `json:"stack_policy_url,omitempty"`
+	TemplateURL                 string            `json:"template_url"`
+	TimeoutInMinutes            int64             `json:"timeout_in_minutes,omitempty"`
+	PreDeleteHooks              []PreDeleteHook   `json:"pre_delete_hooks,omitempty"`
+	UpdateTimeoutInMinutes      int64             `json:"update_timeout_in_minutes,omitempty"`
+	SynchronousTimeoutInSeconds int64             `json:"synchronous_timeout_in_seconds,omitempty"`
+	SensitiveParameters         []string          `json:"sensitive_parameters,omitempty"`
+	SensitiveOutputs            []string          `json:"sensitive_outputs,omitempty"`
+}
+
+// MaxSynchronousTimeoutInSeconds keeps synchronous requests, which also
+// create, update or delete the stack and poll its status, within the 60
+// seconds platforms wait for a broker response.
+const MaxSynchronousTimeoutInSeconds = 45
+
+const PreDeleteHookEmptyS3Bucket = "empty_s3_bucket"
+const PreDeleteHookDeleteECRImages = "delete_ecr_images"
+const PreDeleteHookWebhook = "webhook"
+
+type PreDeleteHook struct {
+	Type   string `json:"type"`
+	Output string `json:"output,omitempty"`
+	URL    string `json:"url,omitempty"`
 }
 
 func (c Catalog) Validate() error {
@@ -103,6 +123,38 @@ func (c Catalog) FindServicePlan(planID string) (plan ServicePlan, found bool) {
 	return plan, false
 }
 
+// SensitiveNames returns the parameters and outputs flagged as sensitive by
+// any plan of the catalog.
+func (c Catalog) SensitiveNames() []string {
+	sensitiveNames := []string{}
+	for _, service := range c.Services {
+		for _, plan := range service.Plans {
+			sensitiveNames = append(sensitiveNames, plan.CloudFormationProperties.SensitiveParameters...)
+			sensitiveNames = append(sensitiveNames, plan.CloudFormationProperties.SensitiveOutputs...)
+		}
+	}
+
+	return sensitiveNames
+}
+
+// TemplateURLs returns the distinct templates used by the plans of the
+// catalog.
+func (c Catalog) TemplateURLs() []string {
+	templateURLs := []string{}
+	seen := make(map[string]bool)
+	for _, service := range c.Services {
+		for _, plan := range service.Plans {
+			templateURL := plan.CloudFormationProperties.TemplateURL
+			if !seen[templateURL] {
+				seen[templateURL] = true
+				templateURLs = append(templateURLs, templateURL)
+			}
+		}
+	}
+
+	return templateURLs
+}
+
 func (s Service) Validate() error {
 	if s.ID == "" {
 		return fmt.Errorf("Must provide a non-empty ID (%+v)", s)
@@ -158,5 +210,36 @@ func (cp CloudFormationProperties) Validate() error {
 		return fmt.Errorf("Must provide a non-empty TemplateURL (%+v)", cp)
 	}
 
+	if cp.SynchronousTimeoutInSeconds < 0 {
+		return fmt.Errorf("Must provide a non-negative SynchronousTimeoutInSeconds (%+v)", cp)
+	}
+
+	if cp.SynchronousTimeoutInSeconds > MaxSynchronousTimeoutInSeconds {
+		return fmt.Errorf("Must provide a SynchronousTimeoutInSeconds of at most %d (%+v)", MaxSynchronousTimeoutInSeconds, cp)
+	}
+
+	for _, preDeleteHook := range cp.PreDeleteHooks {
+		if err := preDeleteHook.Validate(); err != nil {
+			return fmt.Errorf("Validating PreDeleteHooks configuration: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func (h PreDeleteHook) Validate() error {
+	switch h.Type {
+	case PreDeleteHookEmptyS3Bucket, PreDeleteHookDeleteECRImages:
+		if h.Output == "" {
+			return fmt.Errorf("Must provide a non-empty Output (%+v)", h)
+		}
+	case PreDeleteHookWebhook:
+		if h.URL == "" {
+			return fmt.Errorf("Must provide a non-empty URL (%+v)", h)
+		}
+	default:
+		return fmt.Errorf("PreDeleteHook Type '%s' not supported", h.Type)
+	}
+
 	return nil
 }
@@ -77,6 +77,45 @@ var _ = Describe("Catalog", func() {
 			Expect(found).To(BeFalse())
 		})
 	})
+
+	Describe("SensitiveNames", func() {
+		BeforeEach(func() {
+			catalog = Catalog{
+				Services: []Service{
+					Service{ID: "Service-1", Plans: []ServicePlan{
+						ServicePlan{ID: "Plan-1", CloudFormationProperties: CloudFormationProperties{SensitiveParameters: []string{"DBUsername"}}},
+					}},
+					Service{ID: "Service-2", Plans: []ServicePlan{
+						ServicePlan{ID: "Plan-2", CloudFormationProperties: CloudFormationProperties{SensitiveOutputs: []string{"ConnectionString"}}},
+					}},
+				},
+			}
+		})
+
+		It("returns the sensitive parameters and outputs of every plan", func() {
+			Expect(catalog.SensitiveNames()).To(Equal([]string{"DBUsername", "ConnectionString"}))
+		})
+	})
+
+	Describe("TemplateURLs", func() {
+		BeforeEach(func() {
+			catalog = Catalog{
+				Services: []Service{
+					Service{ID: "Service-1", Plans: []ServicePlan{
+						ServicePlan{ID: "Plan-1", CloudFormationProperties: CloudFormationProperties{TemplateURL: "template-1"}},
+						ServicePlan{ID: "Plan-2", CloudFormationProperties: CloudFormationProperties{TemplateURL: "template-2"}},
+					}},
+					Service{ID: "Service-2", Plans: []ServicePlan{
+						ServicePlan{ID: "Plan-3", CloudFormationProperties: CloudFormationProperties{TemplateURL: "template-1"}},
+					}},
+				},
+			}
+		})
+
+		It("returns the distinct templates of the plans", func() {
+			Expect(catalog.TemplateURLs()).To(Equal([]string{"template-1", "template-2"}))
+		})
+	})
 })
 
 var _ = Describe("Service", func() {
@@ -229,5 +268,90 @@ var _ = Describe("CloudFormationProperties", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty TemplateURL"))
 		})
+
+		It("returns error if SynchronousTimeoutInSeconds is negative", func() {
+			cloudformationProperties.SynchronousTimeoutInSeconds = -1
+
+			err := cloudformationProperties.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide a non-negative SynchronousTimeoutInSeconds"))
+		})
+
+		It("returns error if SynchronousTimeoutInSeconds exceeds the platform request timeout", func() {
+			cloudformationProperties.SynchronousTimeoutInSeconds = 46
+
+			err := cloudformationProperties.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide a SynchronousTimeoutInSeconds of at most 45"))
+		})
+
+		It("returns error if PreDeleteHooks are not valid", func() {
+			cloudformationProperties.PreDeleteHooks = []PreDeleteHook{PreDeleteHook{}}
+
+			err := cloudformationProperties.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Validating PreDeleteHooks configuration"))
+		})
+	})
+})
+
+var _ = Describe("PreDeleteHook", func() {
+	var (
+		preDeleteHook PreDeleteHook
+	)
+
+	Describe("Validate", func() {
+		It("does not return error if an empty_s3_bucket hook is valid", func() {
+			preDeleteHook = PreDeleteHook{Type: "empty_s3_bucket", Output: "BucketName"}
+
+			err := preDeleteHook.Validate()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("does not return error if a delete_ecr_images hook is valid", func() {
+			preDeleteHook = PreDeleteHook{Type: "delete_ecr_images", Output: "RepositoryName"}
+
+			err := preDeleteHook.Validate()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("does not return error if a webhook hook is valid", func() {
+			preDeleteHook = PreDeleteHook{Type: "webhook", URL: "https://example.com/hook"}
+
+			err := preDeleteHook.Validate()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns error if Type is not supported", func() {
+			preDeleteHook = PreDeleteHook{Type: "unknown"}
+
+			err := preDeleteHook.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("PreDeleteHook Type 'unknown' not supported"))
+		})
+
+		It("returns error if Output is empty for an empty_s3_bucket hook", func() {
+			preDeleteHook = PreDeleteHook{Type: "empty_s3_bucket"}
+
+			err := preDeleteHook.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty Output"))
+		})
+
+		It("returns error if Output is empty for a delete_ecr_images hook", func() {
+			preDeleteHook = PreDeleteHook{Type: "delete_ecr_images"}
+
+			err := preDeleteHook.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty Output"))
+		})
+
+		It("returns error if URL is empty for a webhook hook", func() {
+			preDeleteHook = PreDeleteHook{Type: "webhook"}
+
+			err := preDeleteHook.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty URL"))
+		})
 	})
 })
@@ -0,0 +1,95 @@
+package cfbroker
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/frodenas/brokerapi"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+)
+
+// ConcurrencyError is returned when a service instance is requested to
+// change, or to be bound, while an operation on it is still in progress.
+type ConcurrencyError struct {
+	StackName string
+	Operation string
+}
+
+func (e ConcurrencyError) Error() string {
+	return fmt.Sprintf("Stack '%s' has an operation in progress (%s), retry once it completes", e.StackName, e.Operation)
+}
+
+// instanceLocks serialises the requests on the same instance, so the status
+// checked by one request is not changed by another before it acts on it.
+type instanceLocks struct {
+	mutex sync.Mutex
+	locks map[string]*instanceLock
+}
+
+type instanceLock struct {
+	sync.Mutex
+	holders int
+}
+
+func newInstanceLocks() *instanceLocks {
+	return &instanceLocks{
+		locks: make(map[string]*instanceLock),
+	}
+}
+
+// Lock waits for the lock of an instance and returns the function that
+// releases it.
+func (l *instanceLocks) Lock(instanceID string) func() {
+	l.mutex.Lock()
+	lock, ok := l.locks[instanceID]
+	if !ok {
+		lock = &instanceLock{}
+		l.locks[instanceID] = lock
+	}
+	lock.holders++
+	l.mutex.Unlock()
+
+	lock.Lock()
+
+	return func() {
+		lock.Unlock()
+
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+
+		lock.holders--
+		if lock.holders == 0 {
+			delete(l.locks, instanceID)
+		}
+	}
+}
+
+// Unlocked releases the lock of an instance, held by the caller, while fn
+// runs, and waits for it again once fn returns.
+func (l *instanceLocks) Unlocked(instanceID string, fn func()) {
+	l.mutex.Lock()
+	lock := l.locks[instanceID]
+	l.mutex.Unlock()
+
+	lock.Unlock()
+	defer lock.Lock()
+
+	fn()
+}
+
+// checkConcurrentOperation fails when the stack of an instance is in
+// progress, or when the broker is still working on the instance, such as
+// running its pre-delete hooks. Operations watching the stack are only in
+// progress as long as the stack is.
+func (b *CloudFormationBroker) checkConcurrentOperation(instanceID string, stackDetails awscf.StackDetails) error {
+	if operation, ok := b.operations.Get(instanceID); ok && operation.State == brokerapi.LastOperationInProgress && !operation.watchesStack() {
+		return ConcurrencyError{StackName: b.stackName(instanceID), Operation: operation.Description}
+	}
+
+	if stackDetails.Status.Phase == awscf.PhaseInProgress {
+		return ConcurrencyError{StackName: b.stackName(instanceID), Operation: fmt.Sprintf("status is '%s'", stackDetails.Status.Raw)}
+	}
+
+	return nil
+}
@@ -8,6 +8,8 @@ import (
 type Config struct {
 	Region                       string  `json:"region"`
 	CloudFormationPrefix         string  `json:"cloudformation_prefix"`
+	BrokerID                     string  `json:"broker_id"`
+	StackNameTemplate            string  `json:"stack_name_template"`
 	AllowUserProvisionParameters bool    `json:"allow_user_provision_parameters"`
 	AllowUserUpdateParameters    bool    `json:"allow_user_update_parameters"`
 	Catalog                      Catalog `json:"catalog"`
@@ -22,9 +24,21 @@ func (c Config) Validate() error {
 		return errors.New("Must provide a non-empty CloudFormationPrefix")
 	}
 
+	if err := validateStackNameTemplate(c.stackNameTemplate(), c.CloudFormationPrefix); err != nil {
+		return err
+	}
+
 	if err := c.Catalog.Validate(); err != nil {
 		return fmt.Errorf("Validating Catalog configuration: %s", err)
 	}
 
 	return nil
 }
+
+func (c Config) stackNameTemplate() string {
+	if c.StackNameTemplate == "" {
+		return DefaultStackNameTemplate
+	}
+
+	return c.StackNameTemplate
+}
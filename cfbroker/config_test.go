@@ -14,6 +14,7 @@ var _ = Describe("Config", func() {
 		validConfig = Config{
 			Region:               "cloudformation-region",
 			CloudFormationPrefix: "cf",
+			BrokerID:             "broker-id",
 			Catalog: Catalog{
 				[]Service{
 					Service{
@@ -52,6 +53,66 @@ var _ = Describe("Config", func() {
 			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty CloudFormationPrefix"))
 		})
 
+		It("returns error if CloudFormationPrefix is not a valid stack name", func() {
+			config.CloudFormationPrefix = "1_cf"
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("CloudFormationPrefix must start with a letter"))
+		})
+
+		It("does not return error if BrokerID is empty", func() {
+			config.BrokerID = ""
+
+			err := config.Validate()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("does not return error if StackNameTemplate is valid", func() {
+			config.StackNameTemplate = "{{prefix}}-{{service}}-{{plan}}-{{instance_id}}"
+
+			err := config.Validate()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns error if StackNameTemplate does not start with the prefix", func() {
+			config.StackNameTemplate = "{{instance_id}}-{{prefix}}"
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("StackNameTemplate must start with '{{prefix}}-'"))
+		})
+
+		It("returns error if StackNameTemplate does not contain the instance ID", func() {
+			config.StackNameTemplate = "{{prefix}}-{{service}}"
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("StackNameTemplate must contain '{{instance_id}}'"))
+		})
+
+		It("returns error if StackNameTemplate has an unknown placeholder", func() {
+			config.StackNameTemplate = "{{prefix}}-{{org}}-{{instance_id}}"
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("StackNameTemplate contains an unknown placeholder"))
+		})
+
+		It("returns error if StackNameTemplate has invalid characters", func() {
+			config.StackNameTemplate = "{{prefix}}_{{instance_id}}"
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("StackNameTemplate must start with '{{prefix}}-'"))
+
+			config.StackNameTemplate = "{{prefix}}-cf_{{instance_id}}"
+
+			err = config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("StackNameTemplate must contain only letters, digits, hyphens and placeholders"))
+		})
+
 		It("returns error if Catalog is not valid", func() {
 			config.Catalog = Catalog{
 				[]Service{
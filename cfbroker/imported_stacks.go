@@ -0,0 +1,108 @@
+package cfbroker
+
+import (
+	"fmt"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+)
+
+// ImportError is returned when a stack can not be adopted as a service
+// instance.
+type ImportError struct {
+	StackName string
+	Reason    string
+}
+
+func (e ImportError) Error() string {
+	return fmt.Sprintf("Stack '%s' can not be imported, %s", e.StackName, e.Reason)
+}
+
+// ImportInstance adopts a stack created outside of the broker as a service
+// instance of the given service plan. The stack is tagged like the stacks
+// the broker creates, with the instance ID it now belongs to, so it can be
+// bound, updated and deleted like any other service instance. Stacks managed
+// by another service broker, or tagged with the ID of another broker, are
+// refused.
+func (b *CloudFormationBroker) ImportInstance(instanceID string, stackName string, serviceID string, planID string) error {
+	service, ok := b.catalog.FindService(serviceID)
+	if !ok {
+		return ImportError{StackName: stackName, Reason: fmt.Sprintf("service '%s' is not in the catalog", serviceID)}
+	}
+
+	if !servicePlanOf(service, planID) {
+		return ImportError{StackName: stackName, Reason: fmt.Sprintf("service '%s' has no plan '%s'", serviceID, planID)}
+	}
+
+	defer b.locks.Lock(instanceID)()
+
+	stackDetails, err := b.stack.Describe(stackName)
+	if err != nil {
+		if err == awscf.ErrStackDoesNotExist {
+			return ImportError{StackName: stackName, Reason: "it does not exist"}
+		}
+		return err
+	}
+
+	reason := b.ownershipMismatch("", stackDetails)
+	if reason == "" {
+		return ImportError{StackName: stackName, Reason: "it is already managed by the broker"}
+	}
+	if reason != notCreatedByBrokerReason {
+		return ImportError{StackName: stackName, Reason: reason}
+	}
+	if brokerID := stackDetails.Tags[brokerIDTagKey]; brokerID != "" && brokerID != b.brokerID {
+		return ImportError{StackName: stackName, Reason: fmt.Sprintf("it was created by broker '%s'", brokerID)}
+	}
+
+	if !stackDetails.Status.Usable() {
+		return StackStatusError{StackName: stackName, StackStatus: stackDetails.Status.Raw, Action: "imported"}
+	}
+
+	existingStackDetails, err := b.describeInstanceStack(instanceID)
+	if err != nil && err != brokerapi.ErrInstanceDoesNotExist {
+		return err
+	}
+	if err == nil && b.createdByBroker(existingStackDetails) {
+		return brokerapi.ErrInstanceAlreadyExists
+	}
+
+	stackTags := make(map[string]string)
+	for key, value := range stackDetails.Tags {
+		stackTags[key] = value
+	}
+	for key, value := range b.stackTags("Imported", instanceID, serviceID, planID, "", "") {
+		stackTags[key] = value
+	}
+
+	b.logger.Info("import-instance", lager.Data{
+		instanceIDLogKey: instanceID,
+		"stack-name":     stackName,
+		"service-id":     serviceID,
+		"plan-id":        planID,
+	})
+
+	if err := b.stack.UpdateTags(stackName, stackTags); err != nil {
+		return err
+	}
+
+	b.operations.Clear(instanceID)
+	b.stacks.Forget(instanceID)
+	b.issuedOperations.Forget(instanceID)
+	b.stacks.SetName(instanceID, stackDetails.StackName)
+	b.stacks.SetID(instanceID, stackDetails.StackID)
+
+	return nil
+}
+
+func servicePlanOf(service Service, planID string) bool {
+	for _, servicePlan := range service.Plans {
+		if servicePlan.ID == planID {
+			return true
+		}
+	}
+
+	return false
+}
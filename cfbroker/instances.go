@@ -0,0 +1,303 @@
+package cfbroker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/frodenas/brokerapi"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+)
+
+// InstanceSummary describes a service instance and its stack.
+type InstanceSummary struct {
+	InstanceID   string
+	StackName    string
+	StackID      string
+	StackStatus  awscf.Status
+	CreationTime time.Time
+}
+
+// InstanceDetails holds everything an operator needs to look into a
+// service instance stack.
+type InstanceDetails struct {
+	InstanceID      string
+	StackName       string
+	StackID         string
+	StackStatus     awscf.Status
+	Parameters      map[string]string
+	Outputs         map[string]string
+	Tags            map[string]string
+	CreationTime    time.Time
+	LastUpdatedTime time.Time
+	Events          []awscf.StackEvent
+	Template        string
+}
+
+// OwnedInstance is a service instance whose stack was created by the
+// broker.
+type OwnedInstance struct {
+	InstanceSummary
+	StackDetails awscf.StackDetails
+}
+
+// Imported tells whether the stack was imported by the broker rather than
+// created by it.
+func (i OwnedInstance) Imported() bool {
+	return i.StackDetails.Tags[importedByTagKey] == brokerTagValue
+}
+
+// OrphanedInstance is a service instance stack the broker created but can
+// no longer manage, along with the reason why.
+type OrphanedInstance struct {
+	InstanceSummary
+	Reason string
+}
+
+// InstancesByStackStatus counts the service instances of the broker by the
+// status of their stack.
+func (b *CloudFormationBroker) InstancesByStackStatus() (map[string]int, error) {
+	stackSummaries, err := b.stack.List()
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make(map[string]int)
+	for _, stackSummary := range stackSummaries {
+		if strings.HasPrefix(stackSummary.StackName, b.cloudformationPrefix+"-") {
+			instances[stackSummary.Status.Raw]++
+		}
+	}
+
+	return instances, nil
+}
+
+// StackName returns the name of the stack of a service instance.
+func (b *CloudFormationBroker) StackName(instanceID string) (string, error) {
+	return b.resolveStackName(instanceID)
+}
+
+// TrackedStackID returns the ID of the stack of a service instance if the
+// broker already knows it, without describing the stack.
+func (b *CloudFormationBroker) TrackedStackID(instanceID string) (string, bool) {
+	stack, ok := b.stacks.Get(instanceID)
+	if !ok || stack.ID == "" {
+		return "", false
+	}
+
+	return stack.ID, true
+}
+
+// StackID returns the ID of the stack of a service instance.
+func (b *CloudFormationBroker) StackID(instanceID string) (string, error) {
+	stackDetails, err := b.describeInstanceStack(instanceID)
+	if err != nil {
+		return "", err
+	}
+
+	return stackDetails.StackID, nil
+}
+
+// Instances returns the service instances of the broker, found by the
+// prefix of their stack name or imported.
+func (b *CloudFormationBroker) Instances() ([]InstanceSummary, error) {
+	instanceStacks, err := b.instanceStacks()
+	if err != nil {
+		return nil, err
+	}
+
+	instances := []InstanceSummary{}
+	for _, instanceStack := range instanceStacks {
+		instances = append(instances, instanceStack.InstanceSummary)
+	}
+
+	return instances, nil
+}
+
+// Instance returns the stack details, recent events and template of a
+// service instance.
+func (b *CloudFormationBroker) Instance(instanceID string) (InstanceDetails, error) {
+	stackDetails, err := b.describeInstanceStack(instanceID)
+	if err != nil {
+		return InstanceDetails{}, err
+	}
+
+	stackName := b.stackName(instanceID)
+
+	events, err := b.stack.Events(stackName)
+	if err != nil {
+		return InstanceDetails{}, err
+	}
+
+	template, err := b.stack.Template(stackName)
+	if err != nil {
+		return InstanceDetails{}, err
+	}
+
+	return InstanceDetails{
+		InstanceID:      instanceID,
+		StackName:       stackName,
+		StackID:         stackDetails.StackID,
+		StackStatus:     stackDetails.Status,
+		Parameters:      stackDetails.Parameters,
+		Outputs:         stackDetails.Outputs,
+		Tags:            stackDetails.Tags,
+		CreationTime:    stackDetails.CreationTime,
+		LastUpdatedTime: stackDetails.LastUpdatedTime,
+		Events:          events,
+		Template:        template,
+	}, nil
+}
+
+// InstanceEvents returns the most recent events of the stack of a service
+// instance, newest first.
+func (b *CloudFormationBroker) InstanceEvents(instanceID string) ([]awscf.StackEvent, error) {
+	stackName, err := b.resolveStackName(instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := b.stack.Events(stackName)
+	if err != nil {
+		if err == awscf.ErrStackDoesNotExist {
+			return nil, brokerapi.ErrInstanceDoesNotExist
+		}
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// OwnedInstances returns the service instances whose stack was created by
+// the broker, along with the details of their stack.
+func (b *CloudFormationBroker) OwnedInstances() ([]OwnedInstance, error) {
+	instanceStacks, err := b.instanceStacks()
+	if err != nil {
+		return nil, err
+	}
+
+	ownedInstances := []OwnedInstance{}
+	for _, instanceStack := range instanceStacks {
+		if b.createdByBroker(instanceStack.StackDetails) {
+			ownedInstances = append(ownedInstances, OwnedInstance(instanceStack))
+		}
+	}
+
+	return ownedInstances, nil
+}
+
+// OrphanedInstances returns the stacks created by the broker that are left
+// behind: stacks whose creation was rolled back or whose deletion failed,
+// and stacks whose service or plan is no longer in the catalog.
+func (b *CloudFormationBroker) OrphanedInstances() ([]OrphanedInstance, error) {
+	ownedInstances, err := b.OwnedInstances()
+	if err != nil {
+		return nil, err
+	}
+
+	orphanedInstances := []OrphanedInstance{}
+	for _, ownedInstance := range ownedInstances {
+		if reason := b.orphanReason(ownedInstance.StackDetails); reason != "" {
+			orphanedInstances = append(orphanedInstances, OrphanedInstance{
+				InstanceSummary: ownedInstance.InstanceSummary,
+				Reason:          reason,
+			})
+		}
+	}
+
+	return orphanedInstances, nil
+}
+
+func (b *CloudFormationBroker) orphanReason(stackDetails awscf.StackDetails) string {
+	switch stackDetails.Status.Raw {
+	case cloudformation.StackStatusRollbackComplete:
+		return "Stack creation failed and was rolled back"
+	case cloudformation.StackStatusDeleteFailed:
+		return "Stack deletion failed"
+	}
+
+	if serviceID := stackDetails.Tags[serviceIDTagKey]; serviceID != "" {
+		if _, ok := b.catalog.FindService(serviceID); !ok {
+			return fmt.Sprintf("Service '%s' is no longer in the catalog", serviceID)
+		}
+	}
+
+	if planID := stackDetails.Tags[planIDTagKey]; planID != "" {
+		if _, ok := b.catalog.FindServicePlan(planID); !ok {
+			return fmt.Sprintf("Plan '%s' is no longer in the catalog", planID)
+		}
+	}
+
+	return ""
+}
+
+// instanceStack is a stack named with the prefix of the broker, along with
+// the service instance it belongs to.
+type instanceStack struct {
+	InstanceSummary
+	StackDetails awscf.StackDetails
+}
+
+// instanceStacks describes the stacks named with the prefix of the broker and
+// the stacks it imported, tracking the stacks owned by the broker that are
+// not known yet.
+func (b *CloudFormationBroker) instanceStacks() ([]instanceStack, error) {
+	stacksDetails, err := b.stack.DescribeAll()
+	if err != nil {
+		return nil, err
+	}
+
+	instanceStacks := []instanceStack{}
+	for _, stackDetails := range stacksDetails {
+		// Imported stacks keep their name, and are only known by their tags
+		imported := stackDetails.Tags[importedByTagKey] == brokerTagValue && b.createdByBroker(stackDetails)
+		if !strings.HasPrefix(stackDetails.StackName, b.cloudformationPrefix+"-") && !imported {
+			continue
+		}
+
+		instanceID := stackDetails.Tags[instanceIDTagKey]
+		if instanceID == "" {
+			// Stacks created before the instance ID tag are named after
+			// their instance
+			instanceID = strings.TrimPrefix(stackDetails.StackName, b.cloudformationPrefix+"-")
+		}
+
+		if _, ok := b.stacks.Get(instanceID); !ok && b.createdByBroker(stackDetails) {
+			b.stacks.SetName(instanceID, stackDetails.StackName)
+			b.stacks.SetID(instanceID, stackDetails.StackID)
+		}
+
+		instanceStacks = append(instanceStacks, instanceStack{
+			InstanceSummary: InstanceSummary{
+				InstanceID:   instanceID,
+				StackName:    stackDetails.StackName,
+				StackID:      stackDetails.StackID,
+				StackStatus:  stackDetails.Status,
+				CreationTime: stackDetails.CreationTime,
+			},
+			StackDetails: stackDetails,
+		})
+	}
+
+	return instanceStacks, nil
+}
+
+// describeInstanceStack describes the stack of an instance, which does not
+// exist any longer once its stack has been deleted.
+func (b *CloudFormationBroker) describeInstanceStack(instanceID string) (awscf.StackDetails, error) {
+	stackDetails, err := b.describeTrackedStack(instanceID)
+	if err != nil {
+		if err == awscf.ErrStackDoesNotExist {
+			return stackDetails, brokerapi.ErrInstanceDoesNotExist
+		}
+		return stackDetails, err
+	}
+
+	if stackDetails.Status.Raw == cloudformation.StackStatusDeleteComplete {
+		return stackDetails, brokerapi.ErrInstanceDoesNotExist
+	}
+
+	return stackDetails, nil
+}
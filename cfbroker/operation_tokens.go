@@ -0,0 +1,261 @@
+package cfbroker
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/frodenas/brokerapi"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+)
+
+const operationProvision = "provision"
+const operationUpdate = "update"
+const operationDeprovision = "deprovision"
+
+// OperationTokens holds the operation tokens of a Service Broker API
+// request: the token of the operation a last operation request polls, and
+// the token issued to the asynchronous operation a request starts.
+type OperationTokens struct {
+	Requested string
+	Issued    string
+}
+
+// OperationToken identifies an asynchronous operation on a service instance
+// by its type, the ID of its stack, and a token unique to the request that
+// started it.
+type OperationToken struct {
+	Operation    string
+	StackID      string
+	RequestToken string
+}
+
+func newOperationToken(operation string, stackID string) (OperationToken, error) {
+	requestToken := make([]byte, 8)
+	if _, err := rand.Read(requestToken); err != nil {
+		return OperationToken{}, err
+	}
+
+	return OperationToken{
+		Operation:    operation,
+		StackID:      stackID,
+		RequestToken: hex.EncodeToString(requestToken),
+	}, nil
+}
+
+// ParseOperationToken decodes an operation token as issued to the platform.
+func ParseOperationToken(token string) (OperationToken, error) {
+	decodedToken, err := base64.URLEncoding.DecodeString(token)
+	if err != nil {
+		return OperationToken{}, fmt.Errorf("Invalid operation token '%s'", token)
+	}
+
+	fields := strings.Split(string(decodedToken), "|")
+	if len(fields) != 3 || fields[0] == "" || fields[2] == "" {
+		return OperationToken{}, fmt.Errorf("Invalid operation token '%s'", token)
+	}
+
+	return OperationToken{Operation: fields[0], StackID: fields[1], RequestToken: fields[2]}, nil
+}
+
+func (t OperationToken) String() string {
+	return base64.URLEncoding.EncodeToString([]byte(strings.Join([]string{t.Operation, t.StackID, t.RequestToken}, "|")))
+}
+
+// operationTokenTracker keeps the token of the current operation of each
+// instance, and the outcome of the operation it superseded, so a late poll
+// of that operation is not answered with the state of the current one.
+type operationTokenTracker struct {
+	sync.Mutex
+	operations map[string]tokenedOperations
+}
+
+type tokenedOperations struct {
+	Current    OperationToken
+	Superseded OperationToken
+	Outcome    brokerapi.LastOperationResponse
+}
+
+func newOperationTokenTracker() *operationTokenTracker {
+	return &operationTokenTracker{
+		operations: make(map[string]tokenedOperations),
+	}
+}
+
+func (t *operationTokenTracker) Get(instanceID string) (tokenedOperations, bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	operations, ok := t.operations[instanceID]
+	return operations, ok
+}
+
+// Issue makes token the current operation of an instance, recording the
+// outcome of the operation it supersedes, if any.
+func (t *operationTokenTracker) Issue(instanceID string, token OperationToken, supersededOutcome brokerapi.LastOperationResponse) {
+	t.Lock()
+	defer t.Unlock()
+
+	operations := tokenedOperations{Current: token}
+	if previous, ok := t.operations[instanceID]; ok {
+		operations.Superseded = previous.Current
+		operations.Outcome = supersededOutcome
+	}
+	t.operations[instanceID] = operations
+}
+
+func (t *operationTokenTracker) Forget(instanceID string) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.operations, instanceID)
+}
+
+// WithOperationTokens returns a broker that reads the operation token polled
+// by a last operation request from tokens, and records there the token it
+// issues to an asynchronous operation.
+func (b *CloudFormationBroker) WithOperationTokens(tokens *OperationTokens) *CloudFormationBroker {
+	broker := *b
+	broker.operationTokens = tokens
+
+	return &broker
+}
+
+// issueOperationToken issues a token to the asynchronous operation started
+// on the stack of an instance. stackDetails, when known, is the stack before
+// the operation, whose status tells the outcome of the previous operation.
+func (b *CloudFormationBroker) issueOperationToken(instanceID string, operation string, stackDetails *awscf.StackDetails) error {
+	supersededOutcome := brokerapi.LastOperationResponse{}
+	if stackDetails != nil {
+		supersededOutcome = b.completedOperationResponse(instanceID, *stackDetails)
+	}
+
+	stack, _ := b.stacks.Get(instanceID)
+	token, err := newOperationToken(operation, stack.ID)
+	if err != nil {
+		return err
+	}
+	b.issuedOperations.Issue(instanceID, token, supersededOutcome)
+
+	if b.operationTokens != nil {
+		b.operationTokens.Issued = token.String()
+	}
+
+	return nil
+}
+
+// completeTokenedOperation completes an operation started on an existing
+// stack, issuing a token to it if it is left to complete asynchronously.
+func (b *CloudFormationBroker) completeTokenedOperation(instanceID string, operation string, planID string, acceptsIncomplete bool, stackDetails awscf.StackDetails) (bool, error) {
+	asynch, err := b.completeOperation(instanceID, operation, planID, acceptsIncomplete)
+	if err == nil && asynch {
+		err = b.issueOperationToken(instanceID, operation, &stackDetails)
+	}
+
+	return asynch, err
+}
+
+// requestedOperationToken returns the operation token polled by a last
+// operation request, if any that can be parsed.
+func (b *CloudFormationBroker) requestedOperationToken() (OperationToken, bool) {
+	if b.operationTokens == nil || b.operationTokens.Requested == "" {
+		return OperationToken{}, false
+	}
+
+	token, err := ParseOperationToken(b.operationTokens.Requested)
+	if err != nil {
+		return OperationToken{}, false
+	}
+
+	return token, true
+}
+
+// trackRequestedStack tracks the stack identified by the operation token
+// polled by a last operation request when the stack of the instance is not
+// tracked by ID, as happens after a restart, so the stack keeps being
+// described by ID, and found even once deleted. The stack must carry the ID
+// of the instance.
+func (b *CloudFormationBroker) trackRequestedStack(instanceID string) error {
+	token, ok := b.requestedOperationToken()
+	if !ok || token.StackID == "" {
+		return nil
+	}
+
+	if stack, ok := b.stacks.Get(instanceID); ok && stack.ID != "" {
+		return nil
+	}
+
+	stackDetails, err := b.stack.Describe(token.StackID)
+	if err != nil {
+		if err == awscf.ErrStackDoesNotExist {
+			return nil
+		}
+		return err
+	}
+
+	if stackDetails.Tags[instanceIDTagKey] == instanceID {
+		b.stacks.SetID(instanceID, stackDetails.StackID)
+	}
+
+	return nil
+}
+
+// supersededProvision returns whether the provision polled by a last
+// operation request is known to have succeeded because an update of its
+// stack has started since, when the broker did not issue the update its
+// token, such as after a restart. A stack whose creation failed can not be
+// updated.
+func (b *CloudFormationBroker) supersededProvision(instanceID string, stackDetails awscf.StackDetails) bool {
+	token, ok := b.requestedOperationToken()
+	if !ok || token.Operation != operationProvision {
+		return false
+	}
+
+	if token.StackID != "" && token.StackID != stackDetails.StackID {
+		return false
+	}
+
+	if operations, ok := b.issuedOperations.Get(instanceID); ok && operations.Current == token {
+		return false
+	}
+
+	return stackDetails.Status.Operation == awscf.OperationUpdate
+}
+
+// supersededOperation returns the outcome of the operation polled by a last
+// operation request when a later operation has been started on the instance
+// since. A token that can not be parsed was not issued by the broker, so the
+// last operation of the instance is answered as if no token was sent.
+func (b *CloudFormationBroker) supersededOperation(instanceID string) (brokerapi.LastOperationResponse, bool) {
+	token, ok := b.requestedOperationToken()
+	if !ok {
+		return brokerapi.LastOperationResponse{}, false
+	}
+
+	operations, ok := b.issuedOperations.Get(instanceID)
+	if !ok || token == operations.Current || token != operations.Superseded || operations.Outcome.State == "" {
+		return brokerapi.LastOperationResponse{}, false
+	}
+
+	return operations.Outcome, true
+}
+
+// completedOperationResponse describes the outcome of the operation that left
+// a stack in its current, complete, status.
+func (b *CloudFormationBroker) completedOperationResponse(instanceID string, stackDetails awscf.StackDetails) brokerapi.LastOperationResponse {
+	if stackDetails.Status.Phase == awscf.PhaseComplete && !stackDetails.Status.RolledBack {
+		return brokerapi.LastOperationResponse{
+			State:       brokerapi.LastOperationSucceeded,
+			Description: fmt.Sprintf("Stack '%s' status was '%s'", b.stackName(instanceID), stackDetails.Status.Raw),
+		}
+	}
+
+	return brokerapi.LastOperationResponse{
+		State:       brokerapi.LastOperationFailed,
+		Description: b.failedStackDescription(instanceID, stackDetails.Status),
+	}
+}
@@ -0,0 +1,148 @@
+package cfbroker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/frodenas/brokerapi"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+)
+
+// operationTracker keeps the state of the broker-side work (that is, work
+// not yet reflected in the CloudFormation stack status) for each instance.
+type operationTracker struct {
+	sync.Mutex
+	operations map[string]trackedOperation
+}
+
+type trackedOperation struct {
+	brokerapi.LastOperationResponse
+
+	// Stack to create once the stack currently holding the instance stack
+	// name is gone.
+	PendingStack *awscf.StackDetails
+
+	// CloudFormation stack status that completes the operation successfully,
+	// when it is not one the broker would otherwise report as succeeded.
+	ExpectedStackStatus string
+
+	// Time after which an update still in progress is cancelled.
+	UpdateTimeout time.Duration
+
+	// Why the update of the stack was cancelled, if it was.
+	CancelReason string
+}
+
+// watchesStack returns whether the operation state has to be computed from
+// the CloudFormation stack status.
+func (o trackedOperation) watchesStack() bool {
+	return o.ExpectedStackStatus != "" || o.UpdateTimeout > 0 || o.CancelReason != ""
+}
+
+func newOperationTracker() *operationTracker {
+	return &operationTracker{
+		operations: make(map[string]trackedOperation),
+	}
+}
+
+func (t *operationTracker) Get(instanceID string) (trackedOperation, bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	operation, ok := t.operations[instanceID]
+	return operation, ok
+}
+
+func (t *operationTracker) Set(instanceID string, state string, description string) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.operations[instanceID] = trackedOperation{
+		LastOperationResponse: brokerapi.LastOperationResponse{
+			State:       state,
+			Description: description,
+		},
+	}
+}
+
+func (t *operationTracker) SetPendingStack(instanceID string, description string, stackDetails awscf.StackDetails) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.operations[instanceID] = trackedOperation{
+		LastOperationResponse: brokerapi.LastOperationResponse{
+			State:       brokerapi.LastOperationInProgress,
+			Description: description,
+		},
+		PendingStack: &stackDetails,
+	}
+}
+
+// ClaimPendingStack replaces the pending stack operation of an instance with
+// one in progress described by description, and returns whether it did, so
+// only one of several concurrent last operation requests creates the stack.
+func (t *operationTracker) ClaimPendingStack(instanceID string, pendingStack *awscf.StackDetails, description string) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	operation, ok := t.operations[instanceID]
+	if !ok || operation.PendingStack != pendingStack {
+		return false
+	}
+
+	t.operations[instanceID] = trackedOperation{
+		LastOperationResponse: brokerapi.LastOperationResponse{
+			State:       brokerapi.LastOperationInProgress,
+			Description: description,
+		},
+	}
+
+	return true
+}
+
+func (t *operationTracker) SetExpectedStackStatus(instanceID string, description string, stackStatus string) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.operations[instanceID] = trackedOperation{
+		LastOperationResponse: brokerapi.LastOperationResponse{
+			State:       brokerapi.LastOperationInProgress,
+			Description: description,
+		},
+		ExpectedStackStatus: stackStatus,
+	}
+}
+
+func (t *operationTracker) SetUpdateTimeout(instanceID string, description string, updateTimeout time.Duration) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.operations[instanceID] = trackedOperation{
+		LastOperationResponse: brokerapi.LastOperationResponse{
+			State:       brokerapi.LastOperationInProgress,
+			Description: description,
+		},
+		UpdateTimeout: updateTimeout,
+	}
+}
+
+func (t *operationTracker) SetCancelReason(instanceID string, description string, cancelReason string) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.operations[instanceID] = trackedOperation{
+		LastOperationResponse: brokerapi.LastOperationResponse{
+			State:       brokerapi.LastOperationInProgress,
+			Description: description,
+		},
+		CancelReason: cancelReason,
+	}
+}
+
+func (t *operationTracker) Clear(instanceID string) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.operations, instanceID)
+}
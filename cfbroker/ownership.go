@@ -0,0 +1,80 @@
+package cfbroker
+
+import (
+	"fmt"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+)
+
+// Stack tags that identify the broker, the service and the instance that own
+// a stack.
+const createdByTagKey = "Created by"
+const importedByTagKey = "Imported by"
+const brokerIDTagKey = "Broker ID"
+const serviceIDTagKey = "Service ID"
+const planIDTagKey = "Plan ID"
+const instanceIDTagKey = "Instance ID"
+
+// notCreatedByBrokerReason is why a stack that carries no broker tag does not
+// belong to the broker.
+const notCreatedByBrokerReason = "it was not created by a service broker"
+
+// missingBrokerIDReason is why a stack tagged before brokers had an ID is not
+// owned by a broker with an ID, until it is tagged with it.
+const missingBrokerIDReason = "it carries no 'Broker ID' tag"
+
+// OwnershipError is returned when the broker is asked to modify or delete a
+// stack that has the name of one of its service instances but does not
+// belong to it, such as a stack of another broker sharing the same prefix.
+type OwnershipError struct {
+	StackName string
+	Reason    string
+}
+
+func (e OwnershipError) Error() string {
+	return fmt.Sprintf("Stack '%s' is not owned by the broker, %s", e.StackName, e.Reason)
+}
+
+// ownedStack describes the stack of a service instance, failing unless it
+// was created by this broker for the given service, if any.
+func (b *CloudFormationBroker) ownedStack(instanceID string, serviceID string) (awscf.StackDetails, error) {
+	stackDetails, err := b.describeInstanceStack(instanceID)
+	if err != nil {
+		return stackDetails, err
+	}
+
+	if reason := b.ownershipMismatch(serviceID, stackDetails); reason != "" {
+		return stackDetails, OwnershipError{StackName: b.stackName(instanceID), Reason: reason}
+	}
+
+	return stackDetails, nil
+}
+
+func (b *CloudFormationBroker) createdByBroker(stackDetails awscf.StackDetails) bool {
+	return b.ownershipMismatch("", stackDetails) == ""
+}
+
+// ownershipMismatch tells why a stack does not belong to the broker, or to
+// the given service. Stacks imported by the broker belong to it as well as
+// the ones it created. Stacks tagged before brokers had an ID carry no broker
+// ID tag, and could have been created by any broker sharing the prefix, so
+// they only belong to a broker without an ID.
+func (b *CloudFormationBroker) ownershipMismatch(serviceID string, stackDetails awscf.StackDetails) string {
+	if stackDetails.Tags[createdByTagKey] != brokerTagValue && stackDetails.Tags[importedByTagKey] != brokerTagValue {
+		return notCreatedByBrokerReason
+	}
+
+	brokerID := stackDetails.Tags[brokerIDTagKey]
+	if brokerID == "" && b.brokerID != "" {
+		return missingBrokerIDReason
+	}
+	if brokerID != "" && brokerID != b.brokerID {
+		return fmt.Sprintf("it was created by broker '%s'", brokerID)
+	}
+
+	if stackServiceID := stackDetails.Tags[serviceIDTagKey]; serviceID != "" && stackServiceID != "" && stackServiceID != serviceID {
+		return fmt.Sprintf("it belongs to service '%s', not '%s'", stackServiceID, serviceID)
+	}
+
+	return ""
+}
@@ -0,0 +1,108 @@
+package cfbroker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+)
+
+type preDeleteWebhookRequest struct {
+	InstanceID string `json:"instance_id"`
+	StackName  string `json:"stack_name"`
+	StackID    string `json:"stack_id"`
+}
+
+func (b *CloudFormationBroker) deleteStackWithHooks(instanceID string, stackDetails awscf.StackDetails, preDeleteHooks []PreDeleteHook) {
+	stackName := b.stackName(instanceID)
+
+	for i, preDeleteHook := range preDeleteHooks {
+		b.operations.Set(instanceID, brokerapi.LastOperationInProgress, fmt.Sprintf("Running pre-delete hook %d of %d (%s) for stack '%s'", i+1, len(preDeleteHooks), preDeleteHook.Type, stackName))
+
+		if err := b.runPreDeleteHook(instanceID, preDeleteHook, stackDetails); err != nil {
+			b.logger.Error("pre-delete-hook-failed", err, lager.Data{
+				instanceIDLogKey: instanceID,
+				"hook":           preDeleteHook,
+			})
+			b.operations.Set(instanceID, brokerapi.LastOperationFailed, fmt.Sprintf("Pre-delete hook %d of %d (%s) failed, stack '%s' was not deleted: %s", i+1, len(preDeleteHooks), preDeleteHook.Type, stackName, err))
+			return
+		}
+	}
+
+	if err := b.stack.Delete(stackName); err != nil {
+		b.logger.Error("delete-stack-failed", err, lager.Data{
+			instanceIDLogKey: instanceID,
+		})
+		b.operations.Set(instanceID, brokerapi.LastOperationFailed, fmt.Sprintf("Deleting stack '%s' failed: %s", stackName, err))
+		return
+	}
+
+	b.operations.Clear(instanceID)
+}
+
+func (b *CloudFormationBroker) runPreDeleteHook(instanceID string, preDeleteHook PreDeleteHook, stackDetails awscf.StackDetails) error {
+	switch preDeleteHook.Type {
+	case PreDeleteHookEmptyS3Bucket:
+		bucketName, ok := stackDetails.Outputs[preDeleteHook.Output]
+		if !ok {
+			return fmt.Errorf("Stack Output '%s' not found", preDeleteHook.Output)
+		}
+		return b.bucket.Empty(bucketName)
+	case PreDeleteHookDeleteECRImages:
+		repositoryName, ok := stackDetails.Outputs[preDeleteHook.Output]
+		if !ok {
+			return fmt.Errorf("Stack Output '%s' not found", preDeleteHook.Output)
+		}
+		return b.repository.DeleteImages(repositoryName)
+	case PreDeleteHookWebhook:
+		return b.callPreDeleteWebhook(preDeleteHook.URL, preDeleteWebhookRequest{
+			InstanceID: instanceID,
+			StackName:  stackDetails.StackName,
+			StackID:    stackDetails.StackID,
+		})
+	default:
+		return fmt.Errorf("PreDeleteHook Type '%s' not supported", preDeleteHook.Type)
+	}
+}
+
+func (b *CloudFormationBroker) callPreDeleteWebhook(url string, webhookRequest preDeleteWebhookRequest) error {
+	body, err := json.Marshal(webhookRequest)
+	if err != nil {
+		return err
+	}
+
+	resp, err := b.httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("Webhook '%s' returned status code %d", url, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// interruptedDeprovision returns whether the deprovision polled by a last
+// operation request stopped before deleting the stack, as happens to one
+// running its pre-delete hooks when the broker restarts: the hooks only run
+// in memory, and the stack is left as it was.
+func (b *CloudFormationBroker) interruptedDeprovision(instanceID string, stackDetails awscf.StackDetails) bool {
+	token, ok := b.requestedOperationToken()
+	if !ok || token.Operation != operationDeprovision {
+		return false
+	}
+
+	// A deprovision this broker issued the token to is still known to it
+	if operations, ok := b.issuedOperations.Get(instanceID); ok && operations.Current == token {
+		return false
+	}
+
+	return stackDetails.Status.Operation != awscf.OperationDelete
+}
@@ -0,0 +1,173 @@
+package cfbroker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+)
+
+const resourceStatusDeleteComplete = "DELETE_COMPLETE"
+const resourceStatusDeleteSkipped = "DELETE_SKIPPED"
+
+var resourceActions = map[string]string{
+	"CREATE_IN_PROGRESS": "creating",
+	"UPDATE_IN_PROGRESS": "updating",
+	"DELETE_IN_PROGRESS": "deleting",
+	"IMPORT_IN_PROGRESS": "importing",
+}
+
+// stackProgress describes how far the operation in progress on the stack has
+// gone, based on the status of its resources against the resources of its
+// template. The resources notified since the operation started are used
+// when the broker receives stack notifications, and the resources are listed
+// otherwise. An empty string is returned if the resources cannot be listed,
+// as progress is only informative.
+func (b *CloudFormationBroker) stackProgress(stackName string, stackDetails awscf.StackDetails) string {
+	stackResources, ok := b.notifiedResources(stackDetails.StackID)
+	if !ok {
+		var err error
+		stackResources, err = b.stack.ListResources(stackName)
+		if err != nil {
+			b.logger.Error("list-resources-error", err, lager.Data{"stack-name": stackName})
+			return ""
+		}
+	}
+
+	if len(stackResources) == 0 {
+		return ""
+	}
+
+	completeResources := 0
+	inProgressResources := []awscf.StackResource{}
+	for _, stackResource := range stackResources {
+		switch {
+		case strings.HasSuffix(stackResource.ResourceStatus, "_IN_PROGRESS"):
+			inProgressResources = append(inProgressResources, stackResource)
+		case resourceComplete(stackResource.ResourceStatus, stackDetails.Status):
+			completeResources++
+		}
+	}
+
+	progress := fmt.Sprintf("%d resources complete", completeResources)
+	if totalResources, ok := b.templateResourceCount(stackName, stackDetails); ok {
+		// Resources removed from the template by an update are still listed
+		if totalResources < len(stackResources) {
+			totalResources = len(stackResources)
+		}
+		progress = fmt.Sprintf("%d of %d resources complete", completeResources, totalResources)
+	}
+
+	if len(inProgressResources) > 0 {
+		stackResource := inProgressResources[0]
+		action, ok := resourceActions[stackResource.ResourceStatus]
+		if !ok {
+			action = "processing"
+		}
+		progress = fmt.Sprintf("%s, currently %s %s %s", progress, action, stackResource.ResourceType, stackResource.LogicalResourceID)
+		if len(inProgressResources) > 1 {
+			progress = fmt.Sprintf("%s and %d more", progress, len(inProgressResources)-1)
+		}
+	}
+
+	return progress
+}
+
+// resourceComplete returns whether a resource is done with the operation in
+// progress on the stack. Deleting a stack, or rolling back its creation, is
+// only done with a resource once it has been deleted.
+func resourceComplete(resourceStatus string, status awscf.Status) bool {
+	if status.Operation == awscf.OperationDelete || (status.Operation == awscf.OperationCreate && status.RolledBack) {
+		return resourceStatus == resourceStatusDeleteComplete || resourceStatus == resourceStatusDeleteSkipped
+	}
+
+	return strings.HasSuffix(resourceStatus, "_COMPLETE") || resourceStatus == resourceStatusDeleteSkipped
+}
+
+// templateResourceCount returns the number of resources in the template of a
+// stack, which, unlike the resources listed, includes those not created yet.
+// The template does not change while an operation is in progress, so it is
+// only read once per stack operation.
+func (b *CloudFormationBroker) templateResourceCount(stackName string, stackDetails awscf.StackDetails) (int, bool) {
+	if count, ok := b.templateResources.Get(stackName, stackDetails.LastUpdatedTime); ok {
+		return count, true
+	}
+
+	templateBody, err := b.stack.Template(stackName)
+	if err != nil {
+		b.logger.Error("get-template-error", err, lager.Data{"stack-name": stackName})
+		return 0, false
+	}
+
+	count, ok := countTemplateResources(templateBody)
+	if !ok {
+		return 0, false
+	}
+
+	b.templateResources.Set(stackName, stackDetails.LastUpdatedTime, count)
+
+	return count, true
+}
+
+// countTemplateResources returns the number of resources of a JSON template.
+// YAML templates are not counted, as no YAML parser is available to the
+// broker, so the progress of their stacks is reported without a total.
+func countTemplateResources(templateBody string) (int, bool) {
+	var template struct {
+		Resources map[string]json.RawMessage `json:"Resources"`
+	}
+	if err := json.Unmarshal([]byte(templateBody), &template); err != nil {
+		return 0, false
+	}
+
+	return len(template.Resources), template.Resources != nil
+}
+
+// templateResourceTracker keeps the number of resources in the template of
+// each stack, by stack name, as of its last update.
+type templateResourceTracker struct {
+	sync.Mutex
+	counts map[string]templateResourceCount
+}
+
+type templateResourceCount struct {
+	LastUpdatedTime time.Time
+	Count           int
+}
+
+func newTemplateResourceTracker() *templateResourceTracker {
+	return &templateResourceTracker{
+		counts: make(map[string]templateResourceCount),
+	}
+}
+
+func (t *templateResourceTracker) Get(stackName string, lastUpdatedTime time.Time) (int, bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	count, ok := t.counts[stackName]
+	if !ok || !count.LastUpdatedTime.Equal(lastUpdatedTime) {
+		return 0, false
+	}
+
+	return count.Count, true
+}
+
+func (t *templateResourceTracker) Set(stackName string, lastUpdatedTime time.Time, count int) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.counts[stackName] = templateResourceCount{LastUpdatedTime: lastUpdatedTime, Count: count}
+}
+
+func (t *templateResourceTracker) Forget(stackName string) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.counts, stackName)
+}
@@ -0,0 +1,135 @@
+package cfbroker
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+)
+
+// StackStatusError is returned when a repair action is requested on a stack
+// whose status does not allow it.
+type StackStatusError struct {
+	StackName   string
+	StackStatus string
+	Action      string
+}
+
+func (e StackStatusError) Error() string {
+	return fmt.Sprintf("Stack '%s' status is '%s', it can not be %s", e.StackName, e.StackStatus, e.Action)
+}
+
+// RetryDelete deletes again a stack whose deletion failed, retaining the
+// resources that could not be deleted, if any.
+func (b *CloudFormationBroker) RetryDelete(instanceID string, resourcesToRetain []string) error {
+	defer b.locks.Lock(instanceID)()
+
+	stackDetails, err := b.ownedStack(instanceID, "")
+	if err != nil {
+		return err
+	}
+
+	if stackDetails.Status.Raw != cloudformation.StackStatusDeleteFailed {
+		return StackStatusError{StackName: b.stackName(instanceID), StackStatus: stackDetails.Status.Raw, Action: "deleted again"}
+	}
+
+	b.logger.Info("retry-delete", lager.Data{
+		instanceIDLogKey:      instanceID,
+		"resources-to-retain": resourcesToRetain,
+	})
+
+	b.operations.Clear(instanceID)
+
+	return b.stack.DeleteRetainingResources(b.stackName(instanceID), resourcesToRetain)
+}
+
+// DeleteInstanceStack deletes the stack of a service instance without going
+// through the Service Broker API, retaining the given resources, if any.
+// Only stacks owned by the broker can be deleted this way.
+func (b *CloudFormationBroker) DeleteInstanceStack(instanceID string, resourcesToRetain []string) error {
+	defer b.locks.Lock(instanceID)()
+
+	if _, err := b.ownedStack(instanceID, ""); err != nil {
+		return err
+	}
+
+	stackName := b.stackName(instanceID)
+
+	b.logger.Info("delete-instance-stack", lager.Data{
+		instanceIDLogKey:      instanceID,
+		"resources-to-retain": resourcesToRetain,
+	})
+
+	b.operations.Clear(instanceID)
+
+	if len(resourcesToRetain) > 0 {
+		return b.stack.DeleteRetainingResources(stackName, resourcesToRetain)
+	}
+
+	return b.stack.Delete(stackName)
+}
+
+// ContinueUpdateRollback continues rolling back a stack whose update
+// rollback failed, skipping the given resources, if any.
+func (b *CloudFormationBroker) ContinueUpdateRollback(instanceID string, resourcesToSkip []string) error {
+	defer b.locks.Lock(instanceID)()
+
+	stackDetails, err := b.ownedStack(instanceID, "")
+	if err != nil {
+		return err
+	}
+
+	if stackDetails.Status.Raw != cloudformation.StackStatusUpdateRollbackFailed {
+		return StackStatusError{StackName: b.stackName(instanceID), StackStatus: stackDetails.Status.Raw, Action: "rolled back"}
+	}
+
+	return b.startContinueUpdateRollback(instanceID, resourcesToSkip)
+}
+
+// Retag adds the given tags to the stack of a service instance, replacing
+// the values of existing tags with the same keys. Stacks tagged before the
+// broker had an ID are adopted by tagging them with the broker ID.
+func (b *CloudFormationBroker) Retag(instanceID string, tags map[string]string) error {
+	defer b.locks.Lock(instanceID)()
+
+	stackDetails, err := b.ownedStack(instanceID, "")
+	if ownershipErr, ok := err.(OwnershipError); ok && ownershipErr.Reason == missingBrokerIDReason && tags[brokerIDTagKey] == b.brokerID {
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+
+	stackName := b.stackName(instanceID)
+
+	if !stackDetails.Status.Usable() {
+		return StackStatusError{StackName: stackName, StackStatus: stackDetails.Status.Raw, Action: "retagged"}
+	}
+
+	stackTags := make(map[string]string)
+	for key, value := range stackDetails.Tags {
+		stackTags[key] = value
+	}
+	for key, value := range tags {
+		stackTags[key] = value
+	}
+
+	b.logger.Info("retag", lager.Data{
+		instanceIDLogKey: instanceID,
+		"tags":           tags,
+	})
+
+	b.operations.Clear(instanceID)
+
+	if err := b.stack.UpdateTags(stackName, stackTags); err != nil {
+		if err == awscf.ErrStackDoesNotExist {
+			return brokerapi.ErrInstanceDoesNotExist
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package cfbroker
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+)
+
+// A stack whose creation failed and was rolled back keeps holding its name
+// until it is deleted, so it must be removed before the instance can be
+// provisioned again.
+func (b *CloudFormationBroker) isRolledBackStack(stackDetails awscf.StackDetails) bool {
+	return stackDetails.Status.Raw == cloudformation.StackStatusRollbackComplete && b.createdByBroker(stackDetails)
+}
+
+func (b *CloudFormationBroker) replaceRolledBackStack(instanceID string, createStackDetails awscf.StackDetails) error {
+	stackName := b.stackName(instanceID)
+
+	b.logger.Info("delete-rolled-back-stack", lager.Data{
+		instanceIDLogKey: instanceID,
+	})
+
+	if err := b.stack.Delete(stackName); err != nil {
+		return err
+	}
+
+	b.operations.SetPendingStack(instanceID, fmt.Sprintf("Deleting rolled back stack '%s' before creating it again", stackName), createStackDetails)
+
+	return nil
+}
+
+func (b *CloudFormationBroker) createPendingStack(instanceID string, operation trackedOperation) (brokerapi.LastOperationResponse, error) {
+	stackName := b.stackName(instanceID)
+
+	stackDetails, err := b.stack.Describe(stackName)
+	if err != nil && err != awscf.ErrStackDoesNotExist {
+		return operation.LastOperationResponse, err
+	}
+
+	if err == nil {
+		if stackDetails.Status.Raw == cloudformation.StackStatusDeleteFailed {
+			b.operations.Set(instanceID, brokerapi.LastOperationFailed, fmt.Sprintf("Deleting rolled back stack '%s' failed: %s", stackName, stackDetails.Status.Reason))
+			operation, _ = b.operations.Get(instanceID)
+		}
+		return operation.LastOperationResponse, nil
+	}
+
+	creatingStack := brokerapi.LastOperationResponse{
+		State:       brokerapi.LastOperationInProgress,
+		Description: fmt.Sprintf("Creating stack '%s'", stackName),
+	}
+
+	// Another last operation request already creates the stack
+	if !b.operations.ClaimPendingStack(instanceID, operation.PendingStack, creatingStack.Description) {
+		return creatingStack, nil
+	}
+
+	stackID, err := b.stack.Create(stackName, *operation.PendingStack)
+	if err != nil {
+		b.logger.Error("create-stack-failed", err, lager.Data{
+			instanceIDLogKey: instanceID,
+		})
+		b.operations.Set(instanceID, brokerapi.LastOperationFailed, fmt.Sprintf("Creating stack '%s' failed: %s", stackName, err))
+		operation, _ = b.operations.Get(instanceID)
+		return operation.LastOperationResponse, nil
+	}
+
+	b.stacks.SetID(instanceID, stackID)
+	b.operations.Clear(instanceID)
+
+	return creatingStack, nil
+}
@@ -0,0 +1,67 @@
+package cfbroker
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/frodenas/brokerapi"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+)
+
+// Update parameters that, instead of updating the stack, ask the broker to
+// act on a stack stuck in a failed state.
+const ContinueUpdateRollbackParameter = "continue_update_rollback"
+const ResourcesToSkipParameter = "resources_to_skip"
+
+type ContinueUpdateRollbackParameters struct {
+	ContinueUpdateRollback bool     `mapstructure:"continue_update_rollback"`
+	ResourcesToSkip        []string `mapstructure:"resources_to_skip"`
+}
+
+func isContinueUpdateRollback(parameters map[string]interface{}) bool {
+	_, ok := parameters[ContinueUpdateRollbackParameter]
+	return ok
+}
+
+func (b *CloudFormationBroker) continueUpdateRollback(instanceID string, parameters map[string]interface{}) error {
+	continueUpdateRollbackParameters := ContinueUpdateRollbackParameters{}
+	if err := mapstructure.Decode(parameters, &continueUpdateRollbackParameters); err != nil {
+		return err
+	}
+
+	for key := range parameters {
+		if key != ContinueUpdateRollbackParameter && key != ResourcesToSkipParameter {
+			return fmt.Errorf("Parameter '%s' can not be used together with '%s'", key, ContinueUpdateRollbackParameter)
+		}
+	}
+
+	if !continueUpdateRollbackParameters.ContinueUpdateRollback {
+		return fmt.Errorf("Parameter '%s' must be true", ContinueUpdateRollbackParameter)
+	}
+
+	return b.startContinueUpdateRollback(instanceID, continueUpdateRollbackParameters.ResourcesToSkip)
+}
+
+func (b *CloudFormationBroker) startContinueUpdateRollback(instanceID string, resourcesToSkip []string) error {
+	b.logger.Info("continue-update-rollback", lager.Data{
+		instanceIDLogKey:         instanceID,
+		ResourcesToSkipParameter: resourcesToSkip,
+	})
+
+	b.operations.Clear(instanceID)
+
+	stackName := b.stackName(instanceID)
+	if err := b.stack.ContinueUpdateRollback(stackName, resourcesToSkip); err != nil {
+		if err == awscf.ErrStackDoesNotExist {
+			return brokerapi.ErrInstanceDoesNotExist
+		}
+		return err
+	}
+
+	b.operations.SetExpectedStackStatus(instanceID, fmt.Sprintf("Continuing the rollback of stack '%s'", stackName), cloudformation.StackStatusUpdateRollbackComplete)
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package cfbroker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+)
+
+// DefaultStackNameTemplate names stacks after the prefix and the instance ID,
+// as the broker always did.
+const DefaultStackNameTemplate = prefixPlaceholder + "-" + instanceIDPlaceholder
+
+// Placeholders of the stack name template.
+const prefixPlaceholder = "{{prefix}}"
+const servicePlaceholder = "{{service}}"
+const planPlaceholder = "{{plan}}"
+const instanceIDPlaceholder = "{{instance_id}}"
+
+// CloudFormation stack names must start with a letter, contain only
+// alphanumeric characters and hyphens, and be at most 128 characters long.
+const maxStackNameLength = 128
+const stackNameHashLength = 8
+
+var invalidStackNameCharacters = regexp.MustCompile("[^A-Za-z0-9-]")
+var validStackNamePrefix = regexp.MustCompile("^[A-Za-z][A-Za-z0-9-]*$")
+
+func validateStackNameTemplate(template string, prefix string) error {
+	if !validStackNamePrefix.MatchString(prefix) {
+		return fmt.Errorf("CloudFormationPrefix must start with a letter and contain only letters, digits and hyphens, got '%s'", prefix)
+	}
+
+	// The broker finds its stacks by the prefix of their name
+	if !strings.HasPrefix(template, prefixPlaceholder+"-") {
+		return fmt.Errorf("StackNameTemplate must start with '%s-', got '%s'", prefixPlaceholder, template)
+	}
+
+	if !strings.Contains(template, instanceIDPlaceholder) {
+		return fmt.Errorf("StackNameTemplate must contain '%s', got '%s'", instanceIDPlaceholder, template)
+	}
+
+	fixedParts := strings.NewReplacer(
+		prefixPlaceholder, prefix,
+		servicePlaceholder, "",
+		planPlaceholder, "",
+		instanceIDPlaceholder, "",
+	).Replace(template)
+
+	if strings.Contains(fixedParts, "{{") || strings.Contains(fixedParts, "}}") {
+		return fmt.Errorf("StackNameTemplate contains an unknown placeholder, got '%s'", template)
+	}
+
+	if invalidStackNameCharacters.MatchString(fixedParts) {
+		return fmt.Errorf("StackNameTemplate must contain only letters, digits, hyphens and placeholders, got '%s'", template)
+	}
+
+	if len(fixedParts) > maxStackNameLength-stackNameHashLength-1 {
+		return errors.New("StackNameTemplate and CloudFormationPrefix leave no room for the instance ID in the stack name")
+	}
+
+	return nil
+}
+
+// renderStackName fills the stack name template in. Characters that are not
+// allowed in stack names are replaced with hyphens, and names that are too
+// long are truncated. As either can give different instances the same name,
+// a hash of the full name is then appended to keep them apart.
+func renderStackName(template string, prefix string, serviceName string, planName string, instanceID string) string {
+	stackName := strings.NewReplacer(
+		prefixPlaceholder, prefix,
+		servicePlaceholder, serviceName,
+		planPlaceholder, planName,
+		instanceIDPlaceholder, instanceID,
+	).Replace(template)
+
+	sanitizedStackName := invalidStackNameCharacters.ReplaceAllString(stackName, "-")
+	if sanitizedStackName == stackName && len(stackName) <= maxStackNameLength {
+		return stackName
+	}
+
+	hash := sha256.Sum256([]byte(stackName))
+	suffix := "-" + hex.EncodeToString(hash[:])[:stackNameHashLength]
+	if len(sanitizedStackName) > maxStackNameLength-len(suffix) {
+		sanitizedStackName = sanitizedStackName[:maxStackNameLength-len(suffix)]
+	}
+
+	return strings.TrimRight(sanitizedStackName, "-") + suffix
+}
+
+// newStackName names the stack of a new instance.
+func (b *CloudFormationBroker) newStackName(instanceID string, serviceID string, planID string) string {
+	var serviceName, planName string
+	if service, ok := b.catalog.FindService(serviceID); ok {
+		serviceName = service.Name
+	}
+	if servicePlan, ok := b.catalog.FindServicePlan(planID); ok {
+		planName = servicePlan.Name
+	}
+
+	return renderStackName(b.stackNameTemplate, b.cloudformationPrefix, serviceName, planName, instanceID)
+}
+
+// resolveStackName returns the name of the stack of an instance. Stacks not
+// known yet are looked up by name first, and only by their instance ID tag
+// across the account when no stack has the names the broker gives them, as
+// they may have been named with another template. The name of a stack found
+// is tracked, while an instance without a stack is looked up again, as its
+// stack may be created by then.
+func (b *CloudFormationBroker) resolveStackName(instanceID string) (string, error) {
+	stackName, found, err := b.findNamedStack(instanceID)
+	if err != nil || found {
+		return stackName, err
+	}
+
+	instanceStacks, err := b.instanceStacks()
+	if err != nil {
+		b.logger.Error("lookup-stack-name-failed", err, lager.Data{
+			instanceIDLogKey: instanceID,
+		})
+		return "", err
+	}
+
+	for _, instanceStack := range instanceStacks {
+		if instanceStack.InstanceID == instanceID && b.createdByBroker(instanceStack.StackDetails) {
+			b.stacks.SetName(instanceID, instanceStack.StackName)
+			return instanceStack.StackName, nil
+		}
+	}
+
+	return stackName, nil
+}
+
+// findNamedStack returns the name of the stack of an instance when it is
+// tracked, or when a stack has the name the current template gives the
+// instance, or the name the broker gave it before stack name templates,
+// tracking it then. Otherwise, it returns the name the current template
+// gives the instance.
+func (b *CloudFormationBroker) findNamedStack(instanceID string) (string, bool, error) {
+	stack, _ := b.stacks.Get(instanceID)
+	if stack.Name != "" {
+		return stack.Name, true, nil
+	}
+
+	stackName := b.newStackName(instanceID, stack.ServiceID, stack.PlanID)
+	stackNames := []string{stackName}
+	if legacyStackName := renderStackName(DefaultStackNameTemplate, b.cloudformationPrefix, "", "", instanceID); legacyStackName != stackName {
+		stackNames = append(stackNames, legacyStackName)
+	}
+
+	for _, name := range stackNames {
+		_, err := b.stack.Describe(name)
+		if err == awscf.ErrStackDoesNotExist {
+			continue
+		}
+		if err != nil {
+			b.logger.Error("lookup-stack-name-failed", err, lager.Data{
+				instanceIDLogKey: instanceID,
+			})
+			return "", false, err
+		}
+
+		b.stacks.SetName(instanceID, name)
+		return name, true, nil
+	}
+
+	return stackName, false, nil
+}
+
+// stackName returns the name of the stack of an instance, as resolved by
+// resolveStackName, or the name the current template gives to the instance
+// if it was not resolved.
+func (b *CloudFormationBroker) stackName(instanceID string) string {
+	stack, _ := b.stacks.Get(instanceID)
+	if stack.Name != "" {
+		return stack.Name
+	}
+
+	return b.newStackName(instanceID, stack.ServiceID, stack.PlanID)
+}
@@ -0,0 +1,80 @@
+package cfbroker
+
+import (
+	"time"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+)
+
+// StackStatusCache holds the latest status of stacks, as notified by AWS
+// CloudFormation, by stack ID, along with when the operation in progress on
+// each stack started and the resources notified since.
+type StackStatusCache interface {
+	StackStatus(stackID string) (awscf.Status, time.Time, bool)
+	StackResources(stackID string) ([]awscf.StackResource, bool)
+}
+
+// ReceiveStackNotifications attaches the notification topic to the stacks
+// the broker creates or updates, and reads the status of stacks with an
+// operation in progress from statusCache, which the notifications of the
+// topic are expected to fill, rather than describing them.
+func (b *CloudFormationBroker) ReceiveStackNotifications(topicARN string, statusCache StackStatusCache) {
+	b.notificationTopicARN = topicARN
+	b.stackStatusCache = statusCache
+}
+
+// notificationARNs adds the notification topic of the broker, if any, to the
+// notification ARNs of a plan.
+func (b *CloudFormationBroker) notificationARNs(planNotificationARNs []string) []string {
+	if b.notificationTopicARN == "" {
+		return planNotificationARNs
+	}
+
+	notificationARNs := []string{}
+	for _, notificationARN := range planNotificationARNs {
+		if notificationARN != b.notificationTopicARN {
+			notificationARNs = append(notificationARNs, notificationARN)
+		}
+	}
+
+	return append(notificationARNs, b.notificationTopicARN)
+}
+
+// describeNotifiedStack returns the status of the stack of an instance from
+// its notifications while an operation is in progress, and describes the
+// stack otherwise. The operation in progress is dated from its notification,
+// so update timeouts still apply. Statuses other than in progress are always
+// described, as the notification of the stack operation just started may not
+// have been received yet.
+func (b *CloudFormationBroker) describeNotifiedStack(instanceID string) (awscf.StackDetails, error) {
+	if b.stackStatusCache != nil {
+		if stack, ok := b.stacks.Get(instanceID); ok && stack.ID != "" {
+			if status, startedAt, ok := b.stackStatusCache.StackStatus(stack.ID); ok && status.Summary() == awscf.StatusInProgress {
+				stackDetails := awscf.StackDetails{
+					StackName:   stack.Name,
+					StackID:     stack.ID,
+					StackStatus: status.Summary(),
+					Status:      status,
+				}
+				if status.Operation == awscf.OperationCreate {
+					stackDetails.CreationTime = startedAt
+				} else {
+					stackDetails.LastUpdatedTime = startedAt
+				}
+				return stackDetails, nil
+			}
+		}
+	}
+
+	return b.describeTrackedStack(instanceID)
+}
+
+// notifiedResources returns the resources of a stack notified since the
+// operation in progress on it started, if any.
+func (b *CloudFormationBroker) notifiedResources(stackID string) ([]awscf.StackResource, bool) {
+	if b.stackStatusCache == nil || stackID == "" {
+		return nil, false
+	}
+
+	return b.stackStatusCache.StackResources(stackID)
+}
@@ -0,0 +1,121 @@
+package cfbroker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager"
+)
+
+// synchronousPollInterval is waited for between two checks of the operation
+// a synchronous request waits for.
+const synchronousPollInterval = 5 * time.Second
+
+// SynchronousTimeoutError is returned when an operation requested without
+// accepting incomplete operations does not complete within the synchronous
+// timeout of its plan. The stack operation itself goes on.
+type SynchronousTimeoutError struct {
+	StackName string
+	Operation string
+	Timeout   time.Duration
+}
+
+func (e SynchronousTimeoutError) Error() string {
+	return fmt.Sprintf("The %s of stack '%s' did not complete within %s and is still in progress, retry once it completes", e.Operation, e.StackName, e.Timeout)
+}
+
+// SynchronousOperationError is returned when an operation requested on a
+// plan with a synchronous timeout fails within the timeout, describing the
+// stack status the operation ended in.
+type SynchronousOperationError struct {
+	StackName   string
+	Operation   string
+	Description string
+}
+
+func (e SynchronousOperationError) Error() string {
+	return e.Description
+}
+
+// acceptsOperation returns whether an operation on a plan can be requested
+// without accepting incomplete operations.
+func (b *CloudFormationBroker) acceptsOperation(planID string, acceptsIncomplete bool) bool {
+	return acceptsIncomplete || b.synchronousTimeout(planID) > 0
+}
+
+func (b *CloudFormationBroker) synchronousTimeout(planID string) time.Duration {
+	servicePlan, ok := b.catalog.FindServicePlan(planID)
+	if !ok {
+		return 0
+	}
+
+	return time.Duration(servicePlan.CloudFormationProperties.SynchronousTimeoutInSeconds) * time.Second
+}
+
+// completeOperation waits for the operation started on the stack of an
+// instance to complete when its plan has a synchronous timeout, and returns
+// whether the operation is left to complete asynchronously. Operations that
+// do not complete in time are only left to complete asynchronously if the
+// request accepts it. The caller must hold the lock of the instance, which
+// is released while waiting so the last operation of the instance can be
+// polled, and requests on it rejected while its stack is in progress.
+func (b *CloudFormationBroker) completeOperation(instanceID string, operation string, planID string, acceptsIncomplete bool) (bool, error) {
+	timeout := b.synchronousTimeout(planID)
+	if timeout == 0 {
+		return true, nil
+	}
+
+	b.logger.Debug("wait-for-operation", lager.Data{
+		instanceIDLogKey: instanceID,
+		"timeout":        timeout.String(),
+	})
+
+	var lastOperationResponse brokerapi.LastOperationResponse
+	var err error
+	b.locks.Unlocked(instanceID, func() {
+		lastOperationResponse, err = b.waitForOperation(instanceID, timeout)
+	})
+	if err != nil {
+		// A stack that is no longer tracked by ID is not found once deleted
+		if operation == operationDeprovision && err == brokerapi.ErrInstanceDoesNotExist {
+			return false, nil
+		}
+		return false, err
+	}
+
+	switch lastOperationResponse.State {
+	case brokerapi.LastOperationSucceeded:
+		return false, nil
+	case brokerapi.LastOperationFailed:
+		return false, SynchronousOperationError{StackName: b.stackName(instanceID), Operation: operation, Description: lastOperationResponse.Description}
+	}
+
+	if acceptsIncomplete {
+		return true, nil
+	}
+
+	return false, SynchronousTimeoutError{StackName: b.stackName(instanceID), Operation: operation, Timeout: timeout}
+}
+
+// waitForOperation polls the last operation of an instance until it is no
+// longer in progress or the timeout expires.
+func (b *CloudFormationBroker) waitForOperation(instanceID string, timeout time.Duration) (brokerapi.LastOperationResponse, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		lastOperationResponse, err := b.LastOperation(instanceID)
+		if err != nil || lastOperationResponse.State != brokerapi.LastOperationInProgress {
+			return lastOperationResponse, err
+		}
+
+		wait := deadline.Sub(time.Now())
+		if wait <= 0 {
+			return lastOperationResponse, nil
+		}
+		if wait > synchronousPollInterval {
+			wait = synchronousPollInterval
+		}
+		time.Sleep(wait)
+	}
+}
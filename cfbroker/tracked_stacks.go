@@ -0,0 +1,105 @@
+package cfbroker
+
+import (
+	"sync"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+)
+
+// trackedStack is the stack of an instance, once known. Its name can not be
+// computed again if the stack name template changes, and unlike its name,
+// which is free to be reused once the stack is deleted, its ID keeps
+// identifying the stack, and describes it even after its deletion. The
+// service and plan of the instance, when known, name the stack after the
+// template until it is found.
+type trackedStack struct {
+	Name      string
+	ID        string
+	ServiceID string
+	PlanID    string
+}
+
+// stackTracker keeps the stack of each instance.
+type stackTracker struct {
+	sync.Mutex
+	stacks map[string]trackedStack
+}
+
+func newStackTracker() *stackTracker {
+	return &stackTracker{
+		stacks: make(map[string]trackedStack),
+	}
+}
+
+func (t *stackTracker) Get(instanceID string) (trackedStack, bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	stack, ok := t.stacks[instanceID]
+	return stack, ok
+}
+
+func (t *stackTracker) SetName(instanceID string, stackName string) {
+	t.Lock()
+	defer t.Unlock()
+
+	stack := t.stacks[instanceID]
+	stack.Name = stackName
+	t.stacks[instanceID] = stack
+}
+
+func (t *stackTracker) SetPlan(instanceID string, serviceID string, planID string) {
+	t.Lock()
+	defer t.Unlock()
+
+	stack := t.stacks[instanceID]
+	if serviceID != "" {
+		stack.ServiceID = serviceID
+	}
+	if planID != "" {
+		stack.PlanID = planID
+	}
+	t.stacks[instanceID] = stack
+}
+
+func (t *stackTracker) SetID(instanceID string, stackID string) {
+	if stackID == "" {
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	stack := t.stacks[instanceID]
+	stack.ID = stackID
+	t.stacks[instanceID] = stack
+}
+
+func (t *stackTracker) Forget(instanceID string) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.stacks, instanceID)
+}
+
+// describeTrackedStack describes the stack of an instance by its ID when it
+// is known, and by its resolved name otherwise, remembering the ID of the
+// stack found.
+func (b *CloudFormationBroker) describeTrackedStack(instanceID string) (awscf.StackDetails, error) {
+	stackName, err := b.resolveStackName(instanceID)
+	if err != nil {
+		return awscf.StackDetails{}, err
+	}
+	if stack, ok := b.stacks.Get(instanceID); ok && stack.ID != "" {
+		stackName = stack.ID
+	}
+
+	stackDetails, err := b.stack.Describe(stackName)
+	if err != nil {
+		return stackDetails, err
+	}
+
+	b.stacks.SetID(instanceID, stackDetails.StackID)
+
+	return stackDetails, nil
+}
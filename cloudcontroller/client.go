@@ -0,0 +1,158 @@
+package cloudcontroller
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const requestTimeout = 30 * time.Second
+
+// tokenExpiryMargin renews access tokens a bit before they expire.
+const tokenExpiryMargin = 30 * time.Second
+
+// Client reads the service instances known to a Cloud Foundry Cloud
+// Controller, authenticating against its UAA with client credentials. The
+// client must have the cloud_controller.admin_read_only authority.
+type Client struct {
+	sync.Mutex
+	apiURL       string
+	clientID     string
+	clientSecret string
+	httpClient   *http.Client
+
+	tokenEndpoint string
+	accessToken   string
+	expiresAt     time.Time
+}
+
+func NewClient(apiURL string, clientID string, clientSecret string, skipSSLValidation bool) *Client {
+	return &Client{
+		apiURL:       strings.TrimSuffix(apiURL, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		httpClient: &http.Client{
+			Timeout: requestTimeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: skipSSLValidation},
+			},
+		},
+	}
+}
+
+type infoResponse struct {
+	TokenEndpoint string `json:"token_endpoint"`
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+type serviceInstancesResponse struct {
+	NextURL   string `json:"next_url"`
+	Resources []struct {
+		Metadata struct {
+			GUID string `json:"guid"`
+		} `json:"metadata"`
+	} `json:"resources"`
+}
+
+// ServiceInstanceGUIDs returns the GUIDs of all the service instances known
+// to the Cloud Controller, which are the instance IDs sent to the brokers.
+func (c *Client) ServiceInstanceGUIDs() (map[string]bool, error) {
+	guids := make(map[string]bool)
+
+	path := "/v2/service_instances?results-per-page=100"
+	for path != "" {
+		var serviceInstances serviceInstancesResponse
+		if err := c.get(path, &serviceInstances); err != nil {
+			return nil, err
+		}
+
+		for _, resource := range serviceInstances.Resources {
+			guids[resource.Metadata.GUID] = true
+		}
+
+		path = serviceInstances.NextURL
+	}
+
+	return guids, nil
+}
+
+func (c *Client) get(path string, response interface{}) error {
+	accessToken, err := c.token()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("GET", c.apiURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+accessToken)
+
+	return c.do(req, response)
+}
+
+func (c *Client) token() (string, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.accessToken != "" && time.Now().Before(c.expiresAt) {
+		return c.accessToken, nil
+	}
+
+	if c.tokenEndpoint == "" {
+		req, err := http.NewRequest("GET", c.apiURL+"/v2/info", nil)
+		if err != nil {
+			return "", err
+		}
+
+		var info infoResponse
+		if err := c.do(req, &info); err != nil {
+			return "", err
+		}
+		c.tokenEndpoint = strings.TrimSuffix(info.TokenEndpoint, "/")
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	req, err := http.NewRequest("POST", c.tokenEndpoint+"/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(c.clientID, c.clientSecret)
+
+	var token tokenResponse
+	if err := c.do(req, &token); err != nil {
+		return "", err
+	}
+
+	c.accessToken = token.AccessToken
+	c.expiresAt = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - tokenExpiryMargin)
+
+	return c.accessToken, nil
+}
+
+func (c *Client) do(req *http.Request, response interface{}) error {
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s returned status %d", req.Method, req.URL.Path, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(response)
+}
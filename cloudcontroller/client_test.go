@@ -0,0 +1,83 @@
+package cloudcontroller_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/cloudcontroller"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		server        *httptest.Server
+		tokenRequests int
+		instancesCode int
+		client        *Client
+	)
+
+	BeforeEach(func() {
+		tokenRequests = 0
+		instancesCode = http.StatusOK
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/v2/info", func(w http.ResponseWriter, req *http.Request) {
+			fmt.Fprintf(w, `{"token_endpoint": "%s/uaa"}`, server.URL)
+		})
+		mux.HandleFunc("/uaa/oauth/token", func(w http.ResponseWriter, req *http.Request) {
+			tokenRequests++
+			username, password, _ := req.BasicAuth()
+			Expect(username).To(Equal("client-id"))
+			Expect(password).To(Equal("client-secret"))
+			Expect(req.FormValue("grant_type")).To(Equal("client_credentials"))
+			fmt.Fprint(w, `{"access_token": "test-token", "expires_in": 3600}`)
+		})
+		mux.HandleFunc("/v2/service_instances", func(w http.ResponseWriter, req *http.Request) {
+			Expect(req.Header.Get("Authorization")).To(Equal("bearer test-token"))
+			if instancesCode != http.StatusOK {
+				w.WriteHeader(instancesCode)
+				return
+			}
+			if req.URL.Query().Get("page") == "2" {
+				fmt.Fprint(w, `{"next_url": null, "resources": [{"metadata": {"guid": "instance-2"}}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"next_url": "/v2/service_instances?page=2", "resources": [{"metadata": {"guid": "instance-1"}}]}`)
+		})
+		server = httptest.NewServer(mux)
+
+		client = NewClient(server.URL+"/", "client-id", "client-secret", false)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("returns the GUIDs of the service instances of every page", func() {
+		guids, err := client.ServiceInstanceGUIDs()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(guids).To(Equal(map[string]bool{"instance-1": true, "instance-2": true}))
+	})
+
+	It("reuses the access token until it expires", func() {
+		_, err := client.ServiceInstanceGUIDs()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.ServiceInstanceGUIDs()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tokenRequests).To(Equal(1))
+	})
+
+	Context("when the Cloud Controller returns an error", func() {
+		BeforeEach(func() {
+			instancesCode = http.StatusForbidden
+		})
+
+		It("returns the proper error", func() {
+			_, err := client.ServiceInstanceGUIDs()
+			Expect(err).To(MatchError("GET /v2/service_instances returned status 403"))
+		})
+	})
+})
@@ -0,0 +1,125 @@
+package concurrency
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/frodenas/brokerapi"
+
+	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+)
+
+const statusUnprocessableEntity = 422
+
+// NewHandler serves the Service Broker API built by newBrokerAPI, answering
+// the requests that failed with a cfbroker.ConcurrencyError with the 422
+// ConcurrencyError response of the Open Service Broker API, and the requests
+// that failed with a cfbroker.SynchronousTimeoutError or a
+// cfbroker.SynchronousOperationError with a 422 response describing the
+// operation still in progress or the stack status it failed with, all of
+// which the Service Broker API library would answer with a 500. A 500 would
+// also have the platform delete the service instance whose provision is
+// still going on, or report a failed stack operation as a broker failure.
+func NewHandler(serviceBroker brokerapi.ServiceBroker, newBrokerAPI func(brokerapi.ServiceBroker) http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		errorRecorder := NewServiceBroker(serviceBroker)
+		newBrokerAPI(errorRecorder).ServeHTTP(&responseWriter{ResponseWriter: w, errorRecorder: errorRecorder}, req)
+	})
+}
+
+// responseWriter replaces the response to a request that failed with a
+// concurrency or synchronous operation error. The Service Broker API library
+// writes its response once the service broker returned, so the error is
+// known by then.
+type responseWriter struct {
+	http.ResponseWriter
+	errorRecorder *ServiceBroker
+	replaced      bool
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	var errorResponse brokerapi.ErrorResponse
+	switch err := w.errorRecorder.Err().(type) {
+	case cfbroker.ConcurrencyError:
+		errorResponse = brokerapi.ErrorResponse{Error: "ConcurrencyError", Description: err.Error()}
+	case cfbroker.SynchronousTimeoutError, cfbroker.SynchronousOperationError:
+		errorResponse = brokerapi.ErrorResponse{Description: err.Error()}
+	default:
+		w.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+
+	w.replaced = true
+	w.Header().Set("Content-Type", "application/json")
+	w.ResponseWriter.WriteHeader(statusUnprocessableEntity)
+	json.NewEncoder(w.ResponseWriter).Encode(errorResponse)
+}
+
+func (w *responseWriter) Write(body []byte) (int, error) {
+	if w.replaced {
+		return len(body), nil
+	}
+
+	return w.ResponseWriter.Write(body)
+}
+
+// ServiceBroker records the error returned by the service broker it wraps.
+type ServiceBroker struct {
+	serviceBroker brokerapi.ServiceBroker
+	err           error
+}
+
+func NewServiceBroker(serviceBroker brokerapi.ServiceBroker) *ServiceBroker {
+	return &ServiceBroker{serviceBroker: serviceBroker}
+}
+
+// Err returns the error of the last operation.
+func (b *ServiceBroker) Err() error {
+	return b.err
+}
+
+func (b *ServiceBroker) Services() brokerapi.CatalogResponse {
+	return b.serviceBroker.Services()
+}
+
+func (b *ServiceBroker) Provision(instanceID string, details brokerapi.ProvisionDetails, acceptsIncomplete bool) (brokerapi.ProvisioningResponse, bool, error) {
+	provisioningResponse, asynch, err := b.serviceBroker.Provision(instanceID, details, acceptsIncomplete)
+	b.err = err
+
+	return provisioningResponse, asynch, err
+}
+
+func (b *ServiceBroker) Update(instanceID string, details brokerapi.UpdateDetails, acceptsIncomplete bool) (bool, error) {
+	asynch, err := b.serviceBroker.Update(instanceID, details, acceptsIncomplete)
+	b.err = err
+
+	return asynch, err
+}
+
+func (b *ServiceBroker) Deprovision(instanceID string, details brokerapi.DeprovisionDetails, acceptsIncomplete bool) (bool, error) {
+	asynch, err := b.serviceBroker.Deprovision(instanceID, details, acceptsIncomplete)
+	b.err = err
+
+	return asynch, err
+}
+
+func (b *ServiceBroker) Bind(instanceID, bindingID string, details brokerapi.BindDetails) (brokerapi.BindingResponse, error) {
+	bindingResponse, err := b.serviceBroker.Bind(instanceID, bindingID, details)
+	b.err = err
+
+	return bindingResponse, err
+}
+
+func (b *ServiceBroker) Unbind(instanceID, bindingID string, details brokerapi.UnbindDetails) error {
+	err := b.serviceBroker.Unbind(instanceID, bindingID, details)
+	b.err = err
+
+	return err
+}
+
+func (b *ServiceBroker) LastOperation(instanceID string) (brokerapi.LastOperationResponse, error) {
+	lastOperationResponse, err := b.serviceBroker.LastOperation(instanceID)
+	b.err = err
+
+	return lastOperationResponse, err
+}
@@ -0,0 +1,113 @@
+package concurrency_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/concurrency"
+
+	"github.com/frodenas/brokerapi"
+	brokerfakes "github.com/frodenas/brokerapi/fakes"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+)
+
+var _ = Describe("NewHandler", func() {
+	var (
+		fakeServiceBroker *brokerfakes.FakeServiceBroker
+		handler           http.Handler
+	)
+
+	BeforeEach(func() {
+		fakeServiceBroker = &brokerfakes.FakeServiceBroker{}
+		credentials := brokerapi.BrokerCredentials{Username: "username", Password: "password"}
+		handler = NewHandler(fakeServiceBroker, func(serviceBroker brokerapi.ServiceBroker) http.Handler {
+			return brokerapi.New(serviceBroker, lagertest.NewTestLogger("concurrency"), credentials)
+		})
+	})
+
+	update := func() *httptest.ResponseRecorder {
+		request, err := http.NewRequest("PATCH", "/v2/service_instances/instance-id?accepts_incomplete=true", strings.NewReader(`{"service_id": "service-id", "plan_id": "plan-id"}`))
+		Expect(err).ToNot(HaveOccurred())
+		request.SetBasicAuth("username", "password")
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	It("serves the Service Broker API", func() {
+		fakeServiceBroker.UpdateAsynch = true
+
+		recorder := update()
+		Expect(recorder.Code).To(Equal(http.StatusAccepted))
+		Expect(fakeServiceBroker.UpdateInstanceID).To(Equal("instance-id"))
+	})
+
+	Context("when the request fails with a concurrency error", func() {
+		BeforeEach(func() {
+			fakeServiceBroker.UpdateError = cfbroker.ConcurrencyError{StackName: "cf-instance-id", Operation: "status is 'CREATE_IN_PROGRESS'"}
+		})
+
+		It("responds with a ConcurrencyError", func() {
+			recorder := update()
+			Expect(recorder.Code).To(Equal(422))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+			Expect(recorder.Body.String()).To(MatchJSON(`{
+				"error": "ConcurrencyError",
+				"description": "Stack 'cf-instance-id' has an operation in progress (status is 'CREATE_IN_PROGRESS'), retry once it completes"
+			}`))
+		})
+	})
+
+	Context("when the request fails with a synchronous timeout error", func() {
+		BeforeEach(func() {
+			fakeServiceBroker.UpdateError = cfbroker.SynchronousTimeoutError{StackName: "cf-instance-id", Operation: "update", Timeout: time.Minute}
+		})
+
+		It("responds with the operation in progress", func() {
+			recorder := update()
+			Expect(recorder.Code).To(Equal(422))
+			Expect(recorder.Body.String()).To(MatchJSON(`{
+				"description": "The update of stack 'cf-instance-id' did not complete within 1m0s and is still in progress, retry once it completes"
+			}`))
+		})
+	})
+
+	Context("when the request fails with a synchronous operation error", func() {
+		BeforeEach(func() {
+			fakeServiceBroker.UpdateError = cfbroker.SynchronousOperationError{
+				StackName:   "cf-instance-id",
+				Operation:   "update",
+				Description: "The update of stack 'cf-instance-id' failed and was rolled back, the service instance is still usable with its previous configuration",
+			}
+		})
+
+		It("responds with the stack status the operation failed with", func() {
+			recorder := update()
+			Expect(recorder.Code).To(Equal(422))
+			Expect(recorder.Body.String()).To(MatchJSON(`{
+				"description": "The update of stack 'cf-instance-id' failed and was rolled back, the service instance is still usable with its previous configuration"
+			}`))
+		})
+	})
+
+	Context("when the request fails with another error", func() {
+		BeforeEach(func() {
+			fakeServiceBroker.UpdateError = errors.New("operation failed")
+		})
+
+		It("responds as the Service Broker API does", func() {
+			recorder := update()
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"description": "operation failed"}`))
+		})
+	})
+})
@@ -5,16 +5,93 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+	"github.com/cf-platform-eng/cloudformation-broker/logging"
+	"github.com/cf-platform-eng/cloudformation-broker/webhooks"
 )
 
 type Config struct {
-	LogLevel             string          `json:"log_level"`
-	Username             string          `json:"username"`
-	Password             string          `json:"password"`
-	CloudFormationConfig cfbroker.Config `json:"cloudformation_config"`
+	LogLevel             string                   `json:"log_level"`
+	Username             string                   `json:"username"`
+	Password             string                   `json:"password"`
+	CloudFormationConfig cfbroker.Config          `json:"cloudformation_config"`
+	AuditLog             AuditLogConfig           `json:"audit_log"`
+	Tracing              TracingConfig            `json:"tracing"`
+	LogSinks             []LogSinkConfig          `json:"log_sinks"`
+	Reconciler           ReconcilerConfig         `json:"reconciler"`
+	DriftDetection       DriftDetectionConfig     `json:"drift_detection"`
+	StackNotifications   StackNotificationsConfig `json:"stack_notifications"`
+	Webhooks             WebhooksConfig           `json:"webhooks"`
+}
+
+type AuditLogConfig struct {
+	File          string `json:"file"`
+	Syslog        bool   `json:"syslog"`
+	SyslogNetwork string `json:"syslog_network"`
+	SyslogAddress string `json:"syslog_address"`
+}
+
+const logSinkTypeFile = "file"
+const logSinkTypeSyslog = "syslog"
+
+type LogSinkConfig struct {
+	Type          string `json:"type"`
+	Level         string `json:"level"`
+	Path          string `json:"path"`
+	MaxSizeInMB   int64  `json:"max_size_in_mb"`
+	MaxBackups    int    `json:"max_backups"`
+	SyslogNetwork string `json:"syslog_network"`
+	SyslogAddress string `json:"syslog_address"`
+}
+
+type TracingConfig struct {
+	OTLPEndpoint string `json:"otlp_endpoint"`
+	ServiceName  string `json:"service_name"`
+}
+
+const defaultReconcilerIntervalInMinutes = 60
+const defaultReconcilerGracePeriodInMinutes = 24 * 60
+
+type ReconcilerConfig struct {
+	CloudControllerURL   string `json:"cloud_controller_url"`
+	ClientID             string `json:"client_id"`
+	ClientSecret         string `json:"client_secret"`
+	SkipSSLValidation    bool   `json:"skip_ssl_validation"`
+	IntervalInMinutes    int    `json:"interval_in_minutes"`
+	GracePeriodInMinutes int    `json:"grace_period_in_minutes"`
+	DeleteOrphans        bool   `json:"delete_orphans"`
+}
+
+type DriftDetectionConfig struct {
+	IntervalInMinutes     int  `json:"interval_in_minutes"`
+	ReportInLastOperation bool `json:"report_in_last_operation"`
+}
+
+const defaultStackStatusMaxAgeInSeconds = 5 * 60
+
+type StackNotificationsConfig struct {
+	TopicARN              string `json:"topic_arn"`
+	QueueURL              string `json:"queue_url"`
+	SQSEndpoint           string `json:"sqs_endpoint"`
+	StatusMaxAgeInSeconds int    `json:"status_max_age_in_seconds"`
+}
+
+type WebhooksConfig struct {
+	Endpoints              []WebhookConfig `json:"endpoints"`
+	MaxAttempts            int             `json:"max_attempts"`
+	RetryIntervalInSeconds int             `json:"retry_interval_in_seconds"`
+	DeadLetterFile         string          `json:"dead_letter_file"`
+}
+
+type WebhookConfig struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
 }
 
 func LoadConfig(configFile string) (config *Config, err error) {
@@ -49,6 +126,10 @@ func (c Config) Validate() error {
 		return errors.New("Must provide a non-empty LogLevel")
 	}
 
+	if _, err := logging.ParseLevel(c.LogLevel); err != nil {
+		return err
+	}
+
 	if c.Username == "" {
 		return errors.New("Must provide a non-empty Username")
 	}
@@ -61,5 +142,267 @@ func (c Config) Validate() error {
 		return fmt.Errorf("Validating CloudFormation configuration: %s", err)
 	}
 
+	if err := c.AuditLog.Validate(); err != nil {
+		return fmt.Errorf("Validating Audit Log configuration: %s", err)
+	}
+
+	if err := c.Tracing.Validate(); err != nil {
+		return fmt.Errorf("Validating Tracing configuration: %s", err)
+	}
+
+	if err := c.Reconciler.Validate(); err != nil {
+		return fmt.Errorf("Validating Reconciler configuration: %s", err)
+	}
+
+	if err := c.DriftDetection.Validate(); err != nil {
+		return fmt.Errorf("Validating Drift Detection configuration: %s", err)
+	}
+
+	if err := c.StackNotifications.Validate(); err != nil {
+		return fmt.Errorf("Validating Stack Notifications configuration: %s", err)
+	}
+
+	if err := c.Webhooks.Validate(); err != nil {
+		return fmt.Errorf("Validating Webhooks configuration: %s", err)
+	}
+
+	for i, logSink := range c.LogSinks {
+		if err := logSink.Validate(); err != nil {
+			return fmt.Errorf("Validating Log Sink %d configuration: %s", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (c AuditLogConfig) Enabled() bool {
+	return c.File != "" || c.Syslog
+}
+
+func (c AuditLogConfig) Validate() error {
+	if (c.SyslogNetwork != "" || c.SyslogAddress != "") && !c.Syslog {
+		return errors.New("Must enable Syslog to use a SyslogNetwork or SyslogAddress")
+	}
+
+	if (c.SyslogNetwork == "") != (c.SyslogAddress == "") {
+		return errors.New("Must provide both SyslogNetwork and SyslogAddress, or none to use the local syslog")
+	}
+
+	return nil
+}
+
+func (c LogSinkConfig) Validate() error {
+	if _, err := logging.ParseLevel(c.Level); err != nil {
+		return err
+	}
+
+	switch c.Type {
+	case logSinkTypeFile:
+		if c.Path == "" {
+			return errors.New("Must provide a non-empty Path")
+		}
+		if c.MaxSizeInMB < 0 || c.MaxBackups < 0 {
+			return errors.New("Must provide a non-negative MaxSizeInMB and MaxBackups")
+		}
+	case logSinkTypeSyslog:
+		if (c.SyslogNetwork == "") != (c.SyslogAddress == "") {
+			return errors.New("Must provide both SyslogNetwork and SyslogAddress, or none to use the local syslog")
+		}
+	default:
+		return fmt.Errorf("Invalid log sink type: %s", c.Type)
+	}
+
+	return nil
+}
+
+func (c LogSinkConfig) Name() string {
+	switch c.Type {
+	case logSinkTypeFile:
+		return c.Type + ":" + c.Path
+	case logSinkTypeSyslog:
+		if c.SyslogAddress != "" {
+			return c.Type + ":" + c.SyslogAddress
+		}
+	}
+
+	return c.Type
+}
+
+func (c TracingConfig) Enabled() bool {
+	return c.OTLPEndpoint != ""
+}
+
+func (c TracingConfig) Validate() error {
+	if !c.Enabled() {
+		if c.ServiceName != "" {
+			return errors.New("Must provide an OTLPEndpoint to use a ServiceName")
+		}
+		return nil
+	}
+
+	endpoint, err := url.Parse(c.OTLPEndpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return fmt.Errorf("Must provide an http or https OTLPEndpoint, got '%s'", c.OTLPEndpoint)
+	}
+
+	return nil
+}
+
+func (c ReconcilerConfig) Enabled() bool {
+	return c.CloudControllerURL != ""
+}
+
+func (c ReconcilerConfig) Validate() error {
+	if !c.Enabled() {
+		if c.ClientID != "" || c.DeleteOrphans {
+			return errors.New("Must provide a CloudControllerURL to reconcile stacks")
+		}
+		return nil
+	}
+
+	cloudControllerURL, err := url.Parse(c.CloudControllerURL)
+	if err != nil || (cloudControllerURL.Scheme != "http" && cloudControllerURL.Scheme != "https") || cloudControllerURL.Host == "" {
+		return fmt.Errorf("Must provide an http or https CloudControllerURL, got '%s'", c.CloudControllerURL)
+	}
+
+	if c.ClientID == "" || c.ClientSecret == "" {
+		return errors.New("Must provide a non-empty ClientID and ClientSecret")
+	}
+
+	if c.IntervalInMinutes < 0 || c.GracePeriodInMinutes < 0 {
+		return errors.New("Must provide a non-negative IntervalInMinutes and GracePeriodInMinutes")
+	}
+
+	return nil
+}
+
+func (c ReconcilerConfig) Interval() time.Duration {
+	if c.IntervalInMinutes == 0 {
+		return defaultReconcilerIntervalInMinutes * time.Minute
+	}
+
+	return time.Duration(c.IntervalInMinutes) * time.Minute
+}
+
+func (c ReconcilerConfig) GracePeriod() time.Duration {
+	if c.GracePeriodInMinutes == 0 {
+		return defaultReconcilerGracePeriodInMinutes * time.Minute
+	}
+
+	return time.Duration(c.GracePeriodInMinutes) * time.Minute
+}
+
+func (c DriftDetectionConfig) Enabled() bool {
+	return c.IntervalInMinutes > 0
+}
+
+func (c DriftDetectionConfig) Validate() error {
+	if c.IntervalInMinutes < 0 {
+		return errors.New("Must provide a non-negative IntervalInMinutes")
+	}
+
+	return nil
+}
+
+func (c DriftDetectionConfig) Interval() time.Duration {
+	return time.Duration(c.IntervalInMinutes) * time.Minute
+}
+
+func (c StackNotificationsConfig) Enabled() bool {
+	return c.TopicARN != ""
+}
+
+func (c StackNotificationsConfig) Validate() error {
+	if !c.Enabled() {
+		if c.QueueURL != "" || c.SQSEndpoint != "" {
+			return errors.New("Must provide a TopicARN to receive stack notifications")
+		}
+		return nil
+	}
+
+	if !strings.HasPrefix(c.TopicARN, "arn:") {
+		return fmt.Errorf("Must provide an SNS TopicARN, got '%s'", c.TopicARN)
+	}
+
+	queueURL, err := url.Parse(c.QueueURL)
+	if err != nil || (queueURL.Scheme != "http" && queueURL.Scheme != "https") || queueURL.Host == "" {
+		return fmt.Errorf("Must provide an http or https QueueURL, got '%s'", c.QueueURL)
+	}
+
+	if c.SQSEndpoint != "" {
+		sqsEndpoint, err := url.Parse(c.SQSEndpoint)
+		if err != nil || (sqsEndpoint.Scheme != "http" && sqsEndpoint.Scheme != "https") || sqsEndpoint.Host == "" {
+			return fmt.Errorf("Must provide an http or https SQSEndpoint, got '%s'", c.SQSEndpoint)
+		}
+	}
+
+	if c.StatusMaxAgeInSeconds < 0 {
+		return errors.New("Must provide a non-negative StatusMaxAgeInSeconds")
+	}
+
+	return nil
+}
+
+func (c StackNotificationsConfig) StatusMaxAge() time.Duration {
+	if c.StatusMaxAgeInSeconds == 0 {
+		return defaultStackStatusMaxAgeInSeconds * time.Second
+	}
+
+	return time.Duration(c.StatusMaxAgeInSeconds) * time.Second
+}
+
+func (c WebhooksConfig) Enabled() bool {
+	return len(c.Endpoints) > 0
+}
+
+func (c WebhooksConfig) Validate() error {
+	if !c.Enabled() {
+		if c.DeadLetterFile != "" {
+			return errors.New("Must provide Endpoints to use a DeadLetterFile")
+		}
+		return nil
+	}
+
+	if c.MaxAttempts < 0 || c.RetryIntervalInSeconds < 0 {
+		return errors.New("Must provide a non-negative MaxAttempts and RetryIntervalInSeconds")
+	}
+
+	for i, endpoint := range c.Endpoints {
+		if err := endpoint.Validate(); err != nil {
+			return fmt.Errorf("Validating Endpoint %d configuration: %s", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (c WebhooksConfig) Attempts() int {
+	if c.MaxAttempts == 0 {
+		return webhooks.DefaultMaxAttempts
+	}
+
+	return c.MaxAttempts
+}
+
+func (c WebhooksConfig) RetryInterval() time.Duration {
+	if c.RetryIntervalInSeconds == 0 {
+		return webhooks.DefaultRetryInterval
+	}
+
+	return time.Duration(c.RetryIntervalInSeconds) * time.Second
+}
+
+func (c WebhookConfig) Validate() error {
+	webhookURL, err := url.Parse(c.URL)
+	if err != nil || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") || webhookURL.Host == "" {
+		return fmt.Errorf("Must provide an http or https URL, got '%s'", c.URL)
+	}
+
+	for _, event := range c.Events {
+		if !webhooks.ValidEventType(event) {
+			return fmt.Errorf("Invalid webhook event: %s", event)
+		}
+	}
+
 	return nil
 }
@@ -1,6 +1,8 @@
 package main_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -20,6 +22,7 @@ var _ = Describe("Config", func() {
 			CloudFormationConfig: cfbroker.Config{
 				Region:               "cloudformation-region",
 				CloudFormationPrefix: "cf",
+				BrokerID:             "broker-id",
 			},
 		}
 	)
@@ -42,6 +45,14 @@ var _ = Describe("Config", func() {
 			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty LogLevel"))
 		})
 
+		It("returns error if LogLevel is unknown", func() {
+			config.LogLevel = "verbose"
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Invalid log level: verbose"))
+		})
+
 		It("returns error if Username is not valid", func() {
 			config.Username = ""
 
@@ -65,5 +76,196 @@ var _ = Describe("Config", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("Validating CloudFormation configuration"))
 		})
+
+		It("does not return error if the Audit Log uses a remote syslog", func() {
+			config.AuditLog = AuditLogConfig{Syslog: true, SyslogNetwork: "udp", SyslogAddress: "syslog:514"}
+
+			err := config.Validate()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns error if the Audit Log syslog address is set without syslog", func() {
+			config.AuditLog = AuditLogConfig{SyslogNetwork: "udp", SyslogAddress: "syslog:514"}
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Validating Audit Log configuration: Must enable Syslog"))
+		})
+
+		It("returns error if the Audit Log syslog address is incomplete", func() {
+			config.AuditLog = AuditLogConfig{Syslog: true, SyslogAddress: "syslog:514"}
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide both SyslogNetwork and SyslogAddress"))
+		})
+
+		It("does not return error if Tracing exports to an OTLP collector", func() {
+			config.Tracing = TracingConfig{OTLPEndpoint: "http://localhost:4318", ServiceName: "cloudformation-broker"}
+
+			err := config.Validate()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns error if the Tracing OTLP endpoint is not an http URL", func() {
+			config.Tracing = TracingConfig{OTLPEndpoint: "localhost:4318"}
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Validating Tracing configuration: Must provide an http or https OTLPEndpoint"))
+		})
+
+		It("does not return error if the Reconciler reads the Cloud Controller", func() {
+			config.Reconciler = ReconcilerConfig{CloudControllerURL: "https://api.example.com", ClientID: "client-id", ClientSecret: "client-secret", DeleteOrphans: true}
+
+			err := config.Validate()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.Reconciler.Interval()).To(Equal(time.Hour))
+			Expect(config.Reconciler.GracePeriod()).To(Equal(24 * time.Hour))
+		})
+
+		It("returns error if the Reconciler has no client credentials", func() {
+			config.Reconciler = ReconcilerConfig{CloudControllerURL: "https://api.example.com"}
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Validating Reconciler configuration: Must provide a non-empty ClientID and ClientSecret"))
+		})
+
+		It("returns error if orphans must be deleted without a Cloud Controller", func() {
+			config.Reconciler = ReconcilerConfig{DeleteOrphans: true}
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide a CloudControllerURL to reconcile stacks"))
+		})
+
+		It("does not return error if the Drift Detection runs periodically", func() {
+			config.DriftDetection = DriftDetectionConfig{IntervalInMinutes: 720, ReportInLastOperation: true}
+
+			err := config.Validate()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.DriftDetection.Enabled()).To(BeTrue())
+			Expect(config.DriftDetection.Interval()).To(Equal(12 * time.Hour))
+		})
+
+		It("returns error if the Drift Detection interval is negative", func() {
+			config.DriftDetection = DriftDetectionConfig{IntervalInMinutes: -1}
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Validating Drift Detection configuration: Must provide a non-negative IntervalInMinutes"))
+		})
+
+		It("does not return error if Stack Notifications are received from a queue", func() {
+			config.StackNotifications = StackNotificationsConfig{
+				TopicARN:    "arn:aws:sns:us-east-1:123456789012:stack-notifications",
+				QueueURL:    "http://localhost:9324/queue/stack-notifications",
+				SQSEndpoint: "http://localhost:9324",
+			}
+
+			err := config.Validate()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.StackNotifications.Enabled()).To(BeTrue())
+			Expect(config.StackNotifications.StatusMaxAge()).To(Equal(5 * time.Minute))
+		})
+
+		It("returns error if Stack Notifications have no queue", func() {
+			config.StackNotifications = StackNotificationsConfig{TopicARN: "arn:aws:sns:us-east-1:123456789012:stack-notifications"}
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Validating Stack Notifications configuration: Must provide an http or https QueueURL"))
+		})
+
+		It("returns error if Stack Notifications have no topic", func() {
+			config.StackNotifications = StackNotificationsConfig{QueueURL: "https://sqs.us-east-1.amazonaws.com/123456789012/stack-notifications"}
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide a TopicARN to receive stack notifications"))
+		})
+
+		It("does not return error if Webhooks are configured", func() {
+			config.Webhooks = WebhooksConfig{
+				Endpoints: []WebhookConfig{
+					{URL: "https://portal.example.com/hooks/broker", Secret: "webhook-secret", Events: []string{"provision.succeeded", "provision.failed"}},
+					{URL: "http://chatops.example.com/broker"},
+				},
+				DeadLetterFile: "/var/log/broker-webhooks.log",
+			}
+
+			err := config.Validate()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.Webhooks.Enabled()).To(BeTrue())
+			Expect(config.Webhooks.Attempts()).To(Equal(5))
+			Expect(config.Webhooks.RetryInterval()).To(Equal(5 * time.Second))
+		})
+
+		It("returns error if a Webhook URL is not valid", func() {
+			config.Webhooks = WebhooksConfig{Endpoints: []WebhookConfig{{URL: "portal.example.com"}}}
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Validating Webhooks configuration: Validating Endpoint 0 configuration: Must provide an http or https URL"))
+		})
+
+		It("returns error if a Webhook event is unknown", func() {
+			config.Webhooks = WebhooksConfig{Endpoints: []WebhookConfig{{URL: "https://portal.example.com", Events: []string{"unbind.accepted"}}}}
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Invalid webhook event: unbind.accepted"))
+		})
+
+		It("returns error if Webhooks have a dead letter file but no endpoints", func() {
+			config.Webhooks = WebhooksConfig{DeadLetterFile: "/var/log/broker-webhooks.log"}
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide Endpoints to use a DeadLetterFile"))
+		})
+
+		It("does not return error if the Log Sinks are valid", func() {
+			config.LogSinks = []LogSinkConfig{
+				{Type: "file", Level: "debug", Path: "/var/log/broker.log", MaxSizeInMB: 100, MaxBackups: 5},
+				{Type: "syslog", Level: "error"},
+			}
+
+			err := config.Validate()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns error if a Log Sink type is unknown", func() {
+			config.LogSinks = []LogSinkConfig{{Type: "kafka", Level: "info"}}
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Validating Log Sink 0 configuration: Invalid log sink type: kafka"))
+		})
+
+		It("returns error if a Log Sink level is not valid", func() {
+			config.LogSinks = []LogSinkConfig{{Type: "syslog"}}
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Invalid log level"))
+		})
+
+		It("returns error if a file Log Sink has no path", func() {
+			config.LogSinks = []LogSinkConfig{{Type: "file", Level: "info"}}
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty Path"))
+		})
+
+		It("returns error if a syslog Log Sink address is incomplete", func() {
+			config.LogSinks = []LogSinkConfig{{Type: "syslog", Level: "info", SyslogNetwork: "udp"}}
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide both SyslogNetwork and SyslogAddress"))
+		})
 	})
 })
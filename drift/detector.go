@@ -0,0 +1,249 @@
+package drift
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+)
+
+const instanceIDLogKey = "instance-id"
+const stackNameLogKey = "stack-name"
+
+// detectionTimeout bounds the time a drift detection is polled for.
+const detectionTimeout = 10 * time.Minute
+
+// Broker holds the broker operations the detector is built on.
+type Broker interface {
+	OwnedInstances() ([]cfbroker.OwnedInstance, error)
+	StackName(instanceID string) (string, error)
+}
+
+// Result is the outcome of the last drift detection of the stack of a
+// service instance.
+type Result struct {
+	InstanceID            string
+	StackName             string
+	DetectionStatus       string
+	DetectionStatusReason string
+	StackDriftStatus      string
+	DriftedResources      []awscf.ResourceDrift
+	CheckedAt             time.Time
+}
+
+// Drifted returns whether resources of the stack were modified or deleted
+// outside of AWS CloudFormation.
+func (r Result) Drifted() bool {
+	return r.StackDriftStatus == awscf.StackDriftStatusDrifted
+}
+
+// Detector detects the drift of the stacks created by the broker, either
+// periodically for all of them or on demand for a single one, and keeps the
+// result of the last detection of each stack.
+type Detector struct {
+	sync.Mutex
+	broker       Broker
+	stack        awscf.Stack
+	pollInterval time.Duration
+	logger       lager.Logger
+
+	results map[string]Result
+}
+
+func NewDetector(broker Broker, stack awscf.Stack, pollInterval time.Duration, logger lager.Logger) *Detector {
+	return &Detector{
+		broker:       broker,
+		stack:        stack,
+		pollInterval: pollInterval,
+		logger:       logger.Session("drift-detector"),
+		results:      make(map[string]Result),
+	}
+}
+
+// Run sweeps the stacks every interval, forever.
+func (d *Detector) Run(interval time.Duration) {
+	for range time.Tick(interval) {
+		if err := d.Sweep(); err != nil {
+			d.logger.Error("sweep", err)
+		}
+	}
+}
+
+// Sweep detects the drift of every stack created by the broker whose status
+// allows it, one at a time so as not to exceed the AWS CloudFormation
+// limits. Failing detections are logged and do not stop the sweep.
+func (d *Detector) Sweep() error {
+	ownedInstances, err := d.broker.OwnedInstances()
+	if err != nil {
+		return err
+	}
+
+	owned := make(map[string]bool)
+	for _, ownedInstance := range ownedInstances {
+		owned[ownedInstance.InstanceID] = true
+
+		if !ownedInstance.StackStatus.Usable() {
+			continue
+		}
+
+		detectionID, err := d.stack.DetectDrift(ownedInstance.StackName)
+		if err != nil {
+			d.logger.Error("detect-drift-failed", err, lager.Data{
+				instanceIDLogKey: ownedInstance.InstanceID,
+				stackNameLogKey:  ownedInstance.StackName,
+			})
+			continue
+		}
+
+		if err := d.waitForDetection(ownedInstance.InstanceID, ownedInstance.StackName, detectionID); err != nil {
+			d.logger.Error("detect-drift-failed", err, lager.Data{
+				instanceIDLogKey: ownedInstance.InstanceID,
+				stackNameLogKey:  ownedInstance.StackName,
+			})
+		}
+	}
+
+	d.Lock()
+	for instanceID := range d.results {
+		if !owned[instanceID] {
+			delete(d.results, instanceID)
+		}
+	}
+	d.Unlock()
+
+	return nil
+}
+
+// Start starts detecting the drift of the stack of a service instance, and
+// waits for its result in the background.
+func (d *Detector) Start(instanceID string) error {
+	stackName, err := d.broker.StackName(instanceID)
+	if err != nil {
+		return err
+	}
+
+	detectionID, err := d.stack.DetectDrift(stackName)
+	if err != nil {
+		if err == awscf.ErrStackDoesNotExist {
+			return brokerapi.ErrInstanceDoesNotExist
+		}
+		return err
+	}
+
+	go func() {
+		if err := d.waitForDetection(instanceID, stackName, detectionID); err != nil {
+			d.logger.Error("detect-drift-failed", err, lager.Data{
+				instanceIDLogKey: instanceID,
+				stackNameLogKey:  stackName,
+			})
+		}
+	}()
+
+	return nil
+}
+
+// Result returns the result of the last drift detection of the stack of a
+// service instance, if any.
+func (d *Detector) Result(instanceID string) (Result, bool) {
+	d.Lock()
+	defer d.Unlock()
+
+	result, ok := d.results[instanceID]
+
+	return result, ok
+}
+
+// Results returns the result of the last drift detection of every stack.
+func (d *Detector) Results() []Result {
+	d.Lock()
+	defer d.Unlock()
+
+	results := []Result{}
+	for _, result := range d.results {
+		results = append(results, result)
+	}
+	sort.Sort(byInstanceID(results))
+
+	return results
+}
+
+// DriftDescription describes the drifted resources of the stack of a
+// service instance, if its last drift detection found any.
+func (d *Detector) DriftDescription(instanceID string) string {
+	result, ok := d.Result(instanceID)
+	if !ok || !result.Drifted() {
+		return ""
+	}
+
+	logicalResourceIDs := []string{}
+	for _, resourceDrift := range result.DriftedResources {
+		logicalResourceIDs = append(logicalResourceIDs, resourceDrift.LogicalResourceID)
+	}
+
+	return fmt.Sprintf("Stack '%s' has drifted, resources modified or deleted outside of AWS CloudFormation: %s", result.StackName, strings.Join(logicalResourceIDs, ", "))
+}
+
+func (d *Detector) waitForDetection(instanceID string, stackName string, detectionID string) error {
+	deadline := time.Now().Add(detectionTimeout)
+
+	for {
+		driftDetection, err := d.stack.DriftDetectionStatus(detectionID)
+		if err != nil {
+			return err
+		}
+
+		if driftDetection.DetectionStatus != awscf.DriftDetectionInProgress {
+			return d.recordDetection(instanceID, stackName, driftDetection)
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Drift detection '%s' of stack '%s' did not complete after %s", detectionID, stackName, detectionTimeout)
+		}
+
+		time.Sleep(d.pollInterval)
+	}
+}
+
+func (d *Detector) recordDetection(instanceID string, stackName string, driftDetection awscf.DriftDetection) error {
+	result := Result{
+		InstanceID:            instanceID,
+		StackName:             stackName,
+		DetectionStatus:       driftDetection.DetectionStatus,
+		DetectionStatusReason: driftDetection.DetectionStatusReason,
+		StackDriftStatus:      driftDetection.StackDriftStatus,
+		CheckedAt:             driftDetection.Timestamp,
+	}
+
+	if result.Drifted() {
+		resourceDrifts, err := d.stack.ResourceDrifts(stackName)
+		if err != nil {
+			return err
+		}
+		result.DriftedResources = resourceDrifts
+
+		d.logger.Info("stack-drifted", lager.Data{
+			instanceIDLogKey:    instanceID,
+			stackNameLogKey:     stackName,
+			"drifted-resources": len(resourceDrifts),
+		})
+	}
+
+	d.Lock()
+	d.results[instanceID] = result
+	d.Unlock()
+
+	return nil
+}
+
+type byInstanceID []Result
+
+func (r byInstanceID) Len() int           { return len(r) }
+func (r byInstanceID) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r byInstanceID) Less(i, j int) bool { return r[i].InstanceID < r[j].InstanceID }
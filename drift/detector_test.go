@@ -0,0 +1,179 @@
+package drift_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/drift"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+	cffake "github.com/cf-platform-eng/cloudformation-broker/awscf/fakes"
+	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+	"github.com/cf-platform-eng/cloudformation-broker/drift/fakes"
+)
+
+var _ = Describe("Detector", func() {
+	var (
+		broker *fakes.FakeBroker
+		stack  *cffake.FakeStack
+		logger *lagertest.TestLogger
+
+		detector *Detector
+
+		checkedAt = time.Date(2018, 11, 13, 12, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		broker = &fakes.FakeBroker{
+			OwnedInstancesInstances: []cfbroker.OwnedInstance{
+				cfbroker.OwnedInstance{
+					InstanceSummary: cfbroker.InstanceSummary{
+						InstanceID:  "instance-id",
+						StackName:   "cf-instance-id",
+						StackStatus: awscf.NewStatus("UPDATE_COMPLETE", ""),
+					},
+				},
+				cfbroker.OwnedInstance{
+					InstanceSummary: cfbroker.InstanceSummary{
+						InstanceID:  "updating",
+						StackName:   "cf-updating",
+						StackStatus: awscf.NewStatus("UPDATE_IN_PROGRESS", ""),
+					},
+				},
+			},
+		}
+		stack = &cffake.FakeStack{
+			DetectDriftDetectionID: "detection-id",
+			DriftDetectionStatusDetection: awscf.DriftDetection{
+				DetectionID:          "detection-id",
+				DetectionStatus:      awscf.DriftDetectionComplete,
+				StackDriftStatus:     awscf.StackDriftStatusDrifted,
+				DriftedResourceCount: 1,
+				Timestamp:            checkedAt,
+			},
+			ResourceDriftsDrifts: []awscf.ResourceDrift{
+				awscf.ResourceDrift{LogicalResourceID: "Bucket", DriftStatus: "MODIFIED"},
+			},
+		}
+		logger = lagertest.NewTestLogger("drift-test")
+	})
+
+	JustBeforeEach(func() {
+		detector = NewDetector(broker, stack, time.Millisecond, logger)
+	})
+
+	Describe("Sweep", func() {
+		It("detects the drift of the stacks whose status allows it", func() {
+			Expect(detector.Sweep()).To(Succeed())
+			Expect(stack.DetectDriftStackName).To(Equal("cf-instance-id"))
+			Expect(stack.DriftDetectionStatusDetectionID).To(Equal("detection-id"))
+			Expect(stack.ResourceDriftsStackName).To(Equal("cf-instance-id"))
+
+			Expect(detector.Results()).To(Equal([]Result{
+				Result{
+					InstanceID:       "instance-id",
+					StackName:        "cf-instance-id",
+					DetectionStatus:  awscf.DriftDetectionComplete,
+					StackDriftStatus: awscf.StackDriftStatusDrifted,
+					DriftedResources: stack.ResourceDriftsDrifts,
+					CheckedAt:        checkedAt,
+				},
+			}))
+		})
+
+		It("forgets the stacks that are gone", func() {
+			Expect(detector.Sweep()).To(Succeed())
+
+			broker.OwnedInstancesInstances = []cfbroker.OwnedInstance{}
+			Expect(detector.Sweep()).To(Succeed())
+			Expect(detector.Results()).To(BeEmpty())
+		})
+
+		Context("when the stack is in sync", func() {
+			BeforeEach(func() {
+				stack.DriftDetectionStatusDetection.StackDriftStatus = awscf.StackDriftStatusInSync
+			})
+
+			It("does not look for drifted resources", func() {
+				Expect(detector.Sweep()).To(Succeed())
+				Expect(stack.ResourceDriftsCalled).To(BeFalse())
+
+				result, ok := detector.Result("instance-id")
+				Expect(ok).To(BeTrue())
+				Expect(result.Drifted()).To(BeFalse())
+			})
+		})
+
+		Context("when the detection fails", func() {
+			BeforeEach(func() {
+				stack.DetectDriftError = errors.New("operation failed")
+			})
+
+			It("logs the error and goes on", func() {
+				Expect(detector.Sweep()).To(Succeed())
+				Expect(logger.LogMessages()).To(ContainElement("drift-test.drift-detector.detect-drift-failed"))
+				Expect(detector.Results()).To(BeEmpty())
+			})
+		})
+
+		Context("when the stacks can not be listed", func() {
+			BeforeEach(func() {
+				broker.OwnedInstancesError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				Expect(detector.Sweep()).To(MatchError("operation failed"))
+			})
+		})
+	})
+
+	Describe("Start", func() {
+		It("detects the drift of the instance stack in the background", func() {
+			Expect(detector.Start("instance-id")).To(Succeed())
+			Expect(stack.DetectDriftStackName).To(Equal("cf-instance-id"))
+
+			Eventually(func() bool {
+				_, ok := detector.Result("instance-id")
+				return ok
+			}).Should(BeTrue())
+		})
+
+		Context("when the stack does not exist", func() {
+			BeforeEach(func() {
+				stack.DetectDriftError = awscf.ErrStackDoesNotExist
+			})
+
+			It("returns the proper error", func() {
+				Expect(detector.Start("instance-id")).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+			})
+		})
+
+		Context("when the stack name can not be resolved", func() {
+			BeforeEach(func() {
+				broker.StackNameError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				Expect(detector.Start("instance-id")).To(MatchError("operation failed"))
+				Expect(stack.DetectDriftCalled).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("DriftDescription", func() {
+		It("describes the drifted resources", func() {
+			Expect(detector.Sweep()).To(Succeed())
+			Expect(detector.DriftDescription("instance-id")).To(Equal("Stack 'cf-instance-id' has drifted, resources modified or deleted outside of AWS CloudFormation: Bucket"))
+		})
+
+		It("is empty when the drift of the stack is not known", func() {
+			Expect(detector.DriftDescription("instance-id")).To(BeEmpty())
+		})
+	})
+})
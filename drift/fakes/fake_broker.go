@@ -0,0 +1,23 @@
+package fakes
+
+import (
+	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+)
+
+type FakeBroker struct {
+	OwnedInstancesCalled    bool
+	OwnedInstancesInstances []cfbroker.OwnedInstance
+	OwnedInstancesError     error
+
+	StackNameError error
+}
+
+func (f *FakeBroker) OwnedInstances() ([]cfbroker.OwnedInstance, error) {
+	f.OwnedInstancesCalled = true
+
+	return f.OwnedInstancesInstances, f.OwnedInstancesError
+}
+
+func (f *FakeBroker) StackName(instanceID string) (string, error) {
+	return "cf-" + instanceID, f.StackNameError
+}
@@ -0,0 +1,37 @@
+package health
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+)
+
+// Check is a dependency the broker needs to serve requests.
+type Check struct {
+	Name string
+	Run  func() error
+}
+
+// NewCredentialsCheck checks that the AWS credentials can be resolved.
+func NewCredentialsCheck(region string, creds *credentials.Credentials) Check {
+	return Check{
+		Name: fmt.Sprintf("aws-credentials/%s", region),
+		Run: func() error {
+			_, err := creds.Get()
+			return err
+		},
+	}
+}
+
+// NewCloudFormationCheck checks that AWS CloudFormation can be called, using
+// a request that does not depend on the stacks of the account.
+func NewCloudFormationCheck(region string, cfsvc *cloudformation.CloudFormation) Check {
+	return Check{
+		Name: fmt.Sprintf("cloudformation/%s", region),
+		Run: func() error {
+			_, err := cfsvc.DescribeAccountLimits(&cloudformation.DescribeAccountLimitsInput{})
+			return err
+		},
+	}
+}
@@ -0,0 +1,70 @@
+package health_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/health"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+)
+
+var _ = Describe("Checks", func() {
+	var _ = Describe("NewCredentialsCheck", func() {
+		It("succeeds when the credentials resolve", func() {
+			check := NewCredentialsCheck("test-region", credentials.NewStaticCredentials("id", "secret", ""))
+			Expect(check.Name).To(Equal("aws-credentials/test-region"))
+			Expect(check.Run()).To(Succeed())
+		})
+
+		It("fails when the credentials do not resolve", func() {
+			check := NewCredentialsCheck("test-region", credentials.NewStaticCredentials("", "", ""))
+			Expect(check.Run()).ToNot(Succeed())
+		})
+	})
+
+	var _ = Describe("NewCloudFormationCheck", func() {
+		var (
+			cfsvc          *cloudformation.CloudFormation
+			operationNames []string
+			callError      error
+		)
+
+		BeforeEach(func() {
+			cfsvc = cloudformation.New(session.New(), aws.NewConfig().WithRegion("test-region").WithMaxRetries(0))
+			cfsvc.Handlers.Clear()
+			operationNames = []string{}
+			callError = nil
+			cfsvc.Handlers.Send.PushBack(func(r *request.Request) {
+				operationNames = append(operationNames, r.Operation.Name)
+				r.Error = callError
+			})
+		})
+
+		It("calls DescribeAccountLimits", func() {
+			check := NewCloudFormationCheck("test-region", cfsvc)
+			Expect(check.Name).To(Equal("cloudformation/test-region"))
+			Expect(check.Run()).To(Succeed())
+			Expect(operationNames).To(Equal([]string{"DescribeAccountLimits"}))
+		})
+
+		Context("when the call fails", func() {
+			BeforeEach(func() {
+				callError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				check := NewCloudFormationCheck("test-region", cfsvc)
+				err := check.Run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+		})
+	})
+})
@@ -0,0 +1,139 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const StatusOK = "ok"
+const StatusFailed = "failed"
+const StatusReady = "ready"
+const StatusNotReady = "not ready"
+
+// CheckFailedError is reported in place of the error of a failed check, as
+// readiness is not authenticated and AWS errors may reveal details of the
+// account. The error itself is logged.
+const CheckFailedError = "check failed, see the broker logs for details"
+
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+type ReadinessResponse struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+type CheckResult struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Health reports that the broker process is alive.
+func Health(w http.ResponseWriter, req *http.Request) {
+	respond(w, http.StatusOK, HealthResponse{Status: StatusOK})
+}
+
+// Readiness reports whether the broker dependencies are available. Check
+// results are cached, so frequent probes do not turn into AWS API calls.
+type Readiness struct {
+	sync.Mutex
+	checks   []Check
+	cacheTTL time.Duration
+	results  []CheckResult
+	logger   lager.Logger
+}
+
+func NewReadiness(checks []Check, cacheTTL time.Duration, logger lager.Logger) *Readiness {
+	return &Readiness{
+		checks:   checks,
+		cacheTTL: cacheTTL,
+		logger:   logger.Session("readiness"),
+	}
+}
+
+func (r *Readiness) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	readinessResponse := ReadinessResponse{
+		Status: StatusReady,
+		Checks: r.Results(),
+	}
+
+	status := http.StatusOK
+	for _, checkResult := range readinessResponse.Checks {
+		if checkResult.Status != StatusOK {
+			readinessResponse.Status = StatusNotReady
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	respond(w, status, readinessResponse)
+}
+
+// Results returns the result of every check, running them again if the
+// cached results have expired. The checks call AWS, so they run without the
+// lock held, not to hold up other probes while the cached results are still
+// valid; concurrent probes may then run the checks at the same time, and the
+// latest results are kept.
+func (r *Readiness) Results() []CheckResult {
+	now := time.Now()
+	if results, ok := r.cachedResults(now); ok {
+		return results
+	}
+
+	results := make([]CheckResult, len(r.checks))
+	for i, check := range r.checks {
+		results[i] = CheckResult{
+			Name:      check.Name,
+			Status:    StatusOK,
+			CheckedAt: now,
+		}
+		if err := check.Run(); err != nil {
+			r.logger.Error("check-failed", err, lager.Data{"check": check.Name})
+			results[i].Status = StatusFailed
+			results[i].Error = CheckFailedError
+		}
+	}
+
+	r.cacheResults(results)
+
+	return results
+}
+
+func (r *Readiness) cachedResults(now time.Time) ([]CheckResult, bool) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.results != nil && now.Sub(r.results[0].CheckedAt) < r.cacheTTL {
+		return r.results, true
+	}
+
+	return nil, false
+}
+
+func (r *Readiness) cacheResults(results []CheckResult) {
+	r.Lock()
+	defer r.Unlock()
+
+	if len(results) == 0 {
+		return
+	}
+	if r.results != nil && r.results[0].CheckedAt.After(results[0].CheckedAt) {
+		return
+	}
+
+	r.results = results
+}
+
+func respond(w http.ResponseWriter, status int, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(response)
+}
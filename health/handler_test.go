@@ -0,0 +1,160 @@
+package health_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/health"
+
+	"github.com/pivotal-golang/lager/lagertest"
+)
+
+var _ = Describe("Health", func() {
+	It("reports the broker as alive", func() {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/health", nil)
+		Health(recorder, request)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"status": "ok"}`))
+	})
+})
+
+var _ = Describe("Readiness", func() {
+	var (
+		cacheTTL        time.Duration
+		checkCalls      int
+		checkError      error
+		readiness       *Readiness
+		otherCheckCalls int
+		logger          *lagertest.TestLogger
+	)
+
+	BeforeEach(func() {
+		cacheTTL = time.Hour
+		checkCalls = 0
+		checkError = nil
+		otherCheckCalls = 0
+	})
+
+	JustBeforeEach(func() {
+		checks := []Check{
+			Check{
+				Name: "cloudformation/test-region",
+				Run: func() error {
+					checkCalls++
+					return checkError
+				},
+			},
+			Check{
+				Name: "aws-credentials/test-region",
+				Run: func() error {
+					otherCheckCalls++
+					return nil
+				},
+			},
+		}
+		logger = lagertest.NewTestLogger("health")
+		readiness = NewReadiness(checks, cacheTTL, logger)
+	})
+
+	probe := func() (*httptest.ResponseRecorder, ReadinessResponse) {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/ready", nil)
+		readiness.ServeHTTP(recorder, request)
+
+		readinessResponse := ReadinessResponse{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &readinessResponse)).To(Succeed())
+		return recorder, readinessResponse
+	}
+
+	It("reports the broker as ready", func() {
+		recorder, readinessResponse := probe()
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(readinessResponse.Status).To(Equal(StatusReady))
+		Expect(readinessResponse.Checks).To(HaveLen(2))
+		Expect(readinessResponse.Checks[0].Name).To(Equal("cloudformation/test-region"))
+		Expect(readinessResponse.Checks[0].Status).To(Equal(StatusOK))
+		Expect(readinessResponse.Checks[0].Error).To(BeEmpty())
+		Expect(readinessResponse.Checks[1].Name).To(Equal("aws-credentials/test-region"))
+		Expect(readinessResponse.Checks[1].Status).To(Equal(StatusOK))
+	})
+
+	It("caches the check results", func() {
+		probe()
+		probe()
+		Expect(checkCalls).To(Equal(1))
+		Expect(otherCheckCalls).To(Equal(1))
+	})
+
+	Context("when the cached results have expired", func() {
+		BeforeEach(func() {
+			cacheTTL = 0
+		})
+
+		It("runs the checks again", func() {
+			probe()
+			probe()
+			Expect(checkCalls).To(Equal(2))
+		})
+	})
+
+	Context("when a probe comes in while the checks are running", func() {
+		var otherProbeDone chan struct{}
+
+		JustBeforeEach(func() {
+			otherProbeDone = make(chan struct{})
+			checks := []Check{
+				Check{
+					Name: "cloudformation/test-region",
+					Run: func() error {
+						checkCalls++
+						if checkCalls == 1 {
+							readiness.Results()
+							close(otherProbeDone)
+						}
+						return nil
+					},
+				},
+			}
+			readiness = NewReadiness(checks, cacheTTL, logger)
+		})
+
+		It("does not hold the lock while running the checks", func() {
+			go readiness.Results()
+			Eventually(otherProbeDone).Should(BeClosed())
+		})
+	})
+
+	Context("when a check fails", func() {
+		BeforeEach(func() {
+			checkError = errors.New("operation failed")
+		})
+
+		It("reports the broker as not ready", func() {
+			recorder, readinessResponse := probe()
+			Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(readinessResponse.Status).To(Equal(StatusNotReady))
+			Expect(readinessResponse.Checks[0].Status).To(Equal(StatusFailed))
+			Expect(readinessResponse.Checks[1].Status).To(Equal(StatusOK))
+		})
+
+		It("does not expose the check error", func() {
+			_, readinessResponse := probe()
+			Expect(readinessResponse.Checks[0].Error).To(Equal(CheckFailedError))
+		})
+
+		It("logs the check error", func() {
+			probe()
+			Expect(logger.LogMessages()).To(ContainElement("health.readiness.check-failed"))
+			Expect(logger.Logs()[0].Data["error"]).To(Equal("operation failed"))
+		})
+	})
+})
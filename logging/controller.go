@@ -0,0 +1,167 @@
+package logging
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+)
+
+// SinkLevel holds the current and configured minimum log levels of a sink.
+type SinkLevel struct {
+	Name            string
+	Level           string
+	ConfiguredLevel string
+}
+
+// Levels describes the log levels of the sinks and the override set at
+// runtime, if any. A zero OverrideExpiresAt means the override lasts until
+// it is reset.
+type Levels struct {
+	Sinks             []SinkLevel
+	OverrideLevel     string
+	OverrideExpiresAt time.Time
+}
+
+type controlledSink struct {
+	name            string
+	sink            *lager.ReconfigurableSink
+	configuredLevel lager.LogLevel
+}
+
+// Controller changes the minimum log level of the broker sinks at runtime,
+// either until reset or for a limited time, after which every sink goes
+// back to its configured level.
+type Controller struct {
+	sync.Mutex
+	sinks             []controlledSink
+	overrideLevel     string
+	overrideExpiresAt time.Time
+	overrideTimer     *time.Timer
+	logger            lager.Logger
+}
+
+func NewController() *Controller {
+	return &Controller{}
+}
+
+// Register wraps a sink so that its minimum log level is controlled, and
+// returns the wrapped sink to register with a logger.
+func (c *Controller) Register(name string, sink lager.Sink, configuredLevel lager.LogLevel) lager.Sink {
+	c.Lock()
+	defer c.Unlock()
+
+	reconfigurableSink := lager.NewReconfigurableSink(sink, configuredLevel)
+	c.sinks = append(c.sinks, controlledSink{
+		name:            name,
+		sink:            reconfigurableSink,
+		configuredLevel: configuredLevel,
+	})
+
+	return reconfigurableSink
+}
+
+// SetLogger sets the logger recording the log level changes.
+func (c *Controller) SetLogger(logger lager.Logger) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.logger = logger.Session("log-level")
+}
+
+// SetLevel overrides the log level of every sink. The override is reset
+// once duration has elapsed, unless duration is zero.
+func (c *Controller) SetLevel(level string, duration time.Duration) error {
+	logLevel, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	c.stopOverrideTimer()
+
+	for _, sink := range c.sinks {
+		sink.sink.SetMinLevel(logLevel)
+	}
+	c.overrideLevel = LevelName(logLevel)
+	c.overrideExpiresAt = time.Time{}
+
+	if duration > 0 {
+		var overrideTimer *time.Timer
+		overrideTimer = time.AfterFunc(duration, func() {
+			c.expire(overrideTimer)
+		})
+		c.overrideExpiresAt = time.Now().Add(duration)
+		c.overrideTimer = overrideTimer
+	}
+
+	if c.logger != nil {
+		c.logger.Info("override", lager.Data{"level": c.overrideLevel, "duration": duration.String()})
+	}
+
+	return nil
+}
+
+// Reset sets every sink back to its configured log level.
+func (c *Controller) Reset() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.reset()
+}
+
+func (c *Controller) Levels() Levels {
+	c.Lock()
+	defer c.Unlock()
+
+	levels := Levels{
+		OverrideLevel:     c.overrideLevel,
+		OverrideExpiresAt: c.overrideExpiresAt,
+	}
+
+	for _, sink := range c.sinks {
+		levels.Sinks = append(levels.Sinks, SinkLevel{
+			Name:            sink.name,
+			Level:           LevelName(sink.sink.GetMinLevel()),
+			ConfiguredLevel: LevelName(sink.configuredLevel),
+		})
+	}
+
+	return levels
+}
+
+func (c *Controller) stopOverrideTimer() {
+	if c.overrideTimer != nil {
+		c.overrideTimer.Stop()
+		c.overrideTimer = nil
+	}
+}
+
+// expire resets the override set along with overrideTimer, unless it was
+// replaced while the timer fired.
+func (c *Controller) expire(overrideTimer *time.Timer) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.overrideTimer != overrideTimer {
+		return
+	}
+
+	c.reset()
+}
+
+func (c *Controller) reset() {
+	c.stopOverrideTimer()
+
+	for _, sink := range c.sinks {
+		sink.sink.SetMinLevel(sink.configuredLevel)
+	}
+
+	if c.overrideLevel != "" && c.logger != nil {
+		c.logger.Info("reset")
+	}
+	c.overrideLevel = ""
+	c.overrideExpiresAt = time.Time{}
+}
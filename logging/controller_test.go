@@ -0,0 +1,92 @@
+package logging_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/logging"
+
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+)
+
+var _ = Describe("Controller", func() {
+	var (
+		controller *Controller
+		stdoutSink *lagertest.TestSink
+		fileSink   *lagertest.TestSink
+		logger     lager.Logger
+	)
+
+	BeforeEach(func() {
+		controller = NewController()
+		stdoutSink = lagertest.NewTestSink()
+		fileSink = lagertest.NewTestSink()
+
+		logger = lager.NewLogger("logging")
+		logger.RegisterSink(controller.Register("stdout", stdoutSink, lager.INFO))
+		logger.RegisterSink(controller.Register("file", fileSink, lager.DEBUG))
+	})
+
+	It("logs with the configured level of each sink", func() {
+		logger.Debug("debug-message")
+
+		Expect(stdoutSink.LogMessages()).To(BeEmpty())
+		Expect(fileSink.LogMessages()).To(Equal([]string{"logging.debug-message"}))
+		Expect(controller.Levels()).To(Equal(Levels{
+			Sinks: []SinkLevel{
+				{Name: "stdout", Level: "INFO", ConfiguredLevel: "INFO"},
+				{Name: "file", Level: "DEBUG", ConfiguredLevel: "DEBUG"},
+			},
+		}))
+	})
+
+	Describe("SetLevel", func() {
+		It("overrides the level of every sink until reset", func() {
+			err := controller.SetLevel("error", 0)
+			Expect(err).ToNot(HaveOccurred())
+
+			logger.Info("info-message")
+			Expect(stdoutSink.LogMessages()).To(BeEmpty())
+			Expect(fileSink.LogMessages()).To(BeEmpty())
+
+			levels := controller.Levels()
+			Expect(levels.OverrideLevel).To(Equal("ERROR"))
+			Expect(levels.OverrideExpiresAt.IsZero()).To(BeTrue())
+			Expect(levels.Sinks[0].Level).To(Equal("ERROR"))
+
+			controller.Reset()
+
+			logger.Info("info-message")
+			Expect(stdoutSink.LogMessages()).To(Equal([]string{"logging.info-message"}))
+			Expect(controller.Levels().OverrideLevel).To(BeEmpty())
+			Expect(controller.Levels().Sinks[1].Level).To(Equal("DEBUG"))
+		})
+
+		It("resets the override once the duration has elapsed", func() {
+			err := controller.SetLevel("DEBUG", 50*time.Millisecond)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(controller.Levels().OverrideExpiresAt).To(BeTemporally("~", time.Now().Add(50*time.Millisecond), 20*time.Millisecond))
+
+			logger.Debug("debug-message")
+			Expect(stdoutSink.LogMessages()).To(Equal([]string{"logging.debug-message"}))
+
+			Eventually(func() string { return controller.Levels().Sinks[0].Level }).Should(Equal("INFO"))
+		})
+
+		It("is not reset by the duration of a replaced override", func() {
+			controller.SetLevel("DEBUG", 50*time.Millisecond)
+			controller.SetLevel("ERROR", 0)
+
+			Consistently(func() string { return controller.Levels().OverrideLevel }, 200*time.Millisecond).Should(Equal("ERROR"))
+		})
+
+		It("returns error if the level is not valid", func() {
+			err := controller.SetLevel("verbose", 0)
+			Expect(err).To(HaveOccurred())
+			Expect(controller.Levels().OverrideLevel).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,37 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pivotal-golang/lager"
+)
+
+var levels = map[string]lager.LogLevel{
+	"DEBUG": lager.DEBUG,
+	"INFO":  lager.INFO,
+	"ERROR": lager.ERROR,
+	"FATAL": lager.FATAL,
+}
+
+// ParseLevel returns the lager log level of a level name, such as DEBUG or
+// info.
+func ParseLevel(level string) (lager.LogLevel, error) {
+	logLevel, ok := levels[strings.ToUpper(level)]
+	if !ok {
+		return logLevel, fmt.Errorf("Invalid log level: %s", level)
+	}
+
+	return logLevel, nil
+}
+
+// LevelName returns the name of a lager log level.
+func LevelName(logLevel lager.LogLevel) string {
+	for name, level := range levels {
+		if level == logLevel {
+			return name
+		}
+	}
+
+	return fmt.Sprintf("%d", logLevel)
+}
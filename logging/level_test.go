@@ -0,0 +1,31 @@
+package logging_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/logging"
+
+	"github.com/pivotal-golang/lager"
+)
+
+var _ = Describe("Level", func() {
+	Describe("ParseLevel", func() {
+		It("returns the log level regardless of case", func() {
+			logLevel, err := ParseLevel("debug")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(logLevel).To(Equal(lager.DEBUG))
+		})
+
+		It("returns error if the level is not valid", func() {
+			_, err := ParseLevel("verbose")
+			Expect(err).To(MatchError("Invalid log level: verbose"))
+		})
+	})
+
+	Describe("LevelName", func() {
+		It("returns the name of the log level", func() {
+			Expect(LevelName(lager.ERROR)).To(Equal("ERROR"))
+		})
+	})
+})
@@ -0,0 +1,104 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingFile is a log file that is rotated once it reaches a maximum
+// size. Rotated files are renamed with a numeric suffix, path.1 being the
+// most recent, and only maxBackups of them are kept.
+type RotatingFile struct {
+	sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// NewRotatingFile opens the log file at path for appending. A zero maxSize
+// disables rotation.
+func NewRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	r := &RotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+
+	return n, err
+}
+
+func (r *RotatingFile) Close() error {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.file.Close()
+}
+
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	r.file = file
+	r.size = fileInfo.Size()
+
+	return nil
+}
+
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+
+	var err error
+	if r.maxBackups > 0 {
+		for i := r.maxBackups - 1; i > 0; i-- {
+			os.Rename(r.backupPath(i), r.backupPath(i+1))
+		}
+		err = os.Rename(r.path, r.backupPath(1))
+	} else {
+		err = os.Remove(r.path)
+	}
+
+	// The file is reopened even if it could not be moved, so that logging
+	// goes on
+	if openErr := r.open(); openErr != nil {
+		return openErr
+	}
+
+	return err
+}
+
+func (r *RotatingFile) backupPath(index int) string {
+	return fmt.Sprintf("%s.%d", r.path, index)
+}
@@ -0,0 +1,80 @@
+package logging_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/logging"
+)
+
+var _ = Describe("RotatingFile", func() {
+	var (
+		dir  string
+		path string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "logging")
+		Expect(err).ToNot(HaveOccurred())
+		path = filepath.Join(dir, "broker.log")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	readFile := func(path string) string {
+		contents, err := ioutil.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		return string(contents)
+	}
+
+	It("appends to the existing file", func() {
+		Expect(ioutil.WriteFile(path, []byte("existing\n"), 0600)).To(Succeed())
+
+		rotatingFile, err := NewRotatingFile(path, 0, 0)
+		Expect(err).ToNot(HaveOccurred())
+		defer rotatingFile.Close()
+
+		rotatingFile.Write([]byte("line\n"))
+		Expect(readFile(path)).To(Equal("existing\nline\n"))
+	})
+
+	It("rotates the file once it reaches the maximum size", func() {
+		rotatingFile, err := NewRotatingFile(path, 10, 2)
+		Expect(err).ToNot(HaveOccurred())
+		defer rotatingFile.Close()
+
+		for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+			_, err := rotatingFile.Write([]byte(line))
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		Expect(readFile(path)).To(Equal("fourth\n"))
+		Expect(readFile(path + ".1")).To(Equal("third\n"))
+		Expect(readFile(path + ".2")).To(Equal("second\n"))
+		Expect(path + ".3").ToNot(BeAnExistingFile())
+	})
+
+	It("discards the file when no backups are kept", func() {
+		rotatingFile, err := NewRotatingFile(path, 10, 0)
+		Expect(err).ToNot(HaveOccurred())
+		defer rotatingFile.Close()
+
+		rotatingFile.Write([]byte("first\n"))
+		rotatingFile.Write([]byte("second\n"))
+
+		Expect(readFile(path)).To(Equal("second\n"))
+		Expect(path + ".1").ToNot(BeAnExistingFile())
+	})
+
+	It("returns error if the file cannot be opened", func() {
+		_, err := NewRotatingFile(filepath.Join(dir, "missing", "broker.log"), 0, 0)
+		Expect(err).To(HaveOccurred())
+	})
+})
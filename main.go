@@ -3,10 +3,12 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"log/syslog"
 	"net/http"
 	"os"
-	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -14,37 +16,153 @@ import (
 	"github.com/frodenas/brokerapi"
 	"github.com/pivotal-golang/lager"
 
+	"github.com/cf-platform-eng/cloudformation-broker/adminapi"
+	"github.com/cf-platform-eng/cloudformation-broker/audit"
 	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+	"github.com/cf-platform-eng/cloudformation-broker/awsecr"
+	"github.com/cf-platform-eng/cloudformation-broker/awss3"
+	"github.com/cf-platform-eng/cloudformation-broker/awssqs"
 	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+	"github.com/cf-platform-eng/cloudformation-broker/cloudcontroller"
+	"github.com/cf-platform-eng/cloudformation-broker/concurrency"
+	"github.com/cf-platform-eng/cloudformation-broker/drift"
+	"github.com/cf-platform-eng/cloudformation-broker/health"
+	"github.com/cf-platform-eng/cloudformation-broker/logging"
+	"github.com/cf-platform-eng/cloudformation-broker/metrics"
+	"github.com/cf-platform-eng/cloudformation-broker/notifications"
+	"github.com/cf-platform-eng/cloudformation-broker/operationtokens"
+	"github.com/cf-platform-eng/cloudformation-broker/reconciler"
+	"github.com/cf-platform-eng/cloudformation-broker/redact"
+	"github.com/cf-platform-eng/cloudformation-broker/tracing"
+	"github.com/cf-platform-eng/cloudformation-broker/webhooks"
 )
 
 var (
 	configFilePath string
 	port           string
-
-	logLevels = map[string]lager.LogLevel{
-		"DEBUG": lager.DEBUG,
-		"INFO":  lager.INFO,
-		"ERROR": lager.ERROR,
-		"FATAL": lager.FATAL,
-	}
 )
 
+const readinessCacheTTL = 30 * time.Second
+
+const defaultTracingServiceName = "cloudformation-broker"
+const tracingExportInterval = 5 * time.Second
+
+const driftPollInterval = 5 * time.Second
+
+const instancesMetricTTL = 5 * time.Minute
+
+const webhookTimeout = 10 * time.Second
+
 func init() {
 	flag.StringVar(&configFilePath, "config", "", "Location of the config file")
 	flag.StringVar(&port, "port", "3000", "Listen port")
 }
 
-func buildLogger(logLevel string) lager.Logger {
-	laggerLogLevel, ok := logLevels[strings.ToUpper(logLevel)]
-	if !ok {
-		log.Fatal("Invalid log level: ", logLevel)
+func buildLogger(config *Config, redactor *redact.Redactor) (lager.Logger, *logging.Controller) {
+	logLevels := logging.NewController()
+	logger := lager.NewLogger("cloudformation-broker")
+
+	laggerLogLevel, err := logging.ParseLevel(config.LogLevel)
+	if err != nil {
+		log.Fatal(err)
 	}
+	logger.RegisterSink(logLevels.Register("stdout", redact.NewSink(lager.NewWriterSink(os.Stdout, lager.DEBUG), redactor), laggerLogLevel))
 
-	logger := lager.NewLogger("cloudformation-broker")
-	logger.RegisterSink(lager.NewWriterSink(os.Stdout, laggerLogLevel))
+	for _, logSinkConfig := range config.LogSinks {
+		laggerLogLevel, err := logging.ParseLevel(logSinkConfig.Level)
+		if err != nil {
+			log.Fatal(err)
+		}
+		logger.RegisterSink(logLevels.Register(logSinkConfig.Name(), redact.NewSink(lager.NewWriterSink(buildLogSinkWriter(logSinkConfig), lager.DEBUG), redactor), laggerLogLevel))
+	}
+
+	logLevels.SetLogger(logger)
+
+	return logger, logLevels
+}
+
+func buildLogSinkWriter(logSinkConfig LogSinkConfig) io.Writer {
+	switch logSinkConfig.Type {
+	case logSinkTypeFile:
+		rotatingFile, err := logging.NewRotatingFile(logSinkConfig.Path, logSinkConfig.MaxSizeInMB*1024*1024, logSinkConfig.MaxBackups)
+		if err != nil {
+			log.Fatalf("Error opening log file: %s", err)
+		}
+		return rotatingFile
+	default:
+		syslogWriter, err := syslog.Dial(logSinkConfig.SyslogNetwork, logSinkConfig.SyslogAddress, syslog.LOG_INFO|syslog.LOG_DAEMON, "cloudformation-broker")
+		if err != nil {
+			log.Fatalf("Error connecting to log syslog: %s", err)
+		}
+		return syslogWriter
+	}
+}
+
+func buildAuditLogger(auditLogConfig AuditLogConfig, redactor *redact.Redactor) lager.Logger {
+	auditLogger := lager.NewLogger("cloudformation-broker-audit")
 
-	return logger
+	if auditLogConfig.File != "" {
+		auditFile, err := os.OpenFile(auditLogConfig.File, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			log.Fatalf("Error opening audit log file: %s", err)
+		}
+		auditLogger.RegisterSink(redact.NewSink(lager.NewWriterSink(auditFile, lager.INFO), redactor))
+	}
+
+	if auditLogConfig.Syslog {
+		syslogWriter, err := syslog.Dial(auditLogConfig.SyslogNetwork, auditLogConfig.SyslogAddress, syslog.LOG_INFO|syslog.LOG_AUTH, "cloudformation-broker-audit")
+		if err != nil {
+			log.Fatalf("Error connecting to audit syslog: %s", err)
+		}
+		auditLogger.RegisterSink(redact.NewSink(lager.NewWriterSink(syslogWriter, lager.INFO), redactor))
+	}
+
+	return auditLogger
+}
+
+func buildTracer(tracingConfig TracingConfig, logger lager.Logger) *tracing.Tracer {
+	serviceName := tracingConfig.ServiceName
+	if serviceName == "" {
+		serviceName = defaultTracingServiceName
+	}
+
+	return tracing.NewTracer(tracing.NewOTLPExporter(tracingConfig.OTLPEndpoint, serviceName), tracingExportInterval, logger)
+}
+
+func buildWebhookDispatcher(webhooksConfig WebhooksConfig, logger lager.Logger) *webhooks.HTTPDispatcher {
+	deadLetterLogger := logger
+	if webhooksConfig.DeadLetterFile != "" {
+		deadLetterFile, err := os.OpenFile(webhooksConfig.DeadLetterFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			log.Fatalf("Error opening webhooks dead letter file: %s", err)
+		}
+		deadLetterLogger = lager.NewLogger("cloudformation-broker-webhooks")
+		deadLetterLogger.RegisterSink(lager.NewWriterSink(deadLetterFile, lager.ERROR))
+	}
+
+	endpoints := []webhooks.Webhook{}
+	for _, endpoint := range webhooksConfig.Endpoints {
+		endpoints = append(endpoints, webhooks.Webhook{
+			URL:    endpoint.URL,
+			Secret: endpoint.Secret,
+			Events: endpoint.Events,
+		})
+	}
+
+	return webhooks.NewHTTPDispatcher(endpoints, &http.Client{Timeout: webhookTimeout}, webhooksConfig.Attempts(), webhooksConfig.RetryInterval(), logger, deadLetterLogger)
+}
+
+// addNoEchoParameters flags the NoEcho parameters of the catalog templates
+// as sensitive.
+func addNoEchoParameters(redactor *redact.Redactor, catalog cfbroker.Catalog, stack awscf.Stack, logger lager.Logger) {
+	for _, templateURL := range catalog.TemplateURLs() {
+		noEchoParameters, err := stack.NoEchoParameters(templateURL)
+		if err != nil {
+			logger.Error("no-echo-parameters", err, lager.Data{"template-url": templateURL})
+			continue
+		}
+		redactor.AddSensitiveNames(noEchoParameters...)
+	}
 }
 
 func main() {
@@ -55,24 +173,159 @@ func main() {
 		log.Fatalf("Error loading config file: %s", err)
 	}
 
-	logger := buildLogger(config.LogLevel)
+	if flag.Arg(0) == adminCommand {
+		os.Exit(runAdmin(config, flag.Args()[1:]))
+	}
+
+	redactor := redact.New(config.CloudFormationConfig.Catalog.SensitiveNames()...)
+	logger, logLevels := buildLogger(config, redactor)
 
 	awsConfig := aws.NewConfig().WithRegion(config.CloudFormationConfig.Region)
 	awsSession := session.New(awsConfig)
 
+	metricsRegistry := metrics.NewRegistry(logger)
+	awsClients := metrics.NewAWSClients(metricsRegistry)
+
 	cfsvc := cloudformation.New(awsSession)
+	awsClients.Instrument(&cfsvc.Handlers)
 	stack := awscf.NewCloudFormationStack(cfsvc, logger)
 
-	serviceBroker := cfbroker.New(config.CloudFormationConfig, stack, logger)
+	addNoEchoParameters(redactor, config.CloudFormationConfig.Catalog, stack, logger)
+
+	s3svc := awss3.New(awsSession)
+	awsClients.Instrument(&s3svc.Handlers)
+	bucket := awss3.NewS3Bucket(s3svc, logger)
+
+	ecrsvc := awsecr.New(awsSession)
+	awsClients.Instrument(&ecrsvc.Handlers)
+	repository := awsecr.NewECRRepository(ecrsvc, logger)
+
+	serviceBroker := cfbroker.New(config.CloudFormationConfig, stack, bucket, repository, logger)
+
+	if config.StackNotifications.Enabled() {
+		sqsConfig := aws.NewConfig()
+		if config.StackNotifications.SQSEndpoint != "" {
+			sqsConfig = sqsConfig.WithEndpoint(config.StackNotifications.SQSEndpoint)
+		}
+		sqssvc := awssqs.New(awsSession, sqsConfig)
+		awsClients.Instrument(&sqssvc.Handlers)
+		queue := awssqs.NewSQSQueue(config.StackNotifications.QueueURL, sqssvc, logger)
+
+		stackStatusCache := notifications.NewStatusCache(config.StackNotifications.StatusMaxAge())
+		go notifications.NewConsumer(queue, stackStatusCache, logger).Run()
+		serviceBroker.ReceiveStackNotifications(config.StackNotifications.TopicARN, stackStatusCache)
+	}
+
+	metricsRegistry.Register(metrics.NewGaugeFunc("cloudformation_broker_instances", "Service instances by stack status.", "stack_status", metrics.CachedValues(func() (map[string]float64, error) {
+		instances, err := serviceBroker.InstancesByStackStatus()
+		if err != nil {
+			return nil, err
+		}
+
+		values := make(map[string]float64)
+		for stackStatus, count := range instances {
+			values[stackStatus] = float64(count)
+		}
+		return values, nil
+	}, instancesMetricTTL), logger))
 
 	credentials := brokerapi.BrokerCredentials{
 		Username: config.Username,
 		Password: config.Password,
 	}
 
-	brokerAPI := brokerapi.New(serviceBroker, logger, credentials)
+	instrumentedServiceBroker := metrics.NewServiceBroker(serviceBroker, metricsRegistry)
+
+	var auditor *audit.Auditor
+	if config.AuditLog.Enabled() {
+		auditor = audit.New(buildAuditLogger(config.AuditLog, redactor), serviceBroker, redactor)
+	}
+
+	var webhookServiceBroker *webhooks.ServiceBroker
+	if config.Webhooks.Enabled() {
+		webhookServiceBroker = webhooks.NewServiceBroker(serviceBroker, buildWebhookDispatcher(config.Webhooks, logger))
+	}
+
+	newBrokerAPI := func(serviceBroker brokerapi.ServiceBroker, operationTokens *cfbroker.OperationTokens) http.Handler {
+		if webhookServiceBroker != nil {
+			serviceBroker = webhookServiceBroker.WithServiceBroker(serviceBroker).WithOperationTokens(operationTokens)
+		}
+		serviceBroker = instrumentedServiceBroker.WithServiceBroker(serviceBroker)
+		newServiceBrokerAPI := func(serviceBroker brokerapi.ServiceBroker) http.Handler {
+			return concurrency.NewHandler(serviceBroker, func(recordedServiceBroker brokerapi.ServiceBroker) http.Handler {
+				return brokerapi.New(recordedServiceBroker, logger, credentials)
+			})
+		}
+		if auditor == nil {
+			return newServiceBrokerAPI(serviceBroker)
+		}
+		return audit.NewHandler(serviceBroker, auditor, newServiceBrokerAPI)
+	}
+
+	var tracer *tracing.Tracer
+	if config.Tracing.Enabled() {
+		tracer = buildTracer(config.Tracing, logger)
+	}
+
+	brokerAPI := operationtokens.NewHandler(func(operationTokens *cfbroker.OperationTokens) http.Handler {
+		requestServiceBroker := serviceBroker.WithOperationTokens(operationTokens)
+		if tracer == nil {
+			return newBrokerAPI(requestServiceBroker, operationTokens)
+		}
+		return tracing.NewHandler(tracer, func(requestSpan tracing.SpanContext) http.Handler {
+			return newBrokerAPI(tracing.NewServiceBroker(tracer, requestSpan, func(operationSpan tracing.SpanContext) brokerapi.ServiceBroker {
+				return requestServiceBroker.WithStack(tracing.NewStack(stack, tracer, operationSpan))
+			}), operationTokens)
+		})
+	})
 	http.Handle("/", brokerAPI)
 
+	http.Handle("/metrics", metricsRegistry)
+
+	readinessChecks := []health.Check{
+		health.NewCredentialsCheck(config.CloudFormationConfig.Region, awsSession.Config.Credentials),
+		health.NewCloudFormationCheck(config.CloudFormationConfig.Region, cfsvc),
+	}
+	http.HandleFunc("/health", health.Health)
+	http.Handle("/ready", health.NewReadiness(readinessChecks, readinessCacheTTL, logger))
+
+	if config.Reconciler.Enabled() {
+		platform := cloudcontroller.NewClient(config.Reconciler.CloudControllerURL, config.Reconciler.ClientID, config.Reconciler.ClientSecret, config.Reconciler.SkipSSLValidation)
+		stackReconciler := reconciler.New(serviceBroker, platform, config.Reconciler.GracePeriod(), config.Reconciler.DeleteOrphans, logger)
+		go stackReconciler.Run(config.Reconciler.Interval())
+
+		metricsRegistry.Register(metrics.NewGaugeFunc("cloudformation_broker_orphaned_instances", "Stacks whose service instance is unknown to the Cloud Controller, by grace period state.", "state", func() (map[string]float64, error) {
+			values := map[string]float64{"pending": 0, "expired": 0}
+			for _, orphan := range stackReconciler.Orphans() {
+				if orphan.Expired {
+					values["expired"]++
+				} else {
+					values["pending"]++
+				}
+			}
+			return values, nil
+		}, logger))
+	}
+
+	driftDetector := drift.NewDetector(serviceBroker, stack, driftPollInterval, logger)
+	if config.DriftDetection.Enabled() {
+		go driftDetector.Run(config.DriftDetection.Interval())
+	}
+	if config.DriftDetection.ReportInLastOperation {
+		serviceBroker.ReportDrift(driftDetector)
+	}
+
+	metricsRegistry.Register(metrics.NewGaugeFunc("cloudformation_broker_stack_drift", "Stacks by drift status of their last drift detection.", "drift_status", func() (map[string]float64, error) {
+		values := make(map[string]float64)
+		for _, result := range driftDetector.Results() {
+			values[result.StackDriftStatus]++
+		}
+		return values, nil
+	}, logger))
+
+	adminAPI := adminapi.New(serviceBroker, logLevels, driftDetector, logger, credentials)
+	http.Handle("/admin/", adminAPI)
+
 	fmt.Println("CloudFormation Service Broker started on port " + port + "...")
 	http.ListenAndServe(":"+port, nil)
 }
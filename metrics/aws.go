@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// AWSClients records the calls, errors and latencies of the AWS API
+// requests sent by the instrumented clients. Each retry is a separate call.
+type AWSClients struct {
+	sync.Mutex
+	calls         *CounterVec
+	callErrors    *CounterVec
+	callDurations *HistogramVec
+	started       map[*request.Request]time.Time
+}
+
+func NewAWSClients(registry *Registry) *AWSClients {
+	a := &AWSClients{
+		calls:         NewCounterVec(namespace+"_aws_api_calls_total", "AWS API calls.", "service", "operation"),
+		callErrors:    NewCounterVec(namespace+"_aws_api_errors_total", "AWS API calls that returned an error.", "service", "operation", "code"),
+		callDurations: NewHistogramVec(namespace+"_aws_api_call_duration_seconds", "Latency of AWS API calls.", DefaultBuckets, "service", "operation"),
+		started:       make(map[*request.Request]time.Time),
+	}
+
+	registry.Register(a.calls)
+	registry.Register(a.callErrors)
+	registry.Register(a.callDurations)
+
+	return a
+}
+
+// Instrument adds the handlers recording the metrics of the requests sent
+// with the given client handlers.
+func (a *AWSClients) Instrument(handlers *request.Handlers) {
+	handlers.Send.PushFront(a.sendStarted)
+	handlers.Send.PushBack(a.sendCompleted)
+	handlers.UnmarshalError.PushBack(a.unmarshalErrorCompleted)
+}
+
+func (a *AWSClients) sendStarted(r *request.Request) {
+	a.Lock()
+	defer a.Unlock()
+
+	a.started[r] = time.Now()
+}
+
+func (a *AWSClients) sendCompleted(r *request.Request) {
+	a.Lock()
+	start, ok := a.started[r]
+	delete(a.started, r)
+	a.Unlock()
+
+	serviceName, operationName := requestNames(r)
+	a.calls.Inc(serviceName, operationName)
+	if ok {
+		a.callDurations.Observe(time.Since(start).Seconds(), serviceName, operationName)
+	}
+
+	// Errors sending the request; errors returned by the API are recorded
+	// once they are unmarshaled
+	if r.Error != nil {
+		a.callErrors.Inc(serviceName, operationName, errorCode(r.Error))
+	}
+}
+
+func (a *AWSClients) unmarshalErrorCompleted(r *request.Request) {
+	if r.Error == nil {
+		return
+	}
+
+	serviceName, operationName := requestNames(r)
+	a.callErrors.Inc(serviceName, operationName, errorCode(r.Error))
+}
+
+func requestNames(r *request.Request) (string, string) {
+	operationName := ""
+	if r.Operation != nil {
+		operationName = r.Operation.Name
+	}
+
+	return r.ClientInfo.ServiceName, operationName
+}
+
+func errorCode(err error) string {
+	if awsErr, ok := err.(awserr.Error); ok {
+		return awsErr.Code()
+	}
+
+	return "Unknown"
+}
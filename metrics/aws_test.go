@@ -0,0 +1,71 @@
+package metrics_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/metrics"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/pivotal-golang/lager/lagertest"
+)
+
+var _ = Describe("AWSClients", func() {
+	var (
+		registry *Registry
+		cfsvc    *cloudformation.CloudFormation
+		sendErr  error
+	)
+
+	BeforeEach(func() {
+		registry = NewRegistry(lagertest.NewTestLogger("metrics"))
+		cfsvc = cloudformation.New(session.New(), aws.NewConfig().WithRegion("test-region").WithMaxRetries(0))
+		cfsvc.Handlers.Clear()
+		sendErr = nil
+	})
+
+	JustBeforeEach(func() {
+		cfsvc.Handlers.Send.PushBack(func(r *request.Request) {
+			r.Error = sendErr
+		})
+		NewAWSClients(registry).Instrument(&cfsvc.Handlers)
+	})
+
+	scrape := func() string {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/metrics", nil)
+		registry.ServeHTTP(recorder, request)
+		return recorder.Body.String()
+	}
+
+	It("records the calls and their latency", func() {
+		_, err := cfsvc.DescribeStacks(&cloudformation.DescribeStacksInput{})
+		Expect(err).ToNot(HaveOccurred())
+
+		metrics := scrape()
+		Expect(metrics).To(ContainSubstring(`cloudformation_broker_aws_api_calls_total{service="cloudformation",operation="DescribeStacks"} 1`))
+		Expect(metrics).To(ContainSubstring(`cloudformation_broker_aws_api_call_duration_seconds_count{service="cloudformation",operation="DescribeStacks"} 1`))
+		Expect(metrics).ToNot(ContainSubstring(`cloudformation_broker_aws_api_errors_total{`))
+	})
+
+	Context("when the call fails", func() {
+		BeforeEach(func() {
+			sendErr = awserr.New("RequestError", "send request failed", errors.New("connection refused"))
+		})
+
+		It("records the error code", func() {
+			_, err := cfsvc.DescribeStacks(&cloudformation.DescribeStacksInput{})
+			Expect(err).To(HaveOccurred())
+
+			Expect(scrape()).To(ContainSubstring(`cloudformation_broker_aws_api_errors_total{service="cloudformation",operation="DescribeStacks",code="RequestError"} 1`))
+		})
+	})
+})
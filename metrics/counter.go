@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"fmt"
+	"sync"
+)
+
+// CounterVec is a counter partitioned by a set of labels.
+type CounterVec struct {
+	sync.Mutex
+	name        string
+	help        string
+	labelNames  []string
+	labelValues map[string][]string
+	values      map[string]float64
+}
+
+func NewCounterVec(name string, help string, labelNames ...string) *CounterVec {
+	return &CounterVec{
+		name:        name,
+		help:        help,
+		labelNames:  labelNames,
+		labelValues: make(map[string][]string),
+		values:      make(map[string]float64),
+	}
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(value float64, labelValues ...string) {
+	if len(labelValues) != len(c.labelNames) {
+		panic(fmt.Sprintf("metric '%s' expects %d label values, got %d", c.name, len(c.labelNames), len(labelValues)))
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	key := labelKey(labelValues)
+	c.labelValues[key] = labelValues
+	c.values[key] += value
+}
+
+func (c *CounterVec) Collect() []Family {
+	c.Lock()
+	defer c.Unlock()
+
+	family := Family{Name: c.name, Help: c.help, Type: counterType}
+	for _, key := range sortedKeys(c.labelValues) {
+		family.Samples = append(family.Samples, Sample{
+			Labels: labelPairs(c.labelNames, c.labelValues[key]),
+			Value:  c.values[key],
+		})
+	}
+
+	return []Family{family}
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+)
+
+// GaugeFunc is a gauge partitioned by a single label, whose values are
+// computed each time the metrics are collected.
+type GaugeFunc struct {
+	name      string
+	help      string
+	labelName string
+	values    func() (map[string]float64, error)
+	logger    lager.Logger
+}
+
+func NewGaugeFunc(name string, help string, labelName string, values func() (map[string]float64, error), logger lager.Logger) *GaugeFunc {
+	return &GaugeFunc{
+		name:      name,
+		help:      help,
+		labelName: labelName,
+		values:    values,
+		logger:    logger.Session("gauge", lager.Data{"name": name}),
+	}
+}
+
+// Collect returns no samples when the values cannot be computed, so a
+// scrape still succeeds with the rest of the metrics.
+func (g *GaugeFunc) Collect() []Family {
+	family := Family{Name: g.name, Help: g.help, Type: gaugeType}
+
+	values, err := g.values()
+	if err != nil {
+		g.logger.Error("collect-error", err)
+		return []Family{family}
+	}
+
+	labelValues := make([]string, 0, len(values))
+	for labelValue := range values {
+		labelValues = append(labelValues, labelValue)
+	}
+	sort.Strings(labelValues)
+
+	for _, labelValue := range labelValues {
+		family.Samples = append(family.Samples, Sample{
+			Labels: []Label{Label{Name: g.labelName, Value: labelValue}},
+			Value:  values[labelValue],
+		})
+	}
+
+	return []Family{family}
+}
+
+// CachedValues returns gauge values that are only computed again once ttl
+// has elapsed since they were last computed, for values, such as those
+// listing AWS resources, too costly to compute on every collection. Errors
+// are cached too, so a failing computation is not retried on every
+// collection either.
+func CachedValues(values func() (map[string]float64, error), ttl time.Duration) func() (map[string]float64, error) {
+	var (
+		mutex        sync.Mutex
+		cachedValues map[string]float64
+		cachedErr    error
+		computedAt   time.Time
+	)
+
+	return func() (map[string]float64, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if computedAt.IsZero() || time.Since(computedAt) >= ttl {
+			cachedValues, cachedErr = values()
+			computedAt = time.Now()
+		}
+
+		return cachedValues, cachedErr
+	}
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// DefaultBuckets are the histogram upper bounds, in seconds, used for
+// request durations.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// HistogramVec is a histogram partitioned by a set of labels.
+type HistogramVec struct {
+	sync.Mutex
+	name        string
+	help        string
+	buckets     []float64
+	labelNames  []string
+	labelValues map[string][]string
+	histograms  map[string]*histogram
+}
+
+type histogram struct {
+	bucketCounts []uint64
+	count        uint64
+	sum          float64
+}
+
+func NewHistogramVec(name string, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	return &HistogramVec{
+		name:        name,
+		help:        help,
+		buckets:     buckets,
+		labelNames:  labelNames,
+		labelValues: make(map[string][]string),
+		histograms:  make(map[string]*histogram),
+	}
+}
+
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	if len(labelValues) != len(h.labelNames) {
+		panic(fmt.Sprintf("metric '%s' expects %d label values, got %d", h.name, len(h.labelNames), len(labelValues)))
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	key := labelKey(labelValues)
+	hist, ok := h.histograms[key]
+	if !ok {
+		hist = &histogram{bucketCounts: make([]uint64, len(h.buckets))}
+		h.histograms[key] = hist
+		h.labelValues[key] = labelValues
+	}
+
+	for i, upperBound := range h.buckets {
+		if value <= upperBound {
+			hist.bucketCounts[i]++
+		}
+	}
+	hist.count++
+	hist.sum += value
+}
+
+func (h *HistogramVec) Collect() []Family {
+	h.Lock()
+	defer h.Unlock()
+
+	family := Family{Name: h.name, Help: h.help, Type: histogramType}
+	for _, key := range sortedKeys(h.labelValues) {
+		hist := h.histograms[key]
+		labels := labelPairs(h.labelNames, h.labelValues[key])
+
+		for i, upperBound := range h.buckets {
+			family.Samples = append(family.Samples, Sample{
+				Suffix: "_bucket",
+				Labels: append(labels[:len(labels):len(labels)], Label{Name: "le", Value: formatValue(upperBound)}),
+				Value:  float64(hist.bucketCounts[i]),
+			})
+		}
+		family.Samples = append(family.Samples,
+			Sample{
+				Suffix: "_bucket",
+				Labels: append(labels[:len(labels):len(labels)], Label{Name: "le", Value: formatValue(math.Inf(1))}),
+				Value:  float64(hist.count),
+			},
+			Sample{Suffix: "_sum", Labels: labels, Value: hist.sum},
+			Sample{Suffix: "_count", Labels: labels, Value: float64(hist.count)},
+		)
+	}
+
+	return []Family{family}
+}
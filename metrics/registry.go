@@ -0,0 +1,162 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const contentType = "text/plain; version=0.0.4"
+
+const counterType = "counter"
+const gaugeType = "gauge"
+const histogramType = "histogram"
+
+// Collector is a source of metrics exposed by a Registry.
+type Collector interface {
+	Collect() []Family
+}
+
+// Family holds the samples of a metric.
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// Sample is a single value of a metric. Suffix is appended to the metric
+// name, as histograms expose several series per metric.
+type Sample struct {
+	Suffix string
+	Labels []Label
+	Value  float64
+}
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Registry exposes the metrics of its collectors in the Prometheus text
+// exposition format.
+type Registry struct {
+	sync.Mutex
+	collectors []Collector
+	logger     lager.Logger
+}
+
+func NewRegistry(logger lager.Logger) *Registry {
+	return &Registry{
+		logger: logger.Session("metrics"),
+	}
+}
+
+func (r *Registry) Register(collector Collector) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.collectors = append(r.collectors, collector)
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.Lock()
+	collectors := make([]Collector, len(r.collectors))
+	copy(collectors, r.collectors)
+	r.Unlock()
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+
+	for _, collector := range collectors {
+		for _, family := range collector.Collect() {
+			if err := writeFamily(w, family); err != nil {
+				r.logger.Error("write-metrics-error", err)
+				return
+			}
+		}
+	}
+}
+
+func writeFamily(w io.Writer, family Family) error {
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", family.Name, escapeHelp(family.Help), family.Name, family.Type); err != nil {
+		return err
+	}
+
+	for _, sample := range family.Samples {
+		if _, err := fmt.Fprintf(w, "%s%s%s %s\n", family.Name, sample.Suffix, formatLabels(sample.Labels), formatValue(sample.Value)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func formatLabels(labels []Label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	formattedLabels := make([]string, len(labels))
+	for i, label := range labels {
+		formattedLabels[i] = fmt.Sprintf("%s=\"%s\"", label.Name, escapeLabelValue(label.Value))
+	}
+
+	return "{" + strings.Join(formattedLabels, ",") + "}"
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+var helpReplacer = strings.NewReplacer("\\", "\\\\", "\n", "\\n")
+var labelValueReplacer = strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\"", "\\\"")
+
+func escapeHelp(help string) string {
+	return helpReplacer.Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
+
+// labelKey joins label values into a map key. The separator is not valid
+// UTF-8, so it does not appear in label values.
+func labelKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+func labelPairs(labelNames []string, labelValues []string) []Label {
+	labels := make([]Label, len(labelNames))
+	for i, labelName := range labelNames {
+		labels[i] = Label{Name: labelName, Value: labelValues[i]}
+	}
+
+	return labels
+}
+
+func sortedKeys(keys map[string][]string) []string {
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	return sorted
+}
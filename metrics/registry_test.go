@@ -0,0 +1,140 @@
+package metrics_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/metrics"
+
+	"github.com/pivotal-golang/lager/lagertest"
+)
+
+var _ = Describe("Registry", func() {
+	var (
+		registry *Registry
+		logger   *lagertest.TestLogger
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("metrics")
+		registry = NewRegistry(logger)
+	})
+
+	scrape := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/metrics", nil)
+		registry.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	It("uses the Prometheus text format content type", func() {
+		response := scrape()
+		Expect(response.Code).To(Equal(http.StatusOK))
+		Expect(response.Header().Get("Content-Type")).To(Equal("text/plain; version=0.0.4"))
+	})
+
+	It("exposes counters", func() {
+		counter := NewCounterVec("test_total", "Test counter.", "operation")
+		registry.Register(counter)
+		counter.Inc("update")
+		counter.Inc("provision")
+		counter.Add(2, "provision")
+
+		Expect(scrape().Body.String()).To(Equal(`# HELP test_total Test counter.
+# TYPE test_total counter
+test_total{operation="provision"} 3
+test_total{operation="update"} 1
+`))
+	})
+
+	It("escapes label values", func() {
+		counter := NewCounterVec("test_total", "Test counter.", "reason")
+		registry.Register(counter)
+		counter.Inc("a \"quoted\"\nreason")
+
+		Expect(scrape().Body.String()).To(ContainSubstring(`test_total{reason="a \"quoted\"\nreason"} 1`))
+	})
+
+	It("exposes histograms", func() {
+		histogram := NewHistogramVec("test_seconds", "Test histogram.", []float64{0.1, 1}, "operation")
+		registry.Register(histogram)
+		histogram.Observe(0.05, "provision")
+		histogram.Observe(0.5, "provision")
+		histogram.Observe(5, "provision")
+
+		Expect(scrape().Body.String()).To(Equal(`# HELP test_seconds Test histogram.
+# TYPE test_seconds histogram
+test_seconds_bucket{operation="provision",le="0.1"} 1
+test_seconds_bucket{operation="provision",le="1"} 2
+test_seconds_bucket{operation="provision",le="+Inf"} 3
+test_seconds_sum{operation="provision"} 5.55
+test_seconds_count{operation="provision"} 3
+`))
+	})
+
+	It("exposes gauges computed on collection", func() {
+		gauge := NewGaugeFunc("test_instances", "Test gauge.", "status", func() (map[string]float64, error) {
+			return map[string]float64{"UPDATE_COMPLETE": 1, "CREATE_COMPLETE": 2}, nil
+		}, logger)
+		registry.Register(gauge)
+
+		Expect(scrape().Body.String()).To(Equal(`# HELP test_instances Test gauge.
+# TYPE test_instances gauge
+test_instances{status="CREATE_COMPLETE"} 2
+test_instances{status="UPDATE_COMPLETE"} 1
+`))
+	})
+
+	Context("when gauge values are cached", func() {
+		var computations int
+
+		BeforeEach(func() {
+			computations = 0
+		})
+
+		values := func() (map[string]float64, error) {
+			computations++
+			return map[string]float64{"CREATE_COMPLETE": float64(computations)}, nil
+		}
+
+		It("computes them once per TTL", func() {
+			registry.Register(NewGaugeFunc("test_instances", "Test gauge.", "status", CachedValues(values, time.Hour), logger))
+
+			Expect(scrape().Body.String()).To(ContainSubstring(`test_instances{status="CREATE_COMPLETE"} 1`))
+			Expect(scrape().Body.String()).To(ContainSubstring(`test_instances{status="CREATE_COMPLETE"} 1`))
+			Expect(computations).To(Equal(1))
+		})
+
+		It("computes them again once the TTL has elapsed", func() {
+			registry.Register(NewGaugeFunc("test_instances", "Test gauge.", "status", CachedValues(values, time.Millisecond), logger))
+
+			Expect(scrape().Body.String()).To(ContainSubstring(`test_instances{status="CREATE_COMPLETE"} 1`))
+			time.Sleep(2 * time.Millisecond)
+			Expect(scrape().Body.String()).To(ContainSubstring(`test_instances{status="CREATE_COMPLETE"} 2`))
+		})
+	})
+
+	Context("when a gauge cannot be computed", func() {
+		It("exposes the rest of the metrics", func() {
+			gauge := NewGaugeFunc("test_instances", "Test gauge.", "status", func() (map[string]float64, error) {
+				return nil, errors.New("operation failed")
+			}, logger)
+			counter := NewCounterVec("test_total", "Test counter.")
+			registry.Register(gauge)
+			registry.Register(counter)
+			counter.Inc()
+
+			Expect(scrape().Body.String()).To(Equal(`# HELP test_instances Test gauge.
+# TYPE test_instances gauge
+# HELP test_total Test counter.
+# TYPE test_total counter
+test_total 1
+`))
+		})
+	})
+})
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/frodenas/brokerapi"
+)
+
+const namespace = "cloudformation_broker"
+
+// ServiceBroker records the requests, errors and durations of each Service
+// Broker API operation of the service broker it wraps.
+type ServiceBroker struct {
+	serviceBroker    brokerapi.ServiceBroker
+	requests         *CounterVec
+	requestErrors    *CounterVec
+	requestDurations *HistogramVec
+}
+
+func NewServiceBroker(serviceBroker brokerapi.ServiceBroker, registry *Registry) *ServiceBroker {
+	labelNames := []string{"operation", "service_id", "plan_id"}
+
+	b := &ServiceBroker{
+		serviceBroker:    serviceBroker,
+		requests:         NewCounterVec(namespace+"_requests_total", "Service Broker API requests.", labelNames...),
+		requestErrors:    NewCounterVec(namespace+"_request_errors_total", "Service Broker API requests that returned an error.", labelNames...),
+		requestDurations: NewHistogramVec(namespace+"_request_duration_seconds", "Duration of Service Broker API requests.", DefaultBuckets, labelNames...),
+	}
+
+	registry.Register(b.requests)
+	registry.Register(b.requestErrors)
+	registry.Register(b.requestDurations)
+
+	return b
+}
+
+// WithServiceBroker returns a decorator of another service broker that
+// records its operations in the same metrics.
+func (b *ServiceBroker) WithServiceBroker(serviceBroker brokerapi.ServiceBroker) *ServiceBroker {
+	decorator := *b
+	decorator.serviceBroker = serviceBroker
+
+	return &decorator
+}
+
+func (b *ServiceBroker) Services() brokerapi.CatalogResponse {
+	defer b.observe("catalog", "", "", time.Now(), nil)
+
+	return b.serviceBroker.Services()
+}
+
+func (b *ServiceBroker) Provision(instanceID string, details brokerapi.ProvisionDetails, acceptsIncomplete bool) (provisioningResponse brokerapi.ProvisioningResponse, asynch bool, err error) {
+	defer b.observe("provision", details.ServiceID, details.PlanID, time.Now(), &err)
+
+	return b.serviceBroker.Provision(instanceID, details, acceptsIncomplete)
+}
+
+func (b *ServiceBroker) Update(instanceID string, details brokerapi.UpdateDetails, acceptsIncomplete bool) (asynch bool, err error) {
+	defer b.observe("update", details.ServiceID, details.PlanID, time.Now(), &err)
+
+	return b.serviceBroker.Update(instanceID, details, acceptsIncomplete)
+}
+
+func (b *ServiceBroker) Deprovision(instanceID string, details brokerapi.DeprovisionDetails, acceptsIncomplete bool) (asynch bool, err error) {
+	defer b.observe("deprovision", details.ServiceID, details.PlanID, time.Now(), &err)
+
+	return b.serviceBroker.Deprovision(instanceID, details, acceptsIncomplete)
+}
+
+func (b *ServiceBroker) Bind(instanceID, bindingID string, details brokerapi.BindDetails) (bindingResponse brokerapi.BindingResponse, err error) {
+	defer b.observe("bind", details.ServiceID, details.PlanID, time.Now(), &err)
+
+	return b.serviceBroker.Bind(instanceID, bindingID, details)
+}
+
+func (b *ServiceBroker) Unbind(instanceID, bindingID string, details brokerapi.UnbindDetails) (err error) {
+	defer b.observe("unbind", details.ServiceID, details.PlanID, time.Now(), &err)
+
+	return b.serviceBroker.Unbind(instanceID, bindingID, details)
+}
+
+// LastOperation requests do not carry the service and plan of the instance.
+func (b *ServiceBroker) LastOperation(instanceID string) (lastOperationResponse brokerapi.LastOperationResponse, err error) {
+	defer b.observe("last_operation", "", "", time.Now(), &err)
+
+	return b.serviceBroker.LastOperation(instanceID)
+}
+
+func (b *ServiceBroker) observe(operation string, serviceID string, planID string, start time.Time, err *error) {
+	b.requests.Inc(operation, serviceID, planID)
+	b.requestDurations.Observe(time.Since(start).Seconds(), operation, serviceID, planID)
+	if err != nil && *err != nil {
+		b.requestErrors.Inc(operation, serviceID, planID)
+	}
+}
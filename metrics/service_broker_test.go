@@ -0,0 +1,82 @@
+package metrics_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/metrics"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/frodenas/brokerapi/fakes"
+	"github.com/pivotal-golang/lager/lagertest"
+)
+
+var _ = Describe("ServiceBroker", func() {
+	var (
+		registry          *Registry
+		fakeServiceBroker *fakes.FakeServiceBroker
+		serviceBroker     *ServiceBroker
+	)
+
+	BeforeEach(func() {
+		registry = NewRegistry(lagertest.NewTestLogger("metrics"))
+		fakeServiceBroker = &fakes.FakeServiceBroker{}
+		serviceBroker = NewServiceBroker(fakeServiceBroker, registry)
+	})
+
+	scrape := func() string {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/metrics", nil)
+		registry.ServeHTTP(recorder, request)
+		return recorder.Body.String()
+	}
+
+	It("calls the wrapped service broker", func() {
+		fakeServiceBroker.UpdateAsynch = true
+		details := brokerapi.UpdateDetails{ServiceID: "service-id", PlanID: "plan-id"}
+
+		asynch, err := serviceBroker.Update("instance-id", details, true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(asynch).To(BeTrue())
+		Expect(fakeServiceBroker.UpdateInstanceID).To(Equal("instance-id"))
+		Expect(fakeServiceBroker.UpdateDetails).To(Equal(details))
+	})
+
+	It("records the operations of other service brokers in the same metrics", func() {
+		otherServiceBroker := &fakes.FakeServiceBroker{}
+		serviceBroker.WithServiceBroker(otherServiceBroker).Unbind("instance-id", "binding-id", brokerapi.UnbindDetails{ServiceID: "service-id", PlanID: "plan-id"})
+		serviceBroker.Unbind("instance-id", "binding-id", brokerapi.UnbindDetails{ServiceID: "service-id", PlanID: "plan-id"})
+
+		Expect(otherServiceBroker.UnbindBindingID).To(Equal("binding-id"))
+		Expect(scrape()).To(ContainSubstring(`cloudformation_broker_requests_total{operation="unbind",service_id="service-id",plan_id="plan-id"} 2`))
+	})
+
+	It("records the requests by operation, service and plan", func() {
+		serviceBroker.Provision("instance-id", brokerapi.ProvisionDetails{ServiceID: "service-id", PlanID: "plan-id"}, true)
+		serviceBroker.LastOperation("instance-id")
+
+		metrics := scrape()
+		Expect(metrics).To(ContainSubstring(`cloudformation_broker_requests_total{operation="provision",service_id="service-id",plan_id="plan-id"} 1`))
+		Expect(metrics).To(ContainSubstring(`cloudformation_broker_requests_total{operation="last_operation",service_id="",plan_id=""} 1`))
+		Expect(metrics).To(ContainSubstring(`cloudformation_broker_request_duration_seconds_count{operation="provision",service_id="service-id",plan_id="plan-id"} 1`))
+		Expect(metrics).ToNot(ContainSubstring(`cloudformation_broker_request_errors_total{`))
+	})
+
+	Context("when the operation fails", func() {
+		BeforeEach(func() {
+			fakeServiceBroker.DeprovisionError = errors.New("operation failed")
+		})
+
+		It("returns the error and records it", func() {
+			_, err := serviceBroker.Deprovision("instance-id", brokerapi.DeprovisionDetails{ServiceID: "service-id", PlanID: "plan-id"}, true)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("operation failed"))
+
+			Expect(scrape()).To(ContainSubstring(`cloudformation_broker_request_errors_total{operation="deprovision",service_id="service-id",plan_id="plan-id"} 1`))
+		})
+	})
+})
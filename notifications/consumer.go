@@ -0,0 +1,76 @@
+package notifications
+
+import (
+	"time"
+
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+	"github.com/cf-platform-eng/cloudformation-broker/awssqs"
+)
+
+// receiveRetryInterval is waited for before receiving messages again after
+// receiving them failed.
+const receiveRetryInterval = 10 * time.Second
+
+// Consumer consumes the AWS CloudFormation notifications delivered to a
+// queue, caching the status of the stacks and resources they are about.
+type Consumer struct {
+	queue  awssqs.Queue
+	cache  *StatusCache
+	logger lager.Logger
+}
+
+func NewConsumer(queue awssqs.Queue, cache *StatusCache, logger lager.Logger) *Consumer {
+	return &Consumer{
+		queue:  queue,
+		cache:  cache,
+		logger: logger.Session("stack-notifications"),
+	}
+}
+
+// Run consumes the queue, forever.
+func (c *Consumer) Run() {
+	for {
+		if err := c.Consume(); err != nil {
+			c.logger.Error("consume", err)
+			time.Sleep(receiveRetryInterval)
+		}
+	}
+}
+
+// Consume receives a batch of messages and caches the stack and resource
+// statuses they notify. Every message is deleted once handled, including the
+// ones that can not be parsed, so they are not received again.
+func (c *Consumer) Consume() error {
+	messages, err := c.queue.Receive()
+	if err != nil {
+		return err
+	}
+
+	for _, message := range messages {
+		stackEvent, err := ParseStackEvent(message.Body)
+		if err != nil {
+			c.logger.Error("parse-message-failed", err, lager.Data{"message-id": message.MessageID})
+		} else if stackEvent.StackStatusChanged() {
+			c.logger.Debug("stack-status", lager.Data{
+				"stack-name":   stackEvent.StackName,
+				"stack-status": stackEvent.ResourceStatus,
+			})
+			c.cache.Set(stackEvent.StackID, awscf.NewStatus(stackEvent.ResourceStatus, stackEvent.ResourceStatusReason), stackEvent.Timestamp)
+		} else {
+			c.cache.SetResource(stackEvent.StackID, awscf.StackResource{
+				LogicalResourceID:    stackEvent.LogicalResourceID,
+				ResourceType:         stackEvent.ResourceType,
+				ResourceStatus:       stackEvent.ResourceStatus,
+				ResourceStatusReason: stackEvent.ResourceStatusReason,
+			}, stackEvent.Timestamp)
+		}
+
+		if err := c.queue.Delete(message.ReceiptHandle); err != nil {
+			c.logger.Error("delete-message-failed", err, lager.Data{"message-id": message.MessageID})
+		}
+	}
+
+	return nil
+}
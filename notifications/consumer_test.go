@@ -0,0 +1,119 @@
+package notifications_test
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+	"github.com/cf-platform-eng/cloudformation-broker/awssqs"
+	sqsfake "github.com/cf-platform-eng/cloudformation-broker/awssqs/fakes"
+	. "github.com/cf-platform-eng/cloudformation-broker/notifications"
+)
+
+func snsNotification(message string) string {
+	notification, _ := json.Marshal(map[string]string{
+		"Type":     "Notification",
+		"TopicArn": "arn:aws:sns:us-east-1:123456789012:stack-notifications",
+		"Subject":  "AWS CloudFormation Notification",
+		"Message":  message,
+	})
+
+	return string(notification)
+}
+
+var _ = Describe("Consumer", func() {
+	var (
+		queue *sqsfake.FakeQueue
+		cache *StatusCache
+
+		testSink *lagertest.TestSink
+		logger   lager.Logger
+
+		consumer *Consumer
+	)
+
+	BeforeEach(func() {
+		queue = &sqsfake.FakeQueue{
+			ReceiveMessages: []awssqs.QueueMessage{
+				awssqs.QueueMessage{MessageID: "message-id-1", ReceiptHandle: "receipt-handle-1", Body: snsNotification(stackNotification)},
+				awssqs.QueueMessage{MessageID: "message-id-2", ReceiptHandle: "receipt-handle-2", Body: "Hello"},
+			},
+		}
+		cache = NewStatusCache(time.Minute)
+
+		logger = lager.NewLogger("consumer_test")
+		testSink = lagertest.NewTestSink()
+		logger.RegisterSink(testSink)
+
+		consumer = NewConsumer(queue, cache, logger)
+	})
+
+	It("caches the notified stack statuses", func() {
+		err := consumer.Consume()
+		Expect(err).ToNot(HaveOccurred())
+
+		status, _, ok := cache.StackStatus("arn:aws:cloudformation:us-east-1:123456789012:stack/cf-instance-id/stack-uuid")
+		Expect(ok).To(BeTrue())
+		Expect(status.Raw).To(Equal("UPDATE_ROLLBACK_IN_PROGRESS"))
+		Expect(status.Reason).To(Equal("Resource update cancelled"))
+	})
+
+	It("caches the notified resource statuses", func() {
+		queue.ReceiveMessages = []awssqs.QueueMessage{
+			awssqs.QueueMessage{MessageID: "message-id-1", ReceiptHandle: "receipt-handle-1", Body: snsNotification(`StackId='stack-id'
+Timestamp='2016-04-19T19:34:14.331Z'
+LogicalResourceId='Bucket'
+ResourceStatus='CREATE_IN_PROGRESS'
+ResourceType='AWS::S3::Bucket'
+StackName='cf-instance-id'
+`)},
+		}
+
+		err := consumer.Consume()
+		Expect(err).ToNot(HaveOccurred())
+
+		stackResources, ok := cache.StackResources("stack-id")
+		Expect(ok).To(BeTrue())
+		Expect(stackResources).To(Equal([]awscf.StackResource{
+			awscf.StackResource{LogicalResourceID: "Bucket", ResourceType: "AWS::S3::Bucket", ResourceStatus: "CREATE_IN_PROGRESS"},
+		}))
+	})
+
+	It("deletes every received message", func() {
+		err := consumer.Consume()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(queue.DeleteReceiptHandles).To(Equal([]string{"receipt-handle-1", "receipt-handle-2"}))
+		Expect(testSink.LogMessages()).To(ContainElement("consumer_test.stack-notifications.parse-message-failed"))
+	})
+
+	Context("when receiving the messages fails", func() {
+		BeforeEach(func() {
+			queue.ReceiveError = errors.New("operation failed")
+		})
+
+		It("returns the proper error", func() {
+			err := consumer.Consume()
+			Expect(err).To(MatchError("operation failed"))
+			Expect(queue.DeleteCalled).To(BeFalse())
+		})
+	})
+
+	Context("when deleting a message fails", func() {
+		BeforeEach(func() {
+			queue.DeleteError = errors.New("operation failed")
+		})
+
+		It("handles the other messages", func() {
+			err := consumer.Consume()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(queue.DeleteReceiptHandles).To(HaveLen(2))
+		})
+	})
+})
@@ -0,0 +1,82 @@
+package notifications
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const stackResourceType = "AWS::CloudFormation::Stack"
+
+// StackEvent is a stack event notified by AWS CloudFormation.
+type StackEvent struct {
+	StackID              string
+	StackName            string
+	LogicalResourceID    string
+	ResourceType         string
+	ResourceStatus       string
+	ResourceStatusReason string
+	Timestamp            time.Time
+}
+
+// StackStatusChanged returns whether the event is about the stack itself,
+// rather than one of its resources or nested stacks.
+func (e StackEvent) StackStatusChanged() bool {
+	return e.ResourceType == stackResourceType && e.LogicalResourceID == e.StackName
+}
+
+type snsNotification struct {
+	Type    string `json:"Type"`
+	Message string `json:"Message"`
+}
+
+// Fields of AWS CloudFormation notifications are written one per line as
+// Key='Value', where values such as resource properties may span lines.
+var notificationFieldPattern = regexp.MustCompile(`(?m)^([A-Za-z]+)='`)
+
+// ParseStackEvent parses an AWS CloudFormation notification, either wrapped
+// in the SNS notification delivered to SQS or delivered raw.
+func ParseStackEvent(body string) (StackEvent, error) {
+	message := body
+
+	var notification snsNotification
+	if err := json.Unmarshal([]byte(body), &notification); err == nil {
+		if notification.Type != "Notification" {
+			return StackEvent{}, errors.New("Not an SNS notification: " + notification.Type)
+		}
+		message = notification.Message
+	}
+
+	fields := make(map[string]string)
+	fieldIndexes := notificationFieldPattern.FindAllStringSubmatchIndex(message, -1)
+	for i, fieldIndex := range fieldIndexes {
+		end := len(message)
+		if i+1 < len(fieldIndexes) {
+			end = fieldIndexes[i+1][0]
+		}
+
+		value := strings.TrimRight(message[fieldIndex[1]:end], "\n")
+		fields[message[fieldIndex[2]:fieldIndex[3]]] = strings.TrimSuffix(value, "'")
+	}
+
+	if fields["StackId"] == "" || fields["ResourceStatus"] == "" {
+		return StackEvent{}, errors.New("Not an AWS CloudFormation notification")
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, fields["Timestamp"])
+	if err != nil {
+		return StackEvent{}, err
+	}
+
+	return StackEvent{
+		StackID:              fields["StackId"],
+		StackName:            fields["StackName"],
+		LogicalResourceID:    fields["LogicalResourceId"],
+		ResourceType:         fields["ResourceType"],
+		ResourceStatus:       fields["ResourceStatus"],
+		ResourceStatusReason: fields["ResourceStatusReason"],
+		Timestamp:            timestamp,
+	}, nil
+}
@@ -0,0 +1,74 @@
+package notifications_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/notifications"
+)
+
+const stackNotification = `StackId='arn:aws:cloudformation:us-east-1:123456789012:stack/cf-instance-id/stack-uuid'
+Timestamp='2016-04-19T19:34:14.331Z'
+EventId='event-id'
+LogicalResourceId='cf-instance-id'
+Namespace='123456789012'
+PrincipalId='principal-id'
+ResourceProperties='{
+  "Parameters": {}
+}
+'
+ResourceStatus='UPDATE_ROLLBACK_IN_PROGRESS'
+ResourceStatusReason='Resource update cancelled'
+ResourceType='AWS::CloudFormation::Stack'
+StackName='cf-instance-id'
+ClientRequestToken='null'
+`
+
+var _ = Describe("ParseStackEvent", func() {
+	properStackEvent := StackEvent{
+		StackID:              "arn:aws:cloudformation:us-east-1:123456789012:stack/cf-instance-id/stack-uuid",
+		StackName:            "cf-instance-id",
+		LogicalResourceID:    "cf-instance-id",
+		ResourceType:         "AWS::CloudFormation::Stack",
+		ResourceStatus:       "UPDATE_ROLLBACK_IN_PROGRESS",
+		ResourceStatusReason: "Resource update cancelled",
+		Timestamp:            time.Date(2016, 4, 19, 19, 34, 14, 331000000, time.UTC),
+	}
+
+	It("parses a notification delivered raw", func() {
+		stackEvent, err := ParseStackEvent(stackNotification)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stackEvent).To(Equal(properStackEvent))
+		Expect(stackEvent.StackStatusChanged()).To(BeTrue())
+	})
+
+	It("parses a notification wrapped in an SNS notification", func() {
+		stackEvent, err := ParseStackEvent(snsNotification(stackNotification))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stackEvent).To(Equal(properStackEvent))
+	})
+
+	It("tells the events of the stack resources apart", func() {
+		stackEvent, err := ParseStackEvent(`StackId='stack-id'
+Timestamp='2016-04-19T19:34:14.331Z'
+LogicalResourceId='Bucket'
+ResourceStatus='CREATE_COMPLETE'
+ResourceType='AWS::S3::Bucket'
+StackName='cf-instance-id'
+`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stackEvent.StackStatusChanged()).To(BeFalse())
+	})
+
+	It("returns an error if the message is not an AWS CloudFormation notification", func() {
+		_, err := ParseStackEvent("Hello")
+		Expect(err).To(MatchError("Not an AWS CloudFormation notification"))
+	})
+
+	It("returns an error if the SNS message is not a notification", func() {
+		_, err := ParseStackEvent(`{"Type": "SubscriptionConfirmation", "Message": "You have chosen to subscribe"}`)
+		Expect(err).To(MatchError("Not an SNS notification: SubscriptionConfirmation"))
+	})
+})
@@ -0,0 +1,149 @@
+package notifications
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+)
+
+// StatusCache keeps the latest status of each stack, as notified by AWS
+// CloudFormation, along with the resources notified since the operation in
+// progress on the stack started. Statuses are only trusted for maxAge after
+// they were notified, so that a lost notification does not leave a stack
+// status outdated for good.
+type StatusCache struct {
+	sync.Mutex
+	maxAge   time.Duration
+	statuses map[string]cachedStatus
+}
+
+type cachedStatus struct {
+	status             awscf.Status
+	timestamp          time.Time
+	operationStartedAt time.Time
+	resources          map[string]cachedResource
+	cachedAt           time.Time
+}
+
+type cachedResource struct {
+	resource  awscf.StackResource
+	timestamp time.Time
+}
+
+func NewStatusCache(maxAge time.Duration) *StatusCache {
+	return &StatusCache{
+		maxAge:   maxAge,
+		statuses: make(map[string]cachedStatus),
+	}
+}
+
+// Set caches the status of a stack, unless a later status is already
+// cached, as notifications may be delivered out of order. A stack entering
+// an in progress status from any other status starts a new operation, which
+// forgets the resources notified before it.
+func (c *StatusCache) Set(stackID string, status awscf.Status, timestamp time.Time) {
+	c.Lock()
+	defer c.Unlock()
+
+	cached := c.cached(stackID)
+	if cached.timestamp.After(timestamp) {
+		return
+	}
+
+	if status.Summary() == awscf.StatusInProgress && cached.status.Summary() != awscf.StatusInProgress {
+		cached.operationStartedAt = timestamp
+		for logicalResourceID, resource := range cached.resources {
+			if resource.timestamp.Before(timestamp) {
+				delete(cached.resources, logicalResourceID)
+			}
+		}
+	}
+
+	cached.status = status
+	cached.timestamp = timestamp
+	c.statuses[stackID] = cached
+}
+
+// SetResource caches the status of a resource of a stack, unless a later
+// status of the resource is already cached or the status was notified
+// before the operation in progress on the stack started.
+func (c *StatusCache) SetResource(stackID string, resource awscf.StackResource, timestamp time.Time) {
+	c.Lock()
+	defer c.Unlock()
+
+	cached := c.cached(stackID)
+	if timestamp.Before(cached.operationStartedAt) {
+		return
+	}
+	if cachedResource, ok := cached.resources[resource.LogicalResourceID]; ok && cachedResource.timestamp.After(timestamp) {
+		return
+	}
+
+	cached.resources[resource.LogicalResourceID] = cachedResource{resource: resource, timestamp: timestamp}
+	c.statuses[stackID] = cached
+}
+
+// cached returns the cached status of a stack, refreshed, after forgetting
+// the statuses that are too old. It must be called with the cache locked.
+func (c *StatusCache) cached(stackID string) cachedStatus {
+	now := time.Now()
+	for cachedStackID, cached := range c.statuses {
+		if now.Sub(cached.cachedAt) >= c.maxAge {
+			delete(c.statuses, cachedStackID)
+		}
+	}
+
+	cached, ok := c.statuses[stackID]
+	if !ok {
+		cached.resources = make(map[string]cachedResource)
+	}
+	cached.cachedAt = now
+
+	return cached
+}
+
+// StackStatus returns the latest notified status of a stack, and when the
+// operation in progress on the stack started, if it was notified recently
+// enough.
+func (c *StatusCache) StackStatus(stackID string) (awscf.Status, time.Time, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	cached, ok := c.statuses[stackID]
+	if !ok || cached.status.Raw == "" || time.Since(cached.cachedAt) >= c.maxAge {
+		return awscf.Status{}, time.Time{}, false
+	}
+
+	return cached.status, cached.operationStartedAt, true
+}
+
+// StackResources returns the resources of a stack notified since the
+// operation in progress on the stack started, if any were notified recently
+// enough.
+func (c *StatusCache) StackResources(stackID string) ([]awscf.StackResource, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	cached, ok := c.statuses[stackID]
+	if !ok || len(cached.resources) == 0 || time.Since(cached.cachedAt) >= c.maxAge {
+		return nil, false
+	}
+
+	stackResources := []awscf.StackResource{}
+	for _, resource := range cached.resources {
+		stackResources = append(stackResources, resource.resource)
+	}
+	sort.Sort(byLogicalResourceID(stackResources))
+
+	return stackResources, true
+}
+
+type byLogicalResourceID []awscf.StackResource
+
+func (r byLogicalResourceID) Len() int      { return len(r) }
+func (r byLogicalResourceID) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r byLogicalResourceID) Less(i, j int) bool {
+	return r[i].LogicalResourceID < r[j].LogicalResourceID
+}
@@ -0,0 +1,98 @@
+package notifications_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+	. "github.com/cf-platform-eng/cloudformation-broker/notifications"
+)
+
+var _ = Describe("StatusCache", func() {
+	var (
+		maxAge    time.Duration
+		timestamp time.Time
+
+		cache *StatusCache
+	)
+
+	BeforeEach(func() {
+		maxAge = time.Minute
+		timestamp = time.Now()
+	})
+
+	JustBeforeEach(func() {
+		cache = NewStatusCache(maxAge)
+	})
+
+	It("returns the cached status of a stack", func() {
+		cache.Set("stack-id", awscf.NewStatus("CREATE_IN_PROGRESS", ""), timestamp)
+
+		status, startedAt, ok := cache.StackStatus("stack-id")
+		Expect(ok).To(BeTrue())
+		Expect(status).To(Equal(awscf.NewStatus("CREATE_IN_PROGRESS", "")))
+		Expect(startedAt).To(Equal(timestamp))
+	})
+
+	It("does not return the status of unknown stacks", func() {
+		_, _, ok := cache.StackStatus("stack-id")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("dates the operation in progress from the status that started it", func() {
+		cache.Set("stack-id", awscf.NewStatus("UPDATE_IN_PROGRESS", ""), timestamp)
+		cache.Set("stack-id", awscf.NewStatus("UPDATE_ROLLBACK_IN_PROGRESS", ""), timestamp.Add(time.Minute))
+
+		status, startedAt, _ := cache.StackStatus("stack-id")
+		Expect(status.Raw).To(Equal("UPDATE_ROLLBACK_IN_PROGRESS"))
+		Expect(startedAt).To(Equal(timestamp))
+	})
+
+	It("returns the resources notified since the operation in progress started", func() {
+		cache.SetResource("stack-id", awscf.StackResource{LogicalResourceID: "Queue", ResourceStatus: "UPDATE_COMPLETE"}, timestamp.Add(-time.Hour))
+		cache.Set("stack-id", awscf.NewStatus("UPDATE_IN_PROGRESS", ""), timestamp)
+		cache.SetResource("stack-id", awscf.StackResource{LogicalResourceID: "Bucket", ResourceStatus: "UPDATE_IN_PROGRESS"}, timestamp.Add(time.Second))
+		cache.SetResource("stack-id", awscf.StackResource{LogicalResourceID: "Bucket", ResourceStatus: "UPDATE_COMPLETE"}, timestamp.Add(2*time.Second))
+		cache.SetResource("stack-id", awscf.StackResource{LogicalResourceID: "Bucket", ResourceStatus: "UPDATE_IN_PROGRESS"}, timestamp.Add(time.Second))
+
+		stackResources, ok := cache.StackResources("stack-id")
+		Expect(ok).To(BeTrue())
+		Expect(stackResources).To(Equal([]awscf.StackResource{
+			awscf.StackResource{LogicalResourceID: "Bucket", ResourceStatus: "UPDATE_COMPLETE"},
+		}))
+	})
+
+	It("forgets the resources of the previous operation when a new one starts", func() {
+		cache.Set("stack-id", awscf.NewStatus("CREATE_IN_PROGRESS", ""), timestamp)
+		cache.SetResource("stack-id", awscf.StackResource{LogicalResourceID: "Bucket", ResourceStatus: "CREATE_COMPLETE"}, timestamp.Add(time.Second))
+		cache.Set("stack-id", awscf.NewStatus("CREATE_COMPLETE", ""), timestamp.Add(2*time.Second))
+		cache.Set("stack-id", awscf.NewStatus("DELETE_IN_PROGRESS", ""), timestamp.Add(time.Minute))
+
+		_, ok := cache.StackResources("stack-id")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("keeps the latest status of a stack", func() {
+		cache.Set("stack-id", awscf.NewStatus("CREATE_COMPLETE", ""), timestamp)
+		cache.Set("stack-id", awscf.NewStatus("CREATE_IN_PROGRESS", ""), timestamp.Add(-time.Second))
+
+		status, _, _ := cache.StackStatus("stack-id")
+		Expect(status.Raw).To(Equal("CREATE_COMPLETE"))
+	})
+
+	Context("when the cached status is too old", func() {
+		BeforeEach(func() {
+			maxAge = time.Millisecond
+		})
+
+		It("does not return it", func() {
+			cache.Set("stack-id", awscf.NewStatus("CREATE_IN_PROGRESS", ""), timestamp)
+			time.Sleep(2 * time.Millisecond)
+
+			_, _, ok := cache.StackStatus("stack-id")
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,52 @@
+package operationtokens
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+)
+
+// NewHandler serves each request with the handler built by next, passing it
+// the operation token polled by last operation requests (the `operation`
+// query parameter). The token issued to an asynchronous operation accepted
+// by the request is added as the `operation` field of the 202 response,
+// which the Service Broker API library does not support.
+func NewHandler(next func(*cfbroker.OperationTokens) http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		operationTokens := &cfbroker.OperationTokens{Requested: req.URL.Query().Get("operation")}
+		next(operationTokens).ServeHTTP(&responseWriter{ResponseWriter: w, operationTokens: operationTokens}, req)
+	})
+}
+
+// responseWriter adds the issued operation token to an accepted response.
+// The Service Broker API library writes each response body at once, after
+// the service broker returned.
+type responseWriter struct {
+	http.ResponseWriter
+	operationTokens *cfbroker.OperationTokens
+	accepted        bool
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	w.accepted = statusCode == http.StatusAccepted && w.operationTokens.Issued != ""
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(body []byte) (int, error) {
+	if !w.accepted {
+		return w.ResponseWriter.Write(body)
+	}
+
+	response := make(map[string]interface{})
+	if err := json.Unmarshal(body, &response); err != nil {
+		return w.ResponseWriter.Write(body)
+	}
+	response["operation"] = w.operationTokens.Issued
+
+	if err := json.NewEncoder(w.ResponseWriter).Encode(response); err != nil {
+		return 0, err
+	}
+
+	return len(body), nil
+}
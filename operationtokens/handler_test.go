@@ -0,0 +1,87 @@
+package operationtokens_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/operationtokens"
+
+	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+)
+
+var _ = Describe("NewHandler", func() {
+	var (
+		requestedToken string
+		issuedToken    string
+		statusCode     int
+		responseBody   string
+
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		requestedToken = ""
+		issuedToken = "issued-token"
+		statusCode = http.StatusAccepted
+		responseBody = `{"dashboard_url": "http://dashboard"}`
+
+		handler = NewHandler(func(operationTokens *cfbroker.OperationTokens) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				requestedToken = operationTokens.Requested
+				operationTokens.Issued = issuedToken
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(statusCode)
+				fmt.Fprintln(w, responseBody)
+			})
+		})
+	})
+
+	serve := func(method string, url string) *httptest.ResponseRecorder {
+		request, err := http.NewRequest(method, url, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	It("adds the issued operation token to accepted responses", func() {
+		recorder := serve("PUT", "/v2/service_instances/instance-id?accepts_incomplete=true")
+		Expect(recorder.Code).To(Equal(http.StatusAccepted))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"dashboard_url": "http://dashboard", "operation": "issued-token"}`))
+	})
+
+	It("passes the polled operation token", func() {
+		serve("GET", "/v2/service_instances/instance-id/last_operation?operation=polled-token")
+		Expect(requestedToken).To(Equal("polled-token"))
+	})
+
+	Context("when no operation token is issued", func() {
+		BeforeEach(func() {
+			issuedToken = ""
+		})
+
+		It("does not change the response", func() {
+			recorder := serve("PUT", "/v2/service_instances/instance-id?accepts_incomplete=true")
+			Expect(recorder.Body.String()).To(MatchJSON(`{"dashboard_url": "http://dashboard"}`))
+		})
+	})
+
+	Context("when the request is not accepted", func() {
+		BeforeEach(func() {
+			statusCode = http.StatusOK
+			responseBody = `{}`
+		})
+
+		It("does not change the response", func() {
+			recorder := serve("DELETE", "/v2/service_instances/instance-id")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{}`))
+		})
+	})
+})
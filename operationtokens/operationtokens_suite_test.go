@@ -0,0 +1,13 @@
+package operationtokens_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestOperationTokens(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Operation Tokens Suite")
+}
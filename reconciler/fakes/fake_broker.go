@@ -0,0 +1,28 @@
+package fakes
+
+import (
+	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+)
+
+type FakeBroker struct {
+	OwnedInstancesCalled    bool
+	OwnedInstancesInstances []cfbroker.OwnedInstance
+	OwnedInstancesError     error
+
+	DeleteInstanceStackCalled      bool
+	DeleteInstanceStackInstanceIDs []string
+	DeleteInstanceStackError       error
+}
+
+func (f *FakeBroker) OwnedInstances() ([]cfbroker.OwnedInstance, error) {
+	f.OwnedInstancesCalled = true
+
+	return f.OwnedInstancesInstances, f.OwnedInstancesError
+}
+
+func (f *FakeBroker) DeleteInstanceStack(instanceID string, resourcesToRetain []string) error {
+	f.DeleteInstanceStackCalled = true
+	f.DeleteInstanceStackInstanceIDs = append(f.DeleteInstanceStackInstanceIDs, instanceID)
+
+	return f.DeleteInstanceStackError
+}
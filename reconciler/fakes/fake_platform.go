@@ -0,0 +1,13 @@
+package fakes
+
+type FakePlatform struct {
+	ServiceInstanceGUIDsCalled bool
+	ServiceInstanceGUIDsGUIDs  map[string]bool
+	ServiceInstanceGUIDsError  error
+}
+
+func (f *FakePlatform) ServiceInstanceGUIDs() (map[string]bool, error) {
+	f.ServiceInstanceGUIDsCalled = true
+
+	return f.ServiceInstanceGUIDsGUIDs, f.ServiceInstanceGUIDsError
+}
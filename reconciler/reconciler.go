@@ -0,0 +1,180 @@
+package reconciler
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+)
+
+const instanceIDLogKey = "instance-id"
+const stackNameLogKey = "stack-name"
+
+// Broker holds the broker operations the reconciler is built on.
+type Broker interface {
+	OwnedInstances() ([]cfbroker.OwnedInstance, error)
+	DeleteInstanceStack(instanceID string, resourcesToRetain []string) error
+}
+
+// Platform knows the service instances that exist on the platform, such as
+// the Cloud Controller.
+type Platform interface {
+	ServiceInstanceGUIDs() (map[string]bool, error)
+}
+
+// Orphan is a stack created by the broker whose service instance is not
+// known to the platform.
+type Orphan struct {
+	InstanceID string
+	StackName  string
+	FirstSeen  time.Time
+	Expired    bool
+}
+
+// Reconciler periodically compares the stacks created by the broker with the
+// service instances of the platform. Stacks whose service instance has been
+// missing for longer than the grace period are reported as expired orphans,
+// and deleted if requested. Imported stacks predate the broker, so they are
+// reported but never deleted.
+type Reconciler struct {
+	sync.Mutex
+	broker        Broker
+	platform      Platform
+	gracePeriod   time.Duration
+	deleteOrphans bool
+	logger        lager.Logger
+
+	firstSeen map[string]time.Time
+	orphans   []Orphan
+}
+
+func New(broker Broker, platform Platform, gracePeriod time.Duration, deleteOrphans bool, logger lager.Logger) *Reconciler {
+	return &Reconciler{
+		broker:        broker,
+		platform:      platform,
+		gracePeriod:   gracePeriod,
+		deleteOrphans: deleteOrphans,
+		logger:        logger.Session("reconciler"),
+		firstSeen:     make(map[string]time.Time),
+	}
+}
+
+// Run reconciles the stacks every interval, forever.
+func (r *Reconciler) Run(interval time.Duration) {
+	for range time.Tick(interval) {
+		if err := r.Reconcile(); err != nil {
+			r.logger.Error("reconcile", err)
+		}
+	}
+}
+
+// Reconcile looks for orphaned stacks once. Nothing is reported if either
+// the stacks or the service instances of the platform can not be listed.
+func (r *Reconciler) Reconcile() error {
+	ownedInstances, err := r.broker.OwnedInstances()
+	if err != nil {
+		return err
+	}
+
+	serviceInstanceGUIDs, err := r.platform.ServiceInstanceGUIDs()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	r.Lock()
+	defer r.Unlock()
+
+	firstSeen := make(map[string]time.Time)
+	orphans := []Orphan{}
+	for _, ownedInstance := range ownedInstances {
+		if serviceInstanceGUIDs[ownedInstance.InstanceID] {
+			continue
+		}
+
+		orphan := Orphan{
+			InstanceID: ownedInstance.InstanceID,
+			StackName:  ownedInstance.StackName,
+			FirstSeen:  now,
+		}
+		if seen, ok := r.firstSeen[ownedInstance.InstanceID]; ok {
+			orphan.FirstSeen = seen
+		}
+		orphan.Expired = now.Sub(orphan.FirstSeen) >= r.gracePeriod
+		firstSeen[orphan.InstanceID] = orphan.FirstSeen
+
+		if !orphan.Expired {
+			r.logger.Debug("orphan-in-grace-period", lager.Data{
+				instanceIDLogKey: orphan.InstanceID,
+				stackNameLogKey:  orphan.StackName,
+				"first-seen":     orphan.FirstSeen,
+			})
+			orphans = append(orphans, orphan)
+			continue
+		}
+
+		r.logger.Info("orphan", lager.Data{
+			instanceIDLogKey: orphan.InstanceID,
+			stackNameLogKey:  orphan.StackName,
+			"stack-status":   ownedInstance.StackStatus.Raw,
+			"first-seen":     orphan.FirstSeen,
+		})
+
+		if r.deleteOrphans && ownedInstance.StackStatus.Raw != cloudformation.StackStatusDeleteInProgress {
+			if ownedInstance.Imported() {
+				r.logger.Info("keep-imported-orphan", lager.Data{
+					instanceIDLogKey: orphan.InstanceID,
+					stackNameLogKey:  orphan.StackName,
+				})
+			} else {
+				r.deleteOrphan(orphan)
+			}
+		}
+
+		orphans = append(orphans, orphan)
+	}
+
+	sort.Sort(byFirstSeen(orphans))
+	r.firstSeen = firstSeen
+	r.orphans = orphans
+
+	return nil
+}
+
+// Orphans returns the orphaned stacks found by the last reconciliation.
+func (r *Reconciler) Orphans() []Orphan {
+	r.Lock()
+	defer r.Unlock()
+
+	return append([]Orphan{}, r.orphans...)
+}
+
+func (r *Reconciler) deleteOrphan(orphan Orphan) {
+	r.logger.Info("delete-orphan", lager.Data{
+		instanceIDLogKey: orphan.InstanceID,
+		stackNameLogKey:  orphan.StackName,
+	})
+
+	if err := r.broker.DeleteInstanceStack(orphan.InstanceID, nil); err != nil {
+		r.logger.Error("delete-orphan-failed", err, lager.Data{
+			instanceIDLogKey: orphan.InstanceID,
+			stackNameLogKey:  orphan.StackName,
+		})
+	}
+}
+
+type byFirstSeen []Orphan
+
+func (o byFirstSeen) Len() int      { return len(o) }
+func (o byFirstSeen) Swap(i, j int) { o[i], o[j] = o[j], o[i] }
+func (o byFirstSeen) Less(i, j int) bool {
+	if o[i].FirstSeen.Equal(o[j].FirstSeen) {
+		return o[i].InstanceID < o[j].InstanceID
+	}
+	return o[i].FirstSeen.Before(o[j].FirstSeen)
+}
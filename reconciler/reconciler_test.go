@@ -0,0 +1,169 @@
+package reconciler_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/reconciler"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+	"github.com/cf-platform-eng/cloudformation-broker/reconciler/fakes"
+)
+
+var _ = Describe("Reconciler", func() {
+	var (
+		broker        *fakes.FakeBroker
+		platform      *fakes.FakePlatform
+		gracePeriod   time.Duration
+		deleteOrphans bool
+		logger        *lagertest.TestLogger
+
+		reconciler *Reconciler
+	)
+
+	ownedInstance := func(instanceID string, stackStatus string) cfbroker.OwnedInstance {
+		return cfbroker.OwnedInstance{
+			InstanceSummary: cfbroker.InstanceSummary{
+				InstanceID:  instanceID,
+				StackName:   "cf-" + instanceID,
+				StackStatus: awscf.NewStatus(stackStatus, ""),
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		broker = &fakes.FakeBroker{
+			OwnedInstancesInstances: []cfbroker.OwnedInstance{
+				ownedInstance("known", "CREATE_COMPLETE"),
+				ownedInstance("orphan", "CREATE_COMPLETE"),
+				ownedInstance("deleting", "DELETE_IN_PROGRESS"),
+			},
+		}
+		platform = &fakes.FakePlatform{
+			ServiceInstanceGUIDsGUIDs: map[string]bool{"known": true},
+		}
+		gracePeriod = time.Hour
+		deleteOrphans = true
+		logger = lagertest.NewTestLogger("reconciler-test")
+	})
+
+	JustBeforeEach(func() {
+		reconciler = New(broker, platform, gracePeriod, deleteOrphans, logger)
+	})
+
+	It("reports the stacks whose service instance is unknown to the platform", func() {
+		Expect(reconciler.Reconcile()).To(Succeed())
+
+		orphans := reconciler.Orphans()
+		Expect(orphans).To(HaveLen(2))
+		Expect(orphans[0].InstanceID).To(Equal("deleting"))
+		Expect(orphans[1].InstanceID).To(Equal("orphan"))
+		Expect(orphans[1].StackName).To(Equal("cf-orphan"))
+		Expect(orphans[1].Expired).To(BeFalse())
+	})
+
+	It("does not delete orphans within the grace period", func() {
+		Expect(reconciler.Reconcile()).To(Succeed())
+		Expect(broker.DeleteInstanceStackCalled).To(BeFalse())
+	})
+
+	It("remembers when an orphan was first seen", func() {
+		Expect(reconciler.Reconcile()).To(Succeed())
+		firstSeen := reconciler.Orphans()[0].FirstSeen
+
+		Expect(reconciler.Reconcile()).To(Succeed())
+		Expect(reconciler.Orphans()[0].FirstSeen).To(Equal(firstSeen))
+	})
+
+	It("forgets the stacks whose service instance reappears", func() {
+		Expect(reconciler.Reconcile()).To(Succeed())
+
+		platform.ServiceInstanceGUIDsGUIDs = map[string]bool{"known": true, "orphan": true, "deleting": true}
+		Expect(reconciler.Reconcile()).To(Succeed())
+		Expect(reconciler.Orphans()).To(BeEmpty())
+	})
+
+	Context("when the grace period is over", func() {
+		BeforeEach(func() {
+			gracePeriod = 0
+		})
+
+		It("deletes the orphans whose stack is not already being deleted", func() {
+			Expect(reconciler.Reconcile()).To(Succeed())
+			Expect(broker.DeleteInstanceStackInstanceIDs).To(Equal([]string{"orphan"}))
+
+			orphans := reconciler.Orphans()
+			Expect(orphans).To(HaveLen(2))
+			Expect(orphans[0].Expired).To(BeTrue())
+			Expect(orphans[1].Expired).To(BeTrue())
+		})
+
+		Context("when orphans must only be reported", func() {
+			BeforeEach(func() {
+				deleteOrphans = false
+			})
+
+			It("does not delete them", func() {
+				Expect(reconciler.Reconcile()).To(Succeed())
+				Expect(broker.DeleteInstanceStackCalled).To(BeFalse())
+				Expect(reconciler.Orphans()).To(HaveLen(2))
+			})
+		})
+
+		Context("when an orphan was imported", func() {
+			BeforeEach(func() {
+				imported := ownedInstance("imported", "CREATE_COMPLETE")
+				imported.StackDetails.Tags = map[string]string{"Imported by": "AWS CloudFormation Service Broker"}
+				broker.OwnedInstancesInstances = append(broker.OwnedInstancesInstances, imported)
+			})
+
+			It("reports it without deleting it", func() {
+				Expect(reconciler.Reconcile()).To(Succeed())
+				Expect(broker.DeleteInstanceStackInstanceIDs).To(Equal([]string{"orphan"}))
+				Expect(reconciler.Orphans()).To(HaveLen(3))
+				Expect(logger.LogMessages()).To(ContainElement("reconciler-test.reconciler.keep-imported-orphan"))
+			})
+		})
+
+		Context("when deleting an orphan fails", func() {
+			BeforeEach(func() {
+				broker.DeleteInstanceStackError = errors.New("operation failed")
+			})
+
+			It("logs the error and keeps reporting the orphan", func() {
+				Expect(reconciler.Reconcile()).To(Succeed())
+				Expect(logger.LogMessages()).To(ContainElement("reconciler-test.reconciler.delete-orphan-failed"))
+				Expect(reconciler.Orphans()).To(HaveLen(2))
+			})
+		})
+	})
+
+	Context("when the platform service instances can not be listed", func() {
+		BeforeEach(func() {
+			platform.ServiceInstanceGUIDsError = errors.New("operation failed")
+		})
+
+		It("reports nothing", func() {
+			Expect(reconciler.Reconcile()).To(MatchError("operation failed"))
+			Expect(reconciler.Orphans()).To(BeEmpty())
+			Expect(broker.DeleteInstanceStackCalled).To(BeFalse())
+		})
+	})
+
+	Context("when the stacks can not be listed", func() {
+		BeforeEach(func() {
+			broker.OwnedInstancesError = errors.New("operation failed")
+		})
+
+		It("returns the proper error", func() {
+			Expect(reconciler.Reconcile()).To(MatchError("operation failed"))
+			Expect(platform.ServiceInstanceGUIDsCalled).To(BeFalse())
+		})
+	})
+})
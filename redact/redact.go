@@ -0,0 +1,96 @@
+package redact
+
+import (
+	"strings"
+	"sync"
+)
+
+// Mask replaces the values considered secret.
+const Mask = "[REDACTED]"
+
+// sensitiveNameParts are the parts of a parameter or output name that mark
+// its value as secret. Names are compared case insensitively.
+var sensitiveNameParts = []string{"password", "secret", "token", "accesskey", "privatekey", "credential"}
+
+// IsSensitiveName returns whether the name of a parameter or output matches
+// one of the patterns of secret names.
+func IsSensitiveName(name string) bool {
+	normalizedName := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+	for _, sensitiveNamePart := range sensitiveNameParts {
+		if strings.Contains(normalizedName, sensitiveNamePart) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Redactor masks the values of parameters and outputs whose names match the
+// patterns of secret names or were explicitly flagged as sensitive, such as
+// template NoEcho parameters.
+type Redactor struct {
+	sync.RWMutex
+	sensitiveNames map[string]bool
+}
+
+func New(sensitiveNames ...string) *Redactor {
+	r := &Redactor{
+		sensitiveNames: make(map[string]bool),
+	}
+	r.AddSensitiveNames(sensitiveNames...)
+
+	return r
+}
+
+func (r *Redactor) AddSensitiveNames(sensitiveNames ...string) {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, sensitiveName := range sensitiveNames {
+		r.sensitiveNames[sensitiveName] = true
+	}
+}
+
+// IsSensitive returns whether the value of a parameter or output with the
+// given name must not be logged.
+func (r *Redactor) IsSensitive(name string) bool {
+	r.RLock()
+	flagged := r.sensitiveNames[name]
+	r.RUnlock()
+
+	return flagged || IsSensitiveName(name)
+}
+
+// Parameters returns a copy of the parameters with the values of the
+// sensitive ones masked, including those nested in objects and lists.
+func (r *Redactor) Parameters(parameters map[string]interface{}) map[string]interface{} {
+	if parameters == nil {
+		return nil
+	}
+
+	redactedParameters := make(map[string]interface{}, len(parameters))
+	for name, value := range parameters {
+		if r.IsSensitive(name) {
+			redactedParameters[name] = Mask
+		} else {
+			redactedParameters[name] = r.redactNested(value)
+		}
+	}
+
+	return redactedParameters
+}
+
+func (r *Redactor) redactNested(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		return r.Parameters(typedValue)
+	case []interface{}:
+		redactedValues := make([]interface{}, len(typedValue))
+		for i, nestedValue := range typedValue {
+			redactedValues[i] = r.redactNested(nestedValue)
+		}
+		return redactedValues
+	default:
+		return value
+	}
+}
@@ -0,0 +1,83 @@
+package redact_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/redact"
+)
+
+var _ = Describe("Redact", func() {
+	var _ = Describe("IsSensitiveName", func() {
+		It("matches secret names", func() {
+			Expect(IsSensitiveName("MasterUserPassword")).To(BeTrue())
+			Expect(IsSensitiveName("secret_access_key")).To(BeTrue())
+			Expect(IsSensitiveName("AWS_ACCESS_KEY_ID")).To(BeTrue())
+			Expect(IsSensitiveName("api-token")).To(BeTrue())
+		})
+
+		It("does not match other names", func() {
+			Expect(IsSensitiveName("BucketName")).To(BeFalse())
+			Expect(IsSensitiveName("DBInstanceClass")).To(BeFalse())
+		})
+	})
+
+	var _ = Describe("Redactor", func() {
+		var redactor *Redactor
+
+		BeforeEach(func() {
+			redactor = New("ConnectionString")
+		})
+
+		It("considers sensitive the flagged names and the secret names", func() {
+			redactor.AddSensitiveNames("DBUsername")
+
+			Expect(redactor.IsSensitive("ConnectionString")).To(BeTrue())
+			Expect(redactor.IsSensitive("DBUsername")).To(BeTrue())
+			Expect(redactor.IsSensitive("DBPassword")).To(BeTrue())
+			Expect(redactor.IsSensitive("BucketName")).To(BeFalse())
+		})
+
+		It("masks the sensitive parameters", func() {
+			parameters := map[string]interface{}{
+				"DBPassword":       "test-password",
+				"ConnectionString": "test-connection-string",
+				"DBInstanceType":   "db.t2.micro",
+			}
+
+			Expect(redactor.Parameters(parameters)).To(Equal(map[string]interface{}{
+				"DBPassword":       Mask,
+				"ConnectionString": Mask,
+				"DBInstanceType":   "db.t2.micro",
+			}))
+			Expect(parameters["DBPassword"]).To(Equal("test-password"))
+		})
+
+		It("masks the sensitive parameters nested in objects and lists", func() {
+			parameters := map[string]interface{}{
+				"database": map[string]interface{}{
+					"DBPassword": "test-password",
+					"DBName":     "test-db",
+				},
+				"users": []interface{}{
+					map[string]interface{}{"name": "test-user", "api_token": "test-token"},
+				},
+			}
+
+			Expect(redactor.Parameters(parameters)).To(Equal(map[string]interface{}{
+				"database": map[string]interface{}{
+					"DBPassword": Mask,
+					"DBName":     "test-db",
+				},
+				"users": []interface{}{
+					map[string]interface{}{"name": "test-user", "api_token": Mask},
+				},
+			}))
+			Expect(parameters["database"].(map[string]interface{})["DBPassword"]).To(Equal("test-password"))
+		})
+
+		It("returns nil for nil parameters", func() {
+			Expect(redactor.Parameters(nil)).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,93 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pivotal-golang/lager"
+)
+
+// Sink masks the secret values of the log lines before writing them to the
+// sink it wraps. Redacting the serialized log lines covers every logger
+// writing to the sink, including the ones of vendored libraries.
+type Sink struct {
+	sink     lager.Sink
+	redactor *Redactor
+}
+
+func NewSink(sink lager.Sink, redactor *Redactor) *Sink {
+	return &Sink{
+		sink:     sink,
+		redactor: redactor,
+	}
+}
+
+func (s *Sink) Log(level lager.LogLevel, payload []byte) {
+	s.sink.Log(level, s.redact(payload))
+}
+
+func (s *Sink) redact(payload []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	var log map[string]interface{}
+	if err := decoder.Decode(&log); err != nil {
+		return payload
+	}
+
+	data, ok := log["data"]
+	if !ok {
+		return payload
+	}
+	log["data"] = s.redactValue(data)
+
+	redactedPayload, err := json.Marshal(log)
+	if err != nil {
+		return payload
+	}
+
+	return redactedPayload
+}
+
+// redactValue masks the values of the sensitive keys of JSON objects, and
+// the values of the AWS SDK Parameter and Output structures whose keys are
+// sensitive.
+func (s *Sink) redactValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		for _, pair := range [][2]string{{"ParameterKey", "ParameterValue"}, {"OutputKey", "OutputValue"}} {
+			if key, ok := typedValue[pair[0]].(string); ok && s.redactor.IsSensitive(key) {
+				if _, ok := typedValue[pair[1]]; ok {
+					typedValue[pair[1]] = Mask
+				}
+			}
+		}
+
+		for key, nestedValue := range typedValue {
+			if s.redactor.IsSensitive(key) && isScalar(nestedValue) {
+				typedValue[key] = Mask
+			} else {
+				typedValue[key] = s.redactValue(nestedValue)
+			}
+		}
+
+		return typedValue
+	case []interface{}:
+		for i, nestedValue := range typedValue {
+			typedValue[i] = s.redactValue(nestedValue)
+		}
+
+		return typedValue
+	default:
+		return value
+	}
+}
+
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}, nil:
+		return false
+	default:
+		return true
+	}
+}
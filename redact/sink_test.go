@@ -0,0 +1,76 @@
+package redact_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/redact"
+
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+)
+
+var _ = Describe("Sink", func() {
+	var (
+		testSink *lagertest.TestSink
+		logger   lager.Logger
+	)
+
+	BeforeEach(func() {
+		testSink = lagertest.NewTestSink()
+		logger = lager.NewLogger("redact")
+		logger.RegisterSink(NewSink(testSink, New("ConnectionString")))
+	})
+
+	It("masks the sensitive keys of the log data", func() {
+		logger.Debug("provision", lager.Data{
+			"details": map[string]interface{}{
+				"plan_id":    "plan-id",
+				"parameters": map[string]interface{}{"DBPassword": "test-password", "DBName": "test-db"},
+			},
+			"attempts": 3,
+		})
+
+		logs := testSink.Logs()
+		Expect(logs).To(HaveLen(1))
+		Expect(logs[0].Data["details"]).To(Equal(map[string]interface{}{
+			"plan_id":    "plan-id",
+			"parameters": map[string]interface{}{"DBPassword": Mask, "DBName": "test-db"},
+		}))
+		Expect(logs[0].Data["attempts"]).To(BeNumerically("==", 3))
+	})
+
+	It("masks the sensitive AWS SDK parameters and outputs", func() {
+		logger.Debug("describe-stacks", lager.Data{
+			"stack": map[string]interface{}{
+				"Parameters": []interface{}{
+					map[string]interface{}{"ParameterKey": "DBPassword", "ParameterValue": "test-password"},
+					map[string]interface{}{"ParameterKey": "DBName", "ParameterValue": "test-db"},
+				},
+				"Outputs": []interface{}{
+					map[string]interface{}{"OutputKey": "ConnectionString", "OutputValue": "test-connection-string"},
+				},
+			},
+		})
+
+		logs := testSink.Logs()
+		Expect(logs).To(HaveLen(1))
+		Expect(logs[0].Data["stack"]).To(Equal(map[string]interface{}{
+			"Parameters": []interface{}{
+				map[string]interface{}{"ParameterKey": "DBPassword", "ParameterValue": Mask},
+				map[string]interface{}{"ParameterKey": "DBName", "ParameterValue": "test-db"},
+			},
+			"Outputs": []interface{}{
+				map[string]interface{}{"OutputKey": "ConnectionString", "OutputValue": Mask},
+			},
+		}))
+	})
+
+	It("keeps the log messages", func() {
+		logger.Info("provision")
+
+		logs := testSink.Logs()
+		Expect(logs).To(HaveLen(1))
+		Expect(logs[0].Message).To(Equal("redact.provision"))
+	})
+})
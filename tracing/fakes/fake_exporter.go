@@ -0,0 +1,24 @@
+package fakes
+
+import (
+	"sync"
+
+	"github.com/cf-platform-eng/cloudformation-broker/tracing"
+)
+
+type FakeExporter struct {
+	sync.Mutex
+	ExportCalled bool
+	ExportSpans  []*tracing.Span
+	ExportError  error
+}
+
+func (f *FakeExporter) Export(spans []*tracing.Span) error {
+	f.Lock()
+	defer f.Unlock()
+
+	f.ExportCalled = true
+	f.ExportSpans = append(f.ExportSpans, spans...)
+
+	return f.ExportError
+}
@@ -0,0 +1,40 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// NewHandler serves each request with the handler built by next, within a
+// server span continuing the W3C trace context of the request, if any.
+// The span context is passed to next so that the work done for the request
+// is traced as part of it.
+func NewHandler(tracer *Tracer, next func(SpanContext) http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		parent, _ := ParseTraceparent(req.Header.Get(TraceparentHeader))
+
+		span := tracer.StartSpan(req.Method, SpanKindServer, parent)
+		defer span.End()
+
+		span.SetAttribute("http.request.method", req.Method)
+		span.SetAttribute("url.path", req.URL.Path)
+
+		statusRecorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next(span.Context).ServeHTTP(statusRecorder, req)
+
+		span.SetAttribute("http.response.status_code", statusRecorder.statusCode)
+		if statusRecorder.statusCode >= http.StatusInternalServerError {
+			span.SetError(fmt.Errorf("%d %s", statusRecorder.statusCode, http.StatusText(statusRecorder.statusCode)))
+		}
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
@@ -0,0 +1,83 @@
+package tracing_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/tracing"
+
+	"github.com/cf-platform-eng/cloudformation-broker/tracing/fakes"
+	"github.com/pivotal-golang/lager/lagertest"
+)
+
+var _ = Describe("Handler", func() {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	var (
+		exporter    *fakes.FakeExporter
+		tracer      *Tracer
+		statusCode  int
+		nextContext SpanContext
+		handler     http.Handler
+		request     *http.Request
+	)
+
+	BeforeEach(func() {
+		exporter = &fakes.FakeExporter{}
+		tracer = NewTracer(exporter, time.Hour, lagertest.NewTestLogger("tracing"))
+		statusCode = http.StatusCreated
+		handler = NewHandler(tracer, func(spanContext SpanContext) http.Handler {
+			nextContext = spanContext
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(statusCode)
+			})
+		})
+		request, _ = http.NewRequest("PUT", "/v2/service_instances/instance-id", nil)
+	})
+
+	serve := func() *Span {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(statusCode))
+
+		tracer.Flush()
+		Expect(exporter.ExportSpans).To(HaveLen(1))
+		return exporter.ExportSpans[0]
+	}
+
+	It("records a server span for the request", func() {
+		span := serve()
+		Expect(span.Name).To(Equal("PUT"))
+		Expect(span.Kind).To(Equal(SpanKindServer))
+		Expect(span.Context).To(Equal(nextContext))
+		Expect(span.Attributes).To(Equal([]Attribute{
+			{Key: "http.request.method", Value: "PUT"},
+			{Key: "url.path", Value: "/v2/service_instances/instance-id"},
+			{Key: "http.response.status_code", Value: http.StatusCreated},
+		}))
+		Expect(span.Error).To(BeEmpty())
+	})
+
+	It("continues the trace context of the request", func() {
+		request.Header.Set("traceparent", traceparent)
+
+		span := serve()
+		Expect(span.Context.TraceID.String()).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+		Expect(span.ParentSpanID.String()).To(Equal("00f067aa0ba902b7"))
+	})
+
+	Context("when the request fails", func() {
+		BeforeEach(func() {
+			statusCode = http.StatusInternalServerError
+		})
+
+		It("marks the span as failed", func() {
+			span := serve()
+			Expect(span.Error).To(Equal("500 Internal Server Error"))
+		})
+	})
+})
@@ -0,0 +1,179 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const otlpTracesPath = "/v1/traces"
+const otlpScopeName = "github.com/cf-platform-eng/cloudformation-broker/tracing"
+
+// Span status codes, numbered as in the OpenTelemetry protocol.
+const otlpStatusCodeUnset = 0
+const otlpStatusCodeError = 2
+
+const otlpExportTimeout = 10 * time.Second
+
+// OTLPExporter sends spans to an OpenTelemetry collector with the OTLP/HTTP
+// protocol, using its JSON encoding.
+type OTLPExporter struct {
+	url         string
+	serviceName string
+	httpClient  *http.Client
+}
+
+// NewOTLPExporter builds an exporter for the collector listening at
+// endpoint, such as http://localhost:4318.
+func NewOTLPExporter(endpoint string, serviceName string) *OTLPExporter {
+	return &OTLPExporter{
+		url:         strings.TrimSuffix(endpoint, "/") + otlpTracesPath,
+		serviceName: serviceName,
+		httpClient:  &http.Client{Timeout: otlpExportTimeout},
+	}
+}
+
+type otlpTracesRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              SpanKind        `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+	BoolValue   *bool   `json:"boolValue,omitempty"`
+}
+
+func (e *OTLPExporter) Export(spans []*Span) error {
+	otlpSpans := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		otlpSpans = append(otlpSpans, buildOTLPSpan(span))
+	}
+
+	tracesRequest := otlpTracesRequest{
+		ResourceSpans: []otlpResourceSpans{
+			otlpResourceSpans{
+				Resource: otlpResource{
+					Attributes: []otlpAttribute{buildOTLPAttribute(Attribute{Key: "service.name", Value: e.serviceName})},
+				},
+				ScopeSpans: []otlpScopeSpans{
+					otlpScopeSpans{
+						Scope: otlpScope{Name: otlpScopeName},
+						Spans: otlpSpans,
+					},
+				},
+			},
+		},
+	}
+
+	body, err := json.Marshal(tracesRequest)
+	if err != nil {
+		return err
+	}
+
+	resp, err := e.httpClient.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("OTLP collector at '%s' returned status %d", e.url, resp.StatusCode)
+	}
+
+	return nil
+}
+
+func buildOTLPSpan(span *Span) otlpSpan {
+	span.Lock()
+	defer span.Unlock()
+
+	otlpSpan := otlpSpan{
+		TraceID:           span.Context.TraceID.String(),
+		SpanID:            span.Context.SpanID.String(),
+		Name:              span.Name,
+		Kind:              span.Kind,
+		StartTimeUnixNano: strconv.FormatInt(span.StartTime.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+		Status:            otlpStatus{Code: otlpStatusCodeUnset},
+	}
+
+	if span.ParentSpanID.IsValid() {
+		otlpSpan.ParentSpanID = span.ParentSpanID.String()
+	}
+
+	for _, attribute := range span.Attributes {
+		otlpSpan.Attributes = append(otlpSpan.Attributes, buildOTLPAttribute(attribute))
+	}
+
+	if span.Error != "" {
+		otlpSpan.Status = otlpStatus{Code: otlpStatusCodeError, Message: span.Error}
+	}
+
+	return otlpSpan
+}
+
+func buildOTLPAttribute(attribute Attribute) otlpAttribute {
+	var value otlpAnyValue
+
+	switch v := attribute.Value.(type) {
+	case bool:
+		value.BoolValue = &v
+	case int:
+		intValue := strconv.Itoa(v)
+		value.IntValue = &intValue
+	case int64:
+		intValue := strconv.FormatInt(v, 10)
+		value.IntValue = &intValue
+	case string:
+		value.StringValue = &v
+	default:
+		stringValue := fmt.Sprintf("%v", v)
+		value.StringValue = &stringValue
+	}
+
+	return otlpAttribute{Key: attribute.Key, Value: value}
+}
@@ -0,0 +1,111 @@
+package tracing_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/tracing"
+
+	"github.com/cf-platform-eng/cloudformation-broker/tracing/fakes"
+	"github.com/pivotal-golang/lager/lagertest"
+)
+
+var _ = Describe("OTLPExporter", func() {
+	var (
+		collector      *httptest.Server
+		collectorPath  string
+		collectorType  string
+		collectorBody  []byte
+		collectorReply int
+		exporter       *OTLPExporter
+		spans          []*Span
+	)
+
+	BeforeEach(func() {
+		collectorReply = http.StatusOK
+		collector = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			collectorPath = req.URL.Path
+			collectorType = req.Header.Get("Content-Type")
+			collectorBody, _ = ioutil.ReadAll(req.Body)
+			w.WriteHeader(collectorReply)
+		}))
+		exporter = NewOTLPExporter(collector.URL+"/", "test-service")
+
+		fakeExporter := &fakes.FakeExporter{}
+		tracer := NewTracer(fakeExporter, time.Hour, lagertest.NewTestLogger("tracing"))
+		parent := tracer.StartSpan("parent", SpanKindServer, SpanContext{})
+		span := tracer.StartSpan("child", SpanKindClient, parent.Context)
+		span.SetAttribute("string", "value")
+		span.SetAttribute("int", 200)
+		span.SetAttribute("bool", true)
+		span.SetError(errors.New("operation failed"))
+		span.End()
+		parent.End()
+		tracer.Flush()
+		spans = fakeExporter.ExportSpans
+	})
+
+	AfterEach(func() {
+		collector.Close()
+	})
+
+	It("posts the spans to the collector traces endpoint", func() {
+		err := exporter.Export(spans)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(collectorPath).To(Equal("/v1/traces"))
+		Expect(collectorType).To(Equal("application/json"))
+	})
+
+	It("encodes the spans as OTLP JSON", func() {
+		exporter.Export(spans)
+
+		var request map[string]interface{}
+		Expect(json.Unmarshal(collectorBody, &request)).To(Succeed())
+
+		resourceSpans := request["resourceSpans"].([]interface{})[0].(map[string]interface{})
+		Expect(resourceSpans["resource"]).To(Equal(map[string]interface{}{
+			"attributes": []interface{}{
+				map[string]interface{}{"key": "service.name", "value": map[string]interface{}{"stringValue": "test-service"}},
+			},
+		}))
+
+		otlpSpans := resourceSpans["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})
+		Expect(otlpSpans).To(HaveLen(2))
+
+		child := otlpSpans[0].(map[string]interface{})
+		parent := otlpSpans[1].(map[string]interface{})
+		Expect(child["name"]).To(Equal("child"))
+		Expect(child["kind"]).To(Equal(float64(SpanKindClient)))
+		Expect(child["traceId"]).To(Equal(spans[0].Context.TraceID.String()))
+		Expect(child["spanId"]).To(Equal(spans[0].Context.SpanID.String()))
+		Expect(child["parentSpanId"]).To(Equal(parent["spanId"]))
+		Expect(child["startTimeUnixNano"]).To(MatchRegexp(`^\d+$`))
+		Expect(child["attributes"]).To(Equal([]interface{}{
+			map[string]interface{}{"key": "string", "value": map[string]interface{}{"stringValue": "value"}},
+			map[string]interface{}{"key": "int", "value": map[string]interface{}{"intValue": "200"}},
+			map[string]interface{}{"key": "bool", "value": map[string]interface{}{"boolValue": true}},
+		}))
+		Expect(child["status"]).To(Equal(map[string]interface{}{"code": float64(2), "message": "operation failed"}))
+		Expect(parent).ToNot(HaveKey("parentSpanId"))
+		Expect(parent["status"]).To(Equal(map[string]interface{}{"code": float64(0)}))
+	})
+
+	Context("when the collector rejects the spans", func() {
+		BeforeEach(func() {
+			collectorReply = http.StatusBadRequest
+		})
+
+		It("returns the proper error", func() {
+			err := exporter.Export(spans)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("returned status 400"))
+		})
+	})
+})
@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// TraceparentHeader carries the W3C Trace Context of a request.
+const TraceparentHeader = "traceparent"
+
+const traceparentVersion = "00"
+const sampledFlag = 0x01
+
+// ParseTraceparent extracts the span context of a W3C traceparent header
+// value. It returns false when the value is missing or malformed, in which
+// case the request starts a new trace.
+func ParseTraceparent(traceparent string) (SpanContext, bool) {
+	var spanContext SpanContext
+
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 {
+		return spanContext, false
+	}
+
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+
+	// Future versions may append fields, but version ff is forbidden
+	if len(version) != 2 || version == "ff" || (version == traceparentVersion && len(parts) != 4) {
+		return spanContext, false
+	}
+
+	if !decodeHex(traceID, spanContext.TraceID[:]) || !decodeHex(spanID, spanContext.SpanID[:]) {
+		return spanContext, false
+	}
+
+	var flagBytes [1]byte
+	if !decodeHex(flags, flagBytes[:]) {
+		return spanContext, false
+	}
+	spanContext.Sampled = flagBytes[0]&sampledFlag == sampledFlag
+
+	if !spanContext.IsValid() {
+		return SpanContext{}, false
+	}
+
+	return spanContext, true
+}
+
+// Traceparent formats the span context as a W3C traceparent header value.
+func (c SpanContext) Traceparent() string {
+	var flags byte
+	if c.Sampled {
+		flags = sampledFlag
+	}
+
+	return fmt.Sprintf("%s-%s-%s-%02x", traceparentVersion, c.TraceID, c.SpanID, flags)
+}
+
+// decodeHex decodes a lowercase hex string that exactly fills dst.
+func decodeHex(s string, dst []byte) bool {
+	if len(s) != hex.EncodedLen(len(dst)) || strings.ToLower(s) != s {
+		return false
+	}
+
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
@@ -0,0 +1,58 @@
+package tracing_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/tracing"
+)
+
+var _ = Describe("Propagation", func() {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	Describe("ParseTraceparent", func() {
+		It("returns the span context of the header", func() {
+			spanContext, ok := ParseTraceparent(traceparent)
+			Expect(ok).To(BeTrue())
+			Expect(spanContext.TraceID.String()).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+			Expect(spanContext.SpanID.String()).To(Equal("00f067aa0ba902b7"))
+			Expect(spanContext.Sampled).To(BeTrue())
+		})
+
+		It("returns whether the trace is not sampled", func() {
+			spanContext, ok := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+			Expect(ok).To(BeTrue())
+			Expect(spanContext.Sampled).To(BeFalse())
+		})
+
+		It("accepts fields appended by future versions", func() {
+			_, ok := ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future")
+			Expect(ok).To(BeTrue())
+		})
+
+		invalidTraceparents := map[string]string{
+			"an empty header":     "",
+			"a forbidden version": "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"extra fields":        "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+			"a short trace id":    "00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+			"an uppercase id":     "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+			"a zero trace id":     "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			"a zero span id":      "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+			"invalid flags":       "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-x1",
+		}
+		for description, invalidTraceparent := range invalidTraceparents {
+			invalidTraceparent := invalidTraceparent
+			It("rejects "+description, func() {
+				_, ok := ParseTraceparent(invalidTraceparent)
+				Expect(ok).To(BeFalse())
+			})
+		}
+	})
+
+	Describe("Traceparent", func() {
+		It("formats the span context as a header", func() {
+			spanContext, _ := ParseTraceparent(traceparent)
+			Expect(spanContext.Traceparent()).To(Equal(traceparent))
+		})
+	})
+})
@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"github.com/frodenas/brokerapi"
+)
+
+// ServiceBroker records a span for each operation of the service brokers
+// built by newServiceBroker. Each operation calls a service broker built
+// with the context of its span, so that the calls it makes are traced as
+// its children.
+type ServiceBroker struct {
+	tracer           *Tracer
+	parent           SpanContext
+	newServiceBroker func(SpanContext) brokerapi.ServiceBroker
+}
+
+func NewServiceBroker(tracer *Tracer, parent SpanContext, newServiceBroker func(SpanContext) brokerapi.ServiceBroker) *ServiceBroker {
+	return &ServiceBroker{
+		tracer:           tracer,
+		parent:           parent,
+		newServiceBroker: newServiceBroker,
+	}
+}
+
+func (b *ServiceBroker) Services() brokerapi.CatalogResponse {
+	span := b.startSpan("ServiceBroker.Services", "")
+	defer span.End()
+
+	return b.newServiceBroker(span.Context).Services()
+}
+
+func (b *ServiceBroker) Provision(instanceID string, details brokerapi.ProvisionDetails, acceptsIncomplete bool) (provisioningResponse brokerapi.ProvisioningResponse, asynch bool, err error) {
+	span := b.startSpan("ServiceBroker.Provision", instanceID)
+	defer b.endSpan(span, details.ServiceID, details.PlanID, &err)
+
+	return b.newServiceBroker(span.Context).Provision(instanceID, details, acceptsIncomplete)
+}
+
+func (b *ServiceBroker) Update(instanceID string, details brokerapi.UpdateDetails, acceptsIncomplete bool) (asynch bool, err error) {
+	span := b.startSpan("ServiceBroker.Update", instanceID)
+	defer b.endSpan(span, details.ServiceID, details.PlanID, &err)
+
+	return b.newServiceBroker(span.Context).Update(instanceID, details, acceptsIncomplete)
+}
+
+func (b *ServiceBroker) Deprovision(instanceID string, details brokerapi.DeprovisionDetails, acceptsIncomplete bool) (asynch bool, err error) {
+	span := b.startSpan("ServiceBroker.Deprovision", instanceID)
+	defer b.endSpan(span, details.ServiceID, details.PlanID, &err)
+
+	return b.newServiceBroker(span.Context).Deprovision(instanceID, details, acceptsIncomplete)
+}
+
+func (b *ServiceBroker) Bind(instanceID, bindingID string, details brokerapi.BindDetails) (bindingResponse brokerapi.BindingResponse, err error) {
+	span := b.startSpan("ServiceBroker.Bind", instanceID)
+	span.SetAttribute("binding_id", bindingID)
+	defer b.endSpan(span, details.ServiceID, details.PlanID, &err)
+
+	return b.newServiceBroker(span.Context).Bind(instanceID, bindingID, details)
+}
+
+func (b *ServiceBroker) Unbind(instanceID, bindingID string, details brokerapi.UnbindDetails) (err error) {
+	span := b.startSpan("ServiceBroker.Unbind", instanceID)
+	span.SetAttribute("binding_id", bindingID)
+	defer b.endSpan(span, details.ServiceID, details.PlanID, &err)
+
+	return b.newServiceBroker(span.Context).Unbind(instanceID, bindingID, details)
+}
+
+func (b *ServiceBroker) LastOperation(instanceID string) (lastOperationResponse brokerapi.LastOperationResponse, err error) {
+	span := b.startSpan("ServiceBroker.LastOperation", instanceID)
+	defer b.endSpan(span, "", "", &err)
+
+	lastOperationResponse, err = b.newServiceBroker(span.Context).LastOperation(instanceID)
+	span.SetAttribute("last_operation.state", lastOperationResponse.State)
+
+	return lastOperationResponse, err
+}
+
+func (b *ServiceBroker) startSpan(name string, instanceID string) *Span {
+	span := b.tracer.StartSpan(name, SpanKindInternal, b.parent)
+	if instanceID != "" {
+		span.SetAttribute("instance_id", instanceID)
+	}
+
+	return span
+}
+
+func (b *ServiceBroker) endSpan(span *Span, serviceID string, planID string, err *error) {
+	if serviceID != "" {
+		span.SetAttribute("service_id", serviceID)
+	}
+	if planID != "" {
+		span.SetAttribute("plan_id", planID)
+	}
+	span.SetError(*err)
+	span.End()
+}
@@ -0,0 +1,98 @@
+package tracing_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/tracing"
+
+	"github.com/cf-platform-eng/cloudformation-broker/tracing/fakes"
+	"github.com/frodenas/brokerapi"
+	brokerapifakes "github.com/frodenas/brokerapi/fakes"
+	"github.com/pivotal-golang/lager/lagertest"
+)
+
+var _ = Describe("ServiceBroker", func() {
+	var (
+		exporter          *fakes.FakeExporter
+		tracer            *Tracer
+		parent            *Span
+		fakeServiceBroker *brokerapifakes.FakeServiceBroker
+		operationContext  SpanContext
+		serviceBroker     *ServiceBroker
+	)
+
+	BeforeEach(func() {
+		exporter = &fakes.FakeExporter{}
+		tracer = NewTracer(exporter, time.Hour, lagertest.NewTestLogger("tracing"))
+		parent = tracer.StartSpan("PUT", SpanKindServer, SpanContext{})
+		fakeServiceBroker = &brokerapifakes.FakeServiceBroker{}
+		serviceBroker = NewServiceBroker(tracer, parent.Context, func(spanContext SpanContext) brokerapi.ServiceBroker {
+			operationContext = spanContext
+			return fakeServiceBroker
+		})
+	})
+
+	exportedSpan := func() *Span {
+		tracer.Flush()
+		Expect(exporter.ExportSpans).To(HaveLen(1))
+		return exporter.ExportSpans[0]
+	}
+
+	It("records a span for each operation as a child of the request span", func() {
+		details := brokerapi.UpdateDetails{ServiceID: "service-id", PlanID: "plan-id"}
+		fakeServiceBroker.UpdateAsynch = true
+
+		asynch, err := serviceBroker.Update("instance-id", details, true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(asynch).To(BeTrue())
+		Expect(fakeServiceBroker.UpdateInstanceID).To(Equal("instance-id"))
+		Expect(fakeServiceBroker.UpdateDetails).To(Equal(details))
+
+		span := exportedSpan()
+		Expect(span.Name).To(Equal("ServiceBroker.Update"))
+		Expect(span.Context.TraceID).To(Equal(parent.Context.TraceID))
+		Expect(span.ParentSpanID).To(Equal(parent.Context.SpanID))
+		Expect(span.Attributes).To(Equal([]Attribute{
+			{Key: "instance_id", Value: "instance-id"},
+			{Key: "service_id", Value: "service-id"},
+			{Key: "plan_id", Value: "plan-id"},
+		}))
+		Expect(span.Error).To(BeEmpty())
+	})
+
+	It("calls a service broker built with the context of the operation span", func() {
+		serviceBroker.Services()
+
+		span := exportedSpan()
+		Expect(span.Name).To(Equal("ServiceBroker.Services"))
+		Expect(operationContext).To(Equal(span.Context))
+	})
+
+	It("records the state of the last operation", func() {
+		fakeServiceBroker.LastOperationResponse = brokerapi.LastOperationResponse{State: brokerapi.LastOperationInProgress}
+
+		serviceBroker.LastOperation("instance-id")
+
+		span := exportedSpan()
+		Expect(span.Attributes).To(ContainElement(Attribute{Key: "last_operation.state", Value: brokerapi.LastOperationInProgress}))
+	})
+
+	Context("when the operation fails", func() {
+		BeforeEach(func() {
+			fakeServiceBroker.DeprovisionError = errors.New("deprovision failed")
+		})
+
+		It("marks the span as failed", func() {
+			_, err := serviceBroker.Deprovision("instance-id", brokerapi.DeprovisionDetails{}, true)
+			Expect(err).To(MatchError("deprovision failed"))
+
+			span := exportedSpan()
+			Expect(span.Name).To(Equal("ServiceBroker.Deprovision"))
+			Expect(span.Error).To(Equal("deprovision failed"))
+		})
+	})
+})
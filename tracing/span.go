@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+type SpanKind int
+
+// Span kinds, numbered as in the OpenTelemetry protocol.
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+type TraceID [16]byte
+
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+type SpanID [8]byte
+
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// SpanContext identifies a span within a trace. A zero SpanContext has no
+// parent, and spans started from it begin a new trace.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+func (c SpanContext) IsValid() bool {
+	return c.TraceID.IsValid() && c.SpanID.IsValid()
+}
+
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Span is a timed operation of a trace. Spans are exported by their tracer
+// once ended.
+type Span struct {
+	sync.Mutex
+	tracer       *Tracer
+	Name         string
+	Kind         SpanKind
+	Context      SpanContext
+	ParentSpanID SpanID
+	StartTime    time.Time
+	EndTime      time.Time
+	Attributes   []Attribute
+	Error        string
+	ended        bool
+}
+
+// SetAttribute records a string, integer or boolean attribute of the span.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.Attributes = append(s.Attributes, Attribute{Key: key, Value: value})
+}
+
+// SetError marks the span as failed. A nil error is ignored.
+func (s *Span) SetError(err error) {
+	if err == nil {
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.Error = err.Error()
+}
+
+// End records the end time of the span and queues it for export. Ending a
+// span more than once has no effect.
+func (s *Span) End() {
+	s.Lock()
+	if s.ended {
+		s.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	s.Unlock()
+
+	if s.Context.Sampled {
+		s.tracer.enqueue(s)
+	}
+}
+
+func newTraceID() TraceID {
+	var traceID TraceID
+	rand.Read(traceID[:])
+	return traceID
+}
+
+func newSpanID() SpanID {
+	var spanID SpanID
+	rand.Read(spanID[:])
+	return spanID
+}
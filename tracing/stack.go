@@ -0,0 +1,163 @@
+package tracing
+
+import (
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+)
+
+// Stack records a client span for each call to the stack it wraps, as a
+// child of the span it was built for.
+type Stack struct {
+	stack  awscf.Stack
+	tracer *Tracer
+	parent SpanContext
+}
+
+func NewStack(stack awscf.Stack, tracer *Tracer, parent SpanContext) *Stack {
+	return &Stack{
+		stack:  stack,
+		tracer: tracer,
+		parent: parent,
+	}
+}
+
+func (s *Stack) Describe(stackName string) (stackDetails awscf.StackDetails, err error) {
+	span := s.startSpan("Stack.Describe", stackName)
+	defer s.endSpan(span, &err)
+
+	stackDetails, err = s.stack.Describe(stackName)
+	if err == nil {
+		span.SetAttribute("cloudformation.stack_status", stackDetails.Status.Raw)
+	}
+
+	return stackDetails, err
+}
+
+func (s *Stack) Create(stackName string, stackDetails awscf.StackDetails) (stackID string, err error) {
+	span := s.startSpan("Stack.Create", stackName)
+	defer s.endSpan(span, &err)
+
+	return s.stack.Create(stackName, stackDetails)
+}
+
+func (s *Stack) Modify(stackName string, stackDetails awscf.StackDetails) (err error) {
+	span := s.startSpan("Stack.Modify", stackName)
+	defer s.endSpan(span, &err)
+
+	return s.stack.Modify(stackName, stackDetails)
+}
+
+func (s *Stack) Delete(stackName string) (err error) {
+	span := s.startSpan("Stack.Delete", stackName)
+	defer s.endSpan(span, &err)
+
+	return s.stack.Delete(stackName)
+}
+
+func (s *Stack) ContinueUpdateRollback(stackName string, resourcesToSkip []string) (err error) {
+	span := s.startSpan("Stack.ContinueUpdateRollback", stackName)
+	defer s.endSpan(span, &err)
+
+	return s.stack.ContinueUpdateRollback(stackName, resourcesToSkip)
+}
+
+func (s *Stack) CancelUpdate(stackName string) (err error) {
+	span := s.startSpan("Stack.CancelUpdate", stackName)
+	defer s.endSpan(span, &err)
+
+	return s.stack.CancelUpdate(stackName)
+}
+
+func (s *Stack) ListResources(stackName string) (stackResources []awscf.StackResource, err error) {
+	span := s.startSpan("Stack.ListResources", stackName)
+	defer s.endSpan(span, &err)
+
+	return s.stack.ListResources(stackName)
+}
+
+func (s *Stack) List() (stackSummaries []awscf.StackSummary, err error) {
+	span := s.startSpan("Stack.List", "")
+	defer s.endSpan(span, &err)
+
+	return s.stack.List()
+}
+
+func (s *Stack) DescribeAll() (stacksDetails []awscf.StackDetails, err error) {
+	span := s.startSpan("Stack.DescribeAll", "")
+	defer s.endSpan(span, &err)
+
+	return s.stack.DescribeAll()
+}
+
+func (s *Stack) NoEchoParameters(templateURL string) (noEchoParameters []string, err error) {
+	span := s.startSpan("Stack.NoEchoParameters", "")
+	span.SetAttribute("cloudformation.template_url", templateURL)
+	defer s.endSpan(span, &err)
+
+	return s.stack.NoEchoParameters(templateURL)
+}
+
+func (s *Stack) Events(stackName string) (stackEvents []awscf.StackEvent, err error) {
+	span := s.startSpan("Stack.Events", stackName)
+	defer s.endSpan(span, &err)
+
+	return s.stack.Events(stackName)
+}
+
+func (s *Stack) Template(stackName string) (templateBody string, err error) {
+	span := s.startSpan("Stack.Template", stackName)
+	defer s.endSpan(span, &err)
+
+	return s.stack.Template(stackName)
+}
+
+func (s *Stack) UpdateTags(stackName string, tags map[string]string) (err error) {
+	span := s.startSpan("Stack.UpdateTags", stackName)
+	defer s.endSpan(span, &err)
+
+	return s.stack.UpdateTags(stackName, tags)
+}
+
+func (s *Stack) DeleteRetainingResources(stackName string, resourcesToRetain []string) (err error) {
+	span := s.startSpan("Stack.DeleteRetainingResources", stackName)
+	defer s.endSpan(span, &err)
+
+	return s.stack.DeleteRetainingResources(stackName, resourcesToRetain)
+}
+
+func (s *Stack) DetectDrift(stackName string) (detectionID string, err error) {
+	span := s.startSpan("Stack.DetectDrift", stackName)
+	defer s.endSpan(span, &err)
+
+	return s.stack.DetectDrift(stackName)
+}
+
+func (s *Stack) DriftDetectionStatus(detectionID string) (driftDetection awscf.DriftDetection, err error) {
+	span := s.startSpan("Stack.DriftDetectionStatus", "")
+	span.SetAttribute("cloudformation.drift_detection_id", detectionID)
+	defer s.endSpan(span, &err)
+
+	return s.stack.DriftDetectionStatus(detectionID)
+}
+
+func (s *Stack) ResourceDrifts(stackName string) (resourceDrifts []awscf.ResourceDrift, err error) {
+	span := s.startSpan("Stack.ResourceDrifts", stackName)
+	defer s.endSpan(span, &err)
+
+	return s.stack.ResourceDrifts(stackName)
+}
+
+func (s *Stack) startSpan(name string, stackName string) *Span {
+	span := s.tracer.StartSpan(name, SpanKindClient, s.parent)
+	span.SetAttribute("rpc.system", "aws-api")
+	span.SetAttribute("rpc.service", "CloudFormation")
+	if stackName != "" {
+		span.SetAttribute("cloudformation.stack_name", stackName)
+	}
+
+	return span
+}
+
+func (s *Stack) endSpan(span *Span, err *error) {
+	span.SetError(*err)
+	span.End()
+}
@@ -0,0 +1,77 @@
+package tracing_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/tracing"
+
+	"github.com/cf-platform-eng/cloudformation-broker/awscf"
+	awscffakes "github.com/cf-platform-eng/cloudformation-broker/awscf/fakes"
+	"github.com/cf-platform-eng/cloudformation-broker/tracing/fakes"
+	"github.com/pivotal-golang/lager/lagertest"
+)
+
+var _ = Describe("Stack", func() {
+	var (
+		exporter  *fakes.FakeExporter
+		tracer    *Tracer
+		parent    *Span
+		fakeStack *awscffakes.FakeStack
+		stack     *Stack
+	)
+
+	BeforeEach(func() {
+		exporter = &fakes.FakeExporter{}
+		tracer = NewTracer(exporter, time.Hour, lagertest.NewTestLogger("tracing"))
+		parent = tracer.StartSpan("ServiceBroker.LastOperation", SpanKindInternal, SpanContext{})
+		fakeStack = &awscffakes.FakeStack{}
+		stack = NewStack(fakeStack, tracer, parent.Context)
+	})
+
+	exportedSpan := func() *Span {
+		tracer.Flush()
+		Expect(exporter.ExportSpans).To(HaveLen(1))
+		return exporter.ExportSpans[0]
+	}
+
+	It("records a client span for each call as a child of the operation span", func() {
+		fakeStack.DescribeStackDetails = awscf.StackDetails{Status: awscf.NewStatus("CREATE_COMPLETE", "")}
+
+		stackDetails, err := stack.Describe("stack-name")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stackDetails).To(Equal(fakeStack.DescribeStackDetails))
+		Expect(fakeStack.DescribeStackName).To(Equal("stack-name"))
+
+		span := exportedSpan()
+		Expect(span.Name).To(Equal("Stack.Describe"))
+		Expect(span.Kind).To(Equal(SpanKindClient))
+		Expect(span.Context.TraceID).To(Equal(parent.Context.TraceID))
+		Expect(span.ParentSpanID).To(Equal(parent.Context.SpanID))
+		Expect(span.Attributes).To(Equal([]Attribute{
+			{Key: "rpc.system", Value: "aws-api"},
+			{Key: "rpc.service", Value: "CloudFormation"},
+			{Key: "cloudformation.stack_name", Value: "stack-name"},
+			{Key: "cloudformation.stack_status", Value: "CREATE_COMPLETE"},
+		}))
+	})
+
+	Context("when the call fails", func() {
+		BeforeEach(func() {
+			fakeStack.DeleteError = errors.New("delete failed")
+		})
+
+		It("marks the span as failed", func() {
+			err := stack.Delete("stack-name")
+			Expect(err).To(MatchError("delete failed"))
+			Expect(fakeStack.DeleteStackName).To(Equal("stack-name"))
+
+			span := exportedSpan()
+			Expect(span.Name).To(Equal("Stack.Delete"))
+			Expect(span.Error).To(Equal("delete failed"))
+		})
+	})
+})
@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+)
+
+// maxQueueSize bounds the ended spans waiting to be exported. Spans ended
+// while the queue is full are dropped.
+const maxQueueSize = 2048
+
+// Exporter sends ended spans to a tracing backend.
+type Exporter interface {
+	Export(spans []*Span) error
+}
+
+// Tracer starts spans and exports them in batches, either periodically or
+// when flushed.
+type Tracer struct {
+	sync.Mutex
+	exporter     Exporter
+	queue        []*Span
+	droppedSpans int
+	logger       lager.Logger
+}
+
+func NewTracer(exporter Exporter, exportInterval time.Duration, logger lager.Logger) *Tracer {
+	t := &Tracer{
+		exporter: exporter,
+		logger:   logger.Session("tracer"),
+	}
+
+	go t.exportPeriodically(exportInterval)
+
+	return t
+}
+
+// StartSpan starts a span as a child of parent, or as the root of a new
+// sampled trace when parent is not valid. Spans of traces not sampled by
+// the caller are never exported.
+func (t *Tracer) StartSpan(name string, kind SpanKind, parent SpanContext) *Span {
+	span := &Span{
+		tracer:    t,
+		Name:      name,
+		Kind:      kind,
+		StartTime: time.Now(),
+	}
+
+	if parent.IsValid() {
+		span.Context = SpanContext{TraceID: parent.TraceID, Sampled: parent.Sampled}
+		span.ParentSpanID = parent.SpanID
+	} else {
+		span.Context = SpanContext{TraceID: newTraceID(), Sampled: true}
+	}
+	span.Context.SpanID = newSpanID()
+
+	return span
+}
+
+// Flush exports the spans ended since the last export.
+func (t *Tracer) Flush() error {
+	t.Lock()
+	spans := t.queue
+	droppedSpans := t.droppedSpans
+	t.queue = nil
+	t.droppedSpans = 0
+	t.Unlock()
+
+	if droppedSpans > 0 {
+		t.logger.Info("dropped-spans", lager.Data{"count": droppedSpans})
+	}
+
+	if len(spans) == 0 {
+		return nil
+	}
+
+	return t.exporter.Export(spans)
+}
+
+func (t *Tracer) enqueue(span *Span) {
+	t.Lock()
+	defer t.Unlock()
+
+	if len(t.queue) >= maxQueueSize {
+		t.droppedSpans++
+		return
+	}
+
+	t.queue = append(t.queue, span)
+}
+
+func (t *Tracer) exportPeriodically(exportInterval time.Duration) {
+	for range time.Tick(exportInterval) {
+		if err := t.Flush(); err != nil {
+			t.logger.Error("export-spans", err)
+		}
+	}
+}
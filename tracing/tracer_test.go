@@ -0,0 +1,84 @@
+package tracing_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/tracing"
+
+	"github.com/cf-platform-eng/cloudformation-broker/tracing/fakes"
+	"github.com/pivotal-golang/lager/lagertest"
+)
+
+var _ = Describe("Tracer", func() {
+	var (
+		exporter *fakes.FakeExporter
+		tracer   *Tracer
+	)
+
+	BeforeEach(func() {
+		exporter = &fakes.FakeExporter{}
+		tracer = NewTracer(exporter, time.Hour, lagertest.NewTestLogger("tracing"))
+	})
+
+	Describe("StartSpan", func() {
+		It("starts a new sampled trace without a parent", func() {
+			span := tracer.StartSpan("operation", SpanKindInternal, SpanContext{})
+			Expect(span.Context.IsValid()).To(BeTrue())
+			Expect(span.Context.Sampled).To(BeTrue())
+			Expect(span.ParentSpanID.IsValid()).To(BeFalse())
+		})
+
+		It("continues the trace of the parent", func() {
+			parent := tracer.StartSpan("parent", SpanKindServer, SpanContext{})
+			span := tracer.StartSpan("child", SpanKindInternal, parent.Context)
+			Expect(span.Context.TraceID).To(Equal(parent.Context.TraceID))
+			Expect(span.Context.SpanID).ToNot(Equal(parent.Context.SpanID))
+			Expect(span.ParentSpanID).To(Equal(parent.Context.SpanID))
+		})
+	})
+
+	Describe("Flush", func() {
+		It("exports the ended spans", func() {
+			span := tracer.StartSpan("operation", SpanKindInternal, SpanContext{})
+			span.SetError(errors.New("operation failed"))
+			span.End()
+			tracer.StartSpan("in-progress", SpanKindInternal, SpanContext{})
+
+			err := tracer.Flush()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(exporter.ExportSpans).To(Equal([]*Span{span}))
+			Expect(span.Error).To(Equal("operation failed"))
+			Expect(span.EndTime).ToNot(BeTemporally("<", span.StartTime))
+		})
+
+		It("exports spans only once", func() {
+			span := tracer.StartSpan("operation", SpanKindInternal, SpanContext{})
+			span.End()
+			span.End()
+			tracer.Flush()
+			tracer.Flush()
+
+			Expect(exporter.ExportSpans).To(HaveLen(1))
+		})
+
+		It("does not export spans of traces that are not sampled", func() {
+			parent, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+			tracer.StartSpan("operation", SpanKindInternal, parent).End()
+			tracer.Flush()
+
+			Expect(exporter.ExportCalled).To(BeFalse())
+		})
+
+		It("returns the export error", func() {
+			exporter.ExportError = errors.New("export failed")
+			tracer.StartSpan("operation", SpanKindInternal, SpanContext{}).End()
+
+			err := tracer.Flush()
+			Expect(err).To(MatchError("export failed"))
+		})
+	})
+})
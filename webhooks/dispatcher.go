@@ -0,0 +1,162 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const EventHeader = "X-Broker-Event"
+const SignatureHeader = "X-Broker-Signature"
+
+const DefaultMaxAttempts = 5
+const DefaultRetryInterval = 5 * time.Second
+
+type Dispatcher interface {
+	Dispatch(event Event)
+}
+
+// Webhook is an endpoint notified of the events it subscribed to, or of
+// every event if it did not subscribe to any in particular.
+type Webhook struct {
+	URL    string
+	Secret string
+	Events []string
+}
+
+func (w Webhook) Subscribed(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+
+	for _, event := range w.Events {
+		if event == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of a payload keyed with the
+// webhook secret, as sent in the signature header.
+func (w Webhook) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// HTTPDispatcher posts events to webhooks. Deliveries are retried with an
+// exponential backoff, and the ones that fail every attempt are recorded in
+// the dead letter log so they can be replayed.
+type HTTPDispatcher struct {
+	webhooks         []Webhook
+	httpClient       *http.Client
+	maxAttempts      int
+	retryInterval    time.Duration
+	logger           lager.Logger
+	deadLetterLogger lager.Logger
+}
+
+func NewHTTPDispatcher(
+	webhooks []Webhook,
+	httpClient *http.Client,
+	maxAttempts int,
+	retryInterval time.Duration,
+	logger lager.Logger,
+	deadLetterLogger lager.Logger,
+) *HTTPDispatcher {
+	return &HTTPDispatcher{
+		webhooks:         webhooks,
+		httpClient:       httpClient,
+		maxAttempts:      maxAttempts,
+		retryInterval:    retryInterval,
+		logger:           logger.Session("webhooks"),
+		deadLetterLogger: deadLetterLogger,
+	}
+}
+
+// Dispatch delivers an event to the webhooks subscribed to it in the
+// background, so the broker never waits on a webhook.
+func (d *HTTPDispatcher) Dispatch(event Event) {
+	for _, webhook := range d.webhooks {
+		if webhook.Subscribed(event.Type) {
+			go d.Deliver(webhook, event)
+		}
+	}
+}
+
+// Deliver posts an event to a webhook until it is accepted or the attempts
+// are exhausted, in which case the event is dead lettered.
+func (d *HTTPDispatcher) Deliver(webhook Webhook, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	retryInterval := d.retryInterval
+	for attempt := 1; ; attempt++ {
+		err = d.post(webhook, event.Type, payload)
+		if err == nil {
+			d.logger.Debug("delivered", lager.Data{
+				"url":     webhook.URL,
+				"event":   event.Type,
+				"attempt": attempt,
+			})
+			return nil
+		}
+
+		if attempt >= d.maxAttempts {
+			break
+		}
+
+		d.logger.Info("retry", lager.Data{
+			"url":     webhook.URL,
+			"event":   event.Type,
+			"attempt": attempt,
+			"error":   err.Error(),
+		})
+		time.Sleep(retryInterval)
+		retryInterval *= 2
+	}
+
+	d.deadLetterLogger.Error("webhook-dead-letter", err, lager.Data{
+		"url":      webhook.URL,
+		"attempts": d.maxAttempts,
+		"payload":  event,
+	})
+
+	return err
+}
+
+func (d *HTTPDispatcher) post(webhook Webhook, eventType string, payload []byte) error {
+	req, err := http.NewRequest("POST", webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, eventType)
+	if webhook.Secret != "" {
+		req.Header.Set(SignatureHeader, webhook.Sign(payload))
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("Webhook '%s' returned status code %d", webhook.URL, resp.StatusCode)
+	}
+
+	return nil
+}
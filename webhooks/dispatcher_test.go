@@ -0,0 +1,207 @@
+package webhooks_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/webhooks"
+
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+)
+
+var _ = Describe("HTTPDispatcher", func() {
+	type delivery struct {
+		header http.Header
+		body   []byte
+	}
+
+	var (
+		server        *httptest.Server
+		lock          sync.Mutex
+		deliveries    []delivery
+		responseCodes []int
+
+		webhook          Webhook
+		event            Event
+		deadLetterLogger *lagertest.TestLogger
+
+		dispatcher *HTTPDispatcher
+	)
+
+	received := func() []delivery {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return append([]delivery{}, deliveries...)
+	}
+
+	BeforeEach(func() {
+		deliveries = []delivery{}
+		responseCodes = []int{}
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+
+			body, err := ioutil.ReadAll(r.Body)
+			Expect(err).ToNot(HaveOccurred())
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			deliveries = append(deliveries, delivery{header: r.Header, body: body})
+			responseCode := http.StatusOK
+			if len(responseCodes) > 0 {
+				responseCode = responseCodes[0]
+				responseCodes = responseCodes[1:]
+			}
+			w.WriteHeader(responseCode)
+		}))
+
+		webhook = Webhook{URL: server.URL, Secret: "webhook-secret"}
+		event = Event{
+			Type:       ProvisionSucceeded,
+			InstanceID: "instance-id",
+			ServiceID:  "service-id",
+			PlanID:     "plan-id",
+			State:      "succeeded",
+			Timestamp:  time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC),
+		}
+		deadLetterLogger = lagertest.NewTestLogger("dead-letters")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	JustBeforeEach(func() {
+		dispatcher = NewHTTPDispatcher([]Webhook{webhook}, &http.Client{}, 3, time.Millisecond, lagertest.NewTestLogger("dispatcher"), deadLetterLogger)
+	})
+
+	var _ = Describe("Deliver", func() {
+		It("posts the event", func() {
+			err := dispatcher.Deliver(webhook, event)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(received()).To(HaveLen(1))
+			delivered := received()[0]
+			Expect(delivered.header.Get("Content-Type")).To(Equal("application/json"))
+			Expect(delivered.header.Get(EventHeader)).To(Equal(ProvisionSucceeded))
+			Expect(delivered.body).To(MatchJSON(`{
+				"event": "provision.succeeded",
+				"instance_id": "instance-id",
+				"service_id": "service-id",
+				"plan_id": "plan-id",
+				"state": "succeeded",
+				"timestamp": "2016-01-02T03:04:05Z"
+			}`))
+		})
+
+		It("signs the event with the webhook secret", func() {
+			dispatcher.Deliver(webhook, event)
+
+			delivered := received()[0]
+			Expect(delivered.header.Get(SignatureHeader)).To(HavePrefix("sha256="))
+			Expect(delivered.header.Get(SignatureHeader)).To(Equal(webhook.Sign(delivered.body)))
+			Expect(delivered.header.Get(SignatureHeader)).ToNot(Equal(Webhook{Secret: "other-secret"}.Sign(delivered.body)))
+		})
+
+		Context("when the webhook has no secret", func() {
+			BeforeEach(func() {
+				webhook.Secret = ""
+			})
+
+			It("does not sign the event", func() {
+				dispatcher.Deliver(webhook, event)
+
+				Expect(received()[0].header.Get(SignatureHeader)).To(BeEmpty())
+			})
+		})
+
+		Context("when the webhook fails", func() {
+			BeforeEach(func() {
+				responseCodes = []int{http.StatusInternalServerError, http.StatusBadGateway}
+			})
+
+			It("retries the delivery", func() {
+				err := dispatcher.Deliver(webhook, event)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(received()).To(HaveLen(3))
+				Expect(deadLetterLogger.Logs()).To(BeEmpty())
+			})
+		})
+
+		Context("when the webhook fails every attempt", func() {
+			BeforeEach(func() {
+				responseCodes = []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError}
+			})
+
+			It("returns the proper error", func() {
+				err := dispatcher.Deliver(webhook, event)
+				Expect(err).To(MatchError("Webhook '" + server.URL + "' returned status code 500"))
+				Expect(received()).To(HaveLen(3))
+			})
+
+			It("dead letters the event", func() {
+				dispatcher.Deliver(webhook, event)
+
+				logs := deadLetterLogger.Logs()
+				Expect(logs).To(HaveLen(1))
+				Expect(logs[0].Message).To(Equal("dead-letters.webhook-dead-letter"))
+				Expect(logs[0].LogLevel).To(Equal(lager.ERROR))
+				Expect(logs[0].Data["url"]).To(Equal(server.URL))
+				Expect(logs[0].Data["attempts"]).To(BeNumerically("==", 3))
+
+				payload, err := json.Marshal(logs[0].Data["payload"])
+				Expect(err).ToNot(HaveOccurred())
+				Expect(payload).To(MatchJSON(`{
+					"event": "provision.succeeded",
+					"instance_id": "instance-id",
+					"service_id": "service-id",
+					"plan_id": "plan-id",
+					"state": "succeeded",
+					"timestamp": "2016-01-02T03:04:05Z"
+				}`))
+			})
+		})
+	})
+
+	var _ = Describe("Dispatch", func() {
+		It("delivers the event in the background", func() {
+			dispatcher.Dispatch(event)
+
+			Eventually(received).Should(HaveLen(1))
+		})
+
+		Context("when the webhook did not subscribe to the event", func() {
+			BeforeEach(func() {
+				webhook.Events = []string{ProvisionFailed}
+			})
+
+			It("does not deliver the event", func() {
+				dispatcher.Dispatch(event)
+
+				Consistently(received, 100*time.Millisecond).Should(BeEmpty())
+			})
+		})
+	})
+
+	var _ = Describe("Webhook", func() {
+		It("subscribes to every event by default", func() {
+			Expect(Webhook{}.Subscribed(BindAccepted)).To(BeTrue())
+		})
+
+		It("subscribes to the given events", func() {
+			webhook := Webhook{Events: []string{ProvisionSucceeded, ProvisionFailed}}
+			Expect(webhook.Subscribed(ProvisionFailed)).To(BeTrue())
+			Expect(webhook.Subscribed(BindAccepted)).To(BeFalse())
+		})
+	})
+})
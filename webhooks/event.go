@@ -0,0 +1,63 @@
+package webhooks
+
+import (
+	"time"
+)
+
+const (
+	ProvisionAccepted    = "provision.accepted"
+	ProvisionSucceeded   = "provision.succeeded"
+	ProvisionFailed      = "provision.failed"
+	UpdateAccepted       = "update.accepted"
+	UpdateSucceeded      = "update.succeeded"
+	UpdateFailed         = "update.failed"
+	DeprovisionAccepted  = "deprovision.accepted"
+	DeprovisionSucceeded = "deprovision.succeeded"
+	DeprovisionFailed    = "deprovision.failed"
+	BindAccepted         = "bind.accepted"
+
+	// OperationSucceeded and OperationFailed are sent when the broker
+	// observes an operation it did not accept itself, i.e. one accepted
+	// before the broker was restarted, reaching a terminal state.
+	OperationSucceeded = "operation.succeeded"
+	OperationFailed    = "operation.failed"
+)
+
+var eventTypes = []string{
+	ProvisionAccepted,
+	ProvisionSucceeded,
+	ProvisionFailed,
+	UpdateAccepted,
+	UpdateSucceeded,
+	UpdateFailed,
+	DeprovisionAccepted,
+	DeprovisionSucceeded,
+	DeprovisionFailed,
+	BindAccepted,
+	OperationSucceeded,
+	OperationFailed,
+}
+
+// ValidEventType returns whether webhooks can subscribe to an event type.
+func ValidEventType(eventType string) bool {
+	for _, validEventType := range eventTypes {
+		if eventType == validEventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Event is the payload sent to webhooks on a service instance lifecycle
+// event.
+type Event struct {
+	Type        string    `json:"event"`
+	InstanceID  string    `json:"instance_id"`
+	BindingID   string    `json:"binding_id,omitempty"`
+	ServiceID   string    `json:"service_id,omitempty"`
+	PlanID      string    `json:"plan_id,omitempty"`
+	State       string    `json:"state,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
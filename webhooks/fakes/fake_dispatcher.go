@@ -0,0 +1,34 @@
+package fakes
+
+import (
+	"sync"
+
+	"github.com/cf-platform-eng/cloudformation-broker/webhooks"
+)
+
+type FakeDispatcher struct {
+	sync.Mutex
+
+	DispatchCalled bool
+	DispatchEvents []webhooks.Event
+}
+
+func (f *FakeDispatcher) Dispatch(event webhooks.Event) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.DispatchCalled = true
+	f.DispatchEvents = append(f.DispatchEvents, event)
+}
+
+func (f *FakeDispatcher) EventTypes() []string {
+	f.Lock()
+	defer f.Unlock()
+
+	eventTypes := []string{}
+	for _, event := range f.DispatchEvents {
+		eventTypes = append(eventTypes, event.Type)
+	}
+
+	return eventTypes
+}
@@ -0,0 +1,231 @@
+package webhooks
+
+import (
+	"sync"
+	"time"
+
+	"github.com/frodenas/brokerapi"
+
+	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+)
+
+// ServiceBroker dispatches an event for each provision, update, deprovision
+// and bind request accepted by the service broker it wraps, and another one
+// when a last operation request observes the accepted operation reaching a
+// terminal state.
+type ServiceBroker struct {
+	serviceBroker   brokerapi.ServiceBroker
+	dispatcher      Dispatcher
+	operations      *pendingOperations
+	operationTokens *cfbroker.OperationTokens
+}
+
+func NewServiceBroker(serviceBroker brokerapi.ServiceBroker, dispatcher Dispatcher) *ServiceBroker {
+	return &ServiceBroker{
+		serviceBroker: serviceBroker,
+		dispatcher:    dispatcher,
+		operations:    &pendingOperations{operations: make(map[string]pendingOperation)},
+	}
+}
+
+// WithServiceBroker returns a decorator of another service broker that
+// shares the operations awaiting a terminal state, so it does not dispatch
+// them twice.
+func (b *ServiceBroker) WithServiceBroker(serviceBroker brokerapi.ServiceBroker) *ServiceBroker {
+	decorator := *b
+	decorator.serviceBroker = serviceBroker
+
+	return &decorator
+}
+
+// WithOperationTokens returns a decorator that keys the operations it
+// accepts by the token issued to them, and only dispatches the outcome of
+// an operation to the last operation requests polling its token. A late
+// poll of an operation a later one has superseded then does not dispatch
+// the outcome of the polled operation as the outcome of the later one.
+func (b *ServiceBroker) WithOperationTokens(operationTokens *cfbroker.OperationTokens) *ServiceBroker {
+	decorator := *b
+	decorator.operationTokens = operationTokens
+
+	return &decorator
+}
+
+func (b *ServiceBroker) Services() brokerapi.CatalogResponse {
+	return b.serviceBroker.Services()
+}
+
+func (b *ServiceBroker) Provision(instanceID string, details brokerapi.ProvisionDetails, acceptsIncomplete bool) (brokerapi.ProvisioningResponse, bool, error) {
+	provisioningResponse, asynch, err := b.serviceBroker.Provision(instanceID, details, acceptsIncomplete)
+	if err == nil {
+		b.accepted(pendingOperation{Operation: "provision", ServiceID: details.ServiceID, PlanID: details.PlanID}, instanceID, asynch)
+	}
+
+	return provisioningResponse, asynch, err
+}
+
+func (b *ServiceBroker) Update(instanceID string, details brokerapi.UpdateDetails, acceptsIncomplete bool) (bool, error) {
+	asynch, err := b.serviceBroker.Update(instanceID, details, acceptsIncomplete)
+	if err == nil {
+		b.accepted(pendingOperation{Operation: "update", ServiceID: details.ServiceID, PlanID: details.PlanID}, instanceID, asynch)
+	}
+
+	return asynch, err
+}
+
+func (b *ServiceBroker) Deprovision(instanceID string, details brokerapi.DeprovisionDetails, acceptsIncomplete bool) (bool, error) {
+	asynch, err := b.serviceBroker.Deprovision(instanceID, details, acceptsIncomplete)
+	if err == nil {
+		b.accepted(pendingOperation{Operation: "deprovision", ServiceID: details.ServiceID, PlanID: details.PlanID}, instanceID, asynch)
+	}
+
+	return asynch, err
+}
+
+func (b *ServiceBroker) Bind(instanceID, bindingID string, details brokerapi.BindDetails) (brokerapi.BindingResponse, error) {
+	bindingResponse, err := b.serviceBroker.Bind(instanceID, bindingID, details)
+	if err == nil {
+		b.dispatcher.Dispatch(Event{
+			Type:       BindAccepted,
+			InstanceID: instanceID,
+			BindingID:  bindingID,
+			ServiceID:  details.ServiceID,
+			PlanID:     details.PlanID,
+			Timestamp:  time.Now(),
+		})
+	}
+
+	return bindingResponse, err
+}
+
+func (b *ServiceBroker) Unbind(instanceID, bindingID string, details brokerapi.UnbindDetails) error {
+	return b.serviceBroker.Unbind(instanceID, bindingID, details)
+}
+
+// LastOperation dispatches the outcome of the operation of an instance the
+// first time it is observed in a terminal state. Operations found in
+// progress without having been accepted by this broker, i.e. before it was
+// restarted, are followed as well.
+func (b *ServiceBroker) LastOperation(instanceID string) (brokerapi.LastOperationResponse, error) {
+	lastOperationResponse, err := b.serviceBroker.LastOperation(instanceID)
+
+	if err == brokerapi.ErrInstanceDoesNotExist {
+		if operation, ok := b.operations.Take(instanceID, b.requestedToken()); ok && operation.Operation == "deprovision" {
+			b.dispatch(operation, instanceID, brokerapi.LastOperationResponse{State: brokerapi.LastOperationSucceeded})
+		}
+		return lastOperationResponse, err
+	}
+	if err != nil {
+		return lastOperationResponse, err
+	}
+
+	switch lastOperationResponse.State {
+	case brokerapi.LastOperationInProgress:
+		b.operations.SetIfAbsent(instanceID, pendingOperation{Token: b.requestedToken()})
+	case brokerapi.LastOperationSucceeded, brokerapi.LastOperationFailed:
+		if operation, ok := b.operations.Take(instanceID, b.requestedToken()); ok {
+			b.dispatch(operation, instanceID, lastOperationResponse)
+		}
+	}
+
+	return lastOperationResponse, err
+}
+
+func (b *ServiceBroker) accepted(operation pendingOperation, instanceID string, asynch bool) {
+	b.dispatcher.Dispatch(Event{
+		Type:       operation.Operation + ".accepted",
+		InstanceID: instanceID,
+		ServiceID:  operation.ServiceID,
+		PlanID:     operation.PlanID,
+		Timestamp:  time.Now(),
+	})
+
+	if !asynch {
+		b.operations.Delete(instanceID)
+		b.dispatch(operation, instanceID, brokerapi.LastOperationResponse{State: brokerapi.LastOperationSucceeded})
+		return
+	}
+
+	if b.operationTokens != nil {
+		operation.Token = b.operationTokens.Issued
+	}
+	b.operations.Set(instanceID, operation)
+}
+
+// requestedToken returns the operation token polled by a last operation
+// request, if any.
+func (b *ServiceBroker) requestedToken() string {
+	if b.operationTokens == nil {
+		return ""
+	}
+
+	return b.operationTokens.Requested
+}
+
+func (b *ServiceBroker) dispatch(operation pendingOperation, instanceID string, lastOperationResponse brokerapi.LastOperationResponse) {
+	eventType := operation.Operation
+	if eventType == "" {
+		eventType = "operation"
+	}
+
+	b.dispatcher.Dispatch(Event{
+		Type:        eventType + "." + lastOperationResponse.State,
+		InstanceID:  instanceID,
+		ServiceID:   operation.ServiceID,
+		PlanID:      operation.PlanID,
+		State:       lastOperationResponse.State,
+		Description: lastOperationResponse.Description,
+		Timestamp:   time.Now(),
+	})
+}
+
+type pendingOperation struct {
+	Operation string
+	ServiceID string
+	PlanID    string
+	Token     string
+}
+
+type pendingOperations struct {
+	sync.Mutex
+	operations map[string]pendingOperation
+}
+
+// Take returns and forgets the pending operation of an instance, so only
+// one of concurrent last operation requests dispatches its outcome. An
+// operation with a token is only taken by the requests polling that token,
+// or no token at all.
+func (o *pendingOperations) Take(instanceID string, token string) (pendingOperation, bool) {
+	o.Lock()
+	defer o.Unlock()
+
+	operation, ok := o.operations[instanceID]
+	if !ok || (operation.Token != "" && token != "" && operation.Token != token) {
+		return pendingOperation{}, false
+	}
+
+	delete(o.operations, instanceID)
+	return operation, true
+}
+
+func (o *pendingOperations) Set(instanceID string, operation pendingOperation) {
+	o.Lock()
+	defer o.Unlock()
+
+	o.operations[instanceID] = operation
+}
+
+func (o *pendingOperations) SetIfAbsent(instanceID string, operation pendingOperation) {
+	o.Lock()
+	defer o.Unlock()
+
+	if _, ok := o.operations[instanceID]; !ok {
+		o.operations[instanceID] = operation
+	}
+}
+
+func (o *pendingOperations) Delete(instanceID string) {
+	o.Lock()
+	defer o.Unlock()
+
+	delete(o.operations, instanceID)
+}
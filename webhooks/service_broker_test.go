@@ -0,0 +1,219 @@
+package webhooks_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/cloudformation-broker/webhooks"
+
+	"github.com/frodenas/brokerapi"
+	brokerfakes "github.com/frodenas/brokerapi/fakes"
+
+	"github.com/cf-platform-eng/cloudformation-broker/cfbroker"
+	"github.com/cf-platform-eng/cloudformation-broker/webhooks/fakes"
+)
+
+var _ = Describe("ServiceBroker", func() {
+	var (
+		fakeServiceBroker *brokerfakes.FakeServiceBroker
+		dispatcher        *fakes.FakeDispatcher
+		serviceBroker     *ServiceBroker
+
+		provisionDetails brokerapi.ProvisionDetails
+	)
+
+	BeforeEach(func() {
+		fakeServiceBroker = &brokerfakes.FakeServiceBroker{
+			ProvisionAsynch:   true,
+			UpdateAsynch:      true,
+			DeprovisionAsynch: true,
+		}
+		dispatcher = &fakes.FakeDispatcher{}
+		provisionDetails = brokerapi.ProvisionDetails{ServiceID: "service-id", PlanID: "plan-id"}
+	})
+
+	JustBeforeEach(func() {
+		serviceBroker = NewServiceBroker(fakeServiceBroker, dispatcher)
+	})
+
+	lastOperation := func(state string) {
+		fakeServiceBroker.LastOperationResponse = brokerapi.LastOperationResponse{State: state, Description: "Stack is " + state}
+		_, err := serviceBroker.LastOperation("instance-id")
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	var _ = Describe("Provision", func() {
+		It("dispatches the accepted request", func() {
+			_, asynch, err := serviceBroker.Provision("instance-id", provisionDetails, true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(asynch).To(BeTrue())
+
+			Expect(dispatcher.DispatchEvents).To(HaveLen(1))
+			event := dispatcher.DispatchEvents[0]
+			Expect(event.Type).To(Equal(ProvisionAccepted))
+			Expect(event.InstanceID).To(Equal("instance-id"))
+			Expect(event.ServiceID).To(Equal("service-id"))
+			Expect(event.PlanID).To(Equal("plan-id"))
+			Expect(event.Timestamp).ToNot(BeZero())
+		})
+
+		It("dispatches the outcome once the operation is observed in a terminal state", func() {
+			serviceBroker.Provision("instance-id", provisionDetails, true)
+			lastOperation(brokerapi.LastOperationInProgress)
+			lastOperation(brokerapi.LastOperationSucceeded)
+			lastOperation(brokerapi.LastOperationSucceeded)
+
+			Expect(dispatcher.EventTypes()).To(Equal([]string{ProvisionAccepted, ProvisionSucceeded}))
+			event := dispatcher.DispatchEvents[1]
+			Expect(event.InstanceID).To(Equal("instance-id"))
+			Expect(event.ServiceID).To(Equal("service-id"))
+			Expect(event.PlanID).To(Equal("plan-id"))
+			Expect(event.State).To(Equal(brokerapi.LastOperationSucceeded))
+			Expect(event.Description).To(Equal("Stack is succeeded"))
+		})
+
+		It("dispatches failed operations", func() {
+			serviceBroker.Provision("instance-id", provisionDetails, true)
+			lastOperation(brokerapi.LastOperationFailed)
+
+			Expect(dispatcher.EventTypes()).To(Equal([]string{ProvisionAccepted, ProvisionFailed}))
+		})
+
+		It("shares the pending operations with its decorators of other service brokers", func() {
+			serviceBroker.Provision("instance-id", provisionDetails, true)
+			fakeServiceBroker.LastOperationResponse = brokerapi.LastOperationResponse{State: brokerapi.LastOperationSucceeded}
+			serviceBroker.WithServiceBroker(fakeServiceBroker).LastOperation("instance-id")
+			serviceBroker.LastOperation("instance-id")
+
+			Expect(dispatcher.EventTypes()).To(Equal([]string{ProvisionAccepted, ProvisionSucceeded}))
+		})
+
+		Context("when the instance is provisioned synchronously", func() {
+			BeforeEach(func() {
+				fakeServiceBroker.ProvisionAsynch = false
+			})
+
+			It("dispatches the accepted request and its outcome", func() {
+				serviceBroker.Provision("instance-id", provisionDetails, false)
+
+				Expect(dispatcher.EventTypes()).To(Equal([]string{ProvisionAccepted, ProvisionSucceeded}))
+			})
+		})
+
+		Context("when the request fails", func() {
+			BeforeEach(func() {
+				fakeServiceBroker.ProvisionError = errors.New("operation failed")
+			})
+
+			It("does not dispatch any event", func() {
+				_, _, err := serviceBroker.Provision("instance-id", provisionDetails, true)
+				Expect(err).To(MatchError("operation failed"))
+				Expect(dispatcher.DispatchCalled).To(BeFalse())
+			})
+		})
+	})
+
+	var _ = Describe("Update", func() {
+		It("dispatches the accepted request and its outcome", func() {
+			_, err := serviceBroker.Update("instance-id", brokerapi.UpdateDetails{ServiceID: "service-id", PlanID: "plan-id"}, true)
+			Expect(err).ToNot(HaveOccurred())
+			lastOperation(brokerapi.LastOperationFailed)
+
+			Expect(dispatcher.EventTypes()).To(Equal([]string{UpdateAccepted, UpdateFailed}))
+		})
+
+		Context("when the operations carry tokens", func() {
+			var operationTokens *cfbroker.OperationTokens
+
+			BeforeEach(func() {
+				operationTokens = &cfbroker.OperationTokens{}
+			})
+
+			JustBeforeEach(func() {
+				serviceBroker = serviceBroker.WithOperationTokens(operationTokens)
+			})
+
+			It("does not dispatch the outcome of a superseded operation as its outcome", func() {
+				operationTokens.Issued = "update-token"
+				_, err := serviceBroker.Update("instance-id", brokerapi.UpdateDetails{ServiceID: "service-id", PlanID: "plan-id"}, true)
+				Expect(err).ToNot(HaveOccurred())
+
+				operationTokens.Requested = "provision-token"
+				lastOperation(brokerapi.LastOperationSucceeded)
+				Expect(dispatcher.EventTypes()).To(Equal([]string{UpdateAccepted}))
+
+				operationTokens.Requested = "update-token"
+				lastOperation(brokerapi.LastOperationFailed)
+				Expect(dispatcher.EventTypes()).To(Equal([]string{UpdateAccepted, UpdateFailed}))
+			})
+
+			It("dispatches the outcome to polls without a token", func() {
+				operationTokens.Issued = "update-token"
+				serviceBroker.Update("instance-id", brokerapi.UpdateDetails{ServiceID: "service-id", PlanID: "plan-id"}, true)
+
+				operationTokens.Requested = ""
+				lastOperation(brokerapi.LastOperationSucceeded)
+				Expect(dispatcher.EventTypes()).To(Equal([]string{UpdateAccepted, UpdateSucceeded}))
+			})
+		})
+	})
+
+	var _ = Describe("Deprovision", func() {
+		It("dispatches the accepted request", func() {
+			_, err := serviceBroker.Deprovision("instance-id", brokerapi.DeprovisionDetails{ServiceID: "service-id", PlanID: "plan-id"}, true)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(dispatcher.EventTypes()).To(Equal([]string{DeprovisionAccepted}))
+		})
+
+		It("dispatches the outcome once the instance is gone", func() {
+			serviceBroker.Deprovision("instance-id", brokerapi.DeprovisionDetails{ServiceID: "service-id", PlanID: "plan-id"}, true)
+			fakeServiceBroker.LastOperationError = brokerapi.ErrInstanceDoesNotExist
+			serviceBroker.LastOperation("instance-id")
+			serviceBroker.LastOperation("instance-id")
+
+			Expect(dispatcher.EventTypes()).To(Equal([]string{DeprovisionAccepted, DeprovisionSucceeded}))
+		})
+	})
+
+	var _ = Describe("Bind", func() {
+		It("dispatches the accepted request", func() {
+			_, err := serviceBroker.Bind("instance-id", "binding-id", brokerapi.BindDetails{ServiceID: "service-id", PlanID: "plan-id"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(dispatcher.DispatchEvents).To(HaveLen(1))
+			Expect(dispatcher.DispatchEvents[0].Type).To(Equal(BindAccepted))
+			Expect(dispatcher.DispatchEvents[0].BindingID).To(Equal("binding-id"))
+		})
+
+		Context("when the request fails", func() {
+			BeforeEach(func() {
+				fakeServiceBroker.BindError = errors.New("operation failed")
+			})
+
+			It("does not dispatch any event", func() {
+				serviceBroker.Bind("instance-id", "binding-id", brokerapi.BindDetails{})
+
+				Expect(dispatcher.DispatchCalled).To(BeFalse())
+			})
+		})
+	})
+
+	var _ = Describe("LastOperation", func() {
+		It("does not dispatch operations it has not seen in progress", func() {
+			lastOperation(brokerapi.LastOperationSucceeded)
+
+			Expect(dispatcher.DispatchCalled).To(BeFalse())
+		})
+
+		It("dispatches the outcome of operations accepted before it was started", func() {
+			lastOperation(brokerapi.LastOperationInProgress)
+			lastOperation(brokerapi.LastOperationSucceeded)
+
+			Expect(dispatcher.EventTypes()).To(Equal([]string{OperationSucceeded}))
+			Expect(dispatcher.DispatchEvents[0].InstanceID).To(Equal("instance-id"))
+		})
+	})
+})